@@ -6,6 +6,7 @@ import (
     "CanMe/backend/core/subtitles"
     "CanMe/backend/pkg/events"
     "CanMe/backend/pkg/logger"
+    "CanMe/backend/pkg/tasklog"
     "CanMe/backend/pkg/websockets"
     "CanMe/backend/types"
     "context"
@@ -13,6 +14,7 @@ import (
     "fmt"
     "path/filepath"
     "strings"
+    "sync"
     "go.uber.org/zap"
 )
 
@@ -22,6 +24,9 @@ type DowntasksAPI struct {
     subs     *subtitles.Service
     eventBus events.EventBus
     ws       *websockets.Service
+
+    // task IDs whose logs are streamed to the client
+    logWatch sync.Map
 }
 
 // normalizeLangCode maps human-readable language names to standard codes and
@@ -167,6 +172,21 @@ installHandler := events.HandlerFunc(func(ctx context.Context, event events.Even
 	api.eventBus.Subscribe(consts.TopicDowntasksInstalling, installHandler)
     api.eventBus.Subscribe(consts.TopicDowntasksCookieSync, cookieSyncHandler)
     api.eventBus.Subscribe(consts.TopicDowntasksStage, stageHandler)
+
+	logHandler := events.HandlerFunc(func(ctx context.Context, event events.Event) error {
+		// only stream lines for tasks the client is currently watching
+		if data, ok := event.GetData().(*tasklog.Line); ok {
+			if _, watching := api.logWatch.Load(data.TaskID); watching {
+				api.ws.SendToClient(types.WSResponse{
+					Namespace: consts.NAMESPACE_DOWNTASKS,
+					Event:     consts.EVENT_DOWNTASKS_LOG,
+					Data:      data,
+				})
+			}
+		}
+		return nil
+	})
+	api.eventBus.Subscribe(consts.TopicDowntasksLog, logHandler)
 }
 
 func (api *DowntasksAPI) GetContent(url string, browser string) (resp *types.JSResp) {
@@ -259,6 +279,220 @@ func (api *DowntasksAPI) DeleteTask(id string) (resp *types.JSResp) {
 	return &types.JSResp{Success: true}
 }
 
+// ResumeTask resumes a task that was paused, e.g. because the disk ran low on space.
+func (api *DowntasksAPI) ResumeTask(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	task, err := api.service.ResumeTask(id)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	taskString, err := json.Marshal(task)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(taskString)}
+}
+
+// CancelTask stops a running task; it ends in the cancelled stage.
+func (api *DowntasksAPI) CancelTask(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	if err := api.service.CancelTask(id); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true}
+}
+
+// GetTaskLog returns the full captured yt-dlp/FFmpeg log of a task.
+func (api *DowntasksAPI) GetTaskLog(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	content, err := api.service.GetTaskLog(id)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: content}
+}
+
+// TailTaskLog returns the last n lines of a task log.
+func (api *DowntasksAPI) TailTaskLog(id string, lines int) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	tail, err := api.service.TailTaskLog(id, lines)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	tailString, err := json.Marshal(tail)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(tailString)}
+}
+
+// WatchTaskLog starts or stops streaming new log lines of a task over WebSocket.
+func (api *DowntasksAPI) WatchTaskLog(id string, watch bool) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	if watch {
+		api.logWatch.Store(id, struct{}{})
+	} else {
+		api.logWatch.Delete(id)
+	}
+
+	return &types.JSResp{Success: true}
+}
+
+// DeleteTaskWithMode deletes a task using the given mode: "record", "files" or "trash".
+func (api *DowntasksAPI) DeleteTaskWithMode(id string, mode string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	if err := api.service.DeleteTaskWithMode(id, types.DtDeleteMode(mode)); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true}
+}
+
+// DeleteTasksByFilter deletes all tasks matching the filter using the given mode.
+func (api *DowntasksAPI) DeleteTasksByFilter(filter types.DtTaskFilter, mode string) (resp *types.JSResp) {
+	result, err := api.service.DeleteTasksByFilter(filter, types.DtDeleteMode(mode))
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	resultString, err := json.Marshal(result)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(resultString)}
+}
+
+func (api *DowntasksAPI) ListTrash() (resp *types.JSResp) {
+	items, err := api.service.ListTrash()
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	itemsString, err := json.Marshal(items)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(itemsString)}
+}
+
+func (api *DowntasksAPI) RestoreTrash(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	task, err := api.service.RestoreTrash(id)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	taskString, err := json.Marshal(task)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(taskString)}
+}
+
+func (api *DowntasksAPI) PurgeTrash(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	if err := api.service.PurgeTrash(id); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true}
+}
+
+func (api *DowntasksAPI) EmptyTrash() (resp *types.JSResp) {
+	if err := api.service.EmptyTrash(); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true}
+}
+
+// VerifyTask re-runs ffprobe verification on a task's output file.
+func (api *DowntasksAPI) VerifyTask(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	task, err := api.service.VerifyTask(id)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	taskString, err := json.Marshal(task)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(taskString)}
+}
+
+// OrganizeTask moves a completed task's files into the configured library layout.
+func (api *DowntasksAPI) OrganizeTask(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	task, err := api.service.OrganizeTask(id)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	taskString, err := json.Marshal(task)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(taskString)}
+}
+
+// OrganizeTasks re-runs the library organizer over completed tasks matching the filter
+// (an empty filter selects every completed task).
+func (api *DowntasksAPI) OrganizeTasks(filter types.DtTaskFilter) (resp *types.JSResp) {
+	result, err := api.service.OrganizeTasks(filter)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	resultString, err := json.Marshal(result)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(resultString)}
+}
+
 func (api *DowntasksAPI) GetFormats() (resp *types.JSResp) {
     // check
     formats := api.service.GetFormats()
@@ -457,3 +691,120 @@ func (api *DowntasksAPI) ImportTaskSubtitleByFile(id string, filename string) (r
     b, _ := json.Marshal(payload)
     return &types.JSResp{Success: true, Data: string(b)}
 }
+
+// GetStatistics aggregates download history by period, extractor, task type and error class.
+func (api *DowntasksAPI) GetStatistics(query types.DtStatsQuery) (resp *types.JSResp) {
+	stats, err := api.service.GetStatistics(query)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	statsString, err := json.Marshal(stats)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(statsString)}
+}
+
+// ListDownloadRules returns all per-domain download rules.
+func (api *DowntasksAPI) ListDownloadRules() (resp *types.JSResp) {
+	rules, err := api.service.ListDownloadRules()
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	rulesString, err := json.Marshal(rules)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(rulesString)}
+}
+
+// SaveDownloadRule creates or updates a per-domain download rule.
+func (api *DowntasksAPI) SaveDownloadRule(rule types.DtDownloadRule) (resp *types.JSResp) {
+	saved, err := api.service.SaveDownloadRule(&rule)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	ruleString, err := json.Marshal(saved)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(ruleString)}
+}
+
+// DeleteDownloadRule removes a per-domain download rule.
+func (api *DowntasksAPI) DeleteDownloadRule(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	if err := api.service.DeleteDownloadRule(id); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true}
+}
+
+// MatchDownloadRule previews which rule applies to a URL; Data is empty when none matches.
+func (api *DowntasksAPI) MatchDownloadRule(url string) (resp *types.JSResp) {
+	if url == "" {
+		return &types.JSResp{Msg: "URL is required"}
+	}
+
+	rule, err := api.service.MatchDownloadRule(url)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	if rule == nil {
+		return &types.JSResp{Success: true}
+	}
+
+	ruleString, err := json.Marshal(rule)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(ruleString)}
+}
+
+// FindDuplicates reports completed tasks that share a video ID or file content.
+func (api *DowntasksAPI) FindDuplicates() (resp *types.JSResp) {
+	report, err := api.service.FindDuplicates()
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	reportString, err := json.Marshal(report)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(reportString)}
+}
+
+// ResolveDuplicates hard-links or deletes duplicate tasks, keeping the given task.
+func (api *DowntasksAPI) ResolveDuplicates(request types.DtDuplicateResolveRequest) (resp *types.JSResp) {
+	if request.KeepTaskID == "" {
+		return &types.JSResp{Msg: "keepTaskId is required"}
+	}
+	if len(request.TaskIDs) == 0 {
+		return &types.JSResp{Msg: "taskIds is required"}
+	}
+
+	result, err := api.service.ResolveDuplicates(request)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	resultString, err := json.Marshal(result)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(resultString)}
+}
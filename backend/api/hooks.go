@@ -0,0 +1,97 @@
+package api
+
+import (
+	"CanMe/backend/core/hooks"
+	"CanMe/backend/types"
+	"context"
+	"encoding/json"
+)
+
+type HooksAPI struct {
+	ctx     context.Context
+	service *hooks.Service
+}
+
+func NewHooksAPI(service *hooks.Service) *HooksAPI {
+	return &HooksAPI{
+		service: service,
+	}
+}
+
+func (api *HooksAPI) Subscribe(ctx context.Context) {
+	api.ctx = ctx
+}
+
+func (api *HooksAPI) ListHooks() (resp *types.JSResp) {
+	list, err := api.service.ListHooks()
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	listString, err := json.Marshal(list)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(listString)}
+}
+
+func (api *HooksAPI) SaveHook(hook types.TaskHook) (resp *types.JSResp) {
+	saved, err := api.service.SaveHook(&hook)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	hookString, err := json.Marshal(saved)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(hookString)}
+}
+
+func (api *HooksAPI) DeleteHook(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "ID is required"}
+	}
+
+	if err := api.service.DeleteHook(id); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true}
+}
+
+// ListHookExecutions 查询钩子执行记录，hookID/taskID 为空表示不过滤
+func (api *HooksAPI) ListHookExecutions(hookID, taskID string, limit int) (resp *types.JSResp) {
+	list, err := api.service.ListExecutions(hookID, taskID, limit)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	listString, err := json.Marshal(list)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: true, Data: string(listString)}
+}
+
+// TestHook 使用指定任务立即执行一次钩子
+func (api *HooksAPI) TestHook(hookID, taskID string) (resp *types.JSResp) {
+	if hookID == "" || taskID == "" {
+		return &types.JSResp{Msg: "hook ID and task ID are required"}
+	}
+
+	exec, err := api.service.TestHook(hookID, taskID)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	execString, err := json.Marshal(exec)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+
+	return &types.JSResp{Success: exec.Success, Msg: exec.Error, Data: string(execString)}
+}
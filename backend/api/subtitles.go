@@ -92,8 +92,19 @@ func (api *SubtitlesAPI) UpdateExportConfig(id string, config types.ExportConfig
 	return &types.JSResp{Success: true, Data: content}
 }
 
-// ExportSubtitleToFile 导出字幕到文件
-func (api *SubtitlesAPI) ExportSubtitleToFile(id, langCode, targetFormat string) (resp *types.JSResp) {
+// ExportSubtitleToFile 导出字幕到文件；secondLang 非空时导出 langCode 在上方的双语字幕（SRT/VTT/ASS）
+func (api *SubtitlesAPI) ExportSubtitleToFile(id, langCode, targetFormat, secondLang string) (resp *types.JSResp) {
+	languages := []string{langCode}
+	if secondLang != "" {
+		languages = append(languages, secondLang)
+	}
+	return api.exportToFile(id, targetFormat, languages, func() ([]byte, error) {
+		return api.subs.ConvertSubtile(id, langCode, targetFormat, secondLang)
+	})
+}
+
+// exportToFile 选择保存位置后写入 convert 的结果，并检查 languages 的字幕规范
+func (api *SubtitlesAPI) exportToFile(id, targetFormat string, languages []string, convert func() ([]byte, error)) (resp *types.JSResp) {
 	resp = &types.JSResp{Success: false}
 
 	// 1. 获取项目信息用于生成默认文件名
@@ -158,7 +169,7 @@ func (api *SubtitlesAPI) ExportSubtitleToFile(id, langCode, targetFormat string)
 	}
 
 	// 5. 转换字幕内容
-	subtitleData, err := api.subs.ConvertSubtile(id, langCode, targetFormat)
+	subtitleData, err := convert()
 	if err != nil {
 		resp.Msg = err.Error()
 		return
@@ -171,12 +182,21 @@ func (api *SubtitlesAPI) ExportSubtitleToFile(id, langCode, targetFormat string)
 		return
 	}
 
+	// 7. 导出后检查字幕规范，违规项仅作提示
+	violations := []types.GuidelineViolation{}
+	for _, lang := range languages {
+		if found, err := api.subs.CheckGuidelines(id, lang); err == nil {
+			violations = append(violations, found...)
+		}
+	}
+
 	resp.Success = true
 	resp.Msg = "Save success"
 	resp.Data = map[string]any{
-		"filePath":  filePath,
-		"fileName":  filepath.Base(filePath),
-		"cancelled": false,
+		"filePath":   filePath,
+		"fileName":   filepath.Base(filePath),
+		"cancelled":  false,
+		"violations": violations,
 	}
 	return
 }
@@ -350,3 +370,411 @@ func (api *SubtitlesAPI) ZHConvertSubtitle(id string, origin, converterString st
 	}
 	return &types.JSResp{Success: true}
 }
+
+// GetTranslationProviders 获取可用的机器翻译提供商
+func (api *SubtitlesAPI) GetTranslationProviders() (resp *types.JSResp) {
+	contentString, err := json.Marshal(api.subs.GetTranslationProviders())
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true, Data: string(contentString)}
+}
+
+// TranslateSubtitle 机器翻译字幕为新语言，进度通过 subtitle progress 事件推送
+func (api *SubtitlesAPI) TranslateSubtitle(id, origin, targetLang, provider string) (resp *types.JSResp) {
+	task, err := api.subs.TranslateSubtitle(id, origin, targetLang, provider)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	contentString, err := json.Marshal(task)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true, Data: string(contentString)}
+}
+
+// CancelTranslation 取消进行中的机器翻译
+func (api *SubtitlesAPI) CancelTranslation(id, targetLang string) (resp *types.JSResp) {
+	if err := api.subs.CancelTranslation(id, targetLang); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true}
+}
+
+// ListGlossaries 列出术语表，workspace 为空时列出全部
+func (api *SubtitlesAPI) ListGlossaries(workspace string) (resp *types.JSResp) {
+	glossaries, err := api.subs.ListGlossaries(workspace)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	contentString, err := json.Marshal(glossaries)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true, Data: string(contentString)}
+}
+
+// SaveGlossary 创建或更新术语表
+func (api *SubtitlesAPI) SaveGlossary(glossary types.Glossary) (resp *types.JSResp) {
+	saved, err := api.subs.SaveGlossary(&glossary)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	contentString, err := json.Marshal(saved)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true, Data: string(contentString)}
+}
+
+// DeleteGlossary 删除术语表
+func (api *SubtitlesAPI) DeleteGlossary(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "id is empty"}
+	}
+	if err := api.subs.DeleteGlossary(id); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true}
+}
+
+// ImportGlossaryFromFile 从 TBX/CSV 文件导入术语表；glossary.ID 非空时合并到该术语表
+func (api *SubtitlesAPI) ImportGlossaryFromFile(filePath string, glossary types.Glossary) (resp *types.JSResp) {
+	if filePath == "" {
+		return &types.JSResp{Msg: "filePath is empty"}
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	format := types.GlossaryFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(filePath)), "."))
+	if glossary.ID == "" && glossary.Name == "" {
+		glossary.Name = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	}
+	saved, err := api.subs.ImportGlossary(data, format, glossary)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	contentString, err := json.Marshal(saved)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true, Data: string(contentString)}
+}
+
+// ExportGlossaryToFile 导出术语表为 TBX/CSV 文件
+func (api *SubtitlesAPI) ExportGlossaryToFile(id, format string) (resp *types.JSResp) {
+	resp = &types.JSResp{Success: false}
+
+	glossary, err := api.subs.GetGlossary(id)
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	format = strings.ToLower(format)
+	data, err := api.subs.ExportGlossary(id, types.GlossaryFormat(format))
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	filePath, err := runtime.SaveFileDialog(api.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Glossary",
+		DefaultFilename: fmt.Sprintf("%s.%s", glossary.Name, format),
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: fmt.Sprintf("%s Files (*.%s)", strings.ToUpper(format), format),
+				Pattern:     fmt.Sprintf("*.%s", format),
+			},
+		},
+		ShowHiddenFiles: true,
+	})
+	if err != nil {
+		msg := strings.ToLower(err.Error())
+		if strings.Contains(msg, "shellitem") || strings.Contains(msg, "cancel") {
+			resp.Success = true
+			resp.Data = map[string]any{"filePath": "", "cancelled": true}
+			return
+		}
+		resp.Msg = err.Error()
+		return
+	}
+	if filePath == "" {
+		resp.Success = true
+		resp.Data = map[string]any{"filePath": "", "cancelled": true}
+		return
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	resp.Data = map[string]any{"filePath": filePath, "cancelled": false}
+	return
+}
+
+// ApproveSegments 审核通过译文并写入翻译记忆，segmentIDs 为空时审核全部片段
+func (api *SubtitlesAPI) ApproveSegments(id, origin, targetLang string, segmentIDs []string) (resp *types.JSResp) {
+	project, err := api.subs.ApproveSegments(id, origin, targetLang, segmentIDs)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	contentString, err := json.Marshal(project)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true, Data: string(contentString)}
+}
+
+// ListTMEntries 列出翻译记忆，参数为空表示不限
+func (api *SubtitlesAPI) ListTMEntries(workspace, sourceLang, targetLang string) (resp *types.JSResp) {
+	entries, err := api.subs.ListTMEntries(workspace, sourceLang, targetLang)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	contentString, err := json.Marshal(entries)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true, Data: string(contentString)}
+}
+
+// DeleteTMEntry 删除翻译记忆条目
+func (api *SubtitlesAPI) DeleteTMEntry(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "id is empty"}
+	}
+	if err := api.subs.DeleteTMEntry(id); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true}
+}
+
+// LookupTM 在项目工作区的翻译记忆中查找匹配（编辑译文时提示），未命中时 Data 为 null
+func (api *SubtitlesAPI) LookupTM(id, sourceLang, targetLang, text string) (resp *types.JSResp) {
+	match, err := api.subs.LookupTM(id, sourceLang, targetLang, text)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	contentString, err := json.Marshal(match)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true, Data: string(contentString)}
+}
+
+// ApplyTimingOperation 执行项目级时间轴操作（偏移、两点拉伸、帧率转换）
+func (api *SubtitlesAPI) ApplyTimingOperation(id string, op types.TimingOperation) (resp *types.JSResp) {
+	project, err := api.subs.ApplyTimingOperation(id, op)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	contentString, err := json.Marshal(project)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true, Data: string(contentString)}
+}
+
+// SplitSegment 拆分片段，所有语言同步切分
+func (api *SubtitlesAPI) SplitSegment(id, segmentID string, req types.SegmentSplitRequest) (resp *types.JSResp) {
+	return projectResp(api.subs.SplitSegment(id, segmentID, req))
+}
+
+// MergeSegments 合并相邻片段
+func (api *SubtitlesAPI) MergeSegments(id string, segmentIDs []string) (resp *types.JSResp) {
+	return projectResp(api.subs.MergeSegments(id, segmentIDs))
+}
+
+// InsertSegment 插入片段
+func (api *SubtitlesAPI) InsertSegment(id string, req types.SegmentInsertRequest) (resp *types.JSResp) {
+	return projectResp(api.subs.InsertSegment(id, req))
+}
+
+// DeleteSegments 删除片段
+func (api *SubtitlesAPI) DeleteSegments(id string, segmentIDs []string) (resp *types.JSResp) {
+	return projectResp(api.subs.DeleteSegments(id, segmentIDs))
+}
+
+func projectResp(project *types.SubtitleProject, err error) *types.JSResp {
+	return jsonResp(project, err)
+}
+
+// jsonResp 将结果序列化为 JSON 字符串返回
+func jsonResp[T any](data T, err error) *types.JSResp {
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	contentString, err := json.Marshal(data)
+	if err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true, Data: string(contentString)}
+}
+
+// UndoSubtitleEdit 撤销最近一次片段编辑
+func (api *SubtitlesAPI) UndoSubtitleEdit(id string) (resp *types.JSResp) {
+	return projectResp(api.subs.Undo(id))
+}
+
+// RedoSubtitleEdit 重做最近一次撤销的编辑
+func (api *SubtitlesAPI) RedoSubtitleEdit(id string) (resp *types.JSResp) {
+	return projectResp(api.subs.Redo(id))
+}
+
+// ListEditHistory 列出项目的编辑操作日志
+func (api *SubtitlesAPI) ListEditHistory(id string) (resp *types.JSResp) {
+	return jsonResp(api.subs.ListEditHistory(id))
+}
+
+// CreateSnapshot 为项目当前状态创建命名快照
+func (api *SubtitlesAPI) CreateSnapshot(id, name string) (resp *types.JSResp) {
+	return jsonResp(api.subs.CreateSnapshot(id, name))
+}
+
+// ListSnapshots 列出项目快照
+func (api *SubtitlesAPI) ListSnapshots(id string) (resp *types.JSResp) {
+	return jsonResp(api.subs.ListSnapshots(id))
+}
+
+// DeleteSnapshot 删除快照
+func (api *SubtitlesAPI) DeleteSnapshot(id, snapshotID string) (resp *types.JSResp) {
+	if err := api.subs.DeleteSnapshot(id, snapshotID); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true}
+}
+
+// DiffSnapshots 对比两个快照，快照 ID 为空表示项目当前状态
+func (api *SubtitlesAPI) DiffSnapshots(id, fromSnapshotID, toSnapshotID string, languages []string) (resp *types.JSResp) {
+	return jsonResp(api.subs.DiffSnapshots(id, fromSnapshotID, toSnapshotID, languages))
+}
+
+// DiffProjects 对比两个项目
+func (api *SubtitlesAPI) DiffProjects(fromID, toID string, languages []string) (resp *types.JSResp) {
+	return jsonResp(api.subs.DiffProjects(fromID, toID, languages))
+}
+
+// RestoreSnapshot 从快照恢复整个项目或单个语言（langCode 为空时恢复整个项目）
+func (api *SubtitlesAPI) RestoreSnapshot(id, snapshotID, langCode string) (resp *types.JSResp) {
+	return projectResp(api.subs.RestoreSnapshot(id, snapshotID, langCode))
+}
+
+// AutoFixSubtitle 自动修复字幕，options.dry_run 为 true 时只返回修复报告
+func (api *SubtitlesAPI) AutoFixSubtitle(id string, options types.AutoFixOptions) (resp *types.JSResp) {
+	return jsonResp(api.subs.AutoFix(id, options))
+}
+
+// ListGuidelineProfiles 列出内置与自定义字幕规范
+func (api *SubtitlesAPI) ListGuidelineProfiles() (resp *types.JSResp) {
+	return jsonResp(api.subs.ListGuidelineProfiles())
+}
+
+// SaveGuidelineProfile 创建或更新自定义字幕规范
+func (api *SubtitlesAPI) SaveGuidelineProfile(profile types.GuidelineProfile) (resp *types.JSResp) {
+	return jsonResp(api.subs.SaveGuidelineProfile(&profile))
+}
+
+// DeleteGuidelineProfile 删除自定义字幕规范
+func (api *SubtitlesAPI) DeleteGuidelineProfile(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "id is empty"}
+	}
+	if err := api.subs.DeleteGuidelineProfile(types.GuideLineStandard(id)); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true}
+}
+
+// ApplyGuidelineProfile 为项目语言设置字幕规范并重新评估，langCode 为空时应用到全部语言
+func (api *SubtitlesAPI) ApplyGuidelineProfile(id, langCode, standard string, isKidsContent bool) (resp *types.JSResp) {
+	return projectResp(api.subs.ApplyGuidelineProfile(id, langCode, types.GuideLineStandard(standard), isKidsContent))
+}
+
+// CheckGuidelines 检查字幕是否符合规范，langCode 为空时检查全部语言
+func (api *SubtitlesAPI) CheckGuidelines(id, langCode string) (resp *types.JSResp) {
+	return jsonResp(api.subs.CheckGuidelines(id, langCode))
+}
+
+// ListQCRuleSets 列出内置与自定义质检规则集
+func (api *SubtitlesAPI) ListQCRuleSets() (resp *types.JSResp) {
+	return jsonResp(api.subs.ListQCRuleSets())
+}
+
+// SaveQCRuleSet 创建或更新自定义质检规则集
+func (api *SubtitlesAPI) SaveQCRuleSet(set types.QCRuleSet) (resp *types.JSResp) {
+	return jsonResp(api.subs.SaveQCRuleSet(&set))
+}
+
+// DeleteQCRuleSet 删除自定义质检规则集
+func (api *SubtitlesAPI) DeleteQCRuleSet(id string) (resp *types.JSResp) {
+	if id == "" {
+		return &types.JSResp{Msg: "id is empty"}
+	}
+	if err := api.subs.DeleteQCRuleSet(id); err != nil {
+		return &types.JSResp{Msg: err.Error()}
+	}
+	return &types.JSResp{Success: true}
+}
+
+// RunQC 质检字幕项目，ruleSetID 为空时使用默认规则集，languages 为空时检查全部语言
+func (api *SubtitlesAPI) RunQC(id, ruleSetID string, languages []string) (resp *types.JSResp) {
+	return jsonResp(api.subs.RunQC(id, ruleSetID, languages))
+}
+
+// ExportQCReportToFile 质检字幕项目并导出 JSON/CSV/HTML 报告
+func (api *SubtitlesAPI) ExportQCReportToFile(id, ruleSetID string, languages []string, format string) (resp *types.JSResp) {
+	resp = &types.JSResp{Success: false}
+
+	format = strings.ToLower(format)
+	data, report, err := api.subs.ExportQCReport(id, ruleSetID, languages, types.QCReportFormat(format))
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+
+	name := report.ProjectName
+	if name == "" {
+		name = "Subtile_Project"
+	}
+	filePath, err := runtime.SaveFileDialog(api.ctx, runtime.SaveDialogOptions{
+		Title:           "Export QC Report",
+		DefaultFilename: fmt.Sprintf("%s.qc.%s", name, format),
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: fmt.Sprintf("%s Files (*.%s)", strings.ToUpper(format), format),
+				Pattern:     fmt.Sprintf("*.%s", format),
+			},
+		},
+		ShowHiddenFiles: true,
+	})
+	if err != nil {
+		msg := strings.ToLower(err.Error())
+		if strings.Contains(msg, "shellitem") || strings.Contains(msg, "cancel") {
+			resp.Success = true
+			resp.Data = map[string]any{"filePath": "", "cancelled": true}
+			return
+		}
+		resp.Msg = err.Error()
+		return
+	}
+	if filePath == "" {
+		resp.Success = true
+		resp.Data = map[string]any{"filePath": "", "cancelled": true}
+		return
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Success = true
+	resp.Data = map[string]any{
+		"filePath":  filePath,
+		"cancelled": false,
+		"passed":    report.Passed,
+		"summary":   report.Summary,
+	}
+	return
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"CanMe/backend/core/downtasks"
+	"CanMe/backend/core/subtitles"
+	"CanMe/backend/pkg/downinfo"
+	"CanMe/backend/pkg/events"
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/pkg/proxy"
+	"CanMe/backend/services/preferences"
+	"CanMe/backend/storage"
+	"CanMe/backend/types"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// downloader 命令行用到的下载服务方法，测试中以桩实现替换
+type downloader interface {
+	Download(request *types.DtDownloadRequest) (*types.DtDownloadResponse, error)
+	QuickDownload(request *types.DtQuickDownloadRequest) (*types.DtQuickDownloadResponse, error)
+	GetTaskStatus(id string) (*types.DtTaskStatus, error)
+	FilterTasks(filter types.DtTaskFilter) []*types.DtTaskStatus
+	ListDependencies() (map[types.DependencyType]*types.DependencyInfo, error)
+	CheckDependencyUpdates() (map[types.DependencyType]*types.DependencyInfo, error)
+	InstallDependency(depType types.DependencyType, config types.DownloadConfig) (*types.DependencyInfo, error)
+	Close() error
+}
+
+// App 命令行运行环境：与桌面端相同的服务装配方式，但不启动 Wails
+type App struct {
+	ctx    context.Context
+	stdout io.Writer
+	stderr io.Writer
+
+	// 全局选项
+	jsonOutput bool
+	verbose    bool
+
+	prefs     *preferences.Service
+	storage   *storage.BoltStorage
+	eventBus  events.EventBus
+	downtasks downloader
+	subtitles *subtitles.Service
+}
+
+func newApp(ctx context.Context, stdout, stderr io.Writer) *App {
+	logger.SetConsoleWriter(io.Discard)
+	return &App{ctx: ctx, stdout: stdout, stderr: stderr}
+}
+
+// open 初始化偏好设置、数据库与各服务（与 main.go 保持一致）
+func (a *App) open() error {
+	if a.downtasks != nil {
+		return nil
+	}
+
+	// 控制台日志不写入标准输出，避免与命令输出（尤其是 JSON）混在一起
+	if a.verbose {
+		logger.SetConsoleWriter(a.stderr)
+	}
+	a.prefs = preferences.New()
+
+	boltStorage, err := storage.NewBoltStorage()
+	if err != nil {
+		if errors.Is(err, bbolt.ErrTimeout) {
+			return fmt.Errorf("database is locked, please quit the CanMe desktop app first")
+		}
+		return fmt.Errorf("open database: %w", err)
+	}
+	a.storage = boltStorage
+
+	a.eventBus = events.NewEventBus(events.DefaultEventBusOptions())
+	proxyManager := proxy.NewManager(proxy.DefaultConfig(), a.eventBus)
+	downloadClient := downinfo.NewClient(downinfo.DefaultConfig())
+	a.prefs.SetPackageClients(proxyManager, downloadClient)
+
+	downtasksService := downtasks.NewService(a.eventBus, proxyManager, downloadClient, a.prefs, boltStorage)
+	a.subtitles = subtitles.NewService(boltStorage, proxyManager, a.eventBus, a.prefs)
+
+	a.prefs.SetContext(a.ctx)
+	proxyManager.SetContext(a.ctx)
+	downtasksService.SetContext(a.ctx)
+	a.subtitles.SetContext(a.ctx)
+	a.downtasks = downtasksService
+	return nil
+}
+
+// close 持久化任务并释放数据库文件锁
+func (a *App) close() {
+	if a.downtasks != nil {
+		if err := a.downtasks.Close(); err != nil {
+			logger.Error("Error closing downtasks service", zap.Error(err))
+		}
+	}
+	if a.storage != nil {
+		if err := a.storage.Close(); err != nil {
+			logger.Warn("Error closing bolt storage", zap.Error(err))
+		}
+	}
+	if a.prefs != nil {
+		logger.GetLogger().Sync()
+	}
+}
+
+// isTerminal 判断输出是否为终端（终端下进度行原地刷新）
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,190 @@
+// Package cli 提供不依赖 Wails 的命令行入口，复用下载与字幕服务，
+// 并与桌面端共用同一数据库与偏好设置（桌面端运行时数据库被锁定，命令行会直接报错）。
+package cli
+
+import (
+	"CanMe/backend/consts"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+const usage = `Usage: canme-cli <command> [arguments] [flags]
+
+Commands:
+  download <url>                 download a video and stream progress
+  tasks list                     list download tasks
+  tasks show <id>                show a single task
+  subtitle list                  list subtitle projects
+  subtitle import <file>         import a subtitle file as a project
+  subtitle export <id>           export a subtitle project to a format
+  subtitle convert <file>        convert a subtitle file without keeping a project
+  subtitle timing <id>           shift, stretch or change the frame rate of a project
+  subtitle fix <id>              reflow text and fix durations, gaps and overlaps
+  subtitle check <id>            list cues that break their guideline profile
+  subtitle qc <id>               run delivery QC rules and write a JSON, CSV or HTML report
+  deps list                      list yt-dlp / ffmpeg status
+  deps install <yt-dlp|ffmpeg>   install or update a dependency
+  version                        print the version
+
+Common flags:
+  -json       print machine-readable JSON (progress as JSON lines)
+  -verbose    print service logs to stderr
+
+Run "canme-cli <command> -h" for command flags.
+`
+
+// exitError 携带指定退出码的错误（如下载失败），消息已输出时 msg 为空
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string { return e.msg }
+
+// Run 执行命令行并返回进程退出码
+func Run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	app := newApp(ctx, os.Stdout, os.Stderr)
+	defer app.close()
+
+	var err error
+	switch cmd, rest := args[0], args[1:]; cmd {
+	case "download":
+		err = app.runDownload(rest)
+	case "tasks":
+		err = app.runTasks(rest)
+	case "subtitle", "subtitles":
+		err = app.runSubtitle(rest)
+	case "deps":
+		err = app.runDeps(rest)
+	case "version":
+		fmt.Fprintf(app.stdout, "%s %s\n", consts.APP_NAME, consts.APP_VERSION)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(app.stdout, usage)
+	default:
+		err = usageError("unknown command %q", cmd)
+	}
+	return app.exitCode(err)
+}
+
+func (a *App) exitCode(err error) int {
+	var exit *exitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &exit):
+		if exit.msg != "" {
+			fmt.Fprintln(a.stderr, "error:", exit.msg)
+		}
+		if exit.code == 2 && exit.msg != "" {
+			fmt.Fprint(a.stderr, "\n", usage)
+		}
+		return exit.code
+	case a.ctx.Err() != nil:
+		fmt.Fprintln(a.stderr, "interrupted")
+		return 130
+	default:
+		fmt.Fprintln(a.stderr, "error:", err)
+		return 1
+	}
+}
+
+func usageError(format string, args ...any) error {
+	return &exitError{code: 2, msg: fmt.Sprintf(format, args...)}
+}
+
+// subcommand 拆分子命令与剩余参数，如 "tasks list -json"
+func subcommand(group string, args []string) (string, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", nil, usageError("%s: missing subcommand", group)
+	}
+	return args[0], args[1:], nil
+}
+
+// flagSet 创建注册了全局选项的子命令参数集
+func (a *App) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.BoolVar(&a.jsonOutput, "json", false, "print machine-readable JSON")
+	fs.BoolVar(&a.verbose, "verbose", false, "print service logs to stderr")
+	return fs
+}
+
+// parseFlags 解析参数，允许选项出现在位置参数之后（如 "download <url> -json"）
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &exitError{code: 2}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseArgs 解析参数并检查位置参数个数
+func parseArgs(fs *flag.FlagSet, args []string, want int, names string) ([]string, error) {
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != want {
+		return nil, usageError("%s: expected %s", fs.Name(), names)
+	}
+	return positional, nil
+}
+
+// printJSON 输出缩进的 JSON
+func (a *App) printJSON(v any) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printJSONLine 输出单行 JSON（用于进度流）
+func (a *App) printJSONLine(v any) error {
+	return json.NewEncoder(a.stdout).Encode(v)
+}
+
+// splitList 解析逗号分隔的列表
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// writeOutput 写入文件；路径为空或 "-" 时写到标准输出
+func (a *App) writeOutput(path string, data []byte) error {
+	if path == "" || path == "-" {
+		_, err := a.stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/pkg/events"
+	"CanMe/backend/types"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubDownloader 记录请求并按 tasks 返回任务状态；创建任务时发布 progress 中的进度事件
+type stubDownloader struct {
+	eventBus events.EventBus
+	tasks    map[string]*types.DtTaskStatus
+	progress []types.DtProgress
+
+	quick  *types.DtQuickDownloadRequest
+	custom *types.DtDownloadRequest
+}
+
+func (d *stubDownloader) publish() {
+	for i := range d.progress {
+		d.eventBus.Publish(context.Background(), &events.BaseEvent{Type: consts.TopicDowntasksProgress, Data: &d.progress[i]})
+	}
+}
+
+func (d *stubDownloader) Download(request *types.DtDownloadRequest) (*types.DtDownloadResponse, error) {
+	d.custom = request
+	d.publish()
+	return &types.DtDownloadResponse{ID: "t1"}, nil
+}
+
+func (d *stubDownloader) QuickDownload(request *types.DtQuickDownloadRequest) (*types.DtQuickDownloadResponse, error) {
+	d.quick = request
+	d.publish()
+	return &types.DtQuickDownloadResponse{ID: "t1"}, nil
+}
+
+func (d *stubDownloader) GetTaskStatus(id string) (*types.DtTaskStatus, error) {
+	if task, ok := d.tasks[id]; ok {
+		return task, nil
+	}
+	return nil, fmt.Errorf("task not found: %s", id)
+}
+
+func (d *stubDownloader) FilterTasks(types.DtTaskFilter) []*types.DtTaskStatus { return nil }
+
+func (d *stubDownloader) ListDependencies() (map[types.DependencyType]*types.DependencyInfo, error) {
+	return nil, nil
+}
+
+func (d *stubDownloader) CheckDependencyUpdates() (map[types.DependencyType]*types.DependencyInfo, error) {
+	return nil, nil
+}
+
+func (d *stubDownloader) InstallDependency(types.DependencyType, types.DownloadConfig) (*types.DependencyInfo, error) {
+	return nil, errors.New("not supported")
+}
+
+func (d *stubDownloader) Close() error { return nil }
+
+// newTestApp 返回使用桩服务的 App，open 不会再初始化真实服务
+func newTestApp(t *testing.T) (*App, *stubDownloader, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	a := newApp(context.Background(), &stdout, &stderr)
+	a.eventBus = events.NewEventBus(events.DefaultEventBusOptions())
+	stub := &stubDownloader{eventBus: a.eventBus, tasks: map[string]*types.DtTaskStatus{}}
+	a.downtasks = stub
+	return a, stub, &stdout, &stderr
+}
+
+func TestParseFlags(t *testing.T) {
+	a, _, _, _ := newTestApp(t)
+	fs := a.flagSet("download")
+	format := fs.String("format", "", "")
+
+	// 选项可以出现在位置参数之后
+	positional, err := parseFlags(fs, []string{"https://a", "-format", "audio", "extra", "-json"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://a", "extra"}, positional)
+	assert.Equal(t, "audio", *format)
+	assert.True(t, a.jsonOutput)
+
+	_, err = parseArgs(a.flagSet("show"), []string{"a", "b"}, 1, "a task ID")
+	assert.EqualError(t, err, "show: expected a task ID")
+
+	_, err = parseFlags(a.flagSet("download"), []string{"-h"})
+	assert.ErrorIs(t, err, flag.ErrHelp)
+}
+
+func TestUsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(a *App) error
+		msg  string
+	}{
+		{"sub-langs without custom", func(a *App) error { return a.runDownload([]string{"https://a", "-sub-langs", "en"}) },
+			"download: -sub-langs, -sub-format and -translate-to require -custom"},
+		{"translate-to without custom", func(a *App) error { return a.runDownload([]string{"https://a", "-translate-to", "zh"}) },
+			"download: -sub-langs, -sub-format and -translate-to require -custom"},
+		{"missing url", func(a *App) error { return a.runDownload(nil) }, "download: expected a URL"},
+		{"missing subcommand", func(a *App) error { return a.runTasks([]string{"-json"}) }, "tasks: missing subcommand"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, stub, _, stderr := newTestApp(t)
+			err := tt.run(a)
+			assert.EqualError(t, err, tt.msg)
+			assert.Nil(t, stub.quick)
+			assert.Nil(t, stub.custom)
+
+			// 用法错误以退出码 2 结束，并附带用法说明
+			assert.Equal(t, 2, a.exitCode(err))
+			assert.Contains(t, stderr.String(), "error: "+tt.msg)
+			assert.Contains(t, stderr.String(), "Usage: canme-cli")
+		})
+	}
+
+	// 无法解析的选项由 flag 包输出错误，不再重复用法说明
+	a, _, _, _ := newTestApp(t)
+	err := a.runDownload([]string{"https://a", "-bogus"})
+	assert.Equal(t, 2, a.exitCode(err))
+	assert.Equal(t, 0, a.exitCode(flag.ErrHelp))
+}
+
+func TestDownloadJSONEvents(t *testing.T) {
+	a, stub, stdout, stderr := newTestApp(t)
+	stub.progress = []types.DtProgress{
+		{ID: "other", Stage: types.DtStageDownloading, Percentage: 10},
+		{ID: "t1", Stage: types.DtStageDownloading, Percentage: 50},
+		{ID: "t1", Stage: types.DtStageCompleted, Percentage: 100},
+	}
+	stub.tasks["t1"] = &types.DtTaskStatus{ID: "t1", Stage: types.DtStageCompleted, Title: "Video"}
+
+	require.NoError(t, a.runDownload([]string{"https://a", "-format", "best", "-json"}))
+	assert.Equal(t, "best", stub.quick.Video)
+	assert.Equal(t, consts.TASK_TYPE_QUICK, stub.quick.Type)
+	assert.Empty(t, stderr.String())
+
+	var got []jsonEvent
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		var event jsonEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &event), line)
+		got = append(got, event)
+	}
+	require.Len(t, got, 4)
+	assert.Equal(t, jsonEvent{Event: "created", ID: "t1"}, got[0])
+	assert.Equal(t, "progress", got[1].Event)
+	assert.Equal(t, float64(50), got[1].Progress.Percentage)
+	assert.Equal(t, types.DtStageCompleted, got[2].Progress.Stage)
+	assert.Equal(t, "result", got[3].Event)
+	assert.Equal(t, "Video", got[3].Task.Title)
+}
+
+func TestDownloadFailedExitCode(t *testing.T) {
+	a, stub, stdout, stderr := newTestApp(t)
+	stub.progress = []types.DtProgress{{ID: "t1", Stage: types.DtStageFailed}}
+	stub.tasks["t1"] = &types.DtTaskStatus{ID: "t1", Stage: types.DtStageFailed, URL: "https://a", Error: "boom"}
+
+	err := a.runDownload([]string{"https://a", "-custom", "-sub-langs", "en, zh", "-json"})
+	assert.Equal(t, []string{"en", "zh"}, stub.custom.SubLangs)
+	assert.True(t, stub.custom.DownloadSubs)
+	// JSON 模式下错误已包含在 result 事件中，不再输出到标准错误
+	assert.Equal(t, 1, a.exitCode(err))
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, stdout.String(), `"event":"result"`)
+}
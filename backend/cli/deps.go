@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/types"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+func (a *App) runDeps(args []string) error {
+	sub, rest, err := subcommand("deps", args)
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "list":
+		return a.runDepsList(rest)
+	case "install":
+		return a.runDepsInstall(rest)
+	default:
+		return usageError("deps: unknown subcommand %q", sub)
+	}
+}
+
+func (a *App) runDepsList(args []string) error {
+	fs := a.flagSet("deps list")
+	check := fs.Bool("check", false, "check for newer versions")
+	if _, err := parseArgs(fs, args, 0, "no arguments"); err != nil {
+		return err
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	var deps map[types.DependencyType]*types.DependencyInfo
+	var err error
+	if *check {
+		deps, err = a.downtasks.CheckDependencyUpdates()
+	} else {
+		deps, err = a.downtasks.ListDependencies()
+	}
+	if err != nil {
+		return err
+	}
+	if a.jsonOutput {
+		return a.printJSON(deps)
+	}
+
+	names := make([]string, 0, len(deps))
+	for t := range deps {
+		names = append(names, string(t))
+	}
+	sort.Strings(names)
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tLATEST\tAVAILABLE\tPATH")
+	for _, name := range names {
+		d := deps[types.DependencyType(name)]
+		if d == nil {
+			continue
+		}
+		latest := d.LatestVersion
+		if latest == "" {
+			latest = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\n", name, d.Version, latest, d.Available, d.ExecPath)
+	}
+	return w.Flush()
+}
+
+func (a *App) runDepsInstall(args []string) error {
+	fs := a.flagSet("deps install")
+	version := fs.String("version", "", "version to install (default: latest)")
+	mirror := fs.String("mirror", "", "mirror name")
+	force := fs.Bool("force", false, "reinstall even if up to date")
+	positional, err := parseArgs(fs, args, 1, "yt-dlp or ffmpeg")
+	if err != nil {
+		return err
+	}
+
+	var depType types.DependencyType
+	switch positional[0] {
+	case "yt-dlp", "ytdlp":
+		depType = types.DependencyYTDLP
+	case "ffmpeg":
+		depType = types.DependencyFFmpeg
+	default:
+		return usageError("deps install: unsupported dependency %q", positional[0])
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	// 安装进度与下载进度共用输出格式
+	progress := a.subscribeProgress(consts.TopicDowntasksInstalling)
+	printer := newProgressPrinter(a)
+	stop := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case p := <-progress:
+				printer.print(p)
+			case <-stop:
+				printer.done()
+				return
+			}
+		}
+	}()
+
+	info, err := a.downtasks.InstallDependency(depType, types.DownloadConfig{
+		Version:     *version,
+		Mirror:      *mirror,
+		ForceUpdate: *force,
+		Timeout:     30 * time.Minute,
+	})
+	close(stop)
+	<-finished
+	if err != nil {
+		return err
+	}
+
+	if a.jsonOutput {
+		return a.printJSONLine(info)
+	}
+	fmt.Fprintf(a.stdout, "installed %s %s at %s\n", info.Name, info.Version, info.ExecPath)
+	return nil
+}
@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/pkg/domainrules"
+	"CanMe/backend/pkg/events"
+	"CanMe/backend/types"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// jsonEvent -json 模式下逐行输出的事件
+type jsonEvent struct {
+	Event    string              `json:"event"` // created, progress, result
+	ID       string              `json:"id"`
+	Progress *types.DtProgress   `json:"progress,omitempty"`
+	Task     *types.DtTaskStatus `json:"task,omitempty"`
+	Skipped  bool                `json:"skipped,omitempty"`
+	Dup      string              `json:"duplicateOf,omitempty"`
+}
+
+func (a *App) runDownload(args []string) error {
+	fs := a.flagSet("download")
+	custom := fs.Bool("custom", false, "create a custom task (subtitle languages, translation)")
+	format := fs.String("format", "", "format ID, yt-dlp selector or preset (best, audio, 1080p, ...)")
+	browser := fs.String("browser", "", "browser to read cookies from")
+	subs := fs.Bool("subs", false, "download subtitles")
+	subLangs := fs.String("sub-langs", "", "comma separated subtitle languages (custom only)")
+	subFormat := fs.String("sub-format", "", "subtitle format: srt, vtt or best (custom only)")
+	translateTo := fs.String("translate-to", "", "translate subtitles to this language (custom only)")
+	recode := fs.Int("recode", 0, "conversion format number to recode the video to")
+	noWait := fs.Bool("no-wait", false, "return as soon as the task is created")
+	positional, err := parseArgs(fs, args, 1, "a URL")
+	if err != nil {
+		return err
+	}
+	if !*custom && (*subLangs != "" || *subFormat != "" || *translateTo != "") {
+		return usageError("download: -sub-langs, -sub-format and -translate-to require -custom")
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	// 先订阅再创建任务，避免错过早期进度
+	progress := a.subscribeProgress(consts.TopicDowntasksProgress)
+
+	url := positional[0]
+	var id, duplicateOf string
+	var skipped bool
+	if *custom {
+		resp, err := a.downtasks.Download(&types.DtDownloadRequest{
+			URL:                url,
+			Browser:            *browser,
+			FormatID:           domainrules.FormatSelector(*format),
+			DownloadSubs:       *subs || *subLangs != "" || *translateTo != "",
+			SubLangs:           splitList(*subLangs),
+			SubFormat:          *subFormat,
+			TranslateTo:        *translateTo,
+			RecodeFormatNumber: *recode,
+		})
+		if err != nil {
+			return err
+		}
+		id, duplicateOf, skipped = resp.ID, resp.DuplicateOf, resp.Skipped
+	} else {
+		video := ""
+		if *format != "" {
+			if video = domainrules.FormatSelector(*format); video == "" {
+				video = "best"
+			}
+		}
+		resp, err := a.downtasks.QuickDownload(&types.DtQuickDownloadRequest{
+			URL:                url,
+			Browser:            *browser,
+			Video:              video,
+			BestCaption:        *subs,
+			Type:               consts.TASK_TYPE_QUICK,
+			RecodeFormatNumber: *recode,
+		})
+		if err != nil {
+			return err
+		}
+		id, duplicateOf, skipped = resp.ID, resp.DuplicateOf, resp.Skipped
+	}
+
+	if skipped {
+		task, _ := a.downtasks.GetTaskStatus(id)
+		if a.jsonOutput {
+			return a.printJSONLine(jsonEvent{Event: "result", ID: id, Task: task, Skipped: true, Dup: duplicateOf})
+		}
+		fmt.Fprintf(a.stdout, "already downloaded as task %s, skipped\n", id)
+		return nil
+	}
+	if a.jsonOutput {
+		a.printJSONLine(jsonEvent{Event: "created", ID: id, Dup: duplicateOf})
+	} else {
+		fmt.Fprintf(a.stderr, "task %s created\n", id)
+		if duplicateOf != "" {
+			fmt.Fprintf(a.stderr, "warning: duplicate of completed task %s\n", duplicateOf)
+		}
+	}
+	if *noWait {
+		if !a.jsonOutput {
+			fmt.Fprintln(a.stdout, id)
+		}
+		return nil
+	}
+
+	task, err := a.waitTask(id, progress)
+	if err != nil {
+		return err
+	}
+	return a.printTaskResult(task)
+}
+
+// subscribeProgress 订阅进度事件；通道满时丢弃中间进度，终态由 waitTask 轮询兜底
+func (a *App) subscribeProgress(topic string) <-chan types.DtProgress {
+	ch := make(chan types.DtProgress, 256)
+	a.eventBus.Subscribe(topic, events.HandlerFunc(func(ctx context.Context, event events.Event) error {
+		if data, ok := event.GetData().(*types.DtProgress); ok && data != nil {
+			select {
+			case ch <- *data:
+			default:
+			}
+		}
+		return nil
+	}))
+	return ch
+}
+
+// isFinalStage 命令行不再等待的阶段（暂停的任务需在桌面端或稍后恢复）
+func isFinalStage(stage types.DtTaskStage) bool {
+	switch stage {
+	case types.DtStageCompleted, types.DtStageFailed, types.DtStageCancelled, types.DtStagePaused:
+		return true
+	}
+	return false
+}
+
+// waitTask 输出进度直到任务结束
+func (a *App) waitTask(id string, progress <-chan types.DtProgress) (*types.DtTaskStatus, error) {
+	printer := newProgressPrinter(a)
+	defer printer.done()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.ctx.Done():
+			return nil, a.ctx.Err()
+		case p := <-progress:
+			if p.ID != id {
+				continue
+			}
+			printer.print(p)
+			if !isFinalStage(p.Stage) {
+				continue
+			}
+		case <-ticker.C:
+		}
+		task, err := a.downtasks.GetTaskStatus(id)
+		if err != nil {
+			return nil, err
+		}
+		if isFinalStage(task.Stage) {
+			return task, nil
+		}
+	}
+}
+
+// printTaskResult 输出任务最终结果；未完成时以退出码 1 结束
+func (a *App) printTaskResult(task *types.DtTaskStatus) error {
+	if a.jsonOutput {
+		if err := a.printJSONLine(jsonEvent{Event: "result", ID: task.ID, Task: task}); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(a.stdout, "%s: %s\n", task.Stage, displayTitle(task))
+		for _, f := range task.AllFiles {
+			fmt.Fprintf(a.stdout, "  %s\n", f)
+		}
+	}
+	if task.Stage == types.DtStageCompleted {
+		return nil
+	}
+	msg := ""
+	if !a.jsonOutput {
+		msg = task.Error
+		if task.ErrorInfo != nil && task.ErrorInfo.Action != "" {
+			msg += "\n" + task.ErrorInfo.Action
+		}
+	}
+	return &exitError{code: 1, msg: strings.TrimSpace(msg)}
+}
+
+func displayTitle(task *types.DtTaskStatus) string {
+	if task.Title != "" {
+		return task.Title
+	}
+	return task.URL
+}
+
+// progressPrinter 文本模式下，终端中原地刷新进度行，重定向时仅在阶段变化或每 10% 输出一行
+type progressPrinter struct {
+	app      *App
+	tty      bool
+	lastKey  string
+	lastStep int
+	width    int
+}
+
+func newProgressPrinter(a *App) *progressPrinter {
+	return &progressPrinter{app: a, tty: isTerminal(a.stderr), lastStep: -1}
+}
+
+func (p *progressPrinter) print(progress types.DtProgress) {
+	if p.app.jsonOutput {
+		p.app.printJSONLine(jsonEvent{Event: "progress", ID: progress.ID, Progress: &progress})
+		return
+	}
+
+	label := string(progress.Stage)
+	if progress.SubStage != "" {
+		label += "/" + progress.SubStage
+	}
+	line := fmt.Sprintf("[%s] %5.1f%%", label, progress.Percentage)
+	if progress.Overall > 0 {
+		line += fmt.Sprintf("  overall %5.1f%%", progress.Overall)
+	}
+	if progress.Speed != "" {
+		line += "  " + progress.Speed
+	}
+	if progress.EstimatedTime != "" {
+		line += "  ETA " + progress.EstimatedTime
+	}
+
+	if p.tty {
+		pad := ""
+		if n := p.width - len(line); n > 0 {
+			pad = strings.Repeat(" ", n)
+		}
+		fmt.Fprintf(p.app.stderr, "\r%s%s", line, pad)
+		p.width = len(line)
+		return
+	}
+	step := int(progress.Percentage / 10)
+	if label == p.lastKey && step == p.lastStep {
+		return
+	}
+	p.lastKey, p.lastStep = label, step
+	fmt.Fprintln(p.app.stderr, line)
+}
+
+// done 结束原地刷新的进度行
+func (p *progressPrinter) done() {
+	if p.tty && p.width > 0 {
+		fmt.Fprintln(p.app.stderr)
+		p.width = 0
+	}
+}
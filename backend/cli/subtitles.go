@@ -0,0 +1,472 @@
+package cli
+
+import (
+	"CanMe/backend/pkg/qc"
+	"CanMe/backend/pkg/subtiming"
+	"CanMe/backend/types"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func (a *App) runSubtitle(args []string) error {
+	sub, rest, err := subcommand("subtitle", args)
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "list":
+		return a.runSubtitleList(rest)
+	case "import":
+		return a.runSubtitleImport(rest)
+	case "export":
+		return a.runSubtitleExport(rest)
+	case "convert":
+		return a.runSubtitleConvert(rest)
+	case "timing":
+		return a.runSubtitleTiming(rest)
+	case "fix":
+		return a.runSubtitleFix(rest)
+	case "check":
+		return a.runSubtitleCheck(rest)
+	case "qc":
+		return a.runSubtitleQC(rest)
+	default:
+		return usageError("subtitle: unknown subcommand %q", sub)
+	}
+}
+
+// importFlags 注册导入时的文本处理选项
+func importFlags(fs *flag.FlagSet) *types.TextProcessingOptions {
+	options := &types.TextProcessingOptions{}
+	fs.BoolVar(&options.TrimWhitespace, "trim", true, "trim whitespace")
+	fs.BoolVar(&options.RemoveEmptyLines, "remove-empty", true, "remove empty lines")
+	fs.BoolVar(&options.NormalizeLineBreaks, "normalize", true, "normalize line breaks")
+	fs.BoolVar(&options.FixEncoding, "fix-encoding", false, "fix text encoding")
+	fs.BoolVar(&options.FixCommonErrors, "fix-errors", false, "fix common subtitle errors")
+	fs.BoolVar(&options.ValidateGuidelines, "validate", true, "assess reading speed and line length")
+	fs.BoolVar(&options.IsKidsContent, "kids", false, "use the kids variant of the guideline profile")
+	guidelineFlag(fs, &options.GuidelineStandard)
+	return options
+}
+
+func (a *App) runSubtitleList(args []string) error {
+	fs := a.flagSet("subtitle list")
+	if _, err := parseArgs(fs, args, 0, "no arguments"); err != nil {
+		return err
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	projects, err := a.subtitles.ListSubtitles()
+	if err != nil {
+		return err
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].UpdatedAt > projects[j].UpdatedAt })
+	if a.jsonOutput {
+		return a.printJSON(projects)
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSEGMENTS\tLANGUAGES\tUPDATED\tNAME")
+	for _, p := range projects {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n",
+			p.ID, len(p.Segments), strings.Join(projectLanguages(p), ","), formatUnix(p.UpdatedAt), truncate(projectName(p), 60))
+	}
+	return w.Flush()
+}
+
+func (a *App) runSubtitleImport(args []string) error {
+	fs := a.flagSet("subtitle import")
+	options := importFlags(fs)
+	positional, err := parseArgs(fs, args, 1, "a subtitle file")
+	if err != nil {
+		return err
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	path, err := filepath.Abs(positional[0])
+	if err != nil {
+		return err
+	}
+	project, err := a.subtitles.ImportSubtitle(path, *options)
+	if err != nil {
+		return err
+	}
+	if a.jsonOutput {
+		return a.printJSON(project)
+	}
+	fmt.Fprintf(a.stdout, "imported %s (%d segments, languages: %s)\n",
+		project.ID, len(project.Segments), strings.Join(projectLanguages(project), ","))
+	return nil
+}
+
+func (a *App) runSubtitleExport(args []string) error {
+	fs := a.flagSet("subtitle export")
+	lang := fs.String("lang", "", "language code (required when the project has several)")
+	secondary := fs.String("secondary", "", "second language for a bilingual srt, vtt or ass file, shown below -lang")
+	format := fs.String("format", "srt", "target format: srt, vtt, ass, itt or fcpxml")
+	output := fs.String("o", "", "output file (default: stdout)")
+	positional, err := parseArgs(fs, args, 1, "a project ID")
+	if err != nil {
+		return err
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	project, err := a.subtitles.GetSubtitle(positional[0])
+	if err != nil {
+		return err
+	}
+	if *secondary != "" {
+		return a.exportBilingual(project, *lang, *secondary, *format, *output)
+	}
+	return a.exportProject(project, *lang, *format, *output)
+}
+
+func (a *App) runSubtitleConvert(args []string) error {
+	fs := a.flagSet("subtitle convert")
+	options := importFlags(fs)
+	format := fs.String("to", "", "target format: srt, vtt, ass, itt or fcpxml")
+	output := fs.String("o", "", "output file (default: stdout)")
+	positional, err := parseArgs(fs, args, 1, "a subtitle file")
+	if err != nil {
+		return err
+	}
+	if *format == "" {
+		return usageError("subtitle convert: -to is required")
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	path, err := filepath.Abs(positional[0])
+	if err != nil {
+		return err
+	}
+	// 借助临时项目完成转换，结束后删除，不在字幕列表中留下记录
+	project, err := a.subtitles.ImportSubtitle(path, *options)
+	if err != nil {
+		return err
+	}
+	defer a.subtitles.DeleteSubtitle(project.ID)
+	return a.exportProject(project, "", *format, *output)
+}
+
+func (a *App) runSubtitleTiming(args []string) error {
+	fs := a.flagSet("subtitle timing")
+	offset := fs.Duration("offset", 0, "shift segments by a duration, e.g. 1.5s or -800ms")
+	from := fs.String("from", "", "first segment ID to shift (default: first segment)")
+	to := fs.String("to", "", "last segment ID to shift (default: last segment)")
+	stretch := fs.String("stretch", "", "two sync points old=new, e.g. 10s=12s,1h30m=1h30m9s")
+	fps := fs.String("fps", "", "frame rate conversion from:to, e.g. 23.976:25")
+	positional, err := parseArgs(fs, args, 1, "a project ID")
+	if err != nil {
+		return err
+	}
+
+	var ops []types.TimingOperation
+	if *offset != 0 {
+		ops = append(ops, types.TimingOperation{
+			Type:          types.TimingOperationOffset,
+			OffsetMs:      offset.Milliseconds(),
+			FromSegmentID: *from,
+			ToSegmentID:   *to,
+		})
+	}
+	if *stretch != "" {
+		op, err := parseStretch(*stretch)
+		if err != nil {
+			return usageError("subtitle timing: %v", err)
+		}
+		ops = append(ops, op)
+	}
+	if *fps != "" {
+		fromFPS, toFPS, ok := strings.Cut(*fps, ":")
+		if !ok {
+			return usageError("subtitle timing: -fps must be from:to")
+		}
+		op := types.TimingOperation{Type: types.TimingOperationFrameRate}
+		if op.FromFPS, err = subtiming.ParseFrameRate(fromFPS); err != nil {
+			return usageError("subtitle timing: %v", err)
+		}
+		if op.ToFPS, err = subtiming.ParseFrameRate(toFPS); err != nil {
+			return usageError("subtitle timing: %v", err)
+		}
+		ops = append(ops, op)
+	}
+	if len(ops) != 1 {
+		return usageError("subtitle timing: use exactly one of -offset, -stretch or -fps")
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	project, err := a.subtitles.ApplyTimingOperation(positional[0], ops[0])
+	if err != nil {
+		return err
+	}
+	applied := project.Metadata.TimingHistory[len(project.Metadata.TimingHistory)-1]
+	if a.jsonOutput {
+		return a.printJSON(applied)
+	}
+	fmt.Fprintf(a.stdout, "applied %s to %d segments of %s\n", applied.Type, applied.Segments, project.ID)
+	return nil
+}
+
+func (a *App) runSubtitleFix(args []string) error {
+	fs := a.flagSet("subtitle fix")
+	dryRun := fs.Bool("dry-run", false, "only report the changes")
+	only := fs.String("only", "", "comma-separated fixes: reflow,extend,split,gap,overlap,merge (default: all)")
+	var options types.AutoFixOptions
+	fs.IntVar(&options.MaxLines, "max-lines", 0, "maximum lines per cue (default 2)")
+	fs.IntVar(&options.MaxCPL, "max-cpl", 0, "maximum characters per line (default 42, 16 for CJK)")
+	fs.Int64Var(&options.MinDurationMs, "min-duration", 0, "minimum cue duration in ms (default 833)")
+	fs.Int64Var(&options.MaxDurationMs, "max-duration", 0, "maximum cue duration in ms (default 7000)")
+	fs.IntVar(&options.MinGapFrames, "min-gap", 0, "minimum gap between cues in frames (default 2)")
+	positional, err := parseArgs(fs, args, 1, "a project ID")
+	if err != nil {
+		return err
+	}
+	if *only != "" {
+		valid := []types.AutoFixType{types.AutoFixReflow, types.AutoFixExtend, types.AutoFixSplit,
+			types.AutoFixGap, types.AutoFixOverlap, types.AutoFixMerge}
+		for _, name := range strings.Split(*only, ",") {
+			fix := types.AutoFixType(strings.TrimSpace(name))
+			if !slices.Contains(valid, fix) {
+				return usageError("subtitle fix: unknown fix %q", name)
+			}
+			options.Fixes = append(options.Fixes, fix)
+		}
+	}
+	options.DryRun = *dryRun
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	report, err := a.subtitles.AutoFix(positional[0], options)
+	if err != nil {
+		return err
+	}
+	if a.jsonOutput {
+		return a.printJSON(report)
+	}
+	for _, change := range report.Changes {
+		status := ""
+		if change.Unresolved {
+			status = " (unresolved)"
+		}
+		fmt.Fprintf(a.stdout, "%-8s %s  %s-%s -> %s-%s%s\n", change.Type, strings.Join(change.SegmentIDs, ","),
+			formatMs(change.FromStartMs), formatMs(change.FromEndMs), formatMs(change.ToStartMs), formatMs(change.ToEndMs), status)
+	}
+	verb := "applied"
+	if report.DryRun {
+		verb = "would apply"
+	}
+	fmt.Fprintf(a.stdout, "%s %d fixes\n", verb, len(report.Changes))
+	return nil
+}
+
+func (a *App) runSubtitleCheck(args []string) error {
+	fs := a.flagSet("subtitle check")
+	lang := fs.String("lang", "", "language to check (default: all)")
+	positional, err := parseArgs(fs, args, 1, "a project ID")
+	if err != nil {
+		return err
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	violations, err := a.subtitles.CheckGuidelines(positional[0], *lang)
+	if err != nil {
+		return err
+	}
+	if a.jsonOutput {
+		return a.printJSON(violations)
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEGMENT\tLANGUAGE\tPROFILE\tRULE\tVALUE\tLIMIT")
+	for _, v := range violations {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\n", v.SegmentID, v.LangCode, v.Profile, v.Rule, v.Value, v.Limit)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "%d guideline violations\n", len(violations))
+	return nil
+}
+
+func (a *App) runSubtitleQC(args []string) error {
+	fs := a.flagSet("subtitle qc")
+	rules := fs.String("rules", "", "QC rule set ID (default: built-in delivery rules)")
+	langs := fs.String("lang", "", "comma-separated languages to check (default: all)")
+	format := fs.String("format", "", "write the report as json, csv or html instead of a summary")
+	output := fs.String("o", "", "report file (default: stdout)")
+	positional, err := parseArgs(fs, args, 1, "a project ID")
+	if err != nil {
+		return err
+	}
+	var languages []string
+	for _, lang := range strings.Split(*langs, ",") {
+		if lang = strings.TrimSpace(lang); lang != "" {
+			languages = append(languages, lang)
+		}
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	var report *types.QCReport
+	if *format != "" {
+		var data []byte
+		data, report, err = a.subtitles.ExportQCReport(positional[0], *rules, languages, types.QCReportFormat(strings.ToLower(*format)))
+		if err != nil {
+			return err
+		}
+		if err := a.writeOutput(*output, data); err != nil {
+			return err
+		}
+	} else {
+		if report, err = a.subtitles.RunQC(positional[0], *rules, languages); err != nil {
+			return err
+		}
+		if a.jsonOutput {
+			if err := a.printJSON(report); err != nil {
+				return err
+			}
+		} else {
+			w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "#\tTIME\tLANGUAGE\tSEVERITY\tRULE\tISSUE")
+			for _, f := range report.Findings {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", f.Index, qc.FormatTime(f.StartMs), f.LangCode, f.Severity, f.Rule, f.Message)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stdout, "%d errors, %d warnings, %d info\n", report.Summary[types.QCSeverityError],
+				report.Summary[types.QCSeverityWarning], report.Summary[types.QCSeverityInfo])
+		}
+	}
+	if !report.Passed {
+		return fmt.Errorf("qc failed: %d errors", report.Summary[types.QCSeverityError])
+	}
+	return nil
+}
+
+// guidelineFlag 注册 -guideline，取值为内置规范（netflix、bbc、ade）或自定义规范的 ID
+func guidelineFlag(fs *flag.FlagSet, standard *types.GuideLineStandard) {
+	*standard = types.GuideLineStandardNetflix
+	fs.Func("guideline", "guideline profile: netflix, bbc, ade or a custom profile ID (default netflix)", func(value string) error {
+		*standard = types.GuideLineStandard(strings.TrimSpace(value))
+		return nil
+	})
+}
+
+func formatMs(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+// parseStretch 解析 "old=new,old=new" 形式的两个同步点
+func parseStretch(value string) (types.TimingOperation, error) {
+	op := types.TimingOperation{Type: types.TimingOperationStretch}
+	pairs := strings.Split(value, ",")
+	if len(pairs) != 2 {
+		return op, fmt.Errorf("-stretch needs two sync points")
+	}
+	var points []*types.TimingSyncPoint
+	for _, pair := range pairs {
+		oldTime, newTime, ok := strings.Cut(pair, "=")
+		if !ok {
+			return op, fmt.Errorf("invalid sync point %q", pair)
+		}
+		o, err1 := time.ParseDuration(strings.TrimSpace(oldTime))
+		n, err2 := time.ParseDuration(strings.TrimSpace(newTime))
+		if err1 != nil || err2 != nil {
+			return op, fmt.Errorf("invalid sync point %q", pair)
+		}
+		points = append(points, &types.TimingSyncPoint{FromMs: o.Milliseconds(), ToMs: n.Milliseconds()})
+	}
+	op.First, op.Second = points[0], points[1]
+	return op, nil
+}
+
+// exportProject 将项目中的一种语言导出为目标格式
+func (a *App) exportProject(project *types.SubtitleProject, lang, format, output string) error {
+	if lang == "" {
+		langs := projectLanguages(project)
+		if len(langs) != 1 {
+			return fmt.Errorf("project has languages %s, choose one with -lang", strings.Join(langs, ","))
+		}
+		lang = langs[0]
+	}
+	data, err := a.subtitles.ConvertSubtile(project.ID, lang, format, "")
+	if err != nil {
+		return err
+	}
+	if err := a.writeOutput(output, data); err != nil {
+		return err
+	}
+	if violations, err := a.subtitles.CheckGuidelines(project.ID, lang); err == nil && len(violations) > 0 && !a.jsonOutput {
+		fmt.Fprintf(a.stderr, "warning: %d guideline violations in %s\n", len(violations), lang)
+	}
+	if output != "" && output != "-" {
+		if a.jsonOutput {
+			return a.printJSON(map[string]any{"id": project.ID, "language": lang, "format": format, "file": output})
+		}
+		fmt.Fprintf(a.stderr, "exported %s (%s) to %s\n", project.ID, lang, output)
+	}
+	return nil
+}
+
+// exportBilingual 将项目中的两种语言导出为一个双语字幕文件，top 在上方
+func (a *App) exportBilingual(project *types.SubtitleProject, top, bottom, format, output string) error {
+	if top == "" {
+		return fmt.Errorf("-lang is required with -secondary")
+	}
+	data, err := a.subtitles.ConvertSubtile(project.ID, top, format, bottom)
+	if err != nil {
+		return err
+	}
+	if err := a.writeOutput(output, data); err != nil {
+		return err
+	}
+	if !a.jsonOutput {
+		for _, lang := range []string{top, bottom} {
+			if violations, err := a.subtitles.CheckGuidelines(project.ID, lang); err == nil && len(violations) > 0 {
+				fmt.Fprintf(a.stderr, "warning: %d guideline violations in %s\n", len(violations), lang)
+			}
+		}
+	}
+	if output != "" && output != "-" {
+		if a.jsonOutput {
+			return a.printJSON(map[string]any{"id": project.ID, "languages": []string{top, bottom}, "format": format, "file": output})
+		}
+		fmt.Fprintf(a.stderr, "exported %s (%s + %s) to %s\n", project.ID, top, bottom, output)
+	}
+	return nil
+}
+
+func projectLanguages(p *types.SubtitleProject) []string {
+	langs := make([]string, 0, len(p.LanguageMetadata))
+	for code := range p.LanguageMetadata {
+		langs = append(langs, code)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+func projectName(p *types.SubtitleProject) string {
+	if p.ProjectName != "" {
+		return p.ProjectName
+	}
+	return p.Metadata.Name
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"CanMe/backend/types"
+	"fmt"
+	"text/tabwriter"
+	"time"
+)
+
+func (a *App) runTasks(args []string) error {
+	sub, rest, err := subcommand("tasks", args)
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "list":
+		return a.runTasksList(rest)
+	case "show":
+		return a.runTasksShow(rest)
+	default:
+		return usageError("tasks: unknown subcommand %q", sub)
+	}
+}
+
+func (a *App) runTasksList(args []string) error {
+	fs := a.flagSet("tasks list")
+	stages := fs.String("stage", "", "comma separated stages (completed, failed, downloading, ...)")
+	taskTypes := fs.String("type", "", "comma separated task types (custom, quick, mcp)")
+	keyword := fs.String("keyword", "", "match title, URL or uploader")
+	limit := fs.Int("limit", 0, "show at most N tasks (newest first)")
+	if _, err := parseArgs(fs, args, 0, "no arguments"); err != nil {
+		return err
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	filter := types.DtTaskFilter{Types: splitList(*taskTypes), Keyword: *keyword}
+	for _, st := range splitList(*stages) {
+		filter.Stages = append(filter.Stages, types.DtTaskStage(st))
+	}
+	tasks := a.downtasks.FilterTasks(filter)
+	if *limit > 0 && len(tasks) > *limit {
+		tasks = tasks[:*limit]
+	}
+
+	if a.jsonOutput {
+		return a.printJSON(tasks)
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTAGE\tTYPE\tPROGRESS\tCREATED\tTITLE")
+	for _, t := range tasks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.0f%%\t%s\t%s\n",
+			t.ID, t.Stage, t.Type, taskPercentage(t), formatUnix(t.CreatedAt), truncate(displayTitle(t), 60))
+	}
+	return w.Flush()
+}
+
+func (a *App) runTasksShow(args []string) error {
+	fs := a.flagSet("tasks show")
+	positional, err := parseArgs(fs, args, 1, "a task ID")
+	if err != nil {
+		return err
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	task, err := a.downtasks.GetTaskStatus(positional[0])
+	if err != nil {
+		return err
+	}
+	if a.jsonOutput {
+		return a.printJSON(task)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID\t%s\n", task.ID)
+	fmt.Fprintf(w, "Title\t%s\n", task.Title)
+	fmt.Fprintf(w, "URL\t%s\n", task.URL)
+	fmt.Fprintf(w, "Type\t%s\n", task.Type)
+	fmt.Fprintf(w, "Stage\t%s\n", task.Stage)
+	fmt.Fprintf(w, "Progress\t%.1f%%\n", taskPercentage(task))
+	if task.Error != "" {
+		fmt.Fprintf(w, "Error\t%s\n", task.Error)
+	}
+	if task.DuplicateOf != "" {
+		fmt.Fprintf(w, "Duplicate of\t%s\n", task.DuplicateOf)
+	}
+	fmt.Fprintf(w, "Output dir\t%s\n", task.OutputDir)
+	fmt.Fprintf(w, "Created\t%s\n", formatUnix(task.CreatedAt))
+	for i, f := range task.AllFiles {
+		label := ""
+		if i == 0 {
+			label = "Files"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", label, f)
+	}
+	return w.Flush()
+}
+
+// taskPercentage 优先显示加权总进度
+func taskPercentage(t *types.DtTaskStatus) float64 {
+	if t.OverallPercentage > 0 {
+		return t.OverallPercentage
+	}
+	return t.Percentage
+}
+
+func formatUnix(ts int64) string {
+	if ts <= 0 {
+		return "-"
+	}
+	return time.Unix(ts, 0).Format("2006-01-02 15:04")
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
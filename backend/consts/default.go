@@ -30,6 +30,7 @@ const (
 const (
 	WS_PORT         = 34444
 	MCP_SERVER_PORT = 34445
+	LOCAL_API_PORT  = 34446
 )
 
 // HTTP
@@ -19,6 +19,7 @@ const (
     EVENT_DOWNTASKS_INSTALLING  WSResponseEventType = "response_downtasks_installing"
     EVENT_DOWNTASKS_COOKIE_SYNC WSResponseEventType = "response_downtasks_cookie_sync"
     EVENT_DOWNTASKS_STAGE       WSResponseEventType = "response_downtasks_stage"
+    EVENT_DOWNTASKS_LOG         WSResponseEventType = "response_downtasks_log"
 	// SUBTITLE
 	EVENT_SUBTITLE_PROGRESS WSResponseEventType = "response_subtitle_progress"
 )
@@ -31,6 +32,7 @@ const (
     TopicDowntasksInstalling = "downtasks.installing"
     TopicDowntasksCookieSync = "downtasks.cookie_sync"
     TopicDowntasksStage      = "downtasks.stage"
+    TopicDowntasksLog        = "downtasks.log"
     // SUBTITLE
     TopicSubtitleProgress = "subtitle.progress"
 )
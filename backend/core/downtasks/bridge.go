@@ -0,0 +1,130 @@
+package downtasks
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/pkg/domainrules"
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/types"
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// BridgePresets 返回浏览器桥接可用的下载预设
+func (s *Service) BridgePresets() []types.DtBridgePreset {
+	return s.pref.GetBridgeConfig().Presets
+}
+
+// BridgeSend 处理浏览器“发送到 CanMe”：保存该域名的 Cookies 后按预设创建快速或自定义任务
+func (s *Service) BridgeSend(request *types.DtBridgeRequest) (*types.DtBridgeResponse, error) {
+	plan, err := s.planBridge(s.BridgePresets(), request)
+	if err != nil {
+		return nil, err
+	}
+	resp := plan.resp
+
+	logger.Info("bridge request",
+		zap.String("url", request.URL),
+		zap.String("preset", resp.Preset),
+		zap.Int("cookies", resp.Cookies))
+
+	if plan.custom != nil {
+		created, err := s.Download(plan.custom)
+		if err != nil {
+			return nil, err
+		}
+		resp.ID, resp.Status, resp.DuplicateOf, resp.Skipped = created.ID, created.Status, created.DuplicateOf, created.Skipped
+		return resp, nil
+	}
+
+	created, err := s.QuickDownload(plan.quick)
+	if err != nil {
+		return nil, err
+	}
+	resp.ID, resp.Status, resp.DuplicateOf, resp.Skipped = created.ID, created.Status, created.DuplicateOf, created.Skipped
+	return resp, nil
+}
+
+// bridgePlan 桥接请求对应的任务参数，quick 与 custom 二选一
+type bridgePlan struct {
+	resp   *types.DtBridgeResponse
+	quick  *types.DtQuickDownloadRequest
+	custom *types.DtDownloadRequest
+}
+
+// planBridge 选择预设、保存 Cookies 并生成任务参数
+func (s *Service) planBridge(presets []types.DtBridgePreset, request *types.DtBridgeRequest) (*bridgePlan, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request is nil")
+	}
+	rawURL := strings.TrimSpace(request.URL)
+	u, err := neturl.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid url: %s", request.URL)
+	}
+
+	preset, err := findBridgePreset(presets, request.Preset)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &bridgePlan{resp: &types.DtBridgeResponse{Type: preset.Type, Preset: preset.Name}}
+
+	// 未携带 Cookies 时沿用下载规则中的浏览器
+	var browser string
+	if strings.TrimSpace(request.Cookies) != "" {
+		count, err := s.cookieManager.ImportNetscapeCookies(types.DtBridgeBrowser, u.Hostname(), request.Cookies)
+		if err != nil {
+			return nil, fmt.Errorf("save cookies: %w", err)
+		}
+		browser = types.DtBridgeBrowser
+		plan.resp.Cookies = count
+	}
+
+	if preset.Type == consts.TASK_TYPE_CUSTOM {
+		plan.custom = &types.DtDownloadRequest{
+			URL:                rawURL,
+			Browser:            browser,
+			FormatID:           domainrules.FormatSelector(preset.Format),
+			DownloadSubs:       preset.DownloadSubs || len(preset.SubLangs) > 0 || preset.TranslateTo != "",
+			SubLangs:           append([]string{}, preset.SubLangs...),
+			SubFormat:          preset.SubFormat,
+			TranslateTo:        preset.TranslateTo,
+			RecodeFormatNumber: preset.RecodeFormatNumber,
+		}
+		return plan, nil
+	}
+
+	// 快速下载：预设为 best 时显式传 best，避免被下载规则的格式覆盖
+	video := ""
+	if preset.Format != "" {
+		if video = domainrules.FormatSelector(preset.Format); video == "" {
+			video = "best"
+		}
+	}
+	plan.quick = &types.DtQuickDownloadRequest{
+		URL:                rawURL,
+		Browser:            browser,
+		Video:              video,
+		BestCaption:        preset.DownloadSubs,
+		Type:               consts.TASK_TYPE_QUICK,
+		RecodeFormatNumber: preset.RecodeFormatNumber,
+	}
+	return plan, nil
+}
+
+// findBridgePreset 按名称（不区分大小写）查找预设，名称为空时返回第一个
+func findBridgePreset(presets []types.DtBridgePreset, name string) (types.DtBridgePreset, error) {
+	name = strings.TrimSpace(name)
+	if name == "" && len(presets) > 0 {
+		return presets[0], nil
+	}
+	for _, preset := range presets {
+		if strings.EqualFold(preset.Name, name) {
+			return preset, nil
+		}
+	}
+	return types.DtBridgePreset{}, fmt.Errorf("preset not found: %s", name)
+}
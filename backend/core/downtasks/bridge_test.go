@@ -0,0 +1,119 @@
+package downtasks
+
+import (
+	"CanMe/backend/pkg/browercookies"
+	"CanMe/backend/types"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCookies 记录导入请求，其余方法不应被调用
+type fakeCookies struct {
+	browercookies.CookieManager
+	browser, host, data string
+	count               int
+	err                 error
+}
+
+func (f *fakeCookies) ImportNetscapeCookies(browser, host, data string) (int, error) {
+	f.browser, f.host, f.data = browser, host, data
+	return f.count, f.err
+}
+
+const bridgeCookies = ".example.com\tTRUE\t/\tTRUE\t0\tsid\tabc\n"
+
+func TestPlanBridgePresetSelection(t *testing.T) {
+	s := &Service{cookieManager: &fakeCookies{}}
+	presets := types.DefaultBridgePresets()
+
+	// 未指定预设时使用第一个
+	plan, err := s.planBridge(presets, &types.DtBridgeRequest{URL: " https://example.com/v/1 "})
+	require.NoError(t, err)
+	assert.Equal(t, "quick", plan.resp.Preset)
+	require.NotNil(t, plan.quick)
+	assert.Nil(t, plan.custom)
+	assert.Equal(t, "https://example.com/v/1", plan.quick.URL)
+	assert.Equal(t, "best", plan.quick.Video)
+	assert.Empty(t, plan.quick.Browser)
+
+	// 名称不区分大小写
+	plan, err = s.planBridge(presets, &types.DtBridgeRequest{URL: "https://example.com/v/1", Preset: " AUDIO "})
+	require.NoError(t, err)
+	assert.Equal(t, "audio", plan.resp.Preset)
+	assert.Equal(t, "ba/b", plan.quick.Video)
+
+	plan, err = s.planBridge(presets, &types.DtBridgeRequest{URL: "https://example.com/v/1", Preset: "1080p"})
+	require.NoError(t, err)
+	assert.Equal(t, "bv*[height<=1080]+ba/b[height<=1080]", plan.quick.Video)
+
+	// 自定义预设
+	plan, err = s.planBridge(presets, &types.DtBridgeRequest{URL: "https://example.com/v/1", Preset: "subtitles"})
+	require.NoError(t, err)
+	assert.Equal(t, "custom", plan.resp.Type)
+	assert.Nil(t, plan.quick)
+	require.NotNil(t, plan.custom)
+	assert.True(t, plan.custom.DownloadSubs)
+	assert.Equal(t, "srt", plan.custom.SubFormat)
+	assert.Empty(t, plan.custom.FormatID)
+
+	// 指定翻译语言时自动下载字幕
+	plan, err = s.planBridge([]types.DtBridgePreset{{Name: "zh", Type: "custom", TranslateTo: "zh-Hans"}},
+		&types.DtBridgeRequest{URL: "https://example.com/v/1"})
+	require.NoError(t, err)
+	assert.True(t, plan.custom.DownloadSubs)
+	assert.Equal(t, "zh-Hans", plan.custom.TranslateTo)
+}
+
+func TestPlanBridgeErrors(t *testing.T) {
+	cookies := &fakeCookies{count: 1}
+	s := &Service{cookieManager: cookies}
+	presets := types.DefaultBridgePresets()
+
+	_, err := s.planBridge(presets, nil)
+	assert.Error(t, err)
+	for _, url := range []string{"", "example.com/v/1", "ftp://example.com/x", "https://"} {
+		_, err = s.planBridge(presets, &types.DtBridgeRequest{URL: url})
+		assert.ErrorContains(t, err, "invalid url", url)
+	}
+
+	// 预设不存在时不保存 Cookies
+	_, err = s.planBridge(presets, &types.DtBridgeRequest{URL: "https://example.com/v/1", Preset: "4k", Cookies: bridgeCookies})
+	assert.ErrorContains(t, err, "preset not found: 4k")
+	assert.Empty(t, cookies.browser)
+
+	_, err = s.planBridge(nil, &types.DtBridgeRequest{URL: "https://example.com/v/1"})
+	assert.ErrorContains(t, err, "preset not found")
+}
+
+func TestPlanBridgeCookies(t *testing.T) {
+	cookies := &fakeCookies{count: 3}
+	s := &Service{cookieManager: cookies}
+
+	plan, err := s.planBridge(types.DefaultBridgePresets(), &types.DtBridgeRequest{
+		URL:     "https://www.example.com:8443/v/1?x=1",
+		Cookies: bridgeCookies,
+	})
+	require.NoError(t, err)
+	// Cookies 保存在虚拟浏览器 bridge 名下，任务使用该浏览器
+	assert.Equal(t, types.DtBridgeBrowser, cookies.browser)
+	assert.Equal(t, "www.example.com", cookies.host)
+	assert.Equal(t, bridgeCookies, cookies.data)
+	assert.Equal(t, 3, plan.resp.Cookies)
+	assert.Equal(t, types.DtBridgeBrowser, plan.quick.Browser)
+
+	plan, err = s.planBridge(types.DefaultBridgePresets(), &types.DtBridgeRequest{
+		URL:     "https://www.example.com/v/1",
+		Preset:  "subtitles",
+		Cookies: bridgeCookies,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.DtBridgeBrowser, plan.custom.Browser)
+
+	// 保存失败时不创建任务
+	cookies.err = errors.New("no cookies for www.example.com")
+	_, err = s.planBridge(types.DefaultBridgePresets(), &types.DtBridgeRequest{URL: "https://www.example.com/v/1", Cookies: bridgeCookies})
+	assert.ErrorContains(t, err, "save cookies: no cookies")
+}
@@ -0,0 +1,94 @@
+package downtasks
+
+import (
+	"CanMe/backend/types"
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// errTaskCancelled 用户停止任务时作为任务上下文的取消原因
+	errTaskCancelled = errors.New("download cancelled")
+	// errTaskDeleted 运行中的任务被删除，处理流程直接退出，不再写回任务记录
+	errTaskDeleted = errors.New("task deleted")
+)
+
+// taskRun 运行中任务的取消函数
+type taskRun struct {
+	cancel context.CancelCauseFunc
+}
+
+// beginTask 为任务创建可单独取消的上下文，任务处理结束时调用返回的 done
+func (s *Service) beginTask(id string) (ctx context.Context, done func()) {
+	parent := s.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancelCause(parent)
+	run := &taskRun{cancel: cancel}
+	s.running.Store(id, run)
+	return ctx, func() {
+		s.running.CompareAndDelete(id, run)
+		cancel(nil)
+	}
+}
+
+// CancelTask 停止正在运行的任务，任务随后进入 cancelled 阶段（保留已下载的文件）
+func (s *Service) CancelTask(id string) error {
+	if !s.cancelRunning(id, errTaskCancelled) {
+		return fmt.Errorf("task %s is not running", id)
+	}
+	return nil
+}
+
+// cancelRunning 以 cause 取消运行中的任务，任务未在运行时返回 false
+func (s *Service) cancelRunning(id string, cause error) bool {
+	v, ok := s.running.Load(id)
+	if !ok {
+		return false
+	}
+	v.(*taskRun).cancel(cause)
+	return true
+}
+
+// taskStopped 判断任务是否被用户停止或删除（应用退出不算）
+func taskStopped(ctx context.Context) bool {
+	cause := context.Cause(ctx)
+	return errors.Is(cause, errTaskCancelled) || errors.Is(cause, errTaskDeleted)
+}
+
+// finishStopped 任务被停止或删除时结束处理流程并返回 true；删除的任务不再写回记录
+func (s *Service) finishStopped(ctx context.Context, task *types.DtTaskStatus, progressChan ProgressChan) bool {
+	switch cause := context.Cause(ctx); {
+	case errors.Is(cause, errTaskDeleted):
+		return true
+	case errors.Is(cause, errTaskCancelled):
+		s.markTaskCancelled(task, progressChan)
+		return true
+	}
+	return false
+}
+
+// markTaskCancelled 将任务置为已取消并发送 cancelled 进度（触发钩子）
+func (s *Service) markTaskCancelled(task *types.DtTaskStatus, progressChan ProgressChan) {
+	s.appendTaskLog(task.ID, "info", "task cancelled")
+	task.Stage = types.DtStageCancelled
+	task.Error = errTaskCancelled.Error()
+	task.StageInfo = "Cancelled"
+	// 只更新仍存在的记录
+	if s.taskManager.UpdateTaskWith(task.ID, func(t *types.DtTaskStatus) {
+		t.Stage, t.Error, t.StageInfo = task.Stage, task.Error, task.StageInfo
+	}) == nil {
+		return
+	}
+
+	progressChan <- &types.DtProgress{
+		ID:         task.ID,
+		Type:       task.Type,
+		Stage:      types.DtStageCancelled,
+		Error:      errTaskCancelled.Error(),
+		Percentage: task.Percentage,
+		StageInfo:  "Cancelled",
+	}
+}
@@ -0,0 +1,34 @@
+package downtasks
+
+import (
+	"CanMe/backend/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelTask(t *testing.T) {
+	s := duplicateService(t)
+	task := &types.DtTaskStatus{ID: "t1", Stage: types.DtStageDownloading, Percentage: 40}
+	s.taskManager.UpdateTask(task)
+
+	ctx, done := s.beginTask(task.ID)
+	assert.False(t, taskStopped(ctx))
+	require.NoError(t, s.CancelTask(task.ID))
+	assert.True(t, taskStopped(ctx))
+
+	progressChan := make(ProgressChan, 1)
+	assert.True(t, s.finishStopped(ctx, task, progressChan))
+	progress := <-progressChan
+	assert.Equal(t, types.DtStageCancelled, progress.Stage)
+	assert.Equal(t, float64(40), progress.Percentage)
+	assert.Equal(t, types.DtStageCancelled, s.taskManager.GetTask("t1").Stage)
+
+	// 任务结束后不能再取消；其他原因结束的上下文不算用户取消
+	done()
+	assert.ErrorContains(t, s.CancelTask(task.ID), "not running")
+	ctx, done = s.beginTask("t2")
+	done()
+	assert.False(t, taskStopped(ctx))
+}
@@ -0,0 +1,64 @@
+package downtasks
+
+import (
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/pkg/urlcanon"
+	"CanMe/backend/types"
+	"context"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func (s *Service) newURLResolver() *urlcanon.Resolver {
+	return urlcanon.NewResolver(func() *http.Client {
+		if s.proxyManager == nil {
+			return nil
+		}
+		return s.proxyManager.GetHTTPClient()
+	})
+}
+
+// resolveCanonicalURL 返回规范化链接；开启短链接展开时联网解析已知短链接，失败则回退为离线结果
+func (s *Service) resolveCanonicalURL(raw string) string {
+	canonical := urlcanon.Canonicalize(raw)
+	if s.pref == nil || !urlcanon.IsShortLink(raw) {
+		return canonical
+	}
+	config := s.pref.GetURLConfig()
+	if !config.ResolveShortLinks {
+		return canonical
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, time.Duration(config.ResolveTimeoutSec)*time.Second)
+	defer cancel()
+	resolved, err := s.urlResolver.Resolve(ctx, raw)
+	if err != nil {
+		logger.Warn("failed to resolve short link", zap.String("url", raw), zap.Error(err))
+		return canonical
+	}
+	return resolved
+}
+
+// taskCanonicalURL 返回任务的规范化链接（旧任务未记录时离线计算）
+func taskCanonicalURL(task *types.DtTaskStatus) string {
+	if task.CanonicalURL != "" {
+		return task.CanonicalURL
+	}
+	return urlcanon.Canonicalize(task.URL)
+}
+
+// refreshCanonicalURL 在任务开始处理时展开短链接，更新任务的规范化链接
+func (s *Service) refreshCanonicalURL(task *types.DtTaskStatus) {
+	if !urlcanon.IsShortLink(task.URL) {
+		return
+	}
+	canonical := s.resolveCanonicalURL(task.URL)
+	if canonical == task.CanonicalURL {
+		return
+	}
+	s.taskManager.UpdateTaskWith(task.ID, func(t *types.DtTaskStatus) {
+		t.CanonicalURL = canonical
+	})
+}
@@ -0,0 +1,312 @@
+package downtasks
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/pkg/diskspace"
+	"CanMe/backend/pkg/events"
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/types"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lrstanley/go-ytdlp"
+	"go.uber.org/zap"
+)
+
+const (
+	diskCheckInterval     = 5 * time.Second  // 下载过程中检查剩余空间的间隔
+	diskQueuePollInterval = 30 * time.Second // 排队等待空间时的轮询间隔
+)
+
+// diskSpaceError 表示下载目录所在卷空间不足
+type diskSpaceError struct {
+	Dir       string
+	Required  uint64
+	Available uint64
+	During    bool // 下载过程中触发（暂停），否则为预检失败
+}
+
+func (e *diskSpaceError) Error() string {
+	if e.During {
+		return fmt.Sprintf("download paused: free space on %s dropped to %s (reserve %s)",
+			e.Dir, formatBytes(e.Available), formatBytes(e.Required))
+	}
+	return fmt.Sprintf("insufficient disk space on %s: need %s, available %s",
+		e.Dir, formatBytes(e.Required), formatBytes(e.Available))
+}
+
+// estimateDownloadSize 根据已缓存的元数据估算下载大小，merging 表示需要合并音视频流
+func (s *Service) estimateDownloadSize(task *types.DtTaskStatus, request *types.DownloadVideoRequest) (size int64, merging bool) {
+	metadata, ok := s.getCachedMetadata(request.URL)
+	if !ok || metadata == nil {
+		return task.FileSize, false
+	}
+
+	formatID := request.FormatID
+	if formatID == "" && request.Video != "" && request.Video != "best" {
+		formatID = request.Video
+	}
+
+	if formatID != "" {
+		for _, f := range metadata.Formats {
+			if f.FormatID == nil || *f.FormatID != formatID {
+				continue
+			}
+			size = formatSize(f)
+			// 仅有视频流时会追加 bestaudio 并合并
+			if f.VCodec != nil && *f.VCodec != "none" && (f.ACodec == nil || *f.ACodec == "none") {
+				size += bestAudioSize(metadata.Formats)
+				merging = true
+			}
+			return size, merging
+		}
+	}
+
+	if len(metadata.RequestedFormats) > 0 {
+		for _, f := range metadata.RequestedFormats {
+			size += formatSize(f)
+		}
+		return size, len(metadata.RequestedFormats) > 1
+	}
+	if metadata.FileSize != nil {
+		return int64(*metadata.FileSize), false
+	}
+	if metadata.FileSizeApprox != nil {
+		return int64(*metadata.FileSizeApprox), false
+	}
+	return task.FileSize, false
+}
+
+func formatSize(f *ytdlp.ExtractedFormat) int64 {
+	if f == nil {
+		return 0
+	}
+	if f.FileSize != nil {
+		return int64(*f.FileSize)
+	}
+	if f.FileSizeApprox != nil {
+		return int64(*f.FileSizeApprox)
+	}
+	return 0
+}
+
+func bestAudioSize(formats []*ytdlp.ExtractedFormat) int64 {
+	var best int64
+	for _, f := range formats {
+		if f == nil || f.ACodec == nil || *f.ACodec == "none" {
+			continue
+		}
+		if f.VCodec != nil && *f.VCodec != "none" {
+			continue
+		}
+		if size := formatSize(f); size > best {
+			best = size
+		}
+	}
+	return best
+}
+
+// requiredDiskSpace 计算开始下载前需要的可用空间（含保留空间与合并/转码余量）。
+// 快速下载不预先获取元数据，大小未知，此时只检查保留空间，由下载过程中的监控兜底
+func (s *Service) requiredDiskSpace(task *types.DtTaskStatus, request *types.DownloadVideoRequest) uint64 {
+	config := s.pref.GetDiskConfig()
+	reserve := uint64(config.MinFreeMB) * 1024 * 1024
+
+	size, merging := s.estimateDownloadSize(task, request)
+	if size <= 0 {
+		return reserve
+	}
+	need := float64(size)
+	if merging || task.RecodeExtention != "" {
+		need *= config.HeadroomFactor
+	}
+	return reserve + uint64(need)
+}
+
+// checkDiskSpace 预检输出目录的可用空间；无法获取可用空间时放行
+func (s *Service) checkDiskSpace(task *types.DtTaskStatus, request *types.DownloadVideoRequest) error {
+	if task.OutputDir == "" {
+		return nil
+	}
+	avail, err := diskspace.Available(task.OutputDir)
+	if err != nil {
+		logger.Warn("disk space check skipped", zap.String("taskId", task.ID), zap.Error(err))
+		return nil
+	}
+	required := s.requiredDiskSpace(task, request)
+	if avail < required {
+		return &diskSpaceError{Dir: task.OutputDir, Required: required, Available: avail}
+	}
+	return nil
+}
+
+// waitForDiskSpace 按设置拒绝或排队空间不足的任务；排队时任务被停止或删除则返回取消原因
+func (s *Service) waitForDiskSpace(ctx context.Context, task *types.DtTaskStatus, request *types.DownloadVideoRequest, progressChan ProgressChan) error {
+	err := s.checkDiskSpace(task, request)
+	if err == nil {
+		return nil
+	}
+	if s.pref.GetDiskConfig().OnInsufficient != "queue" {
+		return err
+	}
+
+	logger.Info("task queued for disk space", zap.String("taskId", task.ID), zap.Error(err))
+	task.Stage = types.DtStageQueued
+	task.StageInfo = err.Error()
+	s.taskManager.UpdateTask(task)
+	progressChan <- &types.DtProgress{
+		ID:        task.ID,
+		Type:      task.Type,
+		Stage:     types.DtStageQueued,
+		StageInfo: err.Error(),
+	}
+
+	ticker := time.NewTicker(diskQueuePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-ticker.C:
+		}
+		if err := s.checkDiskSpace(task, request); err == nil {
+			return s.resumeQueuedTask(task)
+		}
+	}
+}
+
+// resumeQueuedTask 空间足够后恢复排队的任务；只更新仍存在的记录，避免重建已删除的任务
+func (s *Service) resumeQueuedTask(task *types.DtTaskStatus) error {
+	task.Stage = types.DtStageDownloading
+	task.StageInfo = ""
+	if s.taskManager.UpdateTaskWith(task.ID, func(t *types.DtTaskStatus) {
+		t.Stage, t.StageInfo = task.Stage, task.StageInfo
+	}) == nil {
+		return errTaskDeleted
+	}
+	return nil
+}
+
+// diskSpaceWatcher 在下载过程中定期检查剩余空间，低于保留值时取消下载
+type diskSpaceWatcher struct {
+	s       *Service
+	task    *types.DtTaskStatus
+	cancel  context.CancelCauseFunc
+	reserve uint64
+
+	mu        sync.Mutex
+	lastCheck time.Time
+	tripped   bool
+}
+
+func (s *Service) newDiskSpaceWatcher(task *types.DtTaskStatus, cancel context.CancelCauseFunc) *diskSpaceWatcher {
+	return &diskSpaceWatcher{
+		s:       s,
+		task:    task,
+		cancel:  cancel,
+		reserve: uint64(s.pref.GetDiskConfig().MinFreeMB) * 1024 * 1024,
+	}
+}
+
+// Check 节流检查；触发后取消下载进程，由调用方将任务置为暂停
+func (w *diskSpaceWatcher) Check(now time.Time) {
+	if w == nil || w.reserve == 0 || w.task.OutputDir == "" {
+		return
+	}
+	w.mu.Lock()
+	if w.tripped || now.Sub(w.lastCheck) < diskCheckInterval {
+		w.mu.Unlock()
+		return
+	}
+	w.lastCheck = now
+	w.mu.Unlock()
+
+	avail, err := diskspace.Available(w.task.OutputDir)
+	if err != nil || avail >= w.reserve {
+		return
+	}
+
+	w.mu.Lock()
+	w.tripped = true
+	w.mu.Unlock()
+
+	derr := &diskSpaceError{Dir: w.task.OutputDir, Required: w.reserve, Available: avail, During: true}
+	logger.Warn("low disk space, pausing download", zap.String("taskId", w.task.ID), zap.Error(derr))
+	w.cancel(derr)
+}
+
+// pauseTask 将任务置为暂停并发送错误事件（保留 .part 文件以便恢复）
+func (s *Service) pauseTask(task *types.DtTaskStatus, err error, progressChan ProgressChan) {
+	task.Stage = types.DtStagePaused
+	task.Error = err.Error()
+	task.StageInfo = "Paused: low disk space"
+	task.DownloadProcess.Video = "error"
+	s.taskManager.UpdateTask(task)
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(s.ctx, &events.BaseEvent{
+			ID:        uuid.New().String(),
+			Type:      consts.TopicDowntasksStage,
+			Source:    "downtasks",
+			Timestamp: time.Now(),
+			Data:      &types.DTStageEvent{ID: task.ID, Kind: "video", Action: "error", Message: err.Error()},
+		})
+	}
+
+	progressChan <- &types.DtProgress{
+		ID:         task.ID,
+		Type:       task.Type,
+		Stage:      types.DtStagePaused,
+		Error:      err.Error(),
+		Percentage: task.Percentage,
+		StageInfo:  "Paused: low disk space",
+	}
+}
+
+// ResumeTask 恢复已暂停的任务
+func (s *Service) ResumeTask(id string) (*types.DtTaskStatus, error) {
+	task := s.taskManager.GetTask(id)
+	if task == nil {
+		return nil, fmt.Errorf("task not found: %s", id)
+	}
+	if task.Stage != types.DtStagePaused {
+		return nil, fmt.Errorf("task %s is not paused", id)
+	}
+	if task.DownloadRequest == nil {
+		return nil, fmt.Errorf("task %s has no stored download request", id)
+	}
+
+	task.Stage = types.DtStageDownloading
+	task.Error = ""
+	task.ErrorInfo = nil
+	task.StageInfo = ""
+	task.DownloadProcess.Video = ""
+	s.taskManager.UpdateTask(task)
+
+	request := *task.DownloadRequest
+	s.startTask(task, &request)
+	return task, nil
+}
+
+// formatBytes 按 1024 进位格式化字节数
+func formatBytes(b uint64) string {
+	const (
+		KB = 1024.0
+		MB = 1024.0 * KB
+		GB = 1024.0 * MB
+	)
+	v := float64(b)
+	switch {
+	case v >= GB:
+		return fmt.Sprintf("%.2f GB", v/GB)
+	case v >= MB:
+		return fmt.Sprintf("%.2f MB", v/MB)
+	case v >= KB:
+		return fmt.Sprintf("%.2f KB", v/KB)
+	default:
+		return fmt.Sprintf("%d B", b)
+	}
+}
@@ -0,0 +1,57 @@
+package downtasks
+
+import (
+	"CanMe/backend/services/preferences"
+	"CanMe/backend/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForDiskSpaceStopsWhenDeleted(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	s := duplicateService(t)
+	s.pref = preferences.New()
+	// 保留空间远大于任何磁盘，任务一直排队
+	require.True(t, s.pref.UpdatePreferences(map[string]any{
+		"disk.minFreeMB":      1 << 40,
+		"disk.onInsufficient": "queue",
+	}).Success)
+
+	task := &types.DtTaskStatus{ID: "t1", Stage: types.DtStageDownloading, OutputDir: t.TempDir()}
+	s.taskManager.UpdateTask(task)
+	ctx, done := s.beginTask(task.ID)
+	defer done()
+
+	progressChan := make(ProgressChan, 1)
+	errc := make(chan error, 1)
+	go func() { errc <- s.waitForDiskSpace(ctx, task, &types.DownloadVideoRequest{}, progressChan) }()
+	assert.Equal(t, types.DtStageQueued, (<-progressChan).Stage)
+
+	require.NoError(t, s.DeleteTask(task.ID))
+	select {
+	case err := <-errc:
+		assert.ErrorIs(t, err, errTaskDeleted)
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitForDiskSpace did not return after the task was deleted")
+	}
+	assert.True(t, s.finishStopped(ctx, task, progressChan))
+	assert.Nil(t, s.taskManager.GetTask(task.ID))
+}
+
+func TestResumeQueuedTask(t *testing.T) {
+	s := duplicateService(t)
+	task := &types.DtTaskStatus{ID: "t1", Stage: types.DtStageQueued, StageInfo: "insufficient disk space"}
+	s.taskManager.UpdateTask(task)
+	require.NoError(t, s.resumeQueuedTask(task))
+	assert.Equal(t, types.DtStageDownloading, s.taskManager.GetTask("t1").Stage)
+	assert.Empty(t, s.taskManager.GetTask("t1").StageInfo)
+
+	// 排队期间被删除的任务不会被重建
+	require.NoError(t, s.DeleteTask("t1"))
+	assert.ErrorIs(t, s.resumeQueuedTask(task), errTaskDeleted)
+	assert.Nil(t, s.taskManager.GetTask("t1"))
+}
@@ -0,0 +1,400 @@
+package downtasks
+
+import (
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/types"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// videoKey 站点 + 视频ID 的去重键（忽略大小写）
+func videoKey(extractor, videoID string) string {
+	extractor = strings.ToLower(strings.TrimSpace(extractor))
+	videoID = strings.TrimSpace(videoID)
+	if extractor == "" || videoID == "" {
+		return ""
+	}
+	return extractor + ":" + videoID
+}
+
+// findCompletedDuplicate 查找与给定条件匹配的最早的已完成任务（排除 excludeID）
+func (s *Service) findCompletedDuplicate(excludeID string, match func(*types.DtTaskStatus) bool) *types.DtTaskStatus {
+	var found *types.DtTaskStatus
+	for _, t := range s.taskManager.ListTasks() {
+		if t == nil || t.ID == excludeID || t.Stage != types.DtStageCompleted || !match(t) {
+			continue
+		}
+		if found == nil || t.CreatedAt < found.CreatedAt {
+			found = t
+		}
+	}
+	return found
+}
+
+// findDuplicateByVideo 按站点 + 视频ID 查找已完成的重复任务
+func (s *Service) findDuplicateByVideo(excludeID, extractor, videoID string) *types.DtTaskStatus {
+	key := videoKey(extractor, videoID)
+	if key == "" {
+		return nil
+	}
+	return s.findCompletedDuplicate(excludeID, func(t *types.DtTaskStatus) bool {
+		return videoKey(t.Extractor, t.VideoID) == key
+	})
+}
+
+// findDuplicateByHash 按文件内容哈希查找已完成的重复任务
+func (s *Service) findDuplicateByHash(excludeID, hash string) *types.DtTaskStatus {
+	if hash == "" {
+		return nil
+	}
+	return s.findCompletedDuplicate(excludeID, func(t *types.DtTaskStatus) bool {
+		return t.ContentHash == hash
+	})
+}
+
+// markDuplicate 记录任务与已有任务重复并写入任务日志（warn 模式）
+func (s *Service) markDuplicate(task *types.DtTaskStatus, existing *types.DtTaskStatus, kind types.DtDuplicateKind) {
+	if existing == nil || task.DuplicateOf != "" {
+		return
+	}
+	s.taskManager.UpdateTaskWith(task.ID, func(t *types.DtTaskStatus) {
+		t.DuplicateOf = existing.ID
+	})
+	logger.Warn("duplicate download detected",
+		zap.String("taskId", task.ID),
+		zap.String("existingTaskId", existing.ID),
+		zap.String("kind", string(kind)),
+	)
+	s.appendTaskLog(task.ID, "info", fmt.Sprintf("duplicate of task %s (%s) by %s", existing.ID, existing.Title, kind))
+}
+
+// checkVideoDuplicate 根据设置检查请求是否与已完成任务为同一视频；返回的任务非空且 skip 为 true 时应跳过下载
+func (s *Service) checkVideoDuplicate(excludeID, extractor, videoID string) (existing *types.DtTaskStatus, skip bool) {
+	config := s.pref.GetDuplicatesConfig()
+	if config.OnDuplicate == "off" {
+		return nil, false
+	}
+	existing = s.findDuplicateByVideo(excludeID, extractor, videoID)
+	return existing, existing != nil && config.OnDuplicate == "skip"
+}
+
+// hashTask 在后台记录主媒体文件的内容哈希，避免大文件阻塞任务的后续阶段
+func (s *Service) hashTask(task *types.DtTaskStatus) {
+	config := s.pref.GetDuplicatesConfig()
+	if !config.HashFiles {
+		return
+	}
+	file := s.primaryMediaFile(task)
+	if file == "" {
+		return
+	}
+	go s.recordContentHash(task.ID, file, config.OnDuplicate != "off")
+}
+
+// 预检时比对的文件开头字节数
+const hashSampleSize = 1 << 20
+
+// recordContentHash 只有已完成任务中存在大小与开头内容都相同的文件时才计算完整 SHA-256（同时补齐候选任务的哈希），
+// 并按内容查找重复任务（内容重复只做标记，不删除已下载的文件）
+func (s *Service) recordContentHash(taskID, file string, mark bool) {
+	candidates, err := s.sameContentCandidates(taskID, file)
+	if err != nil {
+		logger.Warn("failed to sample media file", zap.String("taskId", taskID), zap.String("file", file), zap.Error(err))
+		return
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	hash, err := fileSHA256(file)
+	if err != nil {
+		logger.Warn("failed to hash media file", zap.String("taskId", taskID), zap.String("file", file), zap.Error(err))
+		return
+	}
+	task := s.taskManager.UpdateTaskWith(taskID, func(t *types.DtTaskStatus) {
+		t.ContentHash = hash
+	})
+	for _, c := range candidates {
+		if _, err := s.ensureContentHash(c.task, c.file); err != nil {
+			logger.Warn("failed to hash media file", zap.String("taskId", c.task.ID), zap.String("file", c.file), zap.Error(err))
+		}
+	}
+	if mark && task != nil {
+		s.markDuplicate(task, s.findDuplicateByHash(taskID, hash), types.DtDuplicateByHash)
+	}
+}
+
+type hashCandidate struct {
+	task *types.DtTaskStatus
+	file string
+}
+
+// sameContentCandidates 返回主媒体文件与 file 大小及开头内容相同的已完成任务（不含同一文件）
+func (s *Service) sameContentCandidates(excludeID, file string) ([]hashCandidate, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	var sample []byte
+	var candidates []hashCandidate
+	for _, t := range s.taskManager.ListTasks() {
+		if t == nil || t.ID == excludeID || t.Stage != types.DtStageCompleted {
+			continue
+		}
+		other := s.primaryMediaFile(t)
+		if other == "" {
+			continue
+		}
+		otherInfo, err := os.Stat(other)
+		if err != nil || otherInfo.Size() != info.Size() || os.SameFile(info, otherInfo) {
+			continue
+		}
+		if sample == nil {
+			if sample, err = fileSample(file); err != nil {
+				return nil, err
+			}
+		}
+		if otherSample, err := fileSample(other); err != nil || !bytes.Equal(sample, otherSample) {
+			continue
+		}
+		candidates = append(candidates, hashCandidate{task: t, file: other})
+	}
+	return candidates, nil
+}
+
+// fileSample 读取文件开头至多 hashSampleSize 字节
+func fileSample(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, hashSampleSize))
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FindDuplicates 生成已完成任务的重复报告：先按内容哈希分组，再按站点 + 视频ID 分组（已被哈希分组覆盖的不重复列出）
+func (s *Service) FindDuplicates() (*types.DtDuplicateReport, error) {
+	var tasks []*types.DtTaskStatus
+	for _, t := range s.taskManager.ListTasks() {
+		if t != nil && t.Stage == types.DtStageCompleted {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].CreatedAt < tasks[j].CreatedAt })
+
+	byHash := map[string][]*types.DtTaskStatus{}
+	byVideo := map[string][]*types.DtTaskStatus{}
+	var hashKeys, videoKeys []string
+	for _, t := range tasks {
+		if t.ContentHash != "" {
+			if _, ok := byHash[t.ContentHash]; !ok {
+				hashKeys = append(hashKeys, t.ContentHash)
+			}
+			byHash[t.ContentHash] = append(byHash[t.ContentHash], t)
+		}
+		if key := videoKey(t.Extractor, t.VideoID); key != "" {
+			if _, ok := byVideo[key]; !ok {
+				videoKeys = append(videoKeys, key)
+			}
+			byVideo[key] = append(byVideo[key], t)
+		}
+	}
+
+	report := &types.DtDuplicateReport{Groups: []types.DtDuplicateGroup{}}
+	for _, key := range hashKeys {
+		if group := byHash[key]; len(group) > 1 {
+			report.Groups = append(report.Groups, s.duplicateGroup(types.DtDuplicateByHash, key, group))
+		}
+	}
+	for _, key := range videoKeys {
+		group := byVideo[key]
+		if len(group) < 2 || sameHash(group) {
+			continue
+		}
+		report.Groups = append(report.Groups, s.duplicateGroup(types.DtDuplicateByVideoID, key, group))
+	}
+	for _, g := range report.Groups {
+		report.ReclaimableBytes += g.ReclaimableBytes
+	}
+	return report, nil
+}
+
+// sameHash 组内所有任务的哈希均已知且相同（已在哈希分组中列出）
+func sameHash(group []*types.DtTaskStatus) bool {
+	for _, t := range group {
+		if t.ContentHash == "" || t.ContentHash != group[0].ContentHash {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Service) duplicateGroup(kind types.DtDuplicateKind, key string, group []*types.DtTaskStatus) types.DtDuplicateGroup {
+	g := types.DtDuplicateGroup{Kind: kind, Key: key}
+	var first os.FileInfo
+	for i, t := range group {
+		entry := types.DtDuplicateEntry{
+			TaskID:    t.ID,
+			Title:     t.Title,
+			URL:       t.URL,
+			Hash:      t.ContentHash,
+			CreatedAt: t.CreatedAt,
+		}
+		entry.File = s.primaryMediaFile(t)
+		if entry.File != "" {
+			if info, err := os.Stat(entry.File); err == nil {
+				entry.Size = info.Size()
+				if i == 0 {
+					first = info
+				} else if first != nil && os.SameFile(first, info) {
+					entry.Linked = true
+				}
+			}
+		}
+		// 只有内容相同且尚未硬链接的文件可以回收空间
+		if i > 0 && !entry.Linked && (kind == types.DtDuplicateByHash || entry.Hash != "" && entry.Hash == group[0].ContentHash) {
+			g.ReclaimableBytes += entry.Size
+		}
+		g.Tasks = append(g.Tasks, entry)
+	}
+	return g
+}
+
+// ResolveDuplicates 保留 KeepTaskID，对其余任务以硬链接替换文件或删除任务
+func (s *Service) ResolveDuplicates(request types.DtDuplicateResolveRequest) (*types.DtDuplicateResolveResult, error) {
+	keep := s.taskManager.GetTask(request.KeepTaskID)
+	if keep == nil {
+		return nil, fmt.Errorf("task not found: %s", request.KeepTaskID)
+	}
+	switch request.Action {
+	case types.DtDuplicateHardLink, types.DtDuplicateDelete:
+	default:
+		return nil, fmt.Errorf("unsupported action: %s", request.Action)
+	}
+	mode := request.DeleteMode
+	if mode == "" {
+		mode = types.DtDeleteToTrash
+	}
+
+	keepFile := s.primaryMediaFile(keep)
+	// 同一视频的任务可能指向同一路径，删除重复任务时不能动保留任务的文件
+	keepFiles := s.taskFilePaths(keep)
+	result := &types.DtDuplicateResolveResult{Resolved: []string{}, Failed: map[string]string{}}
+	for _, id := range request.TaskIDs {
+		if id == keep.ID {
+			continue
+		}
+		var reclaimed int64
+		var err error
+		switch request.Action {
+		case types.DtDuplicateHardLink:
+			reclaimed, err = s.linkDuplicate(keep, keepFile, id)
+		case types.DtDuplicateDelete:
+			if t := s.taskManager.GetTask(id); t != nil && mode != types.DtDeleteRecordOnly {
+				if files := withoutFiles([]string{s.primaryMediaFile(t)}, keepFiles); len(files) > 0 {
+					if info, serr := os.Stat(files[0]); serr == nil {
+						reclaimed = info.Size()
+					}
+				}
+			}
+			err = s.deleteTask(id, mode, keepFiles)
+		}
+		if err != nil {
+			result.Failed[id] = err.Error()
+			continue
+		}
+		result.Resolved = append(result.Resolved, id)
+		result.ReclaimedBytes += reclaimed
+	}
+	return result, nil
+}
+
+// linkDuplicate 校验内容相同后，以指向保留文件的硬链接替换重复任务的主媒体文件
+func (s *Service) linkDuplicate(keep *types.DtTaskStatus, keepFile, id string) (int64, error) {
+	dup := s.taskManager.GetTask(id)
+	if dup == nil {
+		return 0, fmt.Errorf("task not found: %s", id)
+	}
+	dupFile := s.primaryMediaFile(dup)
+	if keepFile == "" || dupFile == "" {
+		return 0, fmt.Errorf("media file not found")
+	}
+
+	keepInfo, err := os.Stat(keepFile)
+	if err != nil {
+		return 0, err
+	}
+	dupInfo, err := os.Stat(dupFile)
+	if err != nil {
+		return 0, err
+	}
+	if os.SameFile(keepInfo, dupInfo) {
+		return 0, nil
+	}
+	if keepInfo.Size() != dupInfo.Size() {
+		return 0, fmt.Errorf("file contents differ")
+	}
+
+	keepHash, err := s.ensureContentHash(keep, keepFile)
+	if err != nil {
+		return 0, err
+	}
+	dupHash, err := s.ensureContentHash(dup, dupFile)
+	if err != nil {
+		return 0, err
+	}
+	if keepHash != dupHash {
+		return 0, fmt.Errorf("file contents differ")
+	}
+
+	// 先在同目录创建硬链接再原子替换，失败时原文件不受影响
+	tmp := filepath.Join(filepath.Dir(dupFile), "."+filepath.Base(dupFile)+".canme-link")
+	os.Remove(tmp)
+	if err := os.Link(keepFile, tmp); err != nil {
+		return 0, fmt.Errorf("failed to create hard link: %w", err)
+	}
+	if err := os.Rename(tmp, dupFile); err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	s.appendTaskLog(dup.ID, "info", fmt.Sprintf("replaced %s with a hard link to task %s", dupFile, keep.ID))
+	return dupInfo.Size(), nil
+}
+
+// ensureContentHash 返回任务已记录的哈希，缺失时计算并保存
+func (s *Service) ensureContentHash(task *types.DtTaskStatus, file string) (string, error) {
+	if task.ContentHash != "" {
+		return task.ContentHash, nil
+	}
+	hash, err := fileSHA256(file)
+	if err != nil {
+		return "", err
+	}
+	s.taskManager.UpdateTaskWith(task.ID, func(t *types.DtTaskStatus) {
+		t.ContentHash = hash
+	})
+	return hash, nil
+}
@@ -0,0 +1,158 @@
+package downtasks
+
+import (
+	"CanMe/backend/types"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func duplicateService(t *testing.T) *Service {
+	t.Helper()
+	return &Service{taskManager: NewTaskManager(context.Background(), nil)}
+}
+
+// addMediaTask 添加一个主媒体文件内容为 content 的任务
+func addMediaTask(t *testing.T, s *Service, id string, createdAt int64, stage types.DtTaskStage, content string) *types.DtTaskStatus {
+	t.Helper()
+	dir := t.TempDir()
+	file := filepath.Join(dir, id+".mp4")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+	task := &types.DtTaskStatus{ID: id, Stage: stage, CreatedAt: createdAt, OutputDir: dir, VideoFiles: []string{id + ".mp4"}}
+	s.taskManager.UpdateTask(task)
+	return task
+}
+
+func TestVideoKey(t *testing.T) {
+	assert.Equal(t, "youtube:dQw4w9WgXcQ", videoKey(" YouTube ", " dQw4w9WgXcQ "))
+	assert.Empty(t, videoKey("", "id"))
+	assert.Empty(t, videoKey("youtube", " "))
+}
+
+func TestFindDuplicateByVideo(t *testing.T) {
+	s := duplicateService(t)
+	for _, task := range []*types.DtTaskStatus{
+		{ID: "new", Stage: types.DtStageCompleted, CreatedAt: 3},
+		{ID: "old", Stage: types.DtStageCompleted, CreatedAt: 1},
+		{ID: "failed", Stage: types.DtStageFailed, CreatedAt: 0},
+		{ID: "other", Stage: types.DtStageCompleted, CreatedAt: 0},
+	} {
+		task.Extractor, task.VideoID = "youtube", "abc"
+		if task.ID == "other" {
+			task.VideoID = "xyz"
+		}
+		s.taskManager.UpdateTask(task)
+	}
+
+	found := s.findDuplicateByVideo("current", "YouTube", "abc")
+	require.NotNil(t, found)
+	assert.Equal(t, "old", found.ID)
+	assert.Equal(t, "new", s.findDuplicateByVideo("old", "youtube", "abc").ID)
+	assert.Nil(t, s.findDuplicateByVideo("current", "youtube", ""))
+}
+
+func TestRecordContentHash(t *testing.T) {
+	s := duplicateService(t)
+	content := strings.Repeat("a", 100)
+	same := addMediaTask(t, s, "same", 1, types.DtStageCompleted, content)
+	// 大小相同但开头不同、大小不同、未完成的任务都不计算哈希
+	sameSize := addMediaTask(t, s, "same-size", 2, types.DtStageCompleted, "b"+content[1:])
+	otherSize := addMediaTask(t, s, "other-size", 3, types.DtStageCompleted, content+"a")
+	running := addMediaTask(t, s, "running", 4, types.DtStageDownloading, content)
+	task := addMediaTask(t, s, "task", 5, types.DtStageDownloading, content)
+
+	s.recordContentHash(task.ID, s.primaryMediaFile(task), true)
+
+	hash, err := fileSHA256(s.primaryMediaFile(task))
+	require.NoError(t, err)
+	assert.Equal(t, hash, s.taskManager.GetTask("task").ContentHash)
+	assert.Equal(t, hash, s.taskManager.GetTask("same").ContentHash)
+	assert.Equal(t, same.ID, s.taskManager.GetTask("task").DuplicateOf)
+	for _, other := range []*types.DtTaskStatus{sameSize, otherSize, running} {
+		assert.Empty(t, s.taskManager.GetTask(other.ID).ContentHash, other.ID)
+	}
+}
+
+func TestRecordContentHashSkipsUniqueSizes(t *testing.T) {
+	s := duplicateService(t)
+	addMediaTask(t, s, "existing", 1, types.DtStageCompleted, "short")
+	task := addMediaTask(t, s, "task", 2, types.DtStageDownloading, "a longer file")
+
+	s.recordContentHash(task.ID, s.primaryMediaFile(task), true)
+	assert.Empty(t, s.taskManager.GetTask("task").ContentHash)
+	assert.Empty(t, s.taskManager.GetTask("existing").ContentHash)
+
+	// 只记录哈希，不标记重复
+	addMediaTask(t, s, "copy", 3, types.DtStageCompleted, "a longer file")
+	s.recordContentHash(task.ID, s.primaryMediaFile(task), false)
+	assert.NotEmpty(t, s.taskManager.GetTask("task").ContentHash)
+	assert.Empty(t, s.taskManager.GetTask("task").DuplicateOf)
+}
+
+func TestFindDuplicates(t *testing.T) {
+	s := duplicateService(t)
+	a := addMediaTask(t, s, "a", 1, types.DtStageCompleted, "same content")
+	b := addMediaTask(t, s, "b", 2, types.DtStageCompleted, "same content")
+	c := addMediaTask(t, s, "c", 3, types.DtStageCompleted, "re-encoded")
+	d := addMediaTask(t, s, "d", 4, types.DtStageCompleted, "unrelated")
+	a.ContentHash, b.ContentHash = "h1", "h1"
+	// a、b 同一视频且内容相同，只在哈希分组中列出；c 同一视频但内容不同
+	for _, task := range []*types.DtTaskStatus{a, b, c} {
+		task.Extractor, task.VideoID = "youtube", "abc"
+	}
+	d.Extractor, d.VideoID = "vimeo", "1"
+
+	report, err := s.FindDuplicates()
+	require.NoError(t, err)
+	require.Len(t, report.Groups, 2)
+
+	byHash := report.Groups[0]
+	assert.Equal(t, types.DtDuplicateByHash, byHash.Kind)
+	require.Len(t, byHash.Tasks, 2)
+	assert.Equal(t, []string{"a", "b"}, []string{byHash.Tasks[0].TaskID, byHash.Tasks[1].TaskID})
+	assert.Equal(t, int64(len("same content")), byHash.ReclaimableBytes)
+
+	byVideo := report.Groups[1]
+	assert.Equal(t, types.DtDuplicateByVideoID, byVideo.Kind)
+	assert.Equal(t, "youtube:abc", byVideo.Key)
+	assert.Len(t, byVideo.Tasks, 3)
+	// 哈希相同的 b 可回收，内容未知的 c 不可回收
+	assert.Equal(t, int64(len("same content")), byVideo.ReclaimableBytes)
+	assert.Equal(t, 2*int64(len("same content")), report.ReclaimableBytes)
+}
+
+func TestResolveDuplicatesKeepsSharedFiles(t *testing.T) {
+	s := duplicateService(t)
+	keep := addMediaTask(t, s, "keep", 1, types.DtStageCompleted, "video")
+	// 同一视频的任务写入同一路径，另有自己的字幕文件
+	dir := keep.OutputDir
+	sub := filepath.Join(dir, "dup.en.vtt")
+	require.NoError(t, os.WriteFile(sub, []byte("WEBVTT"), 0o644))
+	s.taskManager.UpdateTask(&types.DtTaskStatus{ID: "dup", Stage: types.DtStageCompleted, CreatedAt: 2, OutputDir: dir,
+		VideoFiles: []string{"keep.mp4"}, SubtitleFiles: []string{"dup.en.vtt"}})
+	// 硬链接到保留文件的任务
+	linked := filepath.Join(t.TempDir(), "linked.mp4")
+	require.NoError(t, os.Link(filepath.Join(dir, "keep.mp4"), linked))
+	s.taskManager.UpdateTask(&types.DtTaskStatus{ID: "linked", Stage: types.DtStageCompleted, CreatedAt: 3, VideoFiles: []string{linked}})
+
+	result, err := s.ResolveDuplicates(types.DtDuplicateResolveRequest{
+		KeepTaskID: "keep",
+		TaskIDs:    []string{"keep", "dup", "linked"},
+		Action:     types.DtDuplicateDelete,
+		DeleteMode: types.DtDeleteWithFiles,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dup", "linked"}, result.Resolved)
+	assert.Zero(t, result.ReclaimedBytes)
+
+	assert.FileExists(t, filepath.Join(dir, "keep.mp4"))
+	assert.FileExists(t, linked)
+	assert.NoFileExists(t, sub)
+	assert.Nil(t, s.taskManager.GetTask("dup"))
+	assert.NotNil(t, s.taskManager.GetTask("keep"))
+}
@@ -0,0 +1,284 @@
+package downtasks
+
+import (
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/pkg/pathtemplate"
+	"CanMe/backend/types"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// libraryPlaceholders 媒体库模板支持的变量
+var libraryPlaceholders = []string{
+	"uploader", "title", "id", "extractor", "type", "task_id",
+	"upload_date", "upload_year", "upload_month", "resolution",
+}
+
+// 字幕文件名中语言后缀（含点）的最大长度，例如 .zh-Hans、.en-orig
+const maxLangSuffix = 16
+
+// errOrganizeConflict 目标位置已存在同名文件且设置为 skip
+var errOrganizeConflict = errors.New("target file already exists")
+
+// OrganizeTask 按媒体库模板整理单个已完成任务的文件
+func (s *Service) OrganizeTask(id string) (*types.DtTaskStatus, error) {
+	task := s.taskManager.GetTask(id)
+	if task == nil {
+		return nil, fmt.Errorf("task not found: %s", id)
+	}
+	if task.Stage != types.DtStageCompleted {
+		return nil, fmt.Errorf("task %s is not completed", id)
+	}
+	if _, err := s.organizeTask(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// OrganizeTasks 批量整理已完成的任务；过滤条件为空时处理全部已完成任务
+func (s *Service) OrganizeTasks(filter types.DtTaskFilter) (*types.DtOrganizeResult, error) {
+	result := &types.DtOrganizeResult{Organized: []string{}}
+	for _, task := range s.taskManager.ListTasks() {
+		if task == nil || task.Stage != types.DtStageCompleted {
+			continue
+		}
+		if !isEmptyTaskFilter(filter) && !matchTaskFilter(task, filter) {
+			continue
+		}
+
+		moved, err := s.organizeTask(task)
+		switch {
+		case errors.Is(err, errOrganizeConflict):
+			if result.Skipped == nil {
+				result.Skipped = map[string]string{}
+			}
+			result.Skipped[task.ID] = err.Error()
+		case err != nil:
+			if result.Failed == nil {
+				result.Failed = map[string]string{}
+			}
+			result.Failed[task.ID] = err.Error()
+		case !moved:
+			if result.Skipped == nil {
+				result.Skipped = map[string]string{}
+			}
+			result.Skipped[task.ID] = "already organized"
+		default:
+			result.Organized = append(result.Organized, task.ID)
+		}
+	}
+	return result, nil
+}
+
+// organizeTask 将任务文件移动到模板目录，并更新任务与关联字幕项目中的路径。
+// 返回 false 表示任务已位于目标目录。
+func (s *Service) organizeTask(task *types.DtTaskStatus) (bool, error) {
+	config := s.pref.GetLibraryConfig()
+	if err := pathtemplate.Validate(config.Template, libraryPlaceholders); err != nil {
+		return false, err
+	}
+
+	root := config.Root
+	if root == "" {
+		root = s.downloadClient.GetDownloadDirWithCanMe()
+	}
+	if root == "" {
+		return false, fmt.Errorf("library root is empty")
+	}
+	rel := pathtemplate.Render(config.Template, libraryValues(task), "unknown")
+	if rel == "" {
+		return false, fmt.Errorf("template rendered an empty path")
+	}
+	// 模板最后一段为文件名（不含扩展名），之前的部分为目录
+	target, name := filepath.Join(root, filepath.Dir(rel)), filepath.Base(rel)
+	var stem string
+	if primary := s.primaryMediaFile(task); primary != "" {
+		stem = strings.TrimSuffix(filepath.Base(primary), filepath.Ext(primary))
+	}
+	oldDir := task.OutputDir
+	if oldDir != "" && filepath.Clean(oldDir) == filepath.Clean(target) && stem == name {
+		return false, nil
+	}
+
+	files := s.taskFilePaths(task)
+	if len(files) == 0 {
+		return false, fmt.Errorf("task %s has no files on disk", task.ID)
+	}
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return false, fmt.Errorf("failed to create library directory: %w", err)
+	}
+
+	moved := map[string]string{}
+	taken := map[string]bool{} // 本任务已移入的目标路径
+	rollback := func() {
+		for src, dst := range moved {
+			_ = moveFile(dst, src)
+		}
+		_ = os.Remove(target)
+	}
+	for _, src := range files {
+		dst := filepath.Join(target, libraryFileName(name, filepath.Base(src), stem))
+		if dst == src {
+			continue
+		}
+		if _, err := os.Stat(dst); err == nil {
+			// 本任务的多个文件重命名后同名（例如两张缩略图）时不算冲突
+			if config.OnConflict == "skip" && !taken[dst] {
+				rollback()
+				return false, fmt.Errorf("%w: %s", errOrganizeConflict, dst)
+			}
+			dst = uniquePath(dst)
+		}
+		if err := moveFile(src, dst); err != nil {
+			rollback()
+			return false, fmt.Errorf("failed to move %s: %w", src, err)
+		}
+		moved[src] = dst
+		taken[dst] = true
+	}
+
+	remap := func(list []string) []string {
+		if len(list) == 0 {
+			return list
+		}
+		out := make([]string, 0, len(list))
+		for _, entry := range list {
+			abs := normalizePath(oldDir, entry)
+			if dst, ok := moved[abs]; ok {
+				if filepath.IsAbs(entry) {
+					out = append(out, dst)
+				} else {
+					out = append(out, filepath.Base(dst))
+				}
+				continue
+			}
+			// 未移动的相对路径改为绝对路径，避免输出目录变化后失效
+			if abs != "" && !filepath.IsAbs(entry) {
+				out = append(out, abs)
+				continue
+			}
+			out = append(out, entry)
+		}
+		return out
+	}
+
+	task.VideoFiles = remap(task.VideoFiles)
+	task.SubtitleFiles = remap(task.SubtitleFiles)
+	task.AllDownloadedFiles = remap(task.AllDownloadedFiles)
+	task.TranslatedSubs = remap(task.TranslatedSubs)
+	task.EmbeddedVideoFiles = remap(task.EmbeddedVideoFiles)
+	task.AllFiles = remap(task.AllFiles)
+	task.SubtitleProcess.Files = remap(task.SubtitleProcess.Files)
+	task.TranscodeProcess.OutputFiles = remap(task.TranscodeProcess.OutputFiles)
+	if task.SubtitleProcess.OutputDir == "" || filepath.Clean(task.SubtitleProcess.OutputDir) == filepath.Clean(oldDir) {
+		task.SubtitleProcess.OutputDir = target
+	}
+	if task.TranscodeProcess.OutputDir != "" && filepath.Clean(task.TranscodeProcess.OutputDir) == filepath.Clean(oldDir) {
+		task.TranscodeProcess.OutputDir = target
+	}
+	task.OutputDir = target
+	s.taskManager.UpdateTask(task)
+
+	s.relinkSubtitleProjects(task, moved)
+
+	// 旧目录为空时顺手清理（非空时 Remove 会失败，忽略即可）
+	if oldDir != "" {
+		_ = os.Remove(oldDir)
+	}
+
+	s.appendTaskLog(task.ID, "info", fmt.Sprintf("library organizer: moved %d file(s) to %s", len(moved), target))
+	logger.Info("task organized",
+		zap.String("taskId", task.ID),
+		zap.String("dir", target),
+		zap.Int("files", len(moved)),
+	)
+	return true, nil
+}
+
+// relinkSubtitleProjects 更新字幕项目中指向已移动文件的 SourceInfo
+func (s *Service) relinkSubtitleProjects(task *types.DtTaskStatus, moved map[string]string) {
+	if s.boltStorage == nil || len(moved) == 0 {
+		return
+	}
+	projects, err := s.boltStorage.ListSubtitles()
+	if err != nil {
+		logger.Warn("Failed to list subtitle projects", zap.Error(err))
+		return
+	}
+	for _, p := range projects {
+		if p == nil || p.Metadata.SourceInfo == nil || p.Metadata.SourceInfo.FilePath == "" {
+			continue
+		}
+		dst, ok := moved[filepath.Clean(p.Metadata.SourceInfo.FilePath)]
+		if !ok {
+			continue
+		}
+		p.Metadata.SourceInfo.FilePath = dst
+		p.Metadata.SourceInfo.FileName = filepath.Base(dst)
+		p.Metadata.SourceInfo.FileDir = filepath.Dir(dst)
+		if p.Metadata.OriginTaskID == "" {
+			p.Metadata.OriginTaskID = task.ID
+		}
+		p.UpdatedAt = time.Now().Unix()
+		if err := s.boltStorage.SaveSubtitle(p); err != nil {
+			logger.Warn("Failed to update subtitle source path", zap.String("projectId", p.ID), zap.Error(err))
+		}
+	}
+}
+
+// libraryFileName 以模板渲染的 name 重命名文件：与主媒体文件同名的文件保留其后缀（如 .en.vtt、.info.json），
+// 其他文件保留扩展名，字幕另外保留语言后缀
+func libraryFileName(name, base, stem string) string {
+	if stem != "" && strings.HasPrefix(base, stem+".") {
+		return name + base[len(stem):]
+	}
+	ext := filepath.Ext(base)
+	if classifyByExt(base) == "subtitle" {
+		lang := filepath.Ext(strings.TrimSuffix(base, ext))
+		if len(lang) > 1 && len(lang) <= maxLangSuffix && !strings.ContainsAny(lang, " _") {
+			return name + lang + ext
+		}
+	}
+	return name + ext
+}
+
+// libraryValues 构造模板变量
+func libraryValues(task *types.DtTaskStatus) map[string]string {
+	id := task.VideoID
+	if id == "" {
+		id = task.ID
+	}
+	values := map[string]string{
+		"uploader":    task.Uploader,
+		"title":       task.Title,
+		"id":          id,
+		"extractor":   task.Extractor,
+		"type":        task.Type,
+		"task_id":     task.ID,
+		"upload_date": task.UploadDate,
+		"resolution":  task.Resolution,
+	}
+	if d := task.UploadDate; len(d) == 8 {
+		values["upload_year"] = d[:4]
+		values["upload_month"] = d[4:6]
+	}
+	return values
+}
+
+// uniquePath 在文件名后追加 (1)、(2)… 直至不存在
+func uniquePath(p string) string {
+	ext := filepath.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package downtasks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibraryFileName(t *testing.T) {
+	const name = "Song [abc123]"
+	const stem = "Song_1080p_30fps"
+	tests := map[string]string{
+		"Song_1080p_30fps.mp4":           "Song [abc123].mp4",
+		"Song_1080p_30fps.en.vtt":        "Song [abc123].en.vtt",
+		"Song_1080p_30fps.zh-Hans.srt":   "Song [abc123].zh-Hans.srt",
+		"Song_1080p_30fps.info.json":     "Song [abc123].info.json",
+		"Song.webp":                      "Song [abc123].webp",
+		"Song.translated.fr.srt":         "Song [abc123].fr.srt",
+		"Song_1080p_30fps_extra.mp4":     "Song [abc123].mp4",
+		"Other title with.dots_720p.srt": "Song [abc123].srt",
+	}
+	for base, want := range tests {
+		assert.Equal(t, want, libraryFileName(name, base, stem), base)
+	}
+
+	// 没有主媒体文件时只保留扩展名与语言后缀
+	assert.Equal(t, "Song [abc123].en.vtt", libraryFileName(name, "Song_1080p_30fps.en.vtt", ""))
+}
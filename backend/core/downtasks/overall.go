@@ -0,0 +1,112 @@
+package downtasks
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/pkg/stageprogress"
+	"CanMe/backend/types"
+	"strings"
+
+	"github.com/lrstanley/go-ytdlp"
+)
+
+// 各子阶段的权重（按耗时经验值，最终按总和归一化）
+var subStageWeights = map[string]float64{
+	types.DtSubStageVideo:     70,
+	types.DtSubStageAudio:     15,
+	types.DtSubStageMerge:     5,
+	types.DtSubStageTranscode: 10,
+	types.DtSubStageSubtitles: 5,
+	types.DtSubStageTranslate: 15,
+	types.DtSubStageEmbed:     10,
+}
+
+// planStages 根据请求生成按执行顺序排列的子阶段
+func planStages(task *types.DtTaskStatus, request *types.DownloadVideoRequest, separateAudio bool) []types.DtStageProgress {
+	names := []string{types.DtSubStageVideo}
+	if separateAudio {
+		names = append(names, types.DtSubStageAudio)
+	}
+	names = append(names, types.DtSubStageMerge)
+	if task.RecodeExtention != "" {
+		names = append(names, types.DtSubStageTranscode)
+	}
+	if request.DownloadSubs {
+		names = append(names, types.DtSubStageSubtitles)
+		if request.Type == consts.TASK_TYPE_CUSTOM && request.TranslateTo != "" {
+			names = append(names, types.DtSubStageTranslate, types.DtSubStageEmbed)
+		}
+	}
+
+	stages := make([]types.DtStageProgress, 0, len(names))
+	for _, name := range names {
+		stages = append(stages, types.DtStageProgress{Name: name, Weight: subStageWeights[name]})
+	}
+	return stages
+}
+
+// defaultSeparateAudio 未指定单一格式时 yt-dlp 默认分别下载视频流与音频流
+func defaultSeparateAudio(request *types.DownloadVideoRequest) bool {
+	if request.Type == consts.TASK_TYPE_CUSTOM {
+		return request.FormatID == "" || strings.Contains(request.FormatID, "+")
+	}
+	return request.Video == "" || request.Video == "best" || strings.Contains(request.Video, "+")
+}
+
+// setStagePlan 替换任务的子阶段计划并重新计算总进度
+func (s *Service) setStagePlan(task *types.DtTaskStatus, stages []types.DtStageProgress) {
+	s.taskManager.UpdateTaskWith(task.ID, func(t *types.DtTaskStatus) {
+		t.StageProgress = stages
+		t.OverallPercentage = stageprogress.Overall(stages)
+	})
+}
+
+// stageSubStages 只对应一个子阶段的任务阶段，进度事件未带子阶段时据此补全
+var stageSubStages = map[types.DtTaskStage]string{
+	types.DtStageTranslating: types.DtSubStageTranslate,
+	types.DtStageEmbedding:   types.DtSubStageEmbed,
+}
+
+// applyStageProgress 将进度更新计入子阶段，并把总进度回填到进度事件中
+func applyStageProgress(task *types.DtTaskStatus, progress *types.DtProgress) {
+	if progress.SubStage == "" {
+		progress.SubStage = stageSubStages[progress.Stage]
+	}
+	switch {
+	case progress.Stage == types.DtStageCompleted:
+		stageprogress.Complete(task.StageProgress)
+	case progress.SubStage != "":
+		if !stageprogress.Advance(task.StageProgress, progress.SubStage, progress.Percentage) &&
+			progress.SubStage == types.DtSubStageAudio {
+			// 纯音频格式没有单独的音频阶段
+			stageprogress.Advance(task.StageProgress, types.DtSubStageVideo, progress.Percentage)
+		}
+	}
+
+	if len(task.StageProgress) > 0 {
+		task.OverallPercentage = stageprogress.Overall(task.StageProgress)
+	} else if progress.Stage == types.DtStageCompleted {
+		task.OverallPercentage = 100
+	}
+
+	progress.Overall = task.OverallPercentage
+	progress.Stages = append([]types.DtStageProgress{}, task.StageProgress...)
+}
+
+// downloadSubStage 判断 yt-dlp 当前下载的是视频流还是音频流
+func downloadSubStage(update ytdlp.ProgressUpdate) string {
+	if update.Info != nil && update.Info.ExtractedFormat != nil {
+		f := update.Info.ExtractedFormat
+		if f.VCodec != nil && *f.VCodec == "none" && f.ACodec != nil && *f.ACodec != "none" {
+			return types.DtSubStageAudio
+		}
+	}
+	return types.DtSubStageVideo
+}
+
+// finalDownloadSubStage 下载阶段最后一个子阶段（重编码或合并）
+func finalDownloadSubStage(task *types.DtTaskStatus) string {
+	if task.RecodeExtention != "" {
+		return types.DtSubStageTranscode
+	}
+	return types.DtSubStageMerge
+}
@@ -0,0 +1,95 @@
+package downtasks
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stageNames(stages []types.DtStageProgress) []string {
+	names := make([]string, 0, len(stages))
+	for _, s := range stages {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestPlanStages(t *testing.T) {
+	request := &types.DownloadVideoRequest{Type: consts.TASK_TYPE_QUICK}
+	assert.True(t, defaultSeparateAudio(request))
+	assert.Equal(t, []string{types.DtSubStageVideo, types.DtSubStageAudio, types.DtSubStageMerge},
+		stageNames(planStages(&types.DtTaskStatus{}, request, defaultSeparateAudio(request))))
+
+	request = &types.DownloadVideoRequest{Type: consts.TASK_TYPE_CUSTOM, FormatID: "22"}
+	assert.False(t, defaultSeparateAudio(request))
+	request.FormatID = ""
+	assert.True(t, defaultSeparateAudio(request))
+
+	// 翻译与嵌入只在自定义任务中出现
+	request = &types.DownloadVideoRequest{Type: consts.TASK_TYPE_CUSTOM, FormatID: "137+140", DownloadSubs: true, TranslateTo: "zh"}
+	assert.Equal(t, []string{
+		types.DtSubStageVideo, types.DtSubStageAudio, types.DtSubStageMerge, types.DtSubStageTranscode,
+		types.DtSubStageSubtitles, types.DtSubStageTranslate, types.DtSubStageEmbed,
+	}, stageNames(planStages(&types.DtTaskStatus{RecodeExtention: "mp4"}, request, defaultSeparateAudio(request))))
+
+	request.Type = consts.TASK_TYPE_QUICK
+	assert.NotContains(t, stageNames(planStages(&types.DtTaskStatus{}, request, true)), types.DtSubStageTranslate)
+}
+
+// 按 processTask 的事件顺序推进完整计划：总进度单调递增，翻译与嵌入阶段的事件也计入总进度
+func TestOverallProgressAcrossStagePlan(t *testing.T) {
+	request := &types.DownloadVideoRequest{Type: consts.TASK_TYPE_CUSTOM, DownloadSubs: true, TranslateTo: "zh"}
+	task := &types.DtTaskStatus{ID: "t1", Type: consts.TASK_TYPE_CUSTOM}
+	task.StageProgress = planStages(task, request, defaultSeparateAudio(request))
+	require.Len(t, task.StageProgress, 6)
+
+	events := []*types.DtProgress{
+		{Stage: types.DtStageDownloading, SubStage: types.DtSubStageVideo, Percentage: 50},
+		{Stage: types.DtStageDownloading, SubStage: types.DtSubStageVideo, Percentage: 100},
+		{Stage: types.DtStageDownloading, SubStage: types.DtSubStageAudio, Percentage: 100},
+		{Stage: types.DtStageDownloading, SubStage: types.DtSubStageMerge, Percentage: 100},
+		{Stage: types.DtStageDownloading, SubStage: types.DtSubStageSubtitles, Percentage: 0},
+		{Stage: types.DtStageDownloading, SubStage: types.DtSubStageSubtitles, Percentage: 100},
+		{Stage: types.DtStageTranslating, SubStage: types.DtSubStageTranslate, Percentage: 0},
+		// 翻译进行中的事件未带子阶段时按任务阶段补全
+		{Stage: types.DtStageTranslating, Percentage: 60},
+		{Stage: types.DtStageTranslating, SubStage: types.DtSubStageTranslate, Percentage: 100},
+		{Stage: types.DtStageEmbedding, SubStage: types.DtSubStageEmbed, Percentage: 0},
+		{Stage: types.DtStageEmbedding, Percentage: 50},
+		{Stage: types.DtStageEmbedding, SubStage: types.DtSubStageEmbed, Percentage: 100},
+	}
+
+	last := 0.0
+	for i, event := range events {
+		event.ID = task.ID
+		task.UpdateFromProgress(event)
+		applyStageProgress(task, event)
+		assert.GreaterOrEqual(t, event.Overall, last, "event %d", i)
+		assert.NotEmpty(t, event.SubStage, "event %d", i)
+		assert.Equal(t, task.OverallPercentage, event.Overall)
+		last = event.Overall
+	}
+
+	// 翻译阶段的中间事件推进了总进度
+	assert.InDelta(t, 100, last, 0.001)
+	translate := events[7]
+	assert.Greater(t, translate.Overall, events[6].Overall)
+	assert.Equal(t, 60.0, translate.Stages[4].Percentage)
+
+	done := &types.DtProgress{ID: task.ID, Stage: types.DtStageCompleted, Percentage: 100}
+	applyStageProgress(task, done)
+	assert.Equal(t, 100.0, done.Overall)
+}
+
+func TestApplyStageProgressAudioOnly(t *testing.T) {
+	// 纯音频格式：音频进度计入视频阶段
+	task := &types.DtTaskStatus{ID: "t1"}
+	task.StageProgress = planStages(task, &types.DownloadVideoRequest{Type: consts.TASK_TYPE_QUICK, Video: "ba"}, false)
+	progress := &types.DtProgress{ID: "t1", Stage: types.DtStageDownloading, SubStage: types.DtSubStageAudio, Percentage: 40}
+	applyStageProgress(task, progress)
+	assert.Equal(t, 40.0, task.StageProgress[0].Percentage)
+	assert.Greater(t, progress.Overall, 0.0)
+}
@@ -0,0 +1,171 @@
+package downtasks
+
+import (
+	"CanMe/backend/pkg/domainrules"
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/pkg/ytdlprun"
+	"CanMe/backend/types"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ListDownloadRules 列出所有按域名下载规则
+func (s *Service) ListDownloadRules() ([]*types.DtDownloadRule, error) {
+	return s.boltStorage.ListDownloadRules()
+}
+
+// SaveDownloadRule 校验并保存下载规则（ID 为空时创建）
+func (s *Service) SaveDownloadRule(rule *types.DtDownloadRule) (*types.DtDownloadRule, error) {
+	if rule == nil {
+		return nil, fmt.Errorf("rule is nil")
+	}
+	if err := validateDownloadRule(rule); err != nil {
+		return nil, err
+	}
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	if existing, err := s.boltStorage.GetDownloadRule(rule.ID); err == nil {
+		rule.CreatedAt = existing.CreatedAt
+	}
+	if rule.CreatedAt == 0 {
+		rule.CreatedAt = time.Now().Unix()
+	}
+	if err := s.boltStorage.SaveDownloadRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteDownloadRule 删除下载规则
+func (s *Service) DeleteDownloadRule(id string) error {
+	return s.boltStorage.DeleteDownloadRule(id)
+}
+
+// MatchDownloadRule 返回 URL 命中的规则，未命中返回 nil
+func (s *Service) MatchDownloadRule(url string) (*types.DtDownloadRule, error) {
+	rules, err := s.boltStorage.ListDownloadRules()
+	if err != nil {
+		return nil, err
+	}
+	return domainrules.Select(rules, url), nil
+}
+
+func validateDownloadRule(rule *types.DtDownloadRule) error {
+	domains := make([]string, 0, len(rule.Domains))
+	for _, d := range rule.Domains {
+		if p := domainrules.NormalizePattern(d); p != "" && p != "*." {
+			domains = append(domains, p)
+		}
+	}
+	if len(domains) == 0 {
+		return fmt.Errorf("at least one domain is required")
+	}
+	rule.Domains = domains
+
+	rule.Proxy = strings.TrimSpace(rule.Proxy)
+	if err := domainrules.ValidateProxy(rule.Proxy); err != nil {
+		return err
+	}
+	rule.OutputDir = strings.TrimSpace(rule.OutputDir)
+	rule.FormatPreset = strings.TrimSpace(rule.FormatPreset)
+	return nil
+}
+
+// matchRule 查找 URL 命中的规则，读取失败时仅记录日志
+func (s *Service) matchRule(url string) *types.DtDownloadRule {
+	if s.boltStorage == nil {
+		return nil
+	}
+	rule, err := s.MatchDownloadRule(url)
+	if err != nil {
+		logger.Warn("failed to load download rules", zap.Error(err))
+		return nil
+	}
+	if rule != nil {
+		logger.Debug("download rule matched", zap.String("url", url), zap.String("rule", rule.Name))
+	}
+	return rule
+}
+
+// applyRuleToRequest 用规则填充自定义下载请求中的空字段
+func applyRuleToRequest(rule *types.DtDownloadRule, request *types.DtDownloadRequest) {
+	if rule == nil {
+		return
+	}
+	if request.Browser == "" {
+		request.Browser = rule.Browser
+	}
+	if request.FormatID == "" {
+		request.FormatID = domainrules.FormatSelector(rule.FormatPreset)
+	}
+	if len(request.SubLangs) == 0 && len(rule.SubLangs) > 0 {
+		request.SubLangs = append([]string{}, rule.SubLangs...)
+	}
+}
+
+// applyRuleToQuickRequest 用规则填充快速下载请求中的空字段
+func applyRuleToQuickRequest(rule *types.DtDownloadRule, request *types.DtQuickDownloadRequest) {
+	if rule == nil {
+		return
+	}
+	if request.Browser == "" {
+		request.Browser = rule.Browser
+	}
+	if request.Video == "" && rule.FormatPreset != "" {
+		request.Video = domainrules.FormatSelector(rule.FormatPreset)
+		if request.Video == "" {
+			request.Video = "best"
+		}
+	}
+}
+
+// ruleOutputDir 返回规则指定的输出目录（支持 ~），不可用时返回空字符串
+func ruleOutputDir(rule *types.DtDownloadRule) string {
+	if rule == nil || rule.OutputDir == "" {
+		return ""
+	}
+	dir := rule.OutputDir
+	if dir == "~" || strings.HasPrefix(dir, "~/") || strings.HasPrefix(dir, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		logger.Warn("download rule output dir unavailable", zap.String("dir", dir), zap.Error(err))
+		return ""
+	}
+	return dir
+}
+
+// ruleProxy 返回规则的代理设置
+func ruleProxy(rule *types.DtDownloadRule) string {
+	if rule == nil {
+		return ""
+	}
+	return rule.Proxy
+}
+
+// applyProxyOverride 使用任务级代理覆盖全局代理；direct 表示直连
+func applyProxyOverride(dl *ytdlprun.Command, proxy string) {
+	switch {
+	case proxy == "":
+		return
+	case strings.EqualFold(proxy, types.DtProxyDirect):
+		dl.SetEnvVar("HTTP_PROXY", "").
+			SetEnvVar("HTTPS_PROXY", "").
+			Set("--proxy", "")
+	default:
+		dl.SetEnvVar("HTTP_PROXY", proxy).
+			SetEnvVar("HTTPS_PROXY", proxy).
+			Set("--proxy", proxy)
+	}
+}
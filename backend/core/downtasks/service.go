@@ -9,11 +9,16 @@ import (
 	"CanMe/backend/pkg/events"
 	"CanMe/backend/pkg/logger"
 	"CanMe/backend/pkg/proxy"
+	"CanMe/backend/pkg/tasklog"
+	"CanMe/backend/pkg/urlcanon"
+	"CanMe/backend/pkg/ytdlperrors"
+	"CanMe/backend/pkg/ytdlprun"
 	"CanMe/backend/services/preferences"
 	"CanMe/backend/storage"
 	"CanMe/backend/types"
 
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -75,6 +80,15 @@ type Service struct {
 
 	// cookie manager
 	cookieManager browercookies.CookieManager
+
+	// per-task command logs
+	taskLogs *tasklog.Store
+
+	// 短链接展开
+	urlResolver *urlcanon.Resolver
+
+	// 运行中任务的取消函数 taskID -> *taskRun
+	running sync.Map
 }
 
 func NewService(eventBus events.EventBus,
@@ -106,6 +120,8 @@ func NewService(eventBus events.EventBus,
 		depManager:     depManager,
 		cookieManager:  browercookies.NewCookieManager(boltStorage, depManager),
 	}
+	s.taskLogs = s.newTaskLogStore()
+	s.urlResolver = s.newURLResolver()
 
 	return s
 }
@@ -113,6 +129,9 @@ func NewService(eventBus events.EventBus,
 func (s *Service) SetContext(ctx context.Context) {
 	s.ctx = ctx
 	s.taskManager = NewTaskManager(ctx, s.boltStorage)
+	if s.boltStorage != nil {
+		s.startTrashPurger()
+	}
 }
 
 func (s *Service) ListTasks() []*types.DtTaskStatus {
@@ -209,7 +228,12 @@ func (s *Service) Path() string {
 
 // DeleteTask 删除指定ID的任务
 func (s *Service) DeleteTask(id string) error {
-	return s.taskManager.DeleteTask(id)
+	if err := s.taskManager.DeleteTask(id); err != nil {
+		return err
+	}
+	s.cancelRunning(id, errTaskDeleted)
+	s.deleteTaskLog(id)
+	return nil
 }
 
 func (s *Service) GetTaskStatus(id string) (*types.DtTaskStatus, error) {
@@ -230,76 +254,32 @@ func (s *Service) UpdateTask(task *types.DtTaskStatus) error {
 }
 
 func (s *Service) GetTaskStatusByURL(raw string) (bool, *types.DtTaskStatus, error) {
-	target := normalizeURLForCompare(raw)
+	// 离线规范化结果用于匹配尚未展开的短链接任务
+	offline := urlcanon.Canonicalize(raw)
+	target := s.resolveCanonicalURL(raw)
 	list := s.taskManager.ListTasks() // use raw list to fetch pointer
 	for _, task := range list {
 		if task == nil {
 			continue
 		}
-		if normalizeURLForCompare(task.URL) == target {
+		if c := taskCanonicalURL(task); c == target || c == offline {
 			return true, task, nil
 		}
 	}
 	return false, nil, nil
 }
 
-// normalizeURLForCompare tries to normalize URLs for duplicate detection without network access.
-func normalizeURLForCompare(raw string) string {
-	s := strings.TrimSpace(raw)
-	if s == "" {
-		return ""
-	}
-	u, err := url.Parse(s)
-	if err != nil {
-		return s
-	}
-	u.User = nil
-	u.Fragment = ""
-	u.Scheme = strings.ToLower(u.Scheme)
-	u.Host = strings.ToLower(u.Host)
-	// Normalize youtu.be short links to youtube watch URL
-	if strings.EqualFold(u.Host, "youtu.be") {
-		id := strings.Trim(u.Path, "/")
-		if id != "" {
-			u.Host = "www.youtube.com"
-			u.Path = "/watch"
-			q := url.Values{}
-			q.Set("v", id)
-			u.RawQuery = q.Encode()
-			return u.String()
-		}
-	}
-	// Remove common tracking params while keeping useful ones
-	if u.RawQuery != "" {
-		q := u.Query()
-		for key := range q {
-			lk := strings.ToLower(key)
-			if strings.HasPrefix(lk, "utm_") || lk == "fbclid" || lk == "gclid" {
-				q.Del(key)
-			}
-		}
-		// sort keys deterministically
-		if len(q) > 0 {
-			// Re-encode preserves deterministic order in Go 1.20+
-			u.RawQuery = q.Encode()
-		} else {
-			u.RawQuery = ""
-		}
-	}
-	// remove trailing slash (except root)
-	if len(u.Path) > 1 && strings.HasSuffix(u.Path, "/") {
-		u.Path = strings.TrimRight(u.Path, "/")
-	}
-	return u.String()
-}
-
 func (s *Service) GetFormats() map[string][]*types.ConversionFormat {
 	return s.taskManager.ListAvalibleConversionFormats()
 }
 
-func (s *Service) newCommand(enbaledFFMpeg bool, cookiesFile string) (*ytdlp.Command, error) {
-	// new
-	dl := ytdlp.New()
+func (s *Service) newCommand(enbaledFFMpeg bool, cookiesFile string) (*ytdlprun.Command, error) {
+	// yt-dlp mustinstall
+	ytExecPath, err := s.YTDLPExecPath()
+	if err != nil {
+		return nil, err
+	}
+	dl := ytdlprun.New(ytExecPath)
 
 	// proxy
 	if httpProxy := s.proxyManager.GetProxyString(); httpProxy != "" {
@@ -312,13 +292,6 @@ func (s *Service) newCommand(enbaledFFMpeg bool, cookiesFile string) (*ytdlp.Com
 	dl.SetEnvVar("PYTHONUTF8", "1").
 		SetEnvVar("PYTHONIOENCODING", "utf-8")
 
-	// yt-dlp mustinstall
-	ytExecPath, err := s.YTDLPExecPath()
-	if err != nil {
-		return nil, err
-	}
-	dl.SetExecutable(ytExecPath)
-
 	// set temp dir: prefer fast system temp to avoid slow I/O (e.g., iCloud/AV scanning in Downloads)
 	sysTmp := os.TempDir()
 	tmpPath := filepath.Join(sysTmp, "CanMe")
@@ -347,12 +320,12 @@ func (s *Service) newCommand(enbaledFFMpeg bool, cookiesFile string) (*ytdlp.Com
 			return nil, err
 		}
 		// set ffmpeg
-		dl.FFmpegLocation(ffExecPath)
+		dl.Set("--ffmpeg-location", ffExecPath)
 	}
 
 	if cookiesFile != "" {
 		// set cookies
-		dl.Cookies(cookiesFile)
+		dl.Set("--cookies", cookiesFile)
 	}
 
 	return dl, nil
@@ -388,6 +361,11 @@ func (s *Service) createTempFile(prefix string, data []byte) (string, error) {
 
 // ParseURL 从 YouTube 获取视频内容信息
 func (s *Service) ParseURL(url string, browser string) (*ytdlp.ExtractedInfo, error) {
+	return s.parseURL(url, browser, "")
+}
+
+// parseURL 获取视频内容信息，taskID 不为空时将 yt-dlp 调用写入该任务的日志
+func (s *Service) parseURL(url, browser, taskID string) (*ytdlp.ExtractedInfo, error) {
 	// 获取Cookies
 	var cookiesFile string
 	if browser != "" {
@@ -405,14 +383,15 @@ func (s *Service) ParseURL(url string, browser string) (*ytdlp.ExtractedInfo, er
 	if err != nil {
 		return nil, err
 	}
+	applyProxyOverride(dl, ruleProxy(s.matchRule(url)))
 
 	// 添加选项
-	dl.SkipDownload().
-		DumpSingleJSON().
-		NoPlaylist() // 保持与下载流程一致，避免返回整份播放列表
+	dl.Set("--skip-download").
+		Set("--dump-single-json").
+		Set("--no-playlist") // 保持与下载流程一致，避免返回整份播放列表
 
 	// 运行 yt-dlp 命令
-	result, err := dl.Run(s.ctx, url)
+	result, err := s.runYtdlp(s.ctx, taskID, "yt-dlp metadata", dl, url)
 	if err != nil {
 		return nil, err
 	}
@@ -462,7 +441,7 @@ func (s *Service) selectPrimaryEntry(info *ytdlp.ExtractedInfo) *ytdlp.Extracted
 	return info
 }
 
-func (s *Service) getVideoMetadata(url, browser string) (*ytdlp.ExtractedInfo, error) {
+func (s *Service) getVideoMetadata(url, browser, taskID string) (*ytdlp.ExtractedInfo, error) {
 	// 尝试从缓存获取元数据
 	metadata, ok := s.getCachedMetadata(url)
 	if ok {
@@ -470,7 +449,7 @@ func (s *Service) getVideoMetadata(url, browser string) (*ytdlp.ExtractedInfo, e
 	}
 
 	// 如果缓存中没有，则重新获取
-	return s.ParseURL(url, browser)
+	return s.parseURL(url, browser, taskID)
 }
 
 type InfoChan chan *types.FillTaskInfo
@@ -486,13 +465,28 @@ func (s *Service) Download(request *types.DtDownloadRequest) (*types.DtDownloadR
 
 	task.Type = consts.TASK_TYPE_CUSTOM
 
+	// 按域名规则补全未指定的字段
+	rule := s.matchRule(request.URL)
+	applyRuleToRequest(rule, request)
+
 	// 尝试从缓存获取元数据
-	metadata, err := s.getVideoMetadata(request.URL, request.Browser)
+	metadata, err := s.getVideoMetadata(request.URL, request.Browser, taskID)
 	if err != nil {
 		s.handleTaskError(task, err, nil)
 		return nil, err
 	}
 
+	// 内容去重：同一站点的同一视频已下载完成
+	var extractor string
+	if metadata.Extractor != nil {
+		extractor = *metadata.Extractor
+	}
+	existing, skip := s.checkVideoDuplicate(taskID, extractor, metadata.ID)
+	if skip {
+		s.taskManager.DeleteTask(taskID)
+		return &types.DtDownloadResponse{ID: existing.ID, Status: existing.Stage, DuplicateOf: existing.ID, Skipped: true}, nil
+	}
+
 	// request params
 	task.DownloadSubs = request.DownloadSubs
 	task.SubLangs = request.SubLangs
@@ -525,6 +519,7 @@ func (s *Service) Download(request *types.DtDownloadRequest) (*types.DtDownloadR
 		task.Thumbnail = thumb
 	}
 	task.URL = request.URL
+	task.CanonicalURL = urlcanon.Canonicalize(request.URL)
 	task.Stage = types.DtStageDownloading
 	task.Percentage = 0
 	task.FormatID = request.FormatID
@@ -540,9 +535,16 @@ func (s *Service) Download(request *types.DtDownloadRequest) (*types.DtDownloadR
 	if metadata.Duration != nil {
 		task.Duration = *metadata.Duration
 	}
+	task.VideoID = metadata.ID
+	if metadata.UploadDate != nil {
+		task.UploadDate = *metadata.UploadDate
+	}
 
-	// 获取输出目录
+	// 获取输出目录（规则指定的目录优先）
 	outputDir, err := s.downDir(task.Extractor)
+	if dir := ruleOutputDir(rule); dir != "" {
+		outputDir, err = dir, nil
+	}
 	if err == nil {
 		task.OutputDir = outputDir
 		logger.Debug("download: set output dir", zap.String("taskId", task.ID), zap.String("outputDir", outputDir))
@@ -578,20 +580,16 @@ func (s *Service) Download(request *types.DtDownloadRequest) (*types.DtDownloadR
 	}
 
 	s.taskManager.UpdateTask(task)
+	s.markDuplicate(task, existing, types.DtDuplicateByVideoID)
 
 	resp := &types.DtDownloadResponse{
-		ID:     taskID,
-		Status: types.DtStageDownloading,
+		ID:          taskID,
+		Status:      types.DtStageDownloading,
+		DuplicateOf: task.DuplicateOf,
 	}
 
-	// initial task info channel
-	infoChan := make(InfoChan, 1)
-
-	// 初始化进度通道
-	progressChan := make(ProgressChan, 100)
-
 	// 启动处理流程
-	go s.processTask(task, &types.DownloadVideoRequest{
+	s.startTask(task, &types.DownloadVideoRequest{
 		Type:          task.Type,
 		URL:           request.URL,
 		Browser:       request.Browser,
@@ -601,13 +599,8 @@ func (s *Service) Download(request *types.DtDownloadRequest) (*types.DtDownloadR
 		SubFormat:     request.SubFormat,
 		TranslateTo:   request.TranslateTo,
 		SubtitleStyle: request.SubtitleStyle,
-	}, infoChan, progressChan)
-
-	// start info monitor
-	go s.fillTaskInfo(infoChan)
-
-	// 启动进度监控
-	go s.monitorProgress(progressChan)
+		Proxy:         ruleProxy(rule),
+	})
 
 	return resp, nil
 }
@@ -618,8 +611,27 @@ func (s *Service) QuickDownload(request *types.DtQuickDownloadRequest) (*types.D
 	taskID := uuid.New().String()
 	task := s.taskManager.CreateTask(taskID)
 
+	// 按域名规则补全未指定的字段
+	rule := s.matchRule(request.URL)
+	applyRuleToQuickRequest(rule, request)
+
+	// skip 模式下需先获取视频ID判断是否重复（warn 模式在首次进度回调时检查，不阻塞返回）
+	if s.pref.GetDuplicatesConfig().OnDuplicate == "skip" {
+		if metadata, err := s.getVideoMetadata(request.URL, request.Browser, taskID); err == nil {
+			var extractor string
+			if metadata.Extractor != nil {
+				extractor = *metadata.Extractor
+			}
+			if existing, skip := s.checkVideoDuplicate(taskID, extractor, metadata.ID); skip {
+				s.taskManager.DeleteTask(taskID)
+				return &types.DtQuickDownloadResponse{ID: existing.ID, Status: existing.Stage, DuplicateOf: existing.ID, Skipped: true}, nil
+			}
+		}
+	}
+
 	task.Type = request.Type
 	task.URL = request.URL
+	task.CanonicalURL = urlcanon.Canonicalize(request.URL)
 	task.Browser = request.Browser
 
 	task.Stage = types.DtStageDownloading
@@ -641,6 +653,9 @@ func (s *Service) QuickDownload(request *types.DtQuickDownloadRequest) (*types.D
 
 	// define output dir, quick / mcp
 	outputDir, err := s.downDir(request.Type)
+	if dir := ruleOutputDir(rule); dir != "" {
+		outputDir, err = dir, nil
+	}
 	if err == nil {
 		task.OutputDir = outputDir
 	}
@@ -652,14 +667,8 @@ func (s *Service) QuickDownload(request *types.DtQuickDownloadRequest) (*types.D
 		Status: types.DtStageDownloading,
 	}
 
-	// initial task info channel
-	infoChan := make(InfoChan, 1)
-
-	// 初始化进度通道
-	progressChan := make(ProgressChan, 100)
-
 	// 启动处理流程
-	go s.processTask(task, &types.DownloadVideoRequest{
+	quickRequest := &types.DownloadVideoRequest{
 		Type:        request.Type,
 		URL:         request.URL,
 		Browser:     request.Browser,
@@ -668,15 +677,36 @@ func (s *Service) QuickDownload(request *types.DtQuickDownloadRequest) (*types.D
 		// Trigger subtitle download in a separate step for quick mode when bestCaption is chosen
 		DownloadSubs: request.BestCaption,
 		SubFormat:    "best",
-	}, infoChan, progressChan)
+		Proxy:        ruleProxy(rule),
+	}
+	if rule != nil {
+		quickRequest.SubLangs = rule.SubLangs
+	}
+	s.startTask(task, quickRequest)
+
+	return resp, nil
+}
+
+// startTask 初始化通道并异步启动任务处理流程
+func (s *Service) startTask(task *types.DtTaskStatus, request *types.DownloadVideoRequest) {
+	// initial task info channel
+	infoChan := make(InfoChan, 1)
+
+	// 初始化进度通道
+	progressChan := make(ProgressChan, 100)
+
+	// 启动处理流程（同步登记，返回后即可取消）
+	ctx, done := s.beginTask(task.ID)
+	go func() {
+		defer done()
+		s.processTask(ctx, task, request, infoChan, progressChan)
+	}()
 
 	// start info monitor
 	go s.fillTaskInfo(infoChan)
 
 	// 启动进度监控
 	go s.monitorProgress(progressChan)
-
-	return resp, nil
 }
 
 // 缓存元数据
@@ -688,12 +718,13 @@ type cachedMetadata struct {
 const metadataTTL = 30 * time.Minute
 
 func (s *Service) cacheMetadata(url string, metadata *ytdlp.ExtractedInfo) {
-	s.metadataCache.Store(url, &cachedMetadata{at: time.Now(), info: metadata})
+	s.metadataCache.Store(urlcanon.Canonicalize(url), &cachedMetadata{at: time.Now(), info: metadata})
 }
 
 // 获取缓存的元数据（带 TTL）
 func (s *Service) getCachedMetadata(url string) (*ytdlp.ExtractedInfo, bool) {
-	value, ok := s.metadataCache.Load(url)
+	key := urlcanon.Canonicalize(url)
+	value, ok := s.metadataCache.Load(key)
 	if !ok {
 		return nil, false
 	}
@@ -702,14 +733,14 @@ func (s *Service) getCachedMetadata(url string) (*ytdlp.ExtractedInfo, bool) {
 		return nil, false
 	}
 	if time.Since(cm.at) > metadataTTL {
-		s.metadataCache.Delete(url)
+		s.metadataCache.Delete(key)
 		return nil, false
 	}
 	return cm.info, cm.info != nil
 }
 
 // processTask 处理任务的主流程
-func (s *Service) processTask(task *types.DtTaskStatus, request *types.DownloadVideoRequest, infoChan InfoChan, progressChan ProgressChan) {
+func (s *Service) processTask(ctx context.Context, task *types.DtTaskStatus, request *types.DownloadVideoRequest, infoChan InfoChan, progressChan ProgressChan) {
 	// Ensure we always close infoChan to stop fillTaskInfo goroutine
 	// after the video (and optional subtitle) processing completes.
 	// Safe because sends to infoChan only occur during downloadVideo's
@@ -717,14 +748,47 @@ func (s *Service) processTask(task *types.DtTaskStatus, request *types.DownloadV
 	defer close(infoChan)
 	defer close(progressChan)
 
+	// 持久化请求，便于暂停后恢复
+	task.DownloadRequest = request
+	s.taskManager.UpdateTask(task)
+
+	// 展开短链接，便于后续重复检测
+	s.refreshCanonicalURL(task)
+
+	// 子阶段计划（用于加权总进度）
+	s.setStagePlan(task, planStages(task, request, defaultSeparateAudio(request)))
+
+	// 磁盘空间预检（拒绝或排队）
+	if err := s.waitForDiskSpace(ctx, task, request, progressChan); err != nil {
+		if s.finishStopped(ctx, task, progressChan) {
+			return
+		}
+		s.handleTaskError(task, err, progressChan)
+		return
+	}
+
 	// 第一阶段：下载视频
-	err := s.downloadVideo(task, request, infoChan, progressChan)
+	err := s.downloadVideo(ctx, task, request, infoChan, progressChan)
+	if s.finishStopped(ctx, task, progressChan) {
+		return
+	}
 	if err != nil {
+		var dse *diskSpaceError
+		if errors.As(err, &dse) {
+			s.pauseTask(task, err, progressChan)
+			return
+		}
 		s.handleTaskError(task, err, progressChan)
 		return
 	}
 	s.taskManager.UpdateTask(task)
 
+	// 校验输出文件（ffprobe）
+	if err := s.verifyTask(task); err != nil {
+		s.handleTaskError(task, err, progressChan)
+		return
+	}
+
 	// 第二阶段：翻译字幕（如果需要）
 	if request.Type == consts.TASK_TYPE_CUSTOM {
 		if request.DownloadSubs && request.TranslateTo != "" {
@@ -736,6 +800,7 @@ func (s *Service) processTask(task *types.DtTaskStatus, request *types.DownloadV
 				ID:         task.ID,
 				Type:       task.Type,
 				Stage:      types.DtStageTranslating,
+				SubStage:   types.DtSubStageTranslate,
 				Percentage: 0,
 				StageInfo:  "Start translating subtitles",
 			}
@@ -745,6 +810,14 @@ func (s *Service) processTask(task *types.DtTaskStatus, request *types.DownloadV
 				s.handleTaskError(task, err, progressChan)
 				return
 			}
+			progressChan <- &types.DtProgress{
+				ID:         task.ID,
+				Type:       task.Type,
+				Stage:      types.DtStageTranslating,
+				SubStage:   types.DtSubStageTranslate,
+				Percentage: 100,
+				StageInfo:  "Subtitles translated",
+			}
 			task.TranslatedSubs = append(task.TranslatedSubs, subtitleFile)
 			// add to all files
 			task.AllFiles = append(task.AllFiles, subtitleFile)
@@ -763,6 +836,7 @@ func (s *Service) processTask(task *types.DtTaskStatus, request *types.DownloadV
 				ID:         task.ID,
 				Type:       task.Type,
 				Stage:      types.DtStageEmbedding,
+				SubStage:   types.DtSubStageEmbed,
 				Percentage: 0,
 				StageInfo:  "Start embedding subtitles",
 			}
@@ -772,6 +846,14 @@ func (s *Service) processTask(task *types.DtTaskStatus, request *types.DownloadV
 				s.handleTaskError(task, err, progressChan)
 				return
 			}
+			progressChan <- &types.DtProgress{
+				ID:         task.ID,
+				Type:       task.Type,
+				Stage:      types.DtStageEmbedding,
+				SubStage:   types.DtSubStageEmbed,
+				Percentage: 100,
+				StageInfo:  "Subtitles embedded",
+			}
 			task.EmbeddedVideoFiles = append(task.EmbeddedVideoFiles, embeddedVideo)
 			// add to all files
 			task.AllFiles = append(task.AllFiles, embeddedVideo)
@@ -780,6 +862,22 @@ func (s *Service) processTask(task *types.DtTaskStatus, request *types.DownloadV
 			task.EmbeddedVideoFiles = []string{}
 		}
 	}
+	// 后续阶段不可中断，停止请求在此生效
+	if s.finishStopped(ctx, task, progressChan) {
+		return
+	}
+
+	// 按媒体库模板整理文件（失败不影响任务完成）
+	if s.pref.GetLibraryConfig().Enabled {
+		if _, err := s.organizeTask(task); err != nil {
+			logger.Warn("Failed to organize task files", zap.String("taskId", task.ID), zap.Error(err))
+			s.appendTaskLog(task.ID, "info", "library organizer: "+err.Error())
+		}
+	}
+
+	// 文件已在最终位置，后台记录内容哈希，发现不同链接下载的相同内容
+	s.hashTask(task)
+
 	// 完成所有处理
 	task.Stage = types.DtStageCompleted
 	s.taskManager.UpdateTask(task)
@@ -795,24 +893,57 @@ func (s *Service) processTask(task *types.DtTaskStatus, request *types.DownloadV
 	}
 }
 
-// handleTaskError 处理任务错误
+// handleTaskError 处理任务错误：解析 yt-dlp 输出为结构化错误，Error 仅保留简短说明
 func (s *Service) handleTaskError(task *types.DtTaskStatus, err error, progressChan ProgressChan) {
+	s.appendTaskLog(task.ID, "error", err.Error())
+	info := taskErrorInfo(err, s.errorLanguage())
+	message := info.Message
+	if info.Code == types.DtErrUnknown && info.Detail != "" {
+		message = info.Detail
+	}
+
 	task.Stage = types.DtStageFailed
-	task.Error = err.Error()
+	task.Error = message
+	task.ErrorInfo = info
 	s.taskManager.UpdateTask(task)
 
+	if progressChan == nil {
+		return
+	}
 	progressChan <- &types.DtProgress{
 		ID:         task.ID,
 		Type:       task.Type,
 		Stage:      types.DtStageFailed,
-		Error:      err.Error(),
+		Error:      message,
+		ErrorInfo:  info,
 		Percentage: 0,
 		StageInfo:  "Processing failed",
 	}
 }
 
+// taskErrorInfo 将任务错误转换为结构化错误：已知类型的错误直接给出编码，其余按 yt-dlp 输出归类
+func taskErrorInfo(err error, lang string) *types.DtTaskError {
+	var coe *corruptOutputError
+	if errors.As(err, &coe) {
+		return ytdlperrors.New(types.DtErrCorruptOutput, err.Error(), lang)
+	}
+	return ytdlperrors.Parse(err.Error(), lang)
+}
+
+// errorLanguage 返回错误说明使用的语言，auto 时参考系统 LANG
+func (s *Service) errorLanguage() string {
+	lang := ""
+	if s.pref != nil {
+		lang = s.pref.GetLanguage()
+	}
+	if lang == "" || lang == "auto" {
+		lang = os.Getenv("LANG")
+	}
+	return lang
+}
+
 // downloadVideo 实现视频下载阶段
-func (s *Service) downloadVideo(task *types.DtTaskStatus, request *types.DownloadVideoRequest, infoChan InfoChan, progressChan ProgressChan) error {
+func (s *Service) downloadVideo(ctx context.Context, task *types.DtTaskStatus, request *types.DownloadVideoRequest, infoChan InfoChan, progressChan ProgressChan) error {
 	// 发送阶段开始通知：仅 video，在字幕分步下载时再单独发布 subtitle:start
 	if s.eventBus != nil {
 		s.eventBus.Publish(s.ctx, &events.BaseEvent{
@@ -830,6 +961,7 @@ func (s *Service) downloadVideo(task *types.DtTaskStatus, request *types.Downloa
 		ID:         task.ID,
 		Type:       task.Type,
 		Stage:      types.DtStageDownloading,
+		SubStage:   types.DtSubStageVideo,
 		Percentage: 0,
 		StageInfo:  "Start downloading video",
 	}
@@ -853,9 +985,10 @@ func (s *Service) downloadVideo(task *types.DtTaskStatus, request *types.Downloa
 		s.handleTaskError(task, err, progressChan)
 		return err
 	}
+	applyProxyOverride(dl, request.Proxy)
 
 	if task.Type == "custom" {
-		metadata, err := s.getVideoMetadata(request.URL, request.Browser)
+		metadata, err := s.getVideoMetadata(request.URL, request.Browser, task.ID)
 		if err != nil {
 			s.handleTaskError(task, err, progressChan)
 			return err
@@ -880,26 +1013,28 @@ func (s *Service) downloadVideo(task *types.DtTaskStatus, request *types.Downloa
 				}
 			}
 
+			s.setStagePlan(task, planStages(task, request, needAudio || strings.Contains(request.FormatID, "+")))
+
 			if needAudio {
 				if videoExt == "mp4" {
 					// MP4 视频，使用 M4A 音频
-					dl.Format(request.FormatID + "+bestaudio[ext=m4a]")
-					dl.MergeOutputFormat("mp4")
+					dl.Set("--format", request.FormatID+"+bestaudio[ext=m4a]")
+					dl.Set("--merge-output-format", "mp4")
 				} else if videoExt == "webm" {
 					// WebM 视频，使用 WebM 音频
-					dl.Format(request.FormatID + "+bestaudio[ext=webm]")
-					dl.MergeOutputFormat("webm")
+					dl.Set("--format", request.FormatID+"+bestaudio[ext=webm]")
+					dl.Set("--merge-output-format", "webm")
 				} else {
 					// 其他情况，让 yt-dlp 自行决定
-					dl.Format(request.FormatID + "+bestaudio")
+					dl.Set("--format", request.FormatID+"+bestaudio")
 					// 保持原来的设置
-					dl.MergeOutputFormat("mp4/webm")
+					dl.Set("--merge-output-format", "mp4/webm")
 				}
 			} else {
-				dl.Format(request.FormatID)
+				dl.Set("--format", request.FormatID)
 			}
 		} else {
-			dl.UnsetFormat()
+			dl.Unset("--format")
 		}
 
 		// 字幕分步下载，避免影响视频进度回调
@@ -909,9 +1044,9 @@ func (s *Service) downloadVideo(task *types.DtTaskStatus, request *types.Downloa
 		if request.Video != "" {
 			switch request.Video {
 			case "best":
-				dl.UnsetFormat()
+				dl.Unset("--format")
 			default:
-				dl.Format(request.Video)
+				dl.Set("--format", request.Video)
 			}
 		}
 	}
@@ -919,16 +1054,16 @@ func (s *Service) downloadVideo(task *types.DtTaskStatus, request *types.Downloa
 	// 设置工作目录和输出文件
 	// Quick 模式下允许覆盖已有文件（强制重新下载）；其他模式保持不覆盖行为
 	dl.SetWorkDir(task.OutputDir).
-		NoPlaylist()
+		Set("--no-playlist")
 	if request.Type == consts.TASK_TYPE_QUICK {
 		// Quick 模式强制覆盖，确保已存在视频也会重新下载
-		dl.ForceOverwrites()
+		dl.Set("--force-overwrites")
 	} else {
-		dl.NoOverwrites()
+		dl.Set("--no-overwrites")
 	}
-	dl.Output("%(title)s_%(height)sp_%(fps)dfps.%(ext)s").
-		NoRestrictFilenames().
-		NoWindowsFilenames()
+	dl.Set("--output", "%(title)s_%(height)sp_%(fps)dfps.%(ext)s").
+		Set("--no-restrict-filenames").
+		Set("--no-windows-filenames")
 	// print-to-file 已移除：依赖快照差异与兜底扫描
 	logger.Debug("download: command prepared",
 		zap.String("taskId", task.ID),
@@ -939,15 +1074,22 @@ func (s *Service) downloadVideo(task *types.DtTaskStatus, request *types.Downloa
 
 	// Recode
 	if task.RecodeExtention != "" {
-		dl.RecodeVideo(task.RecodeExtention)
+		dl.Set("--recode-video", task.RecodeExtention)
 	}
 
 	var once sync.Once
 	// speed smoother for stable bandwidth reporting
 	ss := newSpeedSmoother(2*time.Second, 2.5) // τ=2s, 峰值抑制系数=2.5
+	sampler := newThroughputSampler(task.ID, throughputSampleInterval)
+
+	// 下载过程中监控剩余空间，不足时取消进程并暂停任务
+	runCtx, cancelRun := context.WithCancelCause(ctx)
+	defer cancelRun(nil)
+	diskWatcher := s.newDiskSpaceWatcher(task, cancelRun)
+	plog := s.newProgressLogger(task.ID)
 
 	// 设置进度回调（更高频率，避免小文件/网络快时错过间隔）
-	dl.ProgressFunc(250*time.Millisecond, func(update ytdlp.ProgressUpdate) {
+	onProgress := func(update ytdlp.ProgressUpdate) {
 		once.Do(func() {
 			infoChan <- &types.FillTaskInfo{
 				ID:   task.ID,
@@ -955,8 +1097,15 @@ func (s *Service) downloadVideo(task *types.DtTaskStatus, request *types.Downloa
 			}
 		})
 
+		diskWatcher.Check(time.Now())
+		plog.Update(update)
+
 		// 平滑瞬时速度（时间常数型 EMA + 峰值抑制）
-		bps, ok := ss.Update(time.Now(), update.DownloadedBytes)
+		now := time.Now()
+		bps, ok := ss.Update(now, update.DownloadedBytes)
+		if ok {
+			sampler.Observe(now, bps, ss.totalBytes)
+		}
 		speedStr := ""
 		if ok && bps > 0 {
 			speedStr = formatBandwidth(bps)
@@ -975,6 +1124,7 @@ func (s *Service) downloadVideo(task *types.DtTaskStatus, request *types.Downloa
 			ID:            task.ID,
 			Type:          task.Type,
 			Stage:         types.DtStageDownloading,
+			SubStage:      downloadSubStage(update),
 			Percentage:    update.Percent(),
 			Speed:         speedStr,
 			Downloaded:    fmt.Sprintf("%.2f MB", float64(update.DownloadedBytes)/1024/1024),
@@ -992,15 +1142,25 @@ func (s *Service) downloadVideo(task *types.DtTaskStatus, request *types.Downloa
 		default:
 			// Channel is full, skip this update
 		}
-	})
+	}
+	dl.ProgressFunc(250*time.Millisecond, onProgress)
 
 	// 记录目录快照与开始时间（用于输出文件增量检测）
 	videoStartedAt := time.Now()
 	beforeSnap := s.dirSnapshot(task.OutputDir)
 
 	// 执行下载
-	result, err := dl.Run(s.ctx, request.URL)
+	s.appendTaskLog(task.ID, "info", "starting yt-dlp download")
+	result, err := s.runYtdlp(runCtx, task.ID, "yt-dlp download", dl, request.URL)
+	s.recordDownloadStats(task, sampler, videoStartedAt)
 	if err != nil {
+		var dse *diskSpaceError
+		if cause := context.Cause(runCtx); errors.As(cause, &dse) {
+			return dse
+		}
+		if taskStopped(ctx) {
+			return context.Cause(ctx)
+		}
 		s.handleTaskError(task, err, progressChan)
 		return fmt.Errorf("Download video failed: %w", err)
 	}
@@ -1136,18 +1296,42 @@ func (s *Service) downloadVideo(task *types.DtTaskStatus, request *types.Downloa
 	}
 	s.taskManager.UpdateTask(task)
 
+	progressChan <- &types.DtProgress{
+		ID:         task.ID,
+		Type:       task.Type,
+		Stage:      types.DtStageDownloading,
+		SubStage:   finalDownloadSubStage(task),
+		Percentage: 100,
+		StageInfo:  "Video downloaded",
+	}
+
 	// 分步下载字幕，避免影响视频进度输出
 	if request.DownloadSubs {
-		if err := s.downloadSubtitlesOnly(task, request); err != nil {
+		progressChan <- &types.DtProgress{
+			ID:        task.ID,
+			Type:      task.Type,
+			Stage:     types.DtStageDownloading,
+			SubStage:  types.DtSubStageSubtitles,
+			StageInfo: "Start downloading subtitles",
+		}
+		if err := s.downloadSubtitlesOnly(ctx, task, request); err != nil {
 			logger.Error("download subtitles failed", zap.Error(err))
 		}
+		progressChan <- &types.DtProgress{
+			ID:         task.ID,
+			Type:       task.Type,
+			Stage:      types.DtStageDownloading,
+			SubStage:   types.DtSubStageSubtitles,
+			Percentage: 100,
+			StageInfo:  "Subtitles downloaded",
+		}
 	}
 
 	return nil
 }
 
 // 单独下载字幕，避免与视频下载进度互相影响
-func (s *Service) downloadSubtitlesOnly(task *types.DtTaskStatus, request *types.DownloadVideoRequest) error {
+func (s *Service) downloadSubtitlesOnly(ctx context.Context, task *types.DtTaskStatus, request *types.DownloadVideoRequest) error {
 	// 获取Cookies
 	var cookiesFile string
 	if request.Browser != "" {
@@ -1165,32 +1349,33 @@ func (s *Service) downloadSubtitlesOnly(task *types.DtTaskStatus, request *types
 	if err != nil {
 		return err
 	}
+	applyProxyOverride(dl, request.Proxy)
 
 	// 仅下载字幕
-	dl.SkipDownload()
-	dl.WriteSubs()
+	dl.Set("--skip-download")
+	dl.Set("--write-subs")
 	if len(request.SubLangs) > 0 {
-		dl.SubLangs(strings.Join(request.SubLangs, ","))
+		dl.Set("--sub-langs", strings.Join(request.SubLangs, ","))
 	} else {
-		dl.SubLangs("all")
+		dl.Set("--sub-langs", "all")
 	}
 	// Use effective subtitle format (default to "best" when not provided)
 	effectiveSubFormat := request.SubFormat
 	if strings.TrimSpace(effectiveSubFormat) == "" {
 		effectiveSubFormat = "best"
 	}
-	dl.SubFormat(effectiveSubFormat)
+	dl.Set("--sub-format", effectiveSubFormat)
 
 	// 工作目录保持一致，输出模板保持一致（便于生成相同基名的字幕文件）
-	dl.SetWorkDir(task.OutputDir).NoPlaylist()
+	dl.SetWorkDir(task.OutputDir).Set("--no-playlist")
 	if request.Type == consts.TASK_TYPE_QUICK {
-		dl.ForceOverwrites()
+		dl.Set("--force-overwrites")
 	} else {
-		dl.NoOverwrites()
+		dl.Set("--no-overwrites")
 	}
-	dl.Output("%(title)s_%(height)sp_%(fps)dfps.%(ext)s").
-		NoRestrictFilenames().
-		NoWindowsFilenames()
+	dl.Set("--output", "%(title)s_%(height)sp_%(fps)dfps.%(ext)s").
+		Set("--no-restrict-filenames").
+		Set("--no-windows-filenames")
 	// print-to-file removed; rely on snapshot diff + stdout parsing
 
 	task.SubtitleProcess.Status = "working"
@@ -1208,7 +1393,8 @@ func (s *Service) downloadSubtitlesOnly(task *types.DtTaskStatus, request *types
 	// 运行
 	startedAt := time.Now()
 	beforeSnap := s.dirSnapshot(task.OutputDir)
-	result, err := dl.Run(s.ctx, request.URL)
+	s.appendTaskLog(task.ID, "info", "starting yt-dlp subtitles download")
+	result, err := s.runYtdlp(ctx, task.ID, "yt-dlp subtitles", dl, request.URL)
 	if err != nil {
 		return err
 	}
@@ -1385,6 +1571,12 @@ func (s *Service) fillTaskInfo(infoChan InfoChan) {
 			if taskInfo.Info.Duration != nil {
 				task.Duration = *taskInfo.Info.Duration
 			}
+			if taskInfo.Info.ID != "" {
+				task.VideoID = taskInfo.Info.ID
+			}
+			if taskInfo.Info.UploadDate != nil {
+				task.UploadDate = *taskInfo.Info.UploadDate
+			}
 
 			if task.Format == "" {
 				task.Format = taskInfo.Info.Extension
@@ -1406,6 +1598,9 @@ func (s *Service) fillTaskInfo(infoChan InfoChan) {
 		})
 
 		if updated != nil {
+			if existing, _ := s.checkVideoDuplicate(updated.ID, updated.Extractor, updated.VideoID); existing != nil {
+				s.markDuplicate(updated, existing, types.DtDuplicateByVideoID)
+			}
 			logger.Debug("fillTaskInfo: core metadata",
 				zap.String("taskId", updated.ID),
 				zap.String("extractor", updated.Extractor),
@@ -1427,7 +1622,7 @@ func (s *Service) fillTaskInfo(infoChan InfoChan) {
 }
 
 // parseYtdlpOutput 解析yt-dlp的输出结果，提取最终保存的文件信息
-func (s *Service) parseYtdlpOutput(task *types.DtTaskStatus, result *ytdlp.Result) {
+func (s *Service) parseYtdlpOutput(task *types.DtTaskStatus, result *ytdlprun.Result) {
 	// 保留 yt-dlp 原始输出，不做全局编码回退（避免把 UTF-8 误判为 GBK 导致乱码）
 	// 依赖 PYTHONUTF8/PYTHONIOENCODING=utf-8 与 --print 输出，尽量保持 UTF‑8
 	stdout := result.Stdout
@@ -1824,6 +2019,7 @@ func (s *Service) monitorProgress(progressChan ProgressChan) {
 
 		s.taskManager.UpdateTaskWith(progress.ID, func(task *types.DtTaskStatus) {
 			task.UpdateFromProgress(progress)
+			applyStageProgress(task, progress)
 		})
 
 		// eventbus
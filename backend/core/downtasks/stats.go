@@ -0,0 +1,267 @@
+package downtasks
+
+import (
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/types"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	throughputSampleInterval = 5 * time.Second
+	throughputRetention      = 90 * 24 * time.Hour
+	defaultStatsWindow       = 30 * 24 * time.Hour
+)
+
+// throughputSampler 在下载过程中按固定间隔采样平滑速度，并统计字节数与峰值
+type throughputSampler struct {
+	mu       sync.Mutex
+	taskID   string
+	interval time.Duration
+	first    time.Time
+	last     time.Time
+	lastSeen time.Time
+	bytes    int64
+	peak     float64
+	samples  []types.DtThroughputSample
+}
+
+func newThroughputSampler(taskID string, interval time.Duration) *throughputSampler {
+	return &throughputSampler{taskID: taskID, interval: interval}
+}
+
+// Observe 记录一次速度平滑器的输出；totalBytes 为累计下载字节数
+func (t *throughputSampler) Observe(now time.Time, bps float64, totalBytes int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.first.IsZero() {
+		t.first = now
+	}
+	t.lastSeen = now
+	if totalBytes > t.bytes {
+		t.bytes = totalBytes
+	}
+	if bps > t.peak {
+		t.peak = bps
+	}
+	if bps <= 0 || (!t.last.IsZero() && now.Sub(t.last) < t.interval) {
+		return
+	}
+	t.last = now
+	t.samples = append(t.samples, types.DtThroughputSample{Time: now.Unix(), TaskID: t.taskID, Bps: bps})
+}
+
+// Finish 返回本次下载的统计与采样
+func (t *throughputSampler) Finish(startedAt, now time.Time) (*types.DtDownloadStats, []types.DtThroughputSample) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := &types.DtDownloadStats{
+		StartedAt:  startedAt.Unix(),
+		FinishedAt: now.Unix(),
+		Bytes:      t.bytes,
+		PeakBps:    t.peak,
+	}
+	if !t.first.IsZero() {
+		if dt := t.lastSeen.Sub(t.first).Seconds(); dt > 0 {
+			stats.AvgBps = float64(t.bytes) / dt
+		}
+	}
+	return stats, append([]types.DtThroughputSample{}, t.samples...)
+}
+
+// recordDownloadStats 保存任务下载统计，并持久化速度采样
+func (s *Service) recordDownloadStats(task *types.DtTaskStatus, sampler *throughputSampler, startedAt time.Time) {
+	stats, samples := sampler.Finish(startedAt, time.Now())
+	task.Stats = stats
+	s.taskManager.UpdateTask(task)
+
+	if s.boltStorage == nil {
+		return
+	}
+	cutoff := time.Now().Add(-throughputRetention).Unix()
+	if err := s.boltStorage.SaveThroughputSamples(samples, cutoff); err != nil {
+		logger.Warn("failed to save throughput samples", zap.String("taskId", task.ID), zap.Error(err))
+	}
+}
+
+// GetStatistics 按时间段、站点、任务类型与错误类别聚合下载统计
+func (s *Service) GetStatistics(query types.DtStatsQuery) (*types.DtStatistics, error) {
+	now := time.Now()
+	if query.To <= 0 {
+		query.To = now.Unix()
+	}
+	if query.From <= 0 {
+		query.From = time.Unix(query.To, 0).Add(-defaultStatsWindow).Unix()
+	}
+	if query.From > query.To {
+		return nil, fmt.Errorf("invalid range: from %d is after to %d", query.From, query.To)
+	}
+	switch query.Period {
+	case "":
+		query.Period = "day"
+	case "day", "week":
+	default:
+		return nil, fmt.Errorf("unsupported period: %s", query.Period)
+	}
+
+	stats := aggregateStats(s.taskManager.ListTasks(), query)
+
+	if s.boltStorage != nil {
+		samples, err := s.boltStorage.ListThroughputSamples(query.From, query.To)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load throughput samples: %w", err)
+		}
+		stats.Throughput = samples
+	}
+	return stats, nil
+}
+
+// statsBucket 聚合中的分组；timedBytes 只累计有下载统计的任务，与 DownloadSec 对应，用于计算平均速度
+type statsBucket struct {
+	types.DtStatsBucket
+	timedBytes int64
+}
+
+// aggregateStats 对创建时间位于查询范围内的任务做聚合
+func aggregateStats(tasks []*types.DtTaskStatus, query types.DtStatsQuery) *types.DtStatistics {
+	total := &statsBucket{DtStatsBucket: types.DtStatsBucket{Key: "total"}}
+	byPeriod := map[string]*statsBucket{}
+	byExtractor := map[string]*statsBucket{}
+	byType := map[string]*statsBucket{}
+	byError := map[string]*statsBucket{}
+
+	get := func(m map[string]*statsBucket, key string) *statsBucket {
+		if key == "" {
+			key = "unknown"
+		}
+		b, ok := m[key]
+		if !ok {
+			b = &statsBucket{DtStatsBucket: types.DtStatsBucket{Key: key}}
+			m[key] = b
+		}
+		return b
+	}
+
+	for _, task := range tasks {
+		if task.CreatedAt < query.From || task.CreatedAt > query.To {
+			continue
+		}
+		targets := []*statsBucket{
+			total,
+			get(byPeriod, periodKey(time.Unix(task.CreatedAt, 0), query.Period)),
+			get(byExtractor, task.Extractor),
+			get(byType, task.Type),
+		}
+		if task.Stage == types.DtStageFailed {
+			code := string(types.DtErrUnknown)
+			if task.ErrorInfo != nil && task.ErrorInfo.Code != "" {
+				code = string(task.ErrorInfo.Code)
+			}
+			targets = append(targets, get(byError, code))
+		}
+		for _, b := range targets {
+			addTask(b, task)
+		}
+	}
+
+	finish(total)
+	return &types.DtStatistics{
+		From:         query.From,
+		To:           query.To,
+		Period:       query.Period,
+		Total:        total.DtStatsBucket,
+		ByPeriod:     sortedBuckets(byPeriod, true),
+		ByExtractor:  sortedBuckets(byExtractor, false),
+		ByType:       sortedBuckets(byType, false),
+		ByErrorClass: sortedBuckets(byError, false),
+	}
+}
+
+func addTask(b *statsBucket, task *types.DtTaskStatus) {
+	b.Tasks++
+	switch task.Stage {
+	case types.DtStageCompleted:
+		b.Completed++
+		b.MediaSec += taskMediaDuration(task)
+	case types.DtStageFailed:
+		b.Failed++
+	case types.DtStageCancelled:
+		b.Cancelled++
+	}
+	b.Bytes += taskBytes(task)
+	if task.Stats != nil && task.Stats.FinishedAt > task.Stats.StartedAt {
+		b.DownloadSec += float64(task.Stats.FinishedAt - task.Stats.StartedAt)
+		b.timedBytes += task.Stats.Bytes
+	}
+}
+
+func finish(b *statsBucket) {
+	if b.DownloadSec > 0 {
+		b.AvgBps = float64(b.timedBytes) / b.DownloadSec
+	}
+	if n := b.Completed + b.Failed; n > 0 {
+		b.SuccessRate = float64(b.Completed) / float64(n)
+	}
+}
+
+// sortedBuckets 时间段按键升序，其余按任务数降序
+func sortedBuckets(m map[string]*statsBucket, byKey bool) []types.DtStatsBucket {
+	list := make([]types.DtStatsBucket, 0, len(m))
+	for _, b := range m {
+		finish(b)
+		list = append(list, b.DtStatsBucket)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if byKey || list[i].Tasks == list[j].Tasks {
+			return list[i].Key < list[j].Key
+		}
+		return list[i].Tasks > list[j].Tasks
+	})
+	return list
+}
+
+// periodKey 按天（2006-01-02）或 ISO 周（2006-W01）分组
+func periodKey(t time.Time, period string) string {
+	if period == "week" {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return t.Format("2006-01-02")
+}
+
+// taskBytes 优先使用下载统计，其次为媒体信息或文件大小
+func taskBytes(task *types.DtTaskStatus) int64 {
+	if task.Stats != nil && task.Stats.Bytes > 0 {
+		return task.Stats.Bytes
+	}
+	if task.Stage != types.DtStageCompleted {
+		return 0
+	}
+	if task.MediaInfo != nil && task.MediaInfo.Size > 0 {
+		return task.MediaInfo.Size
+	}
+	if task.FileSize > 0 {
+		return task.FileSize
+	}
+	var total int64
+	for _, f := range task.VideoFiles {
+		if info, err := os.Stat(normalizePath(task.OutputDir, f)); err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+func taskMediaDuration(task *types.DtTaskStatus) float64 {
+	if task.MediaInfo != nil && task.MediaInfo.Duration > 0 {
+		return task.MediaInfo.Duration
+	}
+	return task.Duration
+}
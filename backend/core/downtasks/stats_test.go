@@ -0,0 +1,98 @@
+package downtasks
+
+import (
+	"CanMe/backend/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodKey(t *testing.T) {
+	tests := []struct {
+		time   time.Time
+		period string
+		want   string
+	}{
+		{time.Date(2024, 3, 5, 23, 59, 0, 0, time.Local), "day", "2024-03-05"},
+		{time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local), "week", "2024-W10"},
+		// ISO 周跨年
+		{time.Date(2024, 12, 30, 12, 0, 0, 0, time.Local), "week", "2025-W01"},
+		{time.Date(2021, 1, 3, 12, 0, 0, 0, time.Local), "week", "2020-W53"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, periodKey(tt.time, tt.period), tt.time)
+	}
+}
+
+func TestAggregateStats(t *testing.T) {
+	day1 := time.Date(2024, 3, 4, 10, 0, 0, 0, time.Local).Unix()
+	day2 := time.Date(2024, 3, 5, 10, 0, 0, 0, time.Local).Unix()
+	stats := func(bytes, sec int64) *types.DtDownloadStats {
+		return &types.DtDownloadStats{StartedAt: 1000, FinishedAt: 1000 + sec, Bytes: bytes}
+	}
+	tasks := []*types.DtTaskStatus{
+		{ID: "a", CreatedAt: day1, Type: "quick", Stage: types.DtStageCompleted, Stats: stats(1000, 10), Duration: 60},
+		{ID: "b", CreatedAt: day1, Type: "custom", Stage: types.DtStageFailed, Stats: stats(500, 5),
+			ErrorInfo: &types.DtTaskError{Code: types.DtErrRateLimited}},
+		// 没有下载统计的已完成任务只计入字节数，不参与平均速度
+		{ID: "c", CreatedAt: day2, Type: "quick", Stage: types.DtStageCompleted, FileSize: 9000},
+		{ID: "d", CreatedAt: day2, Type: "quick", Stage: types.DtStageFailed},
+		{ID: "e", CreatedAt: day2, Type: "quick", Stage: types.DtStageCancelled, Stats: stats(300, 0)},
+		{ID: "old", CreatedAt: day1 - 10*86400, Type: "quick", Stage: types.DtStageCompleted},
+	}
+	for _, task := range tasks {
+		if task.ID != "b" {
+			task.Extractor = "youtube"
+		}
+	}
+
+	result := aggregateStats(tasks, types.DtStatsQuery{From: day1 - 3600, To: day2 + 3600, Period: "day"})
+
+	total := result.Total
+	assert.Equal(t, 5, total.Tasks)
+	assert.Equal(t, 2, total.Completed)
+	assert.Equal(t, 2, total.Failed)
+	assert.Equal(t, 1, total.Cancelled)
+	assert.Equal(t, int64(1000+500+9000+300), total.Bytes)
+	assert.Equal(t, float64(15), total.DownloadSec)
+	assert.Equal(t, float64(100), total.AvgBps)
+	assert.Equal(t, float64(60), total.MediaSec)
+	assert.Equal(t, 0.5, total.SuccessRate)
+
+	tests := []struct {
+		name    string
+		buckets []types.DtStatsBucket
+		want    []types.DtStatsBucket
+	}{
+		{"by period", result.ByPeriod, []types.DtStatsBucket{
+			{Key: "2024-03-04", Tasks: 2, Completed: 1, Failed: 1, Bytes: 1500, MediaSec: 60, DownloadSec: 15, AvgBps: 100, SuccessRate: 0.5},
+			{Key: "2024-03-05", Tasks: 3, Completed: 1, Failed: 1, Cancelled: 1, Bytes: 9300, SuccessRate: 0.5},
+		}},
+		{"by extractor", result.ByExtractor, []types.DtStatsBucket{
+			{Key: "youtube", Tasks: 4, Completed: 2, Failed: 1, Cancelled: 1, Bytes: 10300, MediaSec: 60, DownloadSec: 10, AvgBps: 100, SuccessRate: 2.0 / 3},
+			{Key: "unknown", Tasks: 1, Failed: 1, Bytes: 500, DownloadSec: 5, AvgBps: 100},
+		}},
+		{"by type", result.ByType, []types.DtStatsBucket{
+			{Key: "quick", Tasks: 4, Completed: 2, Failed: 1, Cancelled: 1, Bytes: 10300, MediaSec: 60, DownloadSec: 10, AvgBps: 100, SuccessRate: 2.0 / 3},
+			{Key: "custom", Tasks: 1, Failed: 1, Bytes: 500, DownloadSec: 5, AvgBps: 100},
+		}},
+		{"by error class", result.ByErrorClass, []types.DtStatsBucket{
+			{Key: string(types.DtErrRateLimited), Tasks: 1, Failed: 1, Bytes: 500, DownloadSec: 5, AvgBps: 100},
+			{Key: string(types.DtErrUnknown), Tasks: 1, Failed: 1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Len(t, tt.buckets, len(tt.want))
+			for i, want := range tt.want {
+				got := tt.buckets[i]
+				assert.Equal(t, want.Key, got.Key)
+				assert.InDelta(t, want.SuccessRate, got.SuccessRate, 1e-9, want.Key)
+				got.SuccessRate = want.SuccessRate
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+}
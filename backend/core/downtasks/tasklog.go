@@ -0,0 +1,158 @@
+package downtasks
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/pkg/events"
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/pkg/tasklog"
+	"CanMe/backend/pkg/ytdlprun"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lrstanley/go-ytdlp"
+	"go.uber.org/zap"
+)
+
+// newTaskLogStore 创建任务日志存储，新写入的行通过事件总线推送（用于实时查看）
+func (s *Service) newTaskLogStore() *tasklog.Store {
+	store := tasklog.New(tasklog.DefaultDir(), tasklog.DefaultMaxSize, tasklog.DefaultMaxBackups)
+	store.SetListener(func(line tasklog.Line) {
+		if s.eventBus == nil || s.ctx == nil {
+			return
+		}
+		s.eventBus.Publish(s.ctx, &events.BaseEvent{
+			ID:        uuid.New().String(),
+			Type:      consts.TopicDowntasksLog,
+			Source:    "downtasks",
+			Timestamp: time.Now(),
+			Data:      &line,
+		})
+	})
+	return store
+}
+
+// GetTaskLog 返回任务的完整日志
+func (s *Service) GetTaskLog(id string) (string, error) {
+	return s.taskLogs.Read(id)
+}
+
+// TailTaskLog 返回任务日志的最后 n 行
+func (s *Service) TailTaskLog(id string, n int) ([]string, error) {
+	return s.taskLogs.Tail(id, n)
+}
+
+// appendTaskLog 写入任务日志，失败只记录警告，不影响任务流程
+func (s *Service) appendTaskLog(taskID, stream, text string) {
+	if s.taskLogs == nil || taskID == "" {
+		return
+	}
+	if err := s.taskLogs.Append(taskID, stream, text); err != nil {
+		logger.Warn("Failed to write task log", zap.String("taskId", taskID), zap.Error(err))
+	}
+}
+
+// 写入任务日志的单行长度上限（如 --dump-single-json 输出的整段 JSON），超出部分截断
+const maxTaskLogLine = 4000
+
+// runYtdlp 运行 yt-dlp：先记录命令行（已脱敏），运行中逐行写入输出，结束后记录退出码。taskID 为空时不写日志
+func (s *Service) runYtdlp(ctx context.Context, taskID, label string, dl *ytdlprun.Command, args ...string) (*ytdlprun.Result, error) {
+	var onLine ytdlprun.LineFunc
+	if taskID != "" {
+		s.logCommand(taskID, label, dl.Executable(), dl.Args(args...))
+		onLine = func(stream, line string) {
+			s.logYtdlpLine(taskID, stream, line)
+		}
+	}
+
+	result, err := dl.Run(ctx, onLine, args...)
+	if result != nil {
+		s.appendTaskLog(taskID, "info", fmt.Sprintf("%s exited with code %d", label, result.ExitCode))
+	} else if err != nil {
+		s.appendTaskLog(taskID, "info", fmt.Sprintf("%s failed: %v", label, err))
+	}
+	return result, err
+}
+
+// logCommand 记录一条外部命令的命令行，URL 去掉查询参数与用户信息
+func (s *Service) logCommand(taskID, label, executable string, args []string) {
+	cmdline := append([]string{filepath.Base(executable)}, sanitizeArgs(args)...)
+	s.appendTaskLog(taskID, "cmd", fmt.Sprintf("%s: %s", label, strings.Join(cmdline, " ")))
+}
+
+// logYtdlpLine 写入一行 yt-dlp 输出。--verbose 的调试行只保留 yt-dlp 调用 FFmpeg 的命令行，
+// 其余调试行（含代理、请求地址等）不写入
+func (s *Service) logYtdlpLine(taskID, stream, line string) {
+	if debug, ok := strings.CutPrefix(line, "[debug] "); ok {
+		if cmdline, ok := strings.CutPrefix(debug, "ffmpeg command line: "); ok {
+			s.appendTaskLog(taskID, "cmd", "ffmpeg: "+sanitizeCommandLine(cmdline))
+		}
+		return
+	}
+	s.appendTaskLog(taskID, stream, truncateString(line, maxTaskLogLine))
+}
+
+var (
+	headersArgPattern = regexp.MustCompile(`(-headers\s+)('[^']*'|"[^"]*"|\S+)`)
+	urlArgPattern     = regexp.MustCompile(`https?://[^\s'"]+`)
+)
+
+// sanitizeCommandLine 隐藏 FFmpeg 命令行中的请求头（可能含 Cookie），并去掉 URL 的查询参数
+func sanitizeCommandLine(cmdline string) string {
+	cmdline = headersArgPattern.ReplaceAllString(cmdline, "${1}<redacted>")
+	return urlArgPattern.ReplaceAllStringFunc(cmdline, sanitizeArg)
+}
+
+// compactJSON 将多行 JSON 压缩为一行写入日志，无法解析时原样返回
+func compactJSON(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+// deleteTaskLog 随任务一起清理日志
+func (s *Service) deleteTaskLog(taskID string) {
+	if s.taskLogs == nil {
+		return
+	}
+	if err := s.taskLogs.Delete(taskID); err != nil {
+		logger.Warn("Failed to delete task log", zap.String("taskId", taskID), zap.Error(err))
+	}
+}
+
+// progressLogger 在下载过程中记录状态/文件变化，便于实时查看（不记录每个百分比）
+type progressLogger struct {
+	s      *Service
+	taskID string
+
+	mu       sync.Mutex
+	filename string
+	status   ytdlp.ProgressStatus
+}
+
+func (s *Service) newProgressLogger(taskID string) *progressLogger {
+	return &progressLogger{s: s, taskID: taskID}
+}
+
+func (l *progressLogger) Update(update ytdlp.ProgressUpdate) {
+	l.mu.Lock()
+	if update.Filename == l.filename && update.Status == l.status {
+		l.mu.Unlock()
+		return
+	}
+	l.filename = update.Filename
+	l.status = update.Status
+	l.mu.Unlock()
+
+	l.s.appendTaskLog(l.taskID, "stdout", fmt.Sprintf("[progress] %s %s (%s)",
+		update.Status, filepath.Base(update.Filename), update.PercentString()))
+}
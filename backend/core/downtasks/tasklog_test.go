@@ -0,0 +1,39 @@
+package downtasks
+
+import (
+	"CanMe/backend/pkg/tasklog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeCommandLine(t *testing.T) {
+	assert.Equal(t,
+		`ffmpeg -y -loglevel repeat+info -headers <redacted> -i 'https://cdn.example.com/v.m3u8' -c copy file:out.mp4`,
+		sanitizeCommandLine(`ffmpeg -y -loglevel repeat+info -headers 'Cookie: sid=abc; token=x' -i 'https://cdn.example.com/v.m3u8?sig=secret' -c copy file:out.mp4`))
+	assert.Equal(t,
+		`ffmpeg -headers <redacted> -i "https://cdn.example.com/v.m3u8"`,
+		sanitizeCommandLine(`ffmpeg -headers "Cookie: sid=abc" -i "https://user:pw@cdn.example.com/v.m3u8?sig=secret"`))
+	assert.Equal(t,
+		`ffmpeg -i file:a.webm -c:v libx264 file:a.temp.mp4`,
+		sanitizeCommandLine(`ffmpeg -i file:a.webm -c:v libx264 file:a.temp.mp4`))
+}
+
+func TestLogYtdlpLine(t *testing.T) {
+	s := &Service{taskLogs: tasklog.New(t.TempDir(), 0, 0)}
+
+	s.logYtdlpLine("t1", "stdout", "[download] Destination: a.mp4")
+	s.logYtdlpLine("t1", "stderr", "[debug] Proxy map: {'all': 'http://user:pw@proxy:8080'}")
+	s.logYtdlpLine("t1", "stderr", "[debug] ffmpeg command line: ffmpeg -i file:a.f137.mp4 -i file:a.f140.m4a -c copy file:a.temp.mp4")
+	s.logYtdlpLine("t1", "stdout", strings.Repeat("x", maxTaskLogLine+100))
+
+	lines, err := s.taskLogs.Tail("t1", 10)
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "[stdout] [download] Destination: a.mp4")
+	assert.Contains(t, lines[1], "[cmd] ffmpeg: ffmpeg -i file:a.f137.mp4 -i file:a.f140.m4a -c copy file:a.temp.mp4")
+	assert.Contains(t, lines[2], "…(truncated)")
+	assert.Less(t, len(lines[2]), maxTaskLogLine+100)
+}
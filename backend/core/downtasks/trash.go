@@ -0,0 +1,466 @@
+package downtasks
+
+import (
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/pkg/urlcanon"
+	"CanMe/backend/types"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	trashDirName       = ".trash"
+	trashPurgeInterval = time.Hour
+)
+
+// DeleteTaskWithMode 按指定模式删除任务：仅记录 / 记录+文件 / 文件移入回收站
+func (s *Service) DeleteTaskWithMode(id string, mode types.DtDeleteMode) error {
+	return s.deleteTask(id, mode, nil)
+}
+
+// deleteTask 删除任务，与 keep 中路径相同或为同一文件（硬链接）的文件保留在原处
+func (s *Service) deleteTask(id string, mode types.DtDeleteMode, keep []string) error {
+	task := s.taskManager.GetTask(id)
+	if task == nil {
+		return fmt.Errorf("task not found: %s", id)
+	}
+	files := withoutFiles(s.taskFilePaths(task), keep)
+
+	switch mode {
+	case "", types.DtDeleteRecordOnly:
+		// keep files on disk
+	case types.DtDeleteWithFiles:
+		for _, p := range files {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to delete file %s: %w", p, err)
+			}
+		}
+	case types.DtDeleteToTrash:
+		if _, err := s.moveTaskToTrash(task, files); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported delete mode: %s", mode)
+	}
+
+	if err := s.taskManager.DeleteTask(id); err != nil {
+		return err
+	}
+	s.cancelRunning(id, errTaskDeleted)
+	// 回收站模式保留日志，随条目彻底删除时再清理
+	if mode != types.DtDeleteToTrash {
+		s.deleteTaskLog(id)
+	}
+	return nil
+}
+
+// DeleteTasksByFilter 批量删除符合过滤条件的任务
+func (s *Service) DeleteTasksByFilter(filter types.DtTaskFilter, mode types.DtDeleteMode) (*types.DtBulkDeleteResult, error) {
+	if isEmptyTaskFilter(filter) {
+		return nil, fmt.Errorf("filter is empty")
+	}
+
+	result := &types.DtBulkDeleteResult{Deleted: []string{}}
+	for _, task := range s.taskManager.ListTasks() {
+		if task == nil || !matchTaskFilter(task, filter) {
+			continue
+		}
+		if err := s.DeleteTaskWithMode(task.ID, mode); err != nil {
+			if result.Failed == nil {
+				result.Failed = map[string]string{}
+			}
+			result.Failed[task.ID] = err.Error()
+			continue
+		}
+		result.Deleted = append(result.Deleted, task.ID)
+	}
+
+	return result, nil
+}
+
+// ListTrash 列出回收站条目
+func (s *Service) ListTrash() ([]*types.DtTrashItem, error) {
+	if s.boltStorage == nil {
+		return nil, fmt.Errorf("bolt storage is nil")
+	}
+	return s.boltStorage.ListTrashItems()
+}
+
+// RestoreTrash 将回收站条目中的文件移回原位置并恢复任务记录
+func (s *Service) RestoreTrash(id string) (*types.DtTaskStatus, error) {
+	if s.boltStorage == nil {
+		return nil, fmt.Errorf("bolt storage is nil")
+	}
+	item, err := s.boltStorage.GetTrashItem(id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Task == nil {
+		return nil, fmt.Errorf("trash item %s has no task snapshot", id)
+	}
+	if s.taskManager.GetTask(item.Task.ID) != nil {
+		return nil, fmt.Errorf("task already exists: %s", item.Task.ID)
+	}
+
+	// 先检查冲突，避免恢复一半
+	for _, f := range item.Files {
+		if _, err := os.Stat(f.OriginalPath); err == nil {
+			return nil, fmt.Errorf("file already exists at original location: %s", f.OriginalPath)
+		}
+	}
+
+	if err := restoreTrashFiles(item.Files); err != nil {
+		return nil, err
+	}
+
+	task := item.Task
+	s.taskManager.UpdateTask(task)
+
+	if err := s.boltStorage.DeleteTrashItem(id); err != nil {
+		logger.Warn("Failed to delete restored trash item", zap.String("id", id), zap.Error(err))
+	}
+	for _, f := range item.Files {
+		_ = os.Remove(filepath.Dir(f.TrashPath))
+	}
+
+	return task, nil
+}
+
+// PurgeTrash 彻底删除单个回收站条目及其文件
+func (s *Service) PurgeTrash(id string) error {
+	if s.boltStorage == nil {
+		return fmt.Errorf("bolt storage is nil")
+	}
+	item, err := s.boltStorage.GetTrashItem(id)
+	if err != nil {
+		return err
+	}
+	return s.purgeTrashItem(item)
+}
+
+// EmptyTrash 清空回收站
+func (s *Service) EmptyTrash() error {
+	items, err := s.ListTrash()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, item := range items {
+		if err := s.purgeTrashItem(item); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PurgeExpiredTrash 删除超过保留天数的回收站条目，返回清理数量
+func (s *Service) PurgeExpiredTrash() (int, error) {
+	days := s.pref.GetTrashConfig().AutoPurgeDays
+	if days <= 0 {
+		return 0, nil
+	}
+	items, err := s.ListTrash()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour).Unix()
+	purged := 0
+	var errs []error
+	for _, item := range items {
+		if item.DeletedAt > cutoff {
+			continue
+		}
+		if err := s.purgeTrashItem(item); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		purged++
+	}
+	return purged, errors.Join(errs...)
+}
+
+// startTrashPurger 启动时及之后定期清理过期的回收站条目
+func (s *Service) startTrashPurger() {
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+		for {
+			if n, err := s.PurgeExpiredTrash(); err != nil {
+				logger.Warn("Failed to purge expired trash", zap.Error(err))
+			} else if n > 0 {
+				logger.Info("Expired trash purged", zap.Int("count", n))
+			}
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *Service) purgeTrashItem(item *types.DtTrashItem) error {
+	// 下载目录可能在移入回收站后被修改，按记录的路径逐个删除
+	for _, f := range item.Files {
+		if err := os.Remove(f.TrashPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove trash file %s: %w", f.TrashPath, err)
+		}
+		_ = os.Remove(filepath.Dir(f.TrashPath))
+	}
+	if err := os.RemoveAll(s.trashItemDir(item.ID)); err != nil {
+		return fmt.Errorf("failed to remove trash files for %s: %w", item.ID, err)
+	}
+	if err := s.boltStorage.DeleteTrashItem(item.ID); err != nil {
+		return err
+	}
+	s.deleteTaskLog(item.TaskID)
+	return nil
+}
+
+// moveTaskToTrash 将任务的 files 移入回收站，并保存任务快照
+func (s *Service) moveTaskToTrash(task *types.DtTaskStatus, files []string) (*types.DtTrashItem, error) {
+	if s.boltStorage == nil {
+		return nil, fmt.Errorf("bolt storage is nil")
+	}
+
+	snapshot := *task
+	item := &types.DtTrashItem{
+		ID:        uuid.New().String(),
+		TaskID:    task.ID,
+		Title:     task.Title,
+		URL:       task.URL,
+		Thumbnail: task.Thumbnail,
+		Files:     []types.DtTrashFile{},
+		DeletedAt: time.Now().Unix(),
+		Task:      &snapshot,
+	}
+
+	dir := s.trashItemDir(item.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create trash directory: %w", err)
+	}
+
+	used := map[string]bool{}
+	for _, p := range files {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		name := filepath.Base(p)
+		for i := 1; used[name]; i++ {
+			ext := filepath.Ext(p)
+			name = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(filepath.Base(p), ext), i, ext)
+		}
+		used[name] = true
+
+		dst := filepath.Join(dir, name)
+		if err := moveFile(p, dst); err != nil {
+			// roll back files already moved so the task stays intact
+			rollbackTrashMove(item.Files)
+			_ = os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to move %s to trash: %w", p, err)
+		}
+		item.Files = append(item.Files, types.DtTrashFile{OriginalPath: p, TrashPath: dst, Size: info.Size()})
+		item.TotalSize += info.Size()
+	}
+
+	// 记录保存失败时文件已无从恢复，移回原位置
+	if err := s.boltStorage.SaveTrashItem(item); err != nil {
+		rollbackTrashMove(item.Files)
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to save trash item: %w", err)
+	}
+
+	logger.Debug("task moved to trash",
+		zap.String("taskId", task.ID),
+		zap.String("trashId", item.ID),
+		zap.Int("files", len(item.Files)),
+	)
+	return item, nil
+}
+
+// rollbackTrashMove 将已移入回收站的文件移回原位置
+func rollbackTrashMove(files []types.DtTrashFile) {
+	for _, f := range files {
+		if err := moveFile(f.TrashPath, f.OriginalPath); err != nil {
+			logger.Warn("Failed to roll back trash move", zap.String("file", f.OriginalPath), zap.Error(err))
+		}
+	}
+}
+
+// restoreTrashFiles 将回收站文件移回原位置；中途失败时把已恢复的文件移回回收站，条目保持完整
+func restoreTrashFiles(files []types.DtTrashFile) error {
+	for i, f := range files {
+		err := os.MkdirAll(filepath.Dir(f.OriginalPath), 0o755)
+		if err == nil {
+			err = moveFile(f.TrashPath, f.OriginalPath)
+		}
+		if err == nil {
+			continue
+		}
+		for _, done := range files[:i] {
+			if rerr := moveFile(done.OriginalPath, done.TrashPath); rerr != nil {
+				logger.Warn("Failed to roll back trash restore", zap.String("file", done.OriginalPath), zap.Error(rerr))
+			}
+		}
+		return fmt.Errorf("failed to restore file %s: %w", f.OriginalPath, err)
+	}
+	return nil
+}
+
+// taskFilePaths 返回任务产生的、当前仍存在于磁盘上的文件绝对路径（去重）
+func (s *Service) taskFilePaths(task *types.DtTaskStatus) []string {
+	candidates := make([]string, 0, len(task.AllFiles)+len(task.SubtitleFiles))
+	candidates = append(candidates, task.AllFiles...)
+	candidates = append(candidates, task.VideoFiles...)
+	candidates = append(candidates, task.SubtitleFiles...)
+	candidates = append(candidates, task.TranslatedSubs...)
+	candidates = append(candidates, task.EmbeddedVideoFiles...)
+	candidates = append(candidates, task.TranscodeProcess.OutputFiles...)
+
+	out := []string{}
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		p := normalizePath(task.OutputDir, c)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		if info, err := os.Stat(p); err != nil || info.IsDir() {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// withoutFiles 去掉与 keep 中路径相同或为同一文件的路径
+func withoutFiles(files, keep []string) []string {
+	if len(keep) == 0 {
+		return files
+	}
+	var keepInfos []os.FileInfo
+	for _, k := range keep {
+		if info, err := os.Stat(k); err == nil {
+			keepInfos = append(keepInfos, info)
+		}
+	}
+	out := []string{}
+	for _, p := range files {
+		if slices.Contains(keep, p) || slices.ContainsFunc(keepInfos, func(k os.FileInfo) bool {
+			info, err := os.Stat(p)
+			return err == nil && os.SameFile(k, info)
+		}) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func (s *Service) trashItemDir(id string) string {
+	return filepath.Join(s.downloadClient.GetDownloadDirWithCanMe(), trashDirName, id)
+}
+
+// moveFile 优先使用 rename，跨卷时回退为复制后删除
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	in.Close()
+	return os.Remove(src)
+}
+
+// FilterTasks 返回匹配筛选条件的任务（空条件返回全部），按创建时间倒序
+func (s *Service) FilterTasks(filter types.DtTaskFilter) []*types.DtTaskStatus {
+	out := []*types.DtTaskStatus{}
+	for _, t := range s.ListTasks() {
+		if matchTaskFilter(t, filter) {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt > out[j].CreatedAt })
+	return out
+}
+
+func isEmptyTaskFilter(f types.DtTaskFilter) bool {
+	return len(f.IDs) == 0 && len(f.Stages) == 0 && len(f.Types) == 0 &&
+		strings.TrimSpace(f.Extractor) == "" && strings.TrimSpace(f.Keyword) == "" &&
+		f.CreatedBefore == 0 && f.CreatedAfter == 0
+}
+
+func matchTaskFilter(task *types.DtTaskStatus, f types.DtTaskFilter) bool {
+	if len(f.IDs) > 0 && !contains(f.IDs, task.ID) {
+		return false
+	}
+	if len(f.Stages) > 0 {
+		matched := false
+		for _, st := range f.Stages {
+			if task.Stage == st {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(f.Types) > 0 && !containsFold(f.Types, task.Type) {
+		return false
+	}
+	if e := strings.TrimSpace(f.Extractor); e != "" && !strings.EqualFold(task.Extractor, e) {
+		return false
+	}
+	if kw := strings.TrimSpace(f.Keyword); kw != "" {
+		// 关键字为链接时按规范化链接匹配（忽略跟踪参数、短视频/移动端等变体）
+		urlMatch := urlcanon.IsURL(kw) && taskCanonicalURL(task) == urlcanon.Canonicalize(kw)
+		kw = strings.ToLower(kw)
+		if !urlMatch &&
+			!strings.Contains(strings.ToLower(task.Title), kw) &&
+			!strings.Contains(strings.ToLower(task.URL), kw) &&
+			!strings.Contains(strings.ToLower(task.Uploader), kw) {
+			return false
+		}
+	}
+	if f.CreatedBefore > 0 && task.CreatedAt >= f.CreatedBefore {
+		return false
+	}
+	if f.CreatedAfter > 0 && task.CreatedAt <= f.CreatedAfter {
+		return false
+	}
+	return true
+}
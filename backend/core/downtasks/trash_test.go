@@ -0,0 +1,115 @@
+package downtasks
+
+import (
+	"CanMe/backend/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsEmptyTaskFilter(t *testing.T) {
+	assert.True(t, isEmptyTaskFilter(types.DtTaskFilter{}))
+	assert.True(t, isEmptyTaskFilter(types.DtTaskFilter{Keyword: "  ", Extractor: "\t"}))
+	assert.True(t, isEmptyTaskFilter(types.DtTaskFilter{IDs: []string{}}))
+
+	for _, f := range []types.DtTaskFilter{
+		{IDs: []string{"a"}},
+		{Stages: []types.DtTaskStage{types.DtStageFailed}},
+		{Types: []string{"quick"}},
+		{Extractor: "youtube"},
+		{Keyword: "cat"},
+		{CreatedBefore: 1},
+		{CreatedAfter: 1},
+	} {
+		assert.False(t, isEmptyTaskFilter(f), "%+v", f)
+	}
+}
+
+func TestMatchTaskFilter(t *testing.T) {
+	task := &types.DtTaskStatus{
+		ID:        "t1",
+		Type:      "custom",
+		Stage:     types.DtStageCompleted,
+		URL:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ&utm_source=x",
+		CreatedAt: 1000,
+	}
+	task.Title = "Funny Cat Compilation"
+	task.Extractor = "youtube"
+	task.Uploader = "Pet Channel"
+
+	tests := []struct {
+		name   string
+		filter types.DtTaskFilter
+		want   bool
+	}{
+		{"empty", types.DtTaskFilter{}, true},
+		{"id", types.DtTaskFilter{IDs: []string{"x", "t1"}}, true},
+		{"id miss", types.DtTaskFilter{IDs: []string{"x"}}, false},
+		{"stage", types.DtTaskFilter{Stages: []types.DtTaskStage{types.DtStageFailed, types.DtStageCompleted}}, true},
+		{"stage miss", types.DtTaskFilter{Stages: []types.DtTaskStage{types.DtStageFailed}}, false},
+		{"type fold", types.DtTaskFilter{Types: []string{"CUSTOM"}}, true},
+		{"type miss", types.DtTaskFilter{Types: []string{"quick"}}, false},
+		{"extractor fold", types.DtTaskFilter{Extractor: " YouTube "}, true},
+		{"extractor miss", types.DtTaskFilter{Extractor: "vimeo"}, false},
+		{"keyword title", types.DtTaskFilter{Keyword: "cat"}, true},
+		{"keyword uploader", types.DtTaskFilter{Keyword: "pet channel"}, true},
+		{"keyword url", types.DtTaskFilter{Keyword: "dQw4w9"}, true},
+		{"keyword miss", types.DtTaskFilter{Keyword: "dog"}, false},
+		{"keyword short link", types.DtTaskFilter{Keyword: "https://youtu.be/dQw4w9WgXcQ?si=abc"}, true},
+		{"keyword other link", types.DtTaskFilter{Keyword: "https://youtu.be/aaaaaaaaaaa"}, false},
+		{"created before", types.DtTaskFilter{CreatedBefore: 1001}, true},
+		{"created before edge", types.DtTaskFilter{CreatedBefore: 1000}, false},
+		{"created after", types.DtTaskFilter{CreatedAfter: 999}, true},
+		{"created after edge", types.DtTaskFilter{CreatedAfter: 1000}, false},
+		{"all", types.DtTaskFilter{IDs: []string{"t1"}, Types: []string{"custom"}, Keyword: "cat", CreatedAfter: 1}, true},
+		{"all one miss", types.DtTaskFilter{IDs: []string{"t1"}, Types: []string{"custom"}, Keyword: "dog"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchTaskFilter(task, tt.filter))
+		})
+	}
+}
+
+func writeTrashFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestRestoreTrashFilesRollsBack(t *testing.T) {
+	root := t.TempDir()
+	files := []types.DtTrashFile{
+		{OriginalPath: filepath.Join(root, "dl", "a.mp4"), TrashPath: filepath.Join(root, "trash", "a.mp4")},
+		{OriginalPath: filepath.Join(root, "dl", "a.srt"), TrashPath: filepath.Join(root, "trash", "a.srt")},
+	}
+	writeTrashFile(t, files[0].TrashPath, "video")
+
+	// 第二个文件缺失：已恢复的文件移回回收站
+	err := restoreTrashFiles(files)
+	require.ErrorContains(t, err, "a.srt")
+	assert.FileExists(t, files[0].TrashPath)
+	assert.NoFileExists(t, files[0].OriginalPath)
+
+	writeTrashFile(t, files[1].TrashPath, "subs")
+	require.NoError(t, restoreTrashFiles(files))
+	for _, f := range files {
+		assert.FileExists(t, f.OriginalPath)
+		assert.NoFileExists(t, f.TrashPath)
+	}
+}
+
+func TestRollbackTrashMove(t *testing.T) {
+	root := t.TempDir()
+	f := types.DtTrashFile{OriginalPath: filepath.Join(root, "a.mp4"), TrashPath: filepath.Join(root, "trash", "a.mp4")}
+	writeTrashFile(t, f.TrashPath, "video")
+
+	rollbackTrashMove([]types.DtTrashFile{f})
+	data, err := os.ReadFile(f.OriginalPath)
+	require.NoError(t, err)
+	assert.Equal(t, "video", string(data))
+	assert.NoFileExists(t, f.TrashPath)
+}
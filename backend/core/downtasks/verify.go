@@ -0,0 +1,237 @@
+package downtasks
+
+import (
+	"CanMe/backend/pkg/ffprobe"
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/types"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lrstanley/go-ytdlp"
+	"go.uber.org/zap"
+)
+
+const (
+	probeTimeout          = 60 * time.Second
+	minDurationToleranceS = 2.0 // 时长比较的最小绝对误差（秒）
+)
+
+var audioExts = map[string]bool{
+	".m4a": true, ".mp3": true, ".opus": true, ".aac": true, ".flac": true, ".wav": true, ".oga": true,
+}
+
+// VerifyTask 重新校验任务的输出文件并返回更新后的任务
+func (s *Service) VerifyTask(id string) (*types.DtTaskStatus, error) {
+	task := s.taskManager.GetTask(id)
+	if task == nil {
+		return nil, fmt.Errorf("task not found: %s", id)
+	}
+	v := s.verifyOutput(task, s.pref.GetVerifyConfig())
+	task.Verification = v
+	s.taskManager.UpdateTask(task)
+	return task, nil
+}
+
+// verifyTask 下载完成后的校验步骤；返回错误表示按设置应将任务置为失败
+func (s *Service) verifyTask(task *types.DtTaskStatus) error {
+	config := s.pref.GetVerifyConfig()
+	if !config.Enabled {
+		return nil
+	}
+
+	v := s.verifyOutput(task, config)
+	task.Verification = v
+	s.taskManager.UpdateTask(task)
+
+	if v.Status == types.DtVerifyCorrupt && config.OnCorrupt == "fail" {
+		return &corruptOutputError{Issues: v.Issues}
+	}
+	return nil
+}
+
+// corruptOutputError 表示输出文件校验为损坏，任务错误编码为 DtErrCorruptOutput
+type corruptOutputError struct {
+	Issues []string
+}
+
+func (e *corruptOutputError) Error() string {
+	return "media verification failed: " + strings.Join(e.Issues, "; ")
+}
+
+// verifyOutput 使用 ffprobe 检查容器、流与时长，并记录媒体信息
+func (s *Service) verifyOutput(task *types.DtTaskStatus, config types.PreferencesVerify) *types.DtVerification {
+	v := &types.DtVerification{CheckedAt: time.Now().Unix()}
+	defer func() {
+		msg := fmt.Sprintf("media verification: %s", v.Status)
+		if len(v.Issues) > 0 {
+			msg += " (" + strings.Join(v.Issues, "; ") + ")"
+		}
+		s.appendTaskLog(task.ID, "info", msg)
+	}()
+
+	file := s.primaryMediaFile(task)
+	if file == "" {
+		v.Status = types.DtVerifySkipped
+		v.Issues = []string{"no media file found"}
+		return v
+	}
+	v.File = file
+
+	ffmpegPath, _ := s.FFMPEGExecPath()
+	probePath, err := ffprobe.Locate(ffmpegPath)
+	if err != nil {
+		v.Status = types.DtVerifySkipped
+		v.Issues = []string{"ffprobe not available"}
+		return v
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, probeTimeout)
+	defer cancel()
+	s.logCommand(task.ID, "ffprobe", probePath, ffprobe.Args(file))
+	out, err := ffprobe.Run(ctx, probePath, file)
+	var result *ffprobe.Result
+	if err == nil {
+		s.appendTaskLog(task.ID, "stdout", truncateString(compactJSON(out), maxTaskLogLine))
+		result, err = ffprobe.Parse(out)
+	} else {
+		s.appendTaskLog(task.ID, "stderr", err.Error())
+	}
+	if err != nil {
+		v.Status = types.DtVerifyCorrupt
+		v.Issues = []string{err.Error()}
+		logger.Warn("media verification failed", zap.String("taskId", task.ID), zap.String("file", file), zap.Error(err))
+		return v
+	}
+
+	info := result.MediaInfo()
+	task.MediaInfo = info
+	if task.Resolution == "" && info.Width > 0 && info.Height > 0 {
+		task.Resolution = fmt.Sprintf("%dx%d", info.Width, info.Height)
+	}
+
+	audioOnly := audioExts[strings.ToLower(filepath.Ext(file))]
+	v.Status, v.Issues = checkMediaInfo(info, task.Duration, config.DurationTolerance, audioOnly, s.expectsAudio(task))
+	if v.Status != types.DtVerifyOK {
+		logger.Warn("media verification found issues",
+			zap.String("taskId", task.ID),
+			zap.String("file", file),
+			zap.Strings("issues", v.Issues),
+		)
+	}
+	return v
+}
+
+// expectsAudio 根据缓存的元数据判断所选格式是否带有音频：所选格式本身含音频、
+// 合并了多个格式，或 custom 任务为仅视频格式追加了 bestaudio；未指定格式时看 yt-dlp 默认选择的格式
+func (s *Service) expectsAudio(task *types.DtTaskStatus) bool {
+	request := task.DownloadRequest
+	if request == nil {
+		return false
+	}
+	metadata, ok := s.getCachedMetadata(request.URL)
+	if !ok || metadata == nil {
+		return false
+	}
+	formatID := request.FormatID
+	if formatID == "" && request.Video != "" && request.Video != "best" {
+		formatID = request.Video
+	}
+	return formatHasAudio(metadata, formatID, task.Type == "custom")
+}
+
+// formatHasAudio 判断 formatID 对应的下载结果是否应包含音频，mergesAudio 表示仅视频格式会追加 bestaudio
+func formatHasAudio(metadata *ytdlp.ExtractedInfo, formatID string, mergesAudio bool) bool {
+	if formatID != "" {
+		if strings.Contains(formatID, "+") {
+			return true
+		}
+		for _, f := range metadata.Formats {
+			if f == nil || f.FormatID == nil || *f.FormatID != formatID {
+				continue
+			}
+			return hasCodec(f.ACodec) || (mergesAudio && hasCodec(f.VCodec))
+		}
+		return false
+	}
+	for _, f := range metadata.RequestedFormats {
+		if f != nil && hasCodec(f.ACodec) {
+			return true
+		}
+	}
+	return metadata.ExtractedFormat != nil && hasCodec(metadata.ACodec)
+}
+
+func hasCodec(codec *string) bool {
+	return codec != nil && *codec != "" && *codec != "none"
+}
+
+// checkMediaInfo 根据媒体信息判断输出是否正常；expectAudio 表示所选格式带有音频
+func checkMediaInfo(info *types.DtMediaInfo, expectedDuration, tolerance float64, audioOnly, expectAudio bool) (types.DtVerifyStatus, []string) {
+	var corrupt, warnings []string
+
+	switch {
+	case info.VideoStreams == 0 && info.AudioStreams == 0:
+		corrupt = append(corrupt, "no audio or video streams")
+	case !audioOnly && info.VideoStreams > 0 && info.AudioStreams == 0:
+		// 所选格式带音频（或合并了 bestaudio）时缺少音频视为损坏，本就是仅视频格式时只提示
+		if expectAudio {
+			corrupt = append(corrupt, "no audio stream")
+		} else {
+			warnings = append(warnings, "no audio stream")
+		}
+	case !audioOnly && info.VideoStreams == 0:
+		warnings = append(warnings, "no video stream")
+	}
+
+	if info.Duration <= 0 {
+		warnings = append(warnings, "container reports no duration")
+	} else if expectedDuration > 0 {
+		allowed := math.Max(minDurationToleranceS, expectedDuration*tolerance)
+		diff := info.Duration - expectedDuration
+		if diff < -allowed {
+			corrupt = append(corrupt, fmt.Sprintf("duration %.1fs is shorter than expected %.1fs", info.Duration, expectedDuration))
+		} else if diff > allowed {
+			warnings = append(warnings, fmt.Sprintf("duration %.1fs is longer than expected %.1fs", info.Duration, expectedDuration))
+		}
+	}
+
+	switch {
+	case len(corrupt) > 0:
+		return types.DtVerifyCorrupt, append(corrupt, warnings...)
+	case len(warnings) > 0:
+		return types.DtVerifyWarning, warnings
+	default:
+		return types.DtVerifyOK, nil
+	}
+}
+
+// primaryMediaFile 选择任务中体积最大的音视频文件作为校验对象
+func (s *Service) primaryMediaFile(task *types.DtTaskStatus) string {
+	candidates := append([]string{}, task.VideoFiles...)
+	candidates = append(candidates, task.AllFiles...)
+
+	var best string
+	var bestSize int64
+	for _, c := range candidates {
+		p := normalizePath(task.OutputDir, c)
+		if p == "" {
+			continue
+		}
+		if classifyByExt(p) != "video" && !audioExts[strings.ToLower(filepath.Ext(p))] {
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if info.Size() > bestSize {
+			best, bestSize = p, info.Size()
+		}
+	}
+	return best
+}
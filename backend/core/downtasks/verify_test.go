@@ -0,0 +1,100 @@
+package downtasks
+
+import (
+	"CanMe/backend/types"
+	"fmt"
+	"testing"
+
+	"github.com/lrstanley/go-ytdlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckMediaInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		info      types.DtMediaInfo
+		expected  float64
+		audioOnly bool
+		status    types.DtVerifyStatus
+		issues    []string
+	}{
+		{"ok", types.DtMediaInfo{VideoStreams: 1, AudioStreams: 1, Duration: 100}, 100, false, types.DtVerifyOK, nil},
+		{"no streams", types.DtMediaInfo{Duration: 100}, 0, false, types.DtVerifyCorrupt, []string{"no audio or video streams"}},
+		{"no audio", types.DtMediaInfo{VideoStreams: 1, Duration: 100}, 0, false, types.DtVerifyCorrupt, []string{"no audio stream"}},
+		{"no video", types.DtMediaInfo{AudioStreams: 1, Duration: 100}, 0, false, types.DtVerifyWarning, []string{"no video stream"}},
+		{"audio only", types.DtMediaInfo{AudioStreams: 1, Duration: 100}, 100, true, types.DtVerifyOK, nil},
+		{"no duration", types.DtMediaInfo{VideoStreams: 1, AudioStreams: 1}, 100, false, types.DtVerifyWarning, []string{"container reports no duration"}},
+		// 允许误差取 2 秒与 5% 中的较大值
+		{"within tolerance", types.DtMediaInfo{VideoStreams: 1, AudioStreams: 1, Duration: 96}, 100, false, types.DtVerifyOK, nil},
+		{"min tolerance", types.DtMediaInfo{VideoStreams: 1, AudioStreams: 1, Duration: 8.5}, 10, false, types.DtVerifyOK, nil},
+		{"truncated", types.DtMediaInfo{VideoStreams: 1, AudioStreams: 1, Duration: 90}, 100, false, types.DtVerifyCorrupt, []string{"duration 90.0s is shorter than expected 100.0s"}},
+		{"longer", types.DtMediaInfo{VideoStreams: 1, AudioStreams: 1, Duration: 110}, 100, false, types.DtVerifyWarning, []string{"duration 110.0s is longer than expected 100.0s"}},
+		// 损坏时同时附带警告
+		{"corrupt with warnings", types.DtMediaInfo{}, 100, false, types.DtVerifyCorrupt, []string{"no audio or video streams", "container reports no duration"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, issues := checkMediaInfo(&tt.info, tt.expected, 0.05, tt.audioOnly, true)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.issues, issues)
+		})
+	}
+}
+
+func TestCheckMediaInfoVideoOnlyFormat(t *testing.T) {
+	// 所选格式本就没有音频时，缺少音频流只是警告
+	info := types.DtMediaInfo{VideoStreams: 1, Duration: 100}
+	status, issues := checkMediaInfo(&info, 100, 0.05, false, false)
+	assert.Equal(t, types.DtVerifyWarning, status)
+	assert.Equal(t, []string{"no audio stream"}, issues)
+}
+
+func TestFormatHasAudio(t *testing.T) {
+	str := func(s string) *string { return &s }
+	format := func(id, vcodec, acodec string) *ytdlp.ExtractedFormat {
+		return &ytdlp.ExtractedFormat{FormatID: str(id), VCodec: str(vcodec), ACodec: str(acodec)}
+	}
+	metadata := &ytdlp.ExtractedInfo{
+		Formats: []*ytdlp.ExtractedFormat{
+			format("18", "avc1", "mp4a"),
+			format("137", "avc1", "none"),
+			format("140", "none", "mp4a"),
+		},
+		RequestedFormats: []*ytdlp.ExtractedFormat{format("137", "avc1", "none"), format("140", "none", "mp4a")},
+	}
+
+	tests := []struct {
+		name        string
+		formatID    string
+		mergesAudio bool
+		want        bool
+	}{
+		{"muxed format", "18", false, true},
+		{"video only", "137", false, false},
+		{"video only merged with bestaudio", "137", true, true},
+		{"explicit merge", "137+140", false, true},
+		{"unknown format", "999", true, false},
+		{"default selection", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatHasAudio(metadata, tt.formatID, tt.mergesAudio))
+		})
+	}
+
+	// 默认选择的格式没有音频
+	metadata.RequestedFormats = []*ytdlp.ExtractedFormat{format("137", "avc1", "none")}
+	assert.False(t, formatHasAudio(metadata, "", false))
+}
+
+func TestTaskErrorInfo(t *testing.T) {
+	info := taskErrorInfo(fmt.Errorf("wrapped: %w", &corruptOutputError{Issues: []string{"no audio stream"}}), "en")
+	assert.Equal(t, types.DtErrCorruptOutput, info.Code)
+	assert.Equal(t, "wrapped: media verification failed: no audio stream", info.Detail)
+
+	// 只有校验错误才归为 corrupt_output，输出中恰好包含同样文字时按原文归类
+	info = taskErrorInfo(fmt.Errorf("ERROR: media verification failed: HTTP Error 429: Too Many Requests"), "en")
+	assert.Equal(t, types.DtErrRateLimited, info.Code)
+	info = taskErrorInfo(fmt.Errorf("media verification failed"), "en")
+	assert.Equal(t, types.DtErrUnknown, info.Code)
+}
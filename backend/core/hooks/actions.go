@@ -0,0 +1,154 @@
+package hooks
+
+import (
+	"CanMe/backend/types"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runCommand 直接执行本地命令（不经过 shell），任务信息通过环境变量与 stdin 传入
+func (s *Service) runCommand(hook *types.TaskHook, task *types.DtTaskStatus, stage types.DtTaskStage, execLog *types.HookExecution) error {
+	payload, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(s.context(), hookTimeout(hook))
+	defer cancel()
+
+	cmd := createHiddenCommand(ctx, hook.Command, hook.Args...)
+	if hook.WorkDir != "" {
+		cmd.Dir = hook.WorkDir
+	}
+	cmd.Env = append(os.Environ(), taskEnv(task, stage)...)
+	cmd.Stdin = bytes.NewReader(payload)
+
+	execLog.Attempts = 1
+	output, err := cmd.CombinedOutput()
+	execLog.Output = truncateOutput(string(output))
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			execLog.ExitCode = exitErr.ExitCode()
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("command timed out after %s", hookTimeout(hook))
+		}
+		return err
+	}
+	return nil
+}
+
+// taskEnv 构造暴露给命令的 CANME_* 环境变量
+func taskEnv(task *types.DtTaskStatus, stage types.DtTaskStage) []string {
+	files := make([]string, 0, len(task.AllFiles))
+	for _, f := range task.AllFiles {
+		if f == "" {
+			continue
+		}
+		if !filepath.IsAbs(f) && task.OutputDir != "" {
+			f = filepath.Join(task.OutputDir, f)
+		}
+		files = append(files, f)
+	}
+
+	return []string{
+		"CANME_TASK_ID=" + task.ID,
+		"CANME_TASK_STAGE=" + string(stage),
+		"CANME_TASK_TYPE=" + task.Type,
+		"CANME_TASK_TITLE=" + task.Title,
+		"CANME_TASK_URL=" + task.URL,
+		"CANME_TASK_EXTRACTOR=" + task.Extractor,
+		"CANME_TASK_OUTPUT_DIR=" + task.OutputDir,
+		"CANME_TASK_FILES=" + strings.Join(files, string(os.PathListSeparator)),
+		"CANME_TASK_ERROR=" + task.Error,
+	}
+}
+
+// postWebhook POST 任务 JSON，失败（网络错误或非 2xx）时按指数退避重试
+func (s *Service) postWebhook(hook *types.TaskHook, task *types.DtTaskStatus, stage types.DtTaskStage, execLog *types.HookExecution) error {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	retries := hookRetries(hook)
+	client := &http.Client{Timeout: hookTimeout(hook)}
+	ctx := s.context()
+
+	var lastErr error
+	backoff := time.Second
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		execLog.Attempts = attempt + 1
+
+		status, respBody, err := sendWebhook(ctx, client, hook, stage, body)
+		execLog.StatusCode = status
+		execLog.Output = truncateOutput(respBody)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+func sendWebhook(ctx context.Context, client *http.Client, hook *types.TaskHook, stage types.DtTaskStage, body []byte) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	// 自定义请求头先设置，不能覆盖下面的 CanMe 请求头与签名
+	for k, v := range hook.Headers {
+		req.Header.Set(k, v)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "CanMe-Hook")
+	req.Header.Set("X-CanMe-Event", string(stage))
+	req.Header.Set("X-CanMe-Timestamp", timestamp)
+	if hook.Secret != "" {
+		req.Header.Set("X-CanMe-Signature", "sha256="+signPayload(hook.Secret, timestamp, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxOutputLength))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(respBody), fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(respBody), nil
+}
+
+// signPayload 计算 "时间戳.请求体" 的 HMAC-SHA256（十六进制），接收方据时间戳拒绝重放的请求
+func signPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
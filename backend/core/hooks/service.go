@@ -0,0 +1,308 @@
+package hooks
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/core/downtasks"
+	"CanMe/backend/pkg/events"
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/storage"
+	"CanMe/backend/types"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTimeout    = 60 * time.Second
+	defaultMaxRetries = 3
+	maxExecutionLogs  = 500
+	maxOutputLength   = 4096
+	// 同一任务同一阶段在该窗口内只触发一次（失败事件可能被重复上报）
+	dedupeWindow = 30 * time.Second
+)
+
+// Service 在下载任务完成/失败/取消时执行用户配置的钩子
+type Service struct {
+	ctx         context.Context
+	eventBus    events.EventBus
+	boltStorage *storage.BoltStorage
+	downtasks   *downtasks.Service
+
+	mu    sync.Mutex
+	fired map[string]time.Time // taskID|stage -> 最近触发时间
+}
+
+func NewService(boltStorage *storage.BoltStorage, eventBus events.EventBus, dt *downtasks.Service) *Service {
+	return &Service{
+		eventBus:    eventBus,
+		boltStorage: boltStorage,
+		downtasks:   dt,
+		fired:       make(map[string]time.Time),
+	}
+}
+
+func (s *Service) SetContext(ctx context.Context) {
+	s.ctx = ctx
+	if s.eventBus == nil {
+		return
+	}
+	s.eventBus.Subscribe(consts.TopicDowntasksProgress, events.HandlerFunc(func(ctx context.Context, event events.Event) error {
+		progress, ok := event.GetData().(*types.DtProgress)
+		if !ok || progress == nil {
+			return nil
+		}
+		s.onProgress(progress)
+		return nil
+	}))
+}
+
+// ListHooks 列出所有钩子
+func (s *Service) ListHooks() ([]*types.TaskHook, error) {
+	return s.boltStorage.ListHooks()
+}
+
+// SaveHook 新建或更新钩子
+func (s *Service) SaveHook(hook *types.TaskHook) (*types.TaskHook, error) {
+	if hook == nil {
+		return nil, fmt.Errorf("hook is nil")
+	}
+	if err := validateHook(hook); err != nil {
+		return nil, err
+	}
+	if hook.ID == "" {
+		hook.ID = uuid.New().String()
+	}
+	if existing, err := s.boltStorage.GetHook(hook.ID); err == nil {
+		hook.CreatedAt = existing.CreatedAt
+	}
+	if hook.CreatedAt == 0 {
+		hook.CreatedAt = time.Now().Unix()
+	}
+	if err := s.boltStorage.SaveHook(hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// DeleteHook 删除钩子
+func (s *Service) DeleteHook(id string) error {
+	return s.boltStorage.DeleteHook(id)
+}
+
+// ListExecutions 查询执行记录（hookID/taskID 为空表示不过滤）
+func (s *Service) ListExecutions(hookID, taskID string, limit int) ([]*types.HookExecution, error) {
+	return s.boltStorage.ListHookExecutions(hookID, taskID, limit)
+}
+
+// TestHook 使用指定任务立即执行一次钩子（忽略过滤条件与启用状态）
+func (s *Service) TestHook(hookID, taskID string) (*types.HookExecution, error) {
+	hook, err := s.boltStorage.GetHook(hookID)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.downtasks.GetTaskStatus(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return s.execute(hook, task, task.Stage), nil
+}
+
+func (s *Service) onProgress(progress *types.DtProgress) {
+	if !isHookStage(progress.Stage) {
+		return
+	}
+	if !s.markFired(progress.ID, progress.Stage) {
+		return
+	}
+
+	// 事件总线为同步发布，钩子在独立 goroutine 中执行，避免阻塞下载流程
+	go func() {
+		hooks, err := s.boltStorage.ListHooks()
+		if err != nil {
+			logger.Warn("Failed to list hooks", zap.Error(err))
+			return
+		}
+		task, err := s.downtasks.GetTaskStatus(progress.ID)
+		if err != nil {
+			return
+		}
+		for _, hook := range hooks {
+			if !matchHook(hook, task, progress.Stage) {
+				continue
+			}
+			s.execute(hook, task, progress.Stage)
+		}
+	}()
+}
+
+func (s *Service) markFired(taskID string, stage types.DtTaskStage) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, t := range s.fired {
+		if now.Sub(t) > dedupeWindow {
+			delete(s.fired, k)
+		}
+	}
+	key := taskID + "|" + string(stage)
+	if _, ok := s.fired[key]; ok {
+		return false
+	}
+	s.fired[key] = now
+	return true
+}
+
+// execute 执行钩子并记录结果
+func (s *Service) execute(hook *types.TaskHook, task *types.DtTaskStatus, stage types.DtTaskStage) *types.HookExecution {
+	exec := &types.HookExecution{
+		ID:        uuid.New().String(),
+		HookID:    hook.ID,
+		HookName:  hook.Name,
+		Action:    hook.Action,
+		TaskID:    task.ID,
+		TaskTitle: task.Title,
+		Stage:     stage,
+		StartedAt: time.Now().UnixMilli(),
+	}
+
+	var err error
+	switch hook.Action {
+	case types.HookActionCommand:
+		err = s.runCommand(hook, task, stage, exec)
+	case types.HookActionWebhook:
+		err = s.postWebhook(hook, task, stage, exec)
+	default:
+		err = fmt.Errorf("unsupported hook action: %s", hook.Action)
+	}
+
+	exec.FinishedAt = time.Now().UnixMilli()
+	exec.Success = err == nil
+	if err != nil {
+		exec.Error = err.Error()
+		logger.Warn("Hook execution failed",
+			zap.String("hook", hook.Name),
+			zap.String("taskId", task.ID),
+			zap.Error(err),
+		)
+	} else {
+		logger.Info("Hook executed",
+			zap.String("hook", hook.Name),
+			zap.String("taskId", task.ID),
+			zap.String("stage", string(stage)),
+		)
+	}
+
+	if err := s.boltStorage.SaveHookExecution(exec, maxExecutionLogs); err != nil {
+		logger.Warn("Failed to save hook execution", zap.Error(err))
+	}
+	return exec
+}
+
+func (s *Service) context() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+	return context.Background()
+}
+
+func validateHook(hook *types.TaskHook) error {
+	hook.Name = strings.TrimSpace(hook.Name)
+	if hook.Name == "" {
+		return fmt.Errorf("hook name is required")
+	}
+	for _, st := range hook.Stages {
+		if !isHookStage(st) {
+			return fmt.Errorf("unsupported hook stage: %s", st)
+		}
+	}
+	if hook.TimeoutSec < 0 || (hook.MaxRetries != nil && *hook.MaxRetries < 0) {
+		return fmt.Errorf("timeout and retries must not be negative")
+	}
+
+	switch hook.Action {
+	case types.HookActionCommand:
+		hook.Command = strings.TrimSpace(hook.Command)
+		if hook.Command == "" {
+			return fmt.Errorf("command is required")
+		}
+	case types.HookActionWebhook:
+		hook.URL = strings.TrimSpace(hook.URL)
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook url: %s", hook.URL)
+		}
+	default:
+		return fmt.Errorf("unsupported hook action: %s", hook.Action)
+	}
+	return nil
+}
+
+// isHookStage 判断阶段是否可触发钩子（cancelled 由停止任务触发）
+func isHookStage(stage types.DtTaskStage) bool {
+	return stage == types.DtStageCompleted || stage == types.DtStageFailed || stage == types.DtStageCancelled
+}
+
+// matchHook 判断钩子是否应对该任务/阶段触发
+func matchHook(hook *types.TaskHook, task *types.DtTaskStatus, stage types.DtTaskStage) bool {
+	if hook == nil || !hook.Enabled {
+		return false
+	}
+	if len(hook.Stages) > 0 {
+		matched := false
+		for _, st := range hook.Stages {
+			if st == stage {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(hook.TaskTypes) > 0 && !containsFold(hook.TaskTypes, task.Type) {
+		return false
+	}
+	if len(hook.Extractors) > 0 && !containsFold(hook.Extractors, task.Extractor) {
+		return false
+	}
+	return true
+}
+
+func containsFold(list []string, v string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), v) {
+			return true
+		}
+	}
+	return false
+}
+
+func hookTimeout(hook *types.TaskHook) time.Duration {
+	if hook.TimeoutSec > 0 {
+		return time.Duration(hook.TimeoutSec) * time.Second
+	}
+	return defaultTimeout
+}
+
+// hookRetries 返回 Webhook 失败后的重试次数，未设置时使用默认值，0 表示不重试
+func hookRetries(hook *types.TaskHook) int {
+	if hook.MaxRetries != nil {
+		return *hook.MaxRetries
+	}
+	return defaultMaxRetries
+}
+
+func truncateOutput(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= maxOutputLength {
+		return s
+	}
+	return s[:maxOutputLength] + "..."
+}
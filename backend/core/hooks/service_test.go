@@ -0,0 +1,164 @@
+package hooks
+
+import (
+	"CanMe/backend/types"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateHook(t *testing.T) {
+	hook := &types.TaskHook{Name: "  notify ", Action: types.HookActionWebhook, URL: " https://example.com/hook "}
+	require.NoError(t, validateHook(hook))
+	assert.Equal(t, "notify", hook.Name)
+	assert.Equal(t, "https://example.com/hook", hook.URL)
+
+	hook = &types.TaskHook{Name: "copy", Action: types.HookActionCommand, Command: " rsync ",
+		Stages: []types.DtTaskStage{types.DtStageCompleted, types.DtStageFailed, types.DtStageCancelled}}
+	require.NoError(t, validateHook(hook))
+	assert.Equal(t, "rsync", hook.Command)
+
+	tests := []struct {
+		name string
+		hook types.TaskHook
+		err  string
+	}{
+		{"no name", types.TaskHook{Name: " ", Action: types.HookActionCommand, Command: "x"}, "name is required"},
+		{"running stage", types.TaskHook{Name: "a", Action: types.HookActionCommand, Command: "x",
+			Stages: []types.DtTaskStage{types.DtStageDownloading}}, "unsupported hook stage"},
+		{"negative timeout", types.TaskHook{Name: "a", Action: types.HookActionCommand, Command: "x", TimeoutSec: -1}, "must not be negative"},
+		{"negative retries", types.TaskHook{Name: "a", Action: types.HookActionWebhook, URL: "https://e.com", MaxRetries: intPtr(-1)}, "must not be negative"},
+		{"empty command", types.TaskHook{Name: "a", Action: types.HookActionCommand, Command: "  "}, "command is required"},
+		{"ftp url", types.TaskHook{Name: "a", Action: types.HookActionWebhook, URL: "ftp://e.com/x"}, "invalid webhook url"},
+		{"no host", types.TaskHook{Name: "a", Action: types.HookActionWebhook, URL: "https:///x"}, "invalid webhook url"},
+		{"unknown action", types.TaskHook{Name: "a", Action: "email"}, "unsupported hook action"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, validateHook(&tt.hook), tt.err)
+		})
+	}
+}
+
+func TestMatchHook(t *testing.T) {
+	task := &types.DtTaskStatus{ID: "t1", Type: "quick"}
+	task.Extractor = "BiliBili"
+
+	tests := []struct {
+		name  string
+		hook  *types.TaskHook
+		stage types.DtTaskStage
+		want  bool
+	}{
+		{"nil", nil, types.DtStageCompleted, false},
+		{"disabled", &types.TaskHook{}, types.DtStageCompleted, false},
+		{"no filters", &types.TaskHook{Enabled: true}, types.DtStageFailed, true},
+		{"stage", &types.TaskHook{Enabled: true, Stages: []types.DtTaskStage{types.DtStageCompleted}}, types.DtStageCompleted, true},
+		{"stage miss", &types.TaskHook{Enabled: true, Stages: []types.DtTaskStage{types.DtStageCompleted}}, types.DtStageFailed, false},
+		{"type fold", &types.TaskHook{Enabled: true, TaskTypes: []string{"custom", " Quick "}}, types.DtStageCompleted, true},
+		{"type miss", &types.TaskHook{Enabled: true, TaskTypes: []string{"mcp"}}, types.DtStageCompleted, false},
+		{"extractor fold", &types.TaskHook{Enabled: true, Extractors: []string{"bilibili"}}, types.DtStageCompleted, true},
+		{"extractor miss", &types.TaskHook{Enabled: true, Extractors: []string{"youtube"}}, types.DtStageCompleted, false},
+		{"all", &types.TaskHook{Enabled: true, Stages: []types.DtTaskStage{types.DtStageFailed},
+			TaskTypes: []string{"quick"}, Extractors: []string{"bilibili"}}, types.DtStageFailed, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchHook(tt.hook, task, tt.stage))
+		})
+	}
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestSignPayload(t *testing.T) {
+	body := []byte(`{"id":"t1"}`)
+	sig := signPayload("secret", "1700000000", body)
+
+	// 接收方按相同方式校验
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), sig)
+	assert.Len(t, sig, 64)
+
+	assert.NotEqual(t, sig, signPayload("other", "1700000000", body))
+	assert.NotEqual(t, sig, signPayload("secret", "1700000001", body))
+	assert.NotEqual(t, sig, signPayload("secret", "1700000000", []byte(`{"id":"t2"}`)))
+}
+
+func TestPostWebhook(t *testing.T) {
+	var requests []*http.Request
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	s := &Service{}
+	task := &types.DtTaskStatus{ID: "t1"}
+	hook := &types.TaskHook{URL: srv.URL, Secret: "secret", MaxRetries: intPtr(0), Headers: map[string]string{
+		"Authorization":     "Bearer token",
+		"X-CanMe-Signature": "forged",
+		"x-canme-timestamp": "1",
+	}}
+	exec := &types.HookExecution{}
+	require.ErrorContains(t, s.postWebhook(hook, task, types.DtStageCancelled, exec), "status 502")
+
+	// MaxRetries 为 0 时不重试
+	require.Len(t, requests, 1)
+	assert.Equal(t, 1, exec.Attempts)
+	assert.Equal(t, http.StatusBadGateway, exec.StatusCode)
+
+	// 自定义请求头不能覆盖 CanMe 请求头，签名覆盖时间戳
+	req := requests[0]
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	assert.Equal(t, "cancelled", req.Header.Get("X-CanMe-Event"))
+	timestamp := req.Header.Get("X-CanMe-Timestamp")
+	assert.NotEqual(t, "1", timestamp)
+	assert.Equal(t, "sha256="+signPayload("secret", timestamp, bodies[0]), req.Header.Get("X-CanMe-Signature"))
+}
+
+func TestHookRetries(t *testing.T) {
+	assert.Equal(t, defaultMaxRetries, hookRetries(&types.TaskHook{}))
+	assert.Equal(t, 0, hookRetries(&types.TaskHook{MaxRetries: intPtr(0)}))
+	assert.Equal(t, 5, hookRetries(&types.TaskHook{MaxRetries: intPtr(5)}))
+
+	// 未设置的旧配置仍使用默认值
+	var hook types.TaskHook
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"a"}`), &hook))
+	assert.Equal(t, defaultMaxRetries, hookRetries(&hook))
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"a","maxRetries":0}`), &hook))
+	assert.Equal(t, 0, hookRetries(&hook))
+}
+
+func TestMarkFired(t *testing.T) {
+	s := &Service{fired: map[string]time.Time{}}
+	assert.True(t, s.markFired("t1", types.DtStageFailed))
+	assert.False(t, s.markFired("t1", types.DtStageFailed))
+	assert.True(t, s.markFired("t1", types.DtStageCompleted))
+	assert.True(t, s.markFired("t2", types.DtStageFailed))
+
+	// 超出去重窗口后可再次触发
+	s.fired["t1|failed"] = time.Now().Add(-dedupeWindow - time.Second)
+	assert.True(t, s.markFired("t1", types.DtStageFailed))
+}
+
+func TestOnProgressIgnoresUnsupportedStages(t *testing.T) {
+	s := &Service{fired: map[string]time.Time{}}
+	for _, stage := range []types.DtTaskStage{types.DtStageDownloading, types.DtStagePaused, types.DtStageQueued} {
+		s.onProgress(&types.DtProgress{ID: "t1", Stage: stage})
+	}
+	assert.Empty(t, s.fired)
+}
@@ -0,0 +1,43 @@
+package subtitles
+
+import (
+	"CanMe/backend/pkg/autofix"
+	"CanMe/backend/types"
+	"maps"
+	"slices"
+)
+
+// AutoFix 按规范自动修复字幕（断行、时长、间隔、重叠、合并）；DryRun 时只返回将要进行的修改。
+// 实际修复前会自动创建快照，并作为一次可撤销的编辑记录
+func (s *Service) AutoFix(id string, options types.AutoFixOptions) (*types.AutoFixReport, error) {
+	project, err := s.loadProject("auto fix", id)
+	if err != nil {
+		return nil, err
+	}
+	segments, changes := autofix.Apply(project.Segments, options, projectFrameRate(project))
+	report := &types.AutoFixReport{
+		DryRun:  options.DryRun,
+		Changes: changes,
+		Summary: make(map[types.AutoFixType]int),
+	}
+	for _, change := range changes {
+		report.Summary[change.Type]++
+	}
+	if options.DryRun || len(changes) == 0 {
+		return report, nil
+	}
+
+	s.autoSnapshot(project, "Before auto-fix")
+	assessor := s.qualityAssessor.Pass()
+	for i := range segments {
+		// 未修改的片段与原项目共享 Languages，评估前先复制，避免改动操作日志中的编辑前状态
+		segments[i].Languages = maps.Clone(segments[i].Languages)
+		segments[i] = *assessor.AssessSegmentQuality(&segments[i])
+	}
+	change := types.SegmentChange{Before: slices.Clone(project.Segments), After: segments}
+	project.Segments = segments
+	if _, err := s.saveStructureChange("auto fix", project, types.EditAutoFix, change); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,119 @@
+package subtitles
+
+import (
+	"CanMe/backend/types"
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// convertBilingual 将两种语言合并到同一条字幕中导出（SRT/VTT 上下堆叠，ASS 每种语言单独样式），first 在上方
+func convertBilingual(converter FormatConverter, project *types.SubtitleProject, first, second, targetFormat string) ([]byte, error) {
+	if first == "" || second == "" {
+		return nil, fmt.Errorf("bilingual export needs two languages")
+	}
+	if first == second {
+		return nil, fmt.Errorf("bilingual export needs two different languages")
+	}
+	for _, lang := range []string{first, second} {
+		if _, exists := project.LanguageMetadata[lang]; !exists {
+			return nil, fmt.Errorf("language '%s' not found in project", lang)
+		}
+	}
+
+	switch strings.ToLower(targetFormat) {
+	case "srt":
+		return converter.ToSRT(stackedProject(project, first, second), first)
+	case "vtt":
+		return converter.ToVTT(stackedProject(project, first, second), first)
+	case "ass", "ssa":
+		return bilingualAss(project, first, second), nil
+	default:
+		return nil, fmt.Errorf("bilingual export supports srt, vtt and ass, not %s", targetFormat)
+	}
+}
+
+// stackedProject 复制项目，每个片段只保留 first 一种语言，文本为 first 与 second 上下两段；
+// 只有一种语言的片段照常导出该语言
+func stackedProject(project *types.SubtitleProject, first, second string) *types.SubtitleProject {
+	stacked := *project
+	stacked.LanguageMetadata = map[string]types.LanguageMetadata{first: project.LanguageMetadata[first]}
+	stacked.Segments = make([]types.SubtitleSegment, 0, len(project.Segments))
+	for _, segment := range project.Segments {
+		var parts []string
+		for _, lang := range []string{first, second} {
+			if text := strings.TrimSpace(segment.GetText(lang)); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		segment.Languages = map[string]types.LanguageContent{first: {Text: strings.Join(parts, "\n")}}
+		stacked.Segments = append(stacked.Segments, segment)
+	}
+	return &stacked
+}
+
+// bilingualAss 每条字幕为每种语言各写一个 Dialogue。两种语言位置相同时依赖播放器的碰撞处理堆叠：
+// 底部先写下方语言，顶部先写上方语言
+func bilingualAss(project *types.SubtitleProject, first, second string) []byte {
+	var config types.BilingualExportConfig
+	if project.Metadata.ExportConfigs.Bilingual != nil {
+		config = *project.Metadata.ExportConfigs.Bilingual
+	}
+
+	langs := []string{first, second}
+	names := make(map[string]string, len(langs))
+	positions := make(map[string]types.BilingualPosition, len(langs))
+	var styles []string
+	for i, lang := range langs {
+		style := config.Styles[lang]
+		if style.FontName == "" {
+			style.FontName = "Arial"
+		}
+		if style.FontSize <= 0 {
+			style.FontSize = 48
+			if i > 0 {
+				style.FontSize = 36
+			}
+		}
+		if style.Position == "" {
+			style.Position = types.BilingualPositionBottom
+		}
+		if style.MarginV <= 0 {
+			style.MarginV = 10
+		}
+		align := 2
+		if style.Position == types.BilingualPositionTop {
+			align = 8
+		}
+		// 样式名不能包含逗号
+		name := fmt.Sprintf("%s-%d", strings.ReplaceAll(lang, ",", " "), i+1)
+		names[lang], positions[lang] = name, style.Position
+		styles = append(styles, fmt.Sprintf("Style: %s,%s,%.0f,%s,&H000000FF,&H00000000,&H64000000,0,0,0,0,100,100,0,0,1,2,0,%d,10,10,%d,1",
+			name, style.FontName, style.FontSize, hexToAssColor(style.Color), align, style.MarginV))
+	}
+	if positions[first] == types.BilingualPositionBottom && positions[second] == types.BilingualPositionBottom {
+		slices.Reverse(langs)
+	}
+
+	var buf bytes.Buffer
+	writeAssHeader(&buf, project, styles)
+	for _, segment := range project.Segments {
+		start := assTime(segment.StartTime.Time)
+		end := assTime(segment.EndTime.Time)
+		for _, lang := range langs {
+			text := strings.TrimSpace(segment.GetText(lang))
+			if text == "" {
+				continue
+			}
+			buf.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,%s,,0,0,0,,%s\n", start, end, names[lang], assText(text)))
+		}
+	}
+	return buf.Bytes()
+}
+
+// assText 转义 Dialogue 文本：花括号会被当作样式覆盖块，换行写为 \N
+var assText = strings.NewReplacer("{", "\\{", "}", "\\}", "\n", "\\N").Replace
@@ -0,0 +1,136 @@
+package subtitles
+
+import (
+	"CanMe/backend/types"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seg 构造片段，texts 为语言代码到文本的映射，时间单位为毫秒
+func seg(id string, startMs, endMs int, texts map[string]string) types.SubtitleSegment {
+	segment := types.SubtitleSegment{
+		ID:        id,
+		StartTime: types.Timecode{Time: time.Duration(startMs) * time.Millisecond},
+		EndTime:   types.Timecode{Time: time.Duration(endMs) * time.Millisecond},
+		Languages: map[string]types.LanguageContent{},
+	}
+	for lang, text := range texts {
+		segment.Languages[lang] = types.LanguageContent{Text: text}
+	}
+	return segment
+}
+
+func bilingualProject() *types.SubtitleProject {
+	return &types.SubtitleProject{
+		ID: "p1",
+		LanguageMetadata: map[string]types.LanguageMetadata{
+			"zh": {}, "en": {}, "en+fr": {},
+		},
+		Segments: []types.SubtitleSegment{
+			seg("s1", 1000, 2000, map[string]string{"zh": "你好", "en": "Hello\nthere", "en+fr": "Bonjour"}),
+			seg("s2", 3000, 4000, map[string]string{"en": "Only English"}),
+			seg("s3", 5000, 6000, map[string]string{"zh": " ", "en": ""}),
+		},
+	}
+}
+
+// dialogues 返回 ASS 中 Dialogue 行的样式与文本
+func dialogues(data []byte) [][2]string {
+	var events [][2]string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "Dialogue: ") {
+			continue
+		}
+		fields := strings.SplitN(line, ",", 10)
+		events = append(events, [2]string{fields[3], fields[9]})
+	}
+	return events
+}
+
+func TestStackedProject(t *testing.T) {
+	project := bilingualProject()
+	stacked := stackedProject(project, "zh", "en")
+
+	assert.Equal(t, []string{"zh"}, projectLangs(stacked))
+	require.Len(t, stacked.Segments, 2)
+	assert.Equal(t, map[string]types.LanguageContent{"zh": {Text: "你好\nHello\nthere"}}, stacked.Segments[0].Languages)
+	assert.Equal(t, "Only English", stacked.Segments[1].GetText("zh"))
+	// 原项目不变
+	assert.Len(t, project.Segments, 3)
+	assert.Equal(t, "Hello\nthere", project.Segments[0].GetText("en"))
+}
+
+func projectLangs(project *types.SubtitleProject) []string {
+	var langs []string
+	for lang := range project.LanguageMetadata {
+		langs = append(langs, lang)
+	}
+	return langs
+}
+
+func TestBilingualAss(t *testing.T) {
+	project := bilingualProject()
+
+	data := string(bilingualAss(project, "zh", "en"))
+	assert.Contains(t, data, "Style: zh-1,Arial,48,&H00FFFFFF,")
+	assert.Contains(t, data, ",2,10,10,10,1\nStyle: en-2,Arial,36,")
+	// 都在底部时先写下方语言
+	assert.Equal(t, [][2]string{
+		{"en-2", `Hello\Nthere`},
+		{"zh-1", "你好"},
+		{"en-2", "Only English"},
+	}, dialogues([]byte(data)))
+
+	project.Metadata.ExportConfigs.Bilingual = &types.BilingualExportConfig{Styles: map[string]types.BilingualStyle{
+		"zh": {FontName: "Noto Sans", FontSize: 40, Color: "#FF8000", Position: types.BilingualPositionTop, MarginV: 30},
+	}}
+	data = string(bilingualAss(project, "zh", "en"))
+	assert.Contains(t, data, "Style: zh-1,Noto Sans,40,&H000080FF,&H000000FF,&H00000000,&H64000000,0,0,0,0,100,100,0,0,1,2,0,8,10,10,30,1")
+	assert.Equal(t, [][2]string{
+		{"zh-1", "你好"},
+		{"en-2", `Hello\Nthere`},
+		{"en-2", "Only English"},
+	}, dialogues([]byte(data)))
+
+	// 文本中的花括号不能被当作样式覆盖块
+	project.Segments = []types.SubtitleSegment{seg("s1", 0, 1000, map[string]string{"zh": "{\\b1}粗体", "en": "a {note}"})}
+	assert.Equal(t, [][2]string{
+		{"zh-1", `\{\b1\}粗体`},
+		{"en-2", `a \{note\}`},
+	}, dialogues(bilingualAss(project, "zh", "en")))
+}
+
+func TestConvertBilingualErrors(t *testing.T) {
+	project := bilingualProject()
+	converter := NewFormatConverter()
+
+	_, err := convertBilingual(converter, project, "zh", "zh", "srt")
+	assert.EqualError(t, err, "bilingual export needs two different languages")
+	_, err = convertBilingual(converter, project, "zh", "", "srt")
+	assert.EqualError(t, err, "bilingual export needs two languages")
+	_, err = convertBilingual(converter, project, "zh", "ja", "srt")
+	assert.EqualError(t, err, "language 'ja' not found in project")
+	_, err = convertBilingual(converter, project, "zh", "en", "itt")
+	assert.EqualError(t, err, "bilingual export supports srt, vtt and ass, not itt")
+}
+
+func TestConvertSubtileBilingual(t *testing.T) {
+	s := newStorageService(t)
+	s.formatConverter = NewFormatConverter()
+	require.NoError(t, s.boltStorage.SaveSubtitle(bilingualProject()))
+
+	data, err := s.ConvertSubtile("p1", "zh", "srt", "en")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "00:00:01,000 --> 00:00:02,000\n你好\nHello\nthere\n")
+	assert.Contains(t, string(data), "Only English")
+
+	// 语言键本身含有 + 时按单一语言导出
+	data, err = s.ConvertSubtile("p1", "en+fr", "srt", "")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Bonjour")
+	assert.NotContains(t, string(data), "Hello")
+}
@@ -1122,7 +1122,32 @@ func parseAssTimecode(tc string) (types.Timecode, error) {
 
 // toAss 导出为 ASS（最小可用）
 func (s *FormatConverterImpl) toAss(project *types.SubtitleProject, languageCode string) ([]byte, error) {
+	// ensure language exists
+	if _, exists := project.LanguageMetadata[languageCode]; !exists {
+		return nil, fmt.Errorf("language '%s' not found in project", languageCode)
+	}
+
 	var buf bytes.Buffer
+	writeAssHeader(&buf, project, nil)
+	for _, seg := range project.Segments {
+		if !seg.HasLanguage(languageCode) {
+			continue
+		}
+		start := assTime(seg.StartTime.Time)
+		end := assTime(seg.EndTime.Time)
+		lc := seg.Languages[languageCode]
+		styleName := strings.TrimSpace(lc.StyleID)
+		if styleName == "" || project.GlobalStyles[styleName].FontName == "" {
+			styleName = "Default"
+		}
+		text := strings.ReplaceAll(lc.Text, "\n", "\\N")
+		buf.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,%s,,0,0,0,,%s\n", start, end, styleName, text))
+	}
+	return buf.Bytes(), nil
+}
+
+// writeAssHeader 写入 [Script Info]、[V4+ Styles]（项目样式与 extraStyles）及 [Events] 的格式行
+func writeAssHeader(buf *bytes.Buffer, project *types.SubtitleProject, extraStyles []string) {
 	// basic header
 	title := project.ProjectName
 	if project.Metadata.ExportConfigs.ASS != nil && project.Metadata.ExportConfigs.ASS.Title != "" {
@@ -1157,16 +1182,6 @@ func (s *FormatConverterImpl) toAss(project *types.SubtitleProject, languageCode
 	buf.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
 	// Write styles from GlobalStyles; include Default if none
 	wroteAny := false
-	hexToAss := func(hex string) string {
-		// #RRGGBB -> &H00BBGGRR
-		if len(hex) == 7 && hex[0] == '#' {
-			rr := strings.ToUpper(hex[1:3])
-			gg := strings.ToUpper(hex[3:5])
-			bb := strings.ToUpper(hex[5:7])
-			return "&H00" + bb + gg + rr
-		}
-		return "&H00FFFFFF"
-	}
 	for name, st := range project.GlobalStyles {
 		if strings.TrimSpace(name) == "" {
 			continue
@@ -1179,7 +1194,7 @@ func (s *FormatConverterImpl) toAss(project *types.SubtitleProject, languageCode
 		if size <= 0 {
 			size = 48
 		}
-		prim := hexToAss(st.Color)
+		prim := hexToAssColor(st.Color)
 		bold := -0
 		if st.Bold {
 			bold = -1
@@ -1204,30 +1219,24 @@ func (s *FormatConverterImpl) toAss(project *types.SubtitleProject, languageCode
 	if !wroteAny {
 		buf.WriteString("Style: Default,Arial,48,&H00FFFFFF,&H000000FF,&H00000000,&H64000000,-1,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1\n")
 	}
+	for _, line := range extraStyles {
+		buf.WriteString(line + "\n")
+	}
 	buf.WriteString("\n")
 
 	buf.WriteString("[Events]\n")
 	buf.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+}
 
-	// ensure language exists
-	if _, exists := project.LanguageMetadata[languageCode]; !exists {
-		return nil, fmt.Errorf("language '%s' not found in project", languageCode)
-	}
-	for _, seg := range project.Segments {
-		if !seg.HasLanguage(languageCode) {
-			continue
-		}
-		start := assTime(seg.StartTime.Time)
-		end := assTime(seg.EndTime.Time)
-		lc := seg.Languages[languageCode]
-		styleName := strings.TrimSpace(lc.StyleID)
-		if styleName == "" || project.GlobalStyles[styleName].FontName == "" {
-			styleName = "Default"
-		}
-		text := strings.ReplaceAll(lc.Text, "\n", "\\N")
-		buf.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,%s,,0,0,0,,%s\n", start, end, styleName, text))
+// hexToAssColor #RRGGBB -> &H00BBGGRR
+func hexToAssColor(hex string) string {
+	if len(hex) == 7 && hex[0] == '#' {
+		rr := strings.ToUpper(hex[1:3])
+		gg := strings.ToUpper(hex[3:5])
+		bb := strings.ToUpper(hex[5:7])
+		return "&H00" + bb + gg + rr
 	}
-	return buf.Bytes(), nil
+	return "&H00FFFFFF"
 }
 
 func assTime(d time.Duration) string {
@@ -0,0 +1,362 @@
+package subtitles
+
+import (
+	"CanMe/backend/pkg/edithistory"
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/pkg/termbase"
+	"CanMe/backend/types"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ListGlossaries 列出术语表，workspace 为空时列出全部
+func (s *Service) ListGlossaries(workspace string) ([]*types.Glossary, error) {
+	glossaries, err := s.boltStorage.ListGlossaries()
+	if err != nil {
+		return nil, err
+	}
+	if workspace == "" {
+		return glossaries, nil
+	}
+	workspace = normalizeWorkspace(workspace)
+	out := []*types.Glossary{}
+	for _, glossary := range glossaries {
+		if glossary.Workspace == workspace {
+			out = append(out, glossary)
+		}
+	}
+	return out, nil
+}
+
+// GetGlossary 获取术语表
+func (s *Service) GetGlossary(id string) (*types.Glossary, error) {
+	return s.boltStorage.GetGlossary(id)
+}
+
+// SaveGlossary 校验并保存术语表（ID 为空时创建）
+func (s *Service) SaveGlossary(glossary *types.Glossary) (*types.Glossary, error) {
+	if glossary == nil {
+		return nil, errors.New("glossary is nil")
+	}
+	glossary.Name = strings.TrimSpace(glossary.Name)
+	if glossary.Name == "" {
+		return nil, errors.New("glossary name is required")
+	}
+	glossary.Workspace = normalizeWorkspace(glossary.Workspace)
+	glossary.SourceLang = strings.TrimSpace(glossary.SourceLang)
+	glossary.TargetLang = strings.TrimSpace(glossary.TargetLang)
+	glossary.Terms = normalizeTerms(glossary.Terms)
+
+	if glossary.ID == "" {
+		glossary.ID = uuid.New().String()
+	}
+	if existing, err := s.boltStorage.GetGlossary(glossary.ID); err == nil {
+		glossary.CreatedAt = existing.CreatedAt
+	}
+	if glossary.CreatedAt == 0 {
+		glossary.CreatedAt = time.Now().Unix()
+	}
+	if err := s.boltStorage.SaveGlossary(glossary); err != nil {
+		return nil, s.handleError("save glossary", err)
+	}
+	return glossary, nil
+}
+
+// DeleteGlossary 删除术语表
+func (s *Service) DeleteGlossary(id string) error {
+	return s.boltStorage.DeleteGlossary(id)
+}
+
+// ImportGlossary 导入 TBX/CSV 术语表。target.ID 非空时合并到已有术语表（同名术语以导入为准），
+// 否则按 target 的名称、工作区与语言新建；未指定语言时使用文件中的语言
+func (s *Service) ImportGlossary(data []byte, format types.GlossaryFormat, target types.Glossary) (*types.Glossary, error) {
+	if target.ID != "" {
+		existing, err := s.boltStorage.GetGlossary(target.ID)
+		if err != nil {
+			return nil, err
+		}
+		target = *existing
+	}
+
+	var imported *types.Glossary
+	var err error
+	switch format {
+	case types.GlossaryFormatTBX:
+		imported, err = termbase.ParseTBX(bytes.NewReader(data), target.SourceLang, target.TargetLang)
+	case types.GlossaryFormatCSV:
+		imported, err = termbase.ParseCSV(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("unsupported glossary format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(imported.Terms) == 0 {
+		return nil, errors.New("no terms found")
+	}
+
+	if target.SourceLang == "" {
+		target.SourceLang = imported.SourceLang
+	}
+	if target.TargetLang == "" {
+		target.TargetLang = imported.TargetLang
+	}
+	if target.Name == "" {
+		target.Name = strings.TrimSpace(imported.Name)
+	}
+	if target.Name == "" {
+		target.Name = "Imported glossary"
+	}
+	// 导入的术语放在后面，去重时覆盖已有的同名术语
+	target.Terms = append(target.Terms, imported.Terms...)
+	return s.SaveGlossary(&target)
+}
+
+// ExportGlossary 导出术语表为 TBX/CSV
+func (s *Service) ExportGlossary(id string, format types.GlossaryFormat) ([]byte, error) {
+	glossary, err := s.boltStorage.GetGlossary(id)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	switch format {
+	case types.GlossaryFormatTBX:
+		err = termbase.WriteTBX(&buf, glossary)
+	case types.GlossaryFormatCSV:
+		err = termbase.WriteCSV(&buf, glossary)
+	default:
+		return nil, fmt.Errorf("unsupported glossary format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ApproveSegments 审核通过 targetLang 的译文，并将 origin/targetLang 片段对写入项目工作区的翻译记忆。
+// segmentIDs 为空时审核全部片段
+func (s *Service) ApproveSegments(id, origin, targetLang string, segmentIDs []string) (*types.SubtitleProject, error) {
+	if id == "" {
+		return nil, errors.New("subtitle id is empty")
+	}
+	if origin == "" || targetLang == "" || origin == targetLang {
+		return nil, errors.New("origin and target language must be different and non-empty")
+	}
+	sub, err := s.GetSubtitle(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := sub.LanguageMetadata[targetLang]; !ok {
+		return nil, errors.New("target language not found")
+	}
+
+	selected := make(map[string]bool, len(segmentIDs))
+	for _, segmentID := range segmentIDs {
+		selected[segmentID] = true
+	}
+	workspace := projectWorkspace(sub)
+	before := edithistory.Snapshot(sub.Segments)
+	var entries []*types.TMEntry
+	for i := range sub.Segments {
+		segment := &sub.Segments[i]
+		if len(selected) > 0 && !selected[segment.ID] {
+			continue
+		}
+		source := strings.TrimSpace(segment.Languages[origin].Text)
+		content, ok := segment.Languages[targetLang]
+		if !ok || source == "" || strings.TrimSpace(content.Text) == "" {
+			continue
+		}
+		content.Approved = true
+		segment.Languages[targetLang] = content
+		entries = append(entries, &types.TMEntry{
+			ID:         tmEntryID(workspace, origin, targetLang, source),
+			Workspace:  workspace,
+			SourceLang: origin,
+			TargetLang: targetLang,
+			Source:     source,
+			Target:     strings.TrimSpace(content.Text),
+			ProjectID:  sub.ID,
+			SegmentID:  segment.ID,
+		})
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no translated segments to approve")
+	}
+
+	if err := s.boltStorage.SaveTMEntries(entries); err != nil {
+		return nil, s.handleError("save translation memory", err)
+	}
+	if err := s.boltStorage.SaveSubtitle(sub); err != nil {
+		return nil, s.handleError("approve segments", err)
+	}
+	s.recordBulkEdit(sub.ID, types.EditApproveSegments, targetLang, before, sub.Segments)
+	return sub, nil
+}
+
+// ListTMEntries 列出翻译记忆，参数为空表示不限
+func (s *Service) ListTMEntries(workspace, sourceLang, targetLang string) ([]*types.TMEntry, error) {
+	if workspace != "" {
+		workspace = normalizeWorkspace(workspace)
+	}
+	return s.boltStorage.ListTMEntries(func(entry *types.TMEntry) bool {
+		return (workspace == "" || entry.Workspace == workspace) &&
+			(sourceLang == "" || strings.EqualFold(entry.SourceLang, sourceLang)) &&
+			(targetLang == "" || strings.EqualFold(entry.TargetLang, targetLang))
+	})
+}
+
+// DeleteTMEntry 删除翻译记忆条目
+func (s *Service) DeleteTMEntry(id string) error {
+	return s.boltStorage.DeleteTMEntry(id)
+}
+
+// LookupTM 在项目工作区的翻译记忆中查找 text 的最佳匹配，低于阈值时返回 nil
+func (s *Service) LookupTM(id, sourceLang, targetLang, text string) (*types.TMMatch, error) {
+	sub, err := s.GetSubtitle(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadTermResources(sub, sourceLang, targetLang, s.tmThreshold()).lookup(text), nil
+}
+
+// termResources 一次翻译/转换所用的术语与翻译记忆
+type termResources struct {
+	terms     []types.GlossaryTerm
+	memory    []*types.TMEntry
+	threshold float64
+}
+
+// loadTermResources 读取项目工作区中适用于该语言对的术语表与翻译记忆，读取失败时仅记录日志
+func (s *Service) loadTermResources(sub *types.SubtitleProject, sourceLang, targetLang string, threshold float64) *termResources {
+	resources := &termResources{threshold: threshold}
+	workspace := projectWorkspace(sub)
+
+	glossaries, err := s.boltStorage.ListGlossaries()
+	if err != nil {
+		logger.Warn("failed to load glossaries", zap.Error(err))
+	}
+	for _, glossary := range glossaries {
+		if glossary.Workspace == workspace &&
+			termbase.SameLanguage(glossary.SourceLang, sourceLang) && termbase.SameLanguage(glossary.TargetLang, targetLang) {
+			resources.terms = append(resources.terms, glossary.Terms...)
+		}
+	}
+
+	resources.memory, err = s.boltStorage.ListTMEntries(func(entry *types.TMEntry) bool {
+		return entry.Workspace == workspace &&
+			strings.EqualFold(entry.SourceLang, sourceLang) && strings.EqualFold(entry.TargetLang, targetLang)
+	})
+	if err != nil {
+		logger.Warn("failed to load translation memory", zap.Error(err))
+	}
+	return resources
+}
+
+// lookup 返回相似度最高且不低于阈值的翻译记忆
+func (r *termResources) lookup(text string) *types.TMMatch {
+	normalized := termbase.NormalizeText(text)
+	if normalized == "" {
+		return nil
+	}
+	length := len([]rune(normalized))
+	var best *types.TMMatch
+	for _, entry := range r.memory {
+		// 长度差已超过允许的编辑距离时跳过
+		other := len([]rune(termbase.NormalizeText(entry.Source)))
+		if diff := abs(length - other); float64(diff) > (1-r.threshold)*float64(max(length, other)) {
+			continue
+		}
+		score := termbase.Similarity(text, entry.Source)
+		if score >= r.threshold && (best == nil || score > best.Score) {
+			best = &types.TMMatch{Entry: entry, Score: score}
+		}
+		if score == 1 {
+			break
+		}
+	}
+	return best
+}
+
+// relevant 返回这批原文中出现的术语，附带在翻译请求中
+func (r *termResources) relevant(lines []string) []types.GlossaryTerm {
+	if len(r.terms) == 0 {
+		return nil
+	}
+	return termbase.Relevant(r.terms, strings.Join(lines, "\n"))
+}
+
+// apply 按术语表修正译文大小写，并记录仍未遵守术语表的位置
+func (r *termResources) apply(source string, content *types.LanguageContent) {
+	if len(r.terms) == 0 {
+		return
+	}
+	content.Text = termbase.Enforce(r.terms, source, content.Text)
+	content.TermIssues = termbase.Check(r.terms, source, content.Text)
+}
+
+func (s *Service) tmThreshold() float64 {
+	if s.pref == nil {
+		return types.DefaultPreferencesTranslation().TMThreshold
+	}
+	return s.pref.GetTranslationConfig().TMThreshold
+}
+
+// normalizeTerms 去掉空术语并按原文去重（后出现的覆盖先出现的）
+func normalizeTerms(terms []types.GlossaryTerm) []types.GlossaryTerm {
+	out := []types.GlossaryTerm{}
+	index := map[string]int{}
+	for _, term := range terms {
+		term.Source = strings.TrimSpace(term.Source)
+		term.Target = strings.TrimSpace(term.Target)
+		term.Note = strings.TrimSpace(term.Note)
+		if term.Source == "" || (term.Target == "" && !term.DoNotTranslate) {
+			continue
+		}
+		key := term.Source
+		if !term.CaseSensitive {
+			key = strings.ToLower(key)
+		}
+		if i, ok := index[key]; ok {
+			out[i] = term
+			continue
+		}
+		index[key] = len(out)
+		out = append(out, term)
+	}
+	return out
+}
+
+func normalizeWorkspace(workspace string) string {
+	if workspace = strings.TrimSpace(workspace); workspace == "" {
+		return types.DefaultWorkspace
+	}
+	return workspace
+}
+
+func projectWorkspace(sub *types.SubtitleProject) string {
+	return normalizeWorkspace(sub.Metadata.Workspace)
+}
+
+// tmEntryID 同一工作区、语言对与规范化原文只保留一条翻译记忆
+func tmEntryID(workspace, sourceLang, targetLang, source string) string {
+	sum := sha1.Sum([]byte(strings.Join([]string{
+		workspace, strings.ToLower(sourceLang), strings.ToLower(targetLang), termbase.NormalizeText(source),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
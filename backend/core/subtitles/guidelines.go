@@ -0,0 +1,151 @@
+package subtitles
+
+import (
+	"CanMe/backend/pkg/edithistory"
+	"CanMe/backend/pkg/guideline"
+	"CanMe/backend/types"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ListGuidelineProfiles 列出字幕规范，内置规范在前
+func (s *Service) ListGuidelineProfiles() ([]*types.GuidelineProfile, error) {
+	return s.guidelinePresets().all()
+}
+
+// GetGuidelineProfile 获取内置或自定义字幕规范
+func (s *Service) GetGuidelineProfile(id types.GuideLineStandard) (*types.GuidelineProfile, error) {
+	return s.guidelinePresets().lookup(id)
+}
+
+// SaveGuidelineProfile 校验并保存自定义字幕规范（ID 为空时创建），内置规范不可修改
+func (s *Service) SaveGuidelineProfile(profile *types.GuidelineProfile) (*types.GuidelineProfile, error) {
+	return s.guidelinePresets().saveCustom(profile, func(profile *types.GuidelineProfile) error {
+		// 语言键统一为语言代码，便于与项目中的语言名称匹配
+		languages := make(map[string]types.GuidelineRule, len(profile.Languages))
+		for lang, rule := range profile.Languages {
+			if code := guideline.LanguageCode(lang); code != "" {
+				languages[code] = rule
+			}
+		}
+		profile.Languages = languages
+		return guideline.Validate(profile)
+	})
+}
+
+// DeleteGuidelineProfile 删除自定义字幕规范；仍在使用它的项目按 Netflix 标准评估
+func (s *Service) DeleteGuidelineProfile(id types.GuideLineStandard) error {
+	return s.guidelinePresets().deleteCustom(id)
+}
+
+// ApplyGuidelineProfile 为项目的一种语言（为空时为全部语言）设置字幕规范与是否为儿童内容，并重新评估
+func (s *Service) ApplyGuidelineProfile(id, langCode string, standard types.GuideLineStandard, isKidsContent bool) (*types.SubtitleProject, error) {
+	project, err := s.loadProject("apply guideline profile", id)
+	if err != nil {
+		return nil, err
+	}
+	if s.guidelineProfile(standard) == nil {
+		return nil, s.handleError("apply guideline profile", fmt.Errorf("guideline profile not found: %s", standard))
+	}
+	if langCode != "" {
+		if _, ok := project.LanguageMetadata[langCode]; !ok {
+			return nil, s.handleError("apply guideline profile", fmt.Errorf("language %s not found", langCode))
+		}
+	}
+
+	before := edithistory.Snapshot(project.Segments)
+	assessor := s.qualityAssessor.Pass()
+	for i := range project.Segments {
+		segment := &project.Segments[i]
+		segment.GuidelineStandard = maps.Clone(segment.GuidelineStandard)
+		if segment.GuidelineStandard == nil {
+			segment.GuidelineStandard = make(map[string]types.GuideLineStandard)
+		}
+		for lang := range segment.Languages {
+			if langCode == "" || lang == langCode {
+				segment.GuidelineStandard[lang] = standard
+			}
+		}
+		segment.IsKidsContent = isKidsContent
+		assessor.AssessSegmentQuality(segment)
+	}
+	project.UpdatedAt = time.Now().Unix()
+	if err := s.boltStorage.SaveSubtitle(project); err != nil {
+		return nil, s.handleError("apply guideline profile", err)
+	}
+	s.recordBulkEdit(project.ID, types.EditApplyGuideline, langCode, before, project.Segments)
+	return project, nil
+}
+
+// CheckGuidelines 按各片段的字幕规范检查一种语言（为空时为全部语言），用于导出前提示
+func (s *Service) CheckGuidelines(id, langCode string) ([]types.GuidelineViolation, error) {
+	project, err := s.loadProject("check guidelines", id)
+	if err != nil {
+		return nil, err
+	}
+	languages := segmentLanguages(project)
+	if langCode != "" {
+		if _, ok := project.LanguageMetadata[langCode]; !ok {
+			return nil, s.handleError("check guidelines", fmt.Errorf("language %s not found", langCode))
+		}
+		languages = []string{langCode}
+	}
+
+	frameRate := projectFrameRate(project)
+	resolve := s.guidelineResolver()
+	violations := []types.GuidelineViolation{}
+	for _, lang := range languages {
+		for i := range project.Segments {
+			profile, limits := resolve(&project.Segments[i], lang)
+			for _, violation := range guideline.Check(limits, project.Segments, i, lang, frameRate) {
+				violation.Profile = profile.ID
+				violations = append(violations, violation)
+			}
+		}
+	}
+	return violations, nil
+}
+
+// guidelineResolver 返回解析片段所用字幕规范及限制的函数，同一次检查中缓存查找结果。
+// 未设置或已删除的规范按 Netflix 标准处理；lang 为空时取片段任一语言的规范（用于时长等片段级检查）
+func (s *Service) guidelineResolver() func(segment *types.SubtitleSegment, lang string) (*types.GuidelineProfile, types.GuidelineLimits) {
+	profiles := make(map[types.GuideLineStandard]*types.GuidelineProfile)
+	return func(segment *types.SubtitleSegment, lang string) (*types.GuidelineProfile, types.GuidelineLimits) {
+		standard := segment.GuidelineStandard[lang]
+		if lang == "" {
+			for _, code := range slices.Sorted(maps.Keys(segment.GuidelineStandard)) {
+				if standard = segment.GuidelineStandard[code]; standard != "" {
+					break
+				}
+			}
+		}
+		if standard == "" {
+			standard = types.GuideLineStandardNetflix
+		}
+		profile, ok := profiles[standard]
+		if !ok {
+			if profile = s.guidelineProfile(standard); profile == nil {
+				profile, _ = guideline.Builtin(types.GuideLineStandardNetflix)
+			}
+			profiles[standard] = profile
+		}
+		return profile, guideline.Resolve(profile, lang, segment.IsKidsContent)
+	}
+}
+
+// guidelineProfile 查找内置或自定义字幕规范，找不到时返回 nil
+func (s *Service) guidelineProfile(id types.GuideLineStandard) *types.GuidelineProfile {
+	if profile, ok := guideline.Builtin(id); ok {
+		return profile
+	}
+	if s.boltStorage == nil {
+		return nil
+	}
+	profile, err := s.boltStorage.GetGuidelineProfile(id)
+	if err != nil {
+		return nil
+	}
+	return profile
+}
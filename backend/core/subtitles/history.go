@@ -0,0 +1,97 @@
+package subtitles
+
+import (
+	"CanMe/backend/pkg/edithistory"
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/types"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 每个项目保留的可撤销操作条数
+const maxEditHistory = 200
+
+// Undo 撤销项目最近一次片段编辑
+func (s *Service) Undo(id string) (*types.SubtitleProject, error) {
+	return s.stepHistory("undo", id, edithistory.Undo)
+}
+
+// Redo 重做最近一次撤销的编辑
+func (s *Service) Redo(id string) (*types.SubtitleProject, error) {
+	return s.stepHistory("redo", id, edithistory.Redo)
+}
+
+// ListEditHistory 按时间倒序列出项目的编辑操作
+func (s *Service) ListEditHistory(id string) ([]types.EditHistoryEntry, error) {
+	if _, err := s.loadProject("list edit history", id); err != nil {
+		return nil, err
+	}
+	history, err := s.boltStorage.GetEditHistory(id)
+	if err != nil {
+		return nil, s.handleError("list edit history", err)
+	}
+	return edithistory.Entries(history), nil
+}
+
+type historyStep func(*types.EditHistory, []types.SubtitleSegment) ([]types.SubtitleSegment, *types.EditOperation, error)
+
+func (s *Service) stepHistory(operation, id string, step historyStep) (*types.SubtitleProject, error) {
+	project, err := s.loadProject(operation, id)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.boltStorage.GetEditHistory(id)
+	if err != nil {
+		return nil, s.handleError(operation, err)
+	}
+	segments, _, err := step(history, project.Segments)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", operation, err)
+	}
+
+	project.Segments = segments
+	bumpRevisions(project, time.Now().Unix())
+	if err := s.boltStorage.SaveSubtitle(project); err != nil {
+		return nil, s.handleError(operation, err)
+	}
+	// 项目已保存而日志保存失败时，下次撤销/重做会因片段不一致被拒绝，不会误改内容
+	if err := s.boltStorage.SaveEditHistory(history); err != nil {
+		return nil, s.handleError(operation, err)
+	}
+	return project, nil
+}
+
+// recordEdit 将已保存的编辑写入操作日志；日志写入失败不影响编辑本身
+func (s *Service) recordEdit(projectID string, opType types.EditOperationType, langCode string, changes ...types.SegmentChange) {
+	op := types.EditOperation{
+		ID:        uuid.New().String(),
+		Type:      opType,
+		LangCode:  langCode,
+		Changes:   changes,
+		CreatedAt: time.Now().Unix(),
+	}
+	for _, change := range changes {
+		for _, segment := range change.Before {
+			op.SegmentIDs = append(op.SegmentIDs, segment.ID)
+		}
+	}
+
+	history, err := s.boltStorage.GetEditHistory(projectID)
+	if err == nil {
+		edithistory.Record(history, op, maxEditHistory)
+		err = s.boltStorage.SaveEditHistory(history)
+	}
+	if err != nil {
+		logger.Warn("failed to record subtitle edit", zap.String("id", projectID), zap.String("type", string(opType)), zap.Error(err))
+	}
+}
+
+// recordBulkEdit 将批量操作前后的片段差异写入操作日志，before 需在修改前用 edithistory.Snapshot 保存
+func (s *Service) recordBulkEdit(projectID string, opType types.EditOperationType, langCode string, before, after []types.SubtitleSegment) {
+	if changes := edithistory.Diff(before, after); len(changes) > 0 {
+		s.recordEdit(projectID, opType, langCode, changes...)
+	}
+}
@@ -2,12 +2,15 @@ package subtitles
 
 import (
 	"CanMe/backend/types"
+	"context"
 )
 
 // 核心服务接口
 type QualityAssessor interface {
 	AssessSegmentQuality(segment *types.SubtitleSegment) *types.SubtitleSegment
-	AssessSubtitleQuality(text string, duration float64, standard types.GuideLineStandard, isKidsContent bool) *types.SubtitleGuideline
+	AssessSubtitleQuality(text string, duration float64, standard types.GuideLineStandard, langCode string, isKidsContent bool) *types.SubtitleGuideline
+	// Pass 返回用于一次批量评估的评估器，其间每个字幕规范只查找一次
+	Pass() QualityAssessor
 }
 
 type TextProcessor interface {
@@ -35,3 +38,9 @@ type FormatConverter interface {
     ToASS(project *types.SubtitleProject, langCode string) ([]byte, error)
     ToITT(project *types.SubtitleProject, langCode string) ([]byte, error)
 }
+
+// Translator 机器翻译提供商；返回的译文需与 Lines 一一对应
+type Translator interface {
+	Name() string
+	Translate(ctx context.Context, req TranslationRequest) ([]string, error)
+}
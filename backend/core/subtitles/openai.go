@@ -0,0 +1,181 @@
+package subtitles
+
+import (
+	"CanMe/backend/types"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const openAISystemPrompt = `You are a professional subtitle translator.
+Translate every entry of "lines" from the source language to the target language.
+Keep the meaning, tone and any line breaks, and keep each translation about as short as the original so it still fits on screen.
+"context_before" and "context_after" are neighbouring subtitles for reference only; never translate or return them.
+When "glossary" is given, translate each listed source term exactly as its target; keep terms marked do_not_translate unchanged.
+Reply with only a JSON array of strings: exactly one translation per entry of "lines", in the same order.`
+
+// openAITranslator 调用 OpenAI 兼容的 chat/completions 接口（OpenAI、Ollama、LM Studio 等）
+type openAITranslator struct {
+	client      *http.Client
+	baseURL     string
+	apiKey      string
+	model       string
+	temperature float64
+	timeout     time.Duration
+}
+
+func newOpenAITranslator(config types.PreferencesTranslation, client *http.Client) (Translator, error) {
+	c := config.OpenAI
+	if c.BaseURL == "" || c.Model == "" {
+		return nil, errors.New("openai: base url and model are required")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &openAITranslator{
+		client:      client,
+		baseURL:     strings.TrimRight(c.BaseURL, "/"),
+		apiKey:      c.APIKey,
+		model:       c.Model,
+		temperature: c.Temperature,
+		timeout:     time.Duration(c.TimeoutSeconds) * time.Second,
+	}, nil
+}
+
+func (t *openAITranslator) Name() string {
+	return "openai"
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type glossaryEntry struct {
+	Source         string `json:"source"`
+	Target         string `json:"target,omitempty"`
+	DoNotTranslate bool   `json:"do_not_translate,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (t *openAITranslator) Translate(ctx context.Context, req TranslationRequest) ([]string, error) {
+	input := map[string]any{
+		"source_language": req.SourceLang,
+		"target_language": req.TargetLang,
+		"context_before":  nonNil(req.Before),
+		"lines":           nonNil(req.Lines),
+		"context_after":   nonNil(req.After),
+	}
+	if len(req.Terms) > 0 {
+		glossary := make([]glossaryEntry, len(req.Terms))
+		for i, term := range req.Terms {
+			glossary[i] = glossaryEntry{Source: term.Source, Target: term.Target, DoNotTranslate: term.DoNotTranslate}
+		}
+		input["glossary"] = glossary
+	}
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(chatRequest{
+		Model: t.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: openAISystemPrompt},
+			{Role: "user", Content: string(payload)},
+		},
+		Temperature: t.temperature,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, &permanentError{err}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if t.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("openai: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, fmt.Errorf("openai: read response: %w", err)
+	}
+
+	var parsed chatResponse
+	jsonErr := json.Unmarshal(data, &parsed)
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(data))
+		if jsonErr == nil && parsed.Error != nil && parsed.Error.Message != "" {
+			msg = parsed.Error.Message
+		}
+		if len(msg) > 300 {
+			msg = msg[:300]
+		}
+		err := fmt.Errorf("openai: %s: %s", resp.Status, msg)
+		// 除限流外的 4xx（密钥、模型、参数错误）重试无意义
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusRequestTimeout {
+			return nil, &permanentError{err}
+		}
+		return nil, err
+	}
+	if jsonErr != nil {
+		return nil, fmt.Errorf("openai: invalid response: %w", jsonErr)
+	}
+	if len(parsed.Choices) == 0 {
+		return nil, errors.New("openai: empty response")
+	}
+	return parseTranslations(parsed.Choices[0].Message.Content)
+}
+
+// parseTranslations 从模型回复中提取 JSON 字符串数组（容忍 ``` 代码块与前后多余文字）
+func parseTranslations(content string) ([]string, error) {
+	start := strings.Index(content, "[")
+	end := strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, errors.New("openai: reply is not a JSON array")
+	}
+	var out []string
+	if err := json.Unmarshal([]byte(content[start:end+1]), &out); err != nil {
+		return nil, fmt.Errorf("openai: reply is not a JSON array of strings: %w", err)
+	}
+	return out, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
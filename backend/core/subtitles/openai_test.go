@@ -0,0 +1,184 @@
+package subtitles
+
+import (
+	"CanMe/backend/types"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTranslations(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		err     string
+	}{
+		{"plain", `["你好","世界"]`, []string{"你好", "世界"}, ""},
+		{"code fence", "```json\n[\"你好\", \"世界\"]\n```", []string{"你好", "世界"}, ""},
+		{"prose around", "Here are the translations:\n[\"a\", \"b [x]\"]\nHope this helps.", []string{"a", "b [x]"}, ""},
+		{"escaped", `["line one\nline two", "say \"hi\""]`, []string{"line one\nline two", `say "hi"`}, ""},
+		// 条数不符由调用方按请求校验
+		{"short", `["only one"]`, []string{"only one"}, ""},
+		{"empty", `[]`, []string{}, ""},
+		{"not array", `{"lines": "x"}`, nil, "not a JSON array"},
+		{"no reply", ``, nil, "not a JSON array"},
+		{"numbers", `[1, 2]`, nil, "not a JSON array of strings"},
+		{"broken", "```json\n[\"a\", \n```", nil, "not a JSON array"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := parseTranslations(tt.content)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+		})
+	}
+}
+
+// openAIServer 模拟 OpenAI 兼容接口，按 reply 返回状态码与回复内容
+func openAIServer(t *testing.T, reply func(input map[string]any) (int, string)) (*httptest.Server, *[]chatRequest) {
+	t.Helper()
+	var requests []chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+
+		body, _ := io.ReadAll(r.Body)
+		var req chatRequest
+		require.NoError(t, json.Unmarshal(body, &req))
+		requests = append(requests, req)
+		var input map[string]any
+		require.NoError(t, json.Unmarshal([]byte(req.Messages[1].Content), &input))
+
+		status, content := reply(input)
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			fmt.Fprint(w, content)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func newTestOpenAI(t *testing.T, baseURL string) Translator {
+	t.Helper()
+	tr, err := newOpenAITranslator(types.PreferencesTranslation{OpenAI: types.TranslationOpenAIConfig{
+		BaseURL: baseURL + "/v1/", APIKey: "sk-test", Model: "gpt-test", Temperature: 0.2,
+	}}, nil)
+	require.NoError(t, err)
+	return tr
+}
+
+func TestOpenAITranslatorRequest(t *testing.T) {
+	srv, requests := openAIServer(t, func(input map[string]any) (int, string) {
+		return http.StatusOK, "```json\n[\"你好\", \"再见\"]\n```"
+	})
+	tr := newTestOpenAI(t, srv.URL)
+
+	out, err := tr.Translate(context.Background(), TranslationRequest{
+		SourceLang: "en",
+		TargetLang: "zh",
+		Lines:      []string{"Hello", "Bye"},
+		Before:     []string{"Hi there"},
+		Terms:      []types.GlossaryTerm{{Source: "CanMe", DoNotTranslate: true}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"你好", "再见"}, out)
+
+	require.Len(t, *requests, 1)
+	req := (*requests)[0]
+	assert.Equal(t, "gpt-test", req.Model)
+	assert.Equal(t, 0.2, req.Temperature)
+	require.Len(t, req.Messages, 2)
+	assert.Equal(t, "system", req.Messages[0].Role)
+
+	var input map[string]any
+	require.NoError(t, json.Unmarshal([]byte(req.Messages[1].Content), &input))
+	assert.Equal(t, "en", input["source_language"])
+	assert.Equal(t, []any{"Hello", "Bye"}, input["lines"])
+	assert.Equal(t, []any{"Hi there"}, input["context_before"])
+	// 没有上下文时发送空数组而非 null
+	assert.Equal(t, []any{}, input["context_after"])
+	assert.Equal(t, []any{map[string]any{"source": "CanMe", "do_not_translate": true}}, input["glossary"])
+}
+
+func TestOpenAITranslatorErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		err       string
+		permanent bool
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided"}}`, "Incorrect API key provided", true},
+		{"model not found", http.StatusNotFound, `{"error":{"message":"model gpt-test not found"}}`, "not found", true},
+		{"rate limited", http.StatusTooManyRequests, `{"error":{"message":"Rate limit reached"}}`, "Rate limit reached", false},
+		{"request timeout", http.StatusRequestTimeout, `timeout`, "408", false},
+		{"server error", http.StatusBadGateway, `<html>bad gateway</html>`, "bad gateway", false},
+		{"no choices", http.StatusOK, "", "empty response", false},
+		{"prose reply", http.StatusOK, "Sorry, I cannot help with that.", "not a JSON array", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				switch {
+				case tt.status != http.StatusOK:
+					fmt.Fprint(w, tt.body)
+				case tt.body == "":
+					fmt.Fprint(w, `{"choices":[]}`)
+				default:
+					json.NewEncoder(w).Encode(map[string]any{
+						"choices": []any{map[string]any{"message": map[string]string{"content": tt.body}}},
+					})
+				}
+			}))
+			defer srv.Close()
+
+			_, err := newTestOpenAI(t, srv.URL).Translate(context.Background(), TranslationRequest{Lines: []string{"a"}})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Equal(t, tt.permanent, isPermanent(err))
+		})
+	}
+
+	_, err := newOpenAITranslator(types.PreferencesTranslation{OpenAI: types.TranslationOpenAIConfig{BaseURL: "http://localhost"}}, nil)
+	assert.ErrorContains(t, err, "base url and model are required")
+}
+
+// 模型少回复一条时整批重试，仍不符则逐条翻译
+func TestOpenAITranslatorShortReply(t *testing.T) {
+	noRetryDelay(t)
+	srv, requests := openAIServer(t, func(input map[string]any) (int, string) {
+		lines := input["lines"].([]any)
+		if len(lines) > 1 {
+			return http.StatusOK, `["合并的译文"]`
+		}
+		data, _ := json.Marshal([]string{"译:" + lines[0].(string)})
+		return http.StatusOK, string(data)
+	})
+
+	config := types.PreferencesTranslation{BatchSize: 10, ContextSize: 2, MaxRetries: 1}
+	results, failed, err := translateItems(context.Background(), newTestOpenAI(t, srv.URL), items("one", "two"), "en", "zh",
+		config, &termResources{}, nil)
+	require.NoError(t, err)
+	assert.Zero(t, failed)
+	assert.Equal(t, map[string]string{"s1": "译:one", "s2": "译:two"}, results)
+	assert.Len(t, *requests, 4)
+}
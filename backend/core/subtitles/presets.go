@@ -0,0 +1,127 @@
+package subtitles
+
+import (
+	"CanMe/backend/pkg/guideline"
+	"CanMe/backend/pkg/qc"
+	"CanMe/backend/types"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// presetFields 保存预设时统一处理的字段
+type presetFields[ID ~string] struct {
+	id        *ID
+	name      *string
+	builtIn   *bool
+	createdAt *int64
+}
+
+// presetStore 内置项只读、自定义项存储在 bbolt 的预设，字幕规范与质检规则集共用
+type presetStore[ID ~string, T any] struct {
+	kind     string // 错误信息中的名称
+	builtins func() []T
+	builtin  func(id ID) (*T, bool)
+	list     func() ([]*T, error)
+	get      func(id ID) (*T, error)
+	save     func(item *T) error
+	remove   func(id ID) error
+	fields   func(item *T) presetFields[ID]
+}
+
+func (s *Service) guidelinePresets() presetStore[types.GuideLineStandard, types.GuidelineProfile] {
+	return presetStore[types.GuideLineStandard, types.GuidelineProfile]{
+		kind:     "guideline profile",
+		builtins: guideline.Builtins,
+		builtin:  guideline.Builtin,
+		list:     s.boltStorage.ListGuidelineProfiles,
+		get:      s.boltStorage.GetGuidelineProfile,
+		save: func(profile *types.GuidelineProfile) error {
+			return s.handleError("save guideline profile", s.boltStorage.SaveGuidelineProfile(profile))
+		},
+		remove: s.boltStorage.DeleteGuidelineProfile,
+		fields: func(p *types.GuidelineProfile) presetFields[types.GuideLineStandard] {
+			return presetFields[types.GuideLineStandard]{id: &p.ID, name: &p.Name, builtIn: &p.BuiltIn, createdAt: &p.CreatedAt}
+		},
+	}
+}
+
+func (s *Service) qcPresets() presetStore[string, types.QCRuleSet] {
+	return presetStore[string, types.QCRuleSet]{
+		kind:     "qc rule set",
+		builtins: qc.Builtins,
+		builtin:  qc.Builtin,
+		list:     s.boltStorage.ListQCRuleSets,
+		get:      s.boltStorage.GetQCRuleSet,
+		save: func(set *types.QCRuleSet) error {
+			return s.handleError("save qc rule set", s.boltStorage.SaveQCRuleSet(set))
+		},
+		remove: s.boltStorage.DeleteQCRuleSet,
+		fields: func(set *types.QCRuleSet) presetFields[string] {
+			return presetFields[string]{id: &set.ID, name: &set.Name, builtIn: &set.BuiltIn, createdAt: &set.CreatedAt}
+		},
+	}
+}
+
+// all 列出全部预设，内置项在前
+func (p presetStore[ID, T]) all() ([]*T, error) {
+	items := []*T{}
+	for _, item := range p.builtins() {
+		items = append(items, &item)
+	}
+	custom, err := p.list()
+	if err != nil {
+		return nil, err
+	}
+	return append(items, custom...), nil
+}
+
+// lookup 获取内置或自定义预设
+func (p presetStore[ID, T]) lookup(id ID) (*T, error) {
+	if item, ok := p.builtin(id); ok {
+		return item, nil
+	}
+	return p.get(id)
+}
+
+// saveCustom 保存自定义预设（ID 为空时创建），内置项不可修改；
+// prepare 在 ID 与名称规范化后校验内容，更新已有预设时保留创建时间
+func (p presetStore[ID, T]) saveCustom(item *T, prepare func(item *T) error) (*T, error) {
+	if item == nil {
+		return nil, fmt.Errorf("%s is nil", p.kind)
+	}
+	f := p.fields(item)
+	*f.id = ID(strings.TrimSpace(string(*f.id)))
+	if _, ok := p.builtin(*f.id); ok {
+		return nil, fmt.Errorf("built-in %s cannot be modified: %s", p.kind, *f.id)
+	}
+	*f.name = strings.TrimSpace(*f.name)
+	*f.builtIn = false
+	if err := prepare(item); err != nil {
+		return nil, err
+	}
+
+	if *f.id == "" {
+		*f.id = ID(uuid.New().String())
+	}
+	if existing, err := p.get(*f.id); err == nil {
+		*f.createdAt = *p.fields(existing).createdAt
+	}
+	if *f.createdAt == 0 {
+		*f.createdAt = time.Now().Unix()
+	}
+	if err := p.save(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// deleteCustom 删除自定义预设，内置项不可删除
+func (p presetStore[ID, T]) deleteCustom(id ID) error {
+	if _, ok := p.builtin(id); ok {
+		return fmt.Errorf("built-in %s cannot be deleted: %s", p.kind, id)
+	}
+	return p.remove(id)
+}
@@ -0,0 +1,87 @@
+package subtitles
+
+import (
+	"CanMe/backend/pkg/guideline"
+	"CanMe/backend/pkg/qc"
+	"CanMe/backend/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQCRuleSetPresets(t *testing.T) {
+	s := newStorageService(t)
+	rules := []types.QCRule{{Type: types.QCRuleMaxLines, Severity: types.QCSeverityError}}
+
+	saved, err := s.SaveQCRuleSet(&types.QCRuleSet{Name: "  Broadcast ", BuiltIn: true, Rules: rules})
+	require.NoError(t, err)
+	assert.NotEmpty(t, saved.ID)
+	assert.Equal(t, "Broadcast", saved.Name)
+	assert.False(t, saved.BuiltIn)
+	require.NotZero(t, saved.CreatedAt)
+
+	// 更新时保留创建时间
+	created := saved.CreatedAt
+	updated, err := s.SaveQCRuleSet(&types.QCRuleSet{ID: " " + saved.ID + " ", Name: "Broadcast v2", Rules: rules, CreatedAt: 1})
+	require.NoError(t, err)
+	assert.Equal(t, saved.ID, updated.ID)
+	assert.Equal(t, created, updated.CreatedAt)
+
+	sets, err := s.ListQCRuleSets()
+	require.NoError(t, err)
+	require.Len(t, sets, 2)
+	assert.Equal(t, qc.DefaultRuleSetID, sets[0].ID)
+	assert.Equal(t, "Broadcast v2", sets[1].Name)
+
+	set, err := s.GetQCRuleSet("")
+	require.NoError(t, err)
+	assert.True(t, set.BuiltIn)
+	set, err = s.GetQCRuleSet(saved.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Broadcast v2", set.Name)
+
+	_, err = s.SaveQCRuleSet(&types.QCRuleSet{ID: qc.DefaultRuleSetID, Name: "x", Rules: rules})
+	assert.EqualError(t, err, "built-in qc rule set cannot be modified: default")
+	_, err = s.SaveQCRuleSet(&types.QCRuleSet{Name: "empty"})
+	assert.ErrorContains(t, err, "no rules")
+	_, err = s.SaveQCRuleSet(nil)
+	assert.EqualError(t, err, "qc rule set is nil")
+
+	assert.EqualError(t, s.DeleteQCRuleSet(qc.DefaultRuleSetID), "built-in qc rule set cannot be deleted: default")
+	require.NoError(t, s.DeleteQCRuleSet(saved.ID))
+	_, err = s.GetQCRuleSet(saved.ID)
+	assert.Error(t, err)
+}
+
+func TestGuidelineProfilePresets(t *testing.T) {
+	s := newStorageService(t)
+
+	saved, err := s.SaveGuidelineProfile(&types.GuidelineProfile{
+		Name:      " Studio ",
+		Languages: map[string]types.GuidelineRule{"Chinese (Simplified)": {Limits: types.GuidelineLimits{MaxCPS: 9}}},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, saved.ID)
+	assert.Equal(t, "Studio", saved.Name)
+	assert.Contains(t, saved.Languages, "zh")
+	require.NotZero(t, saved.CreatedAt)
+
+	created := saved.CreatedAt
+	updated, err := s.SaveGuidelineProfile(&types.GuidelineProfile{ID: saved.ID, Name: "Studio v2"})
+	require.NoError(t, err)
+	assert.Equal(t, created, updated.CreatedAt)
+
+	profiles, err := s.ListGuidelineProfiles()
+	require.NoError(t, err)
+	require.Len(t, profiles, len(guideline.Builtins())+1)
+	assert.Equal(t, types.GuideLineStandardNetflix, profiles[0].ID)
+	assert.Equal(t, saved.ID, profiles[len(profiles)-1].ID)
+
+	_, err = s.SaveGuidelineProfile(&types.GuidelineProfile{ID: types.GuideLineStandardNetflix, Name: "x"})
+	assert.EqualError(t, err, "built-in guideline profile cannot be modified: netflix")
+	assert.EqualError(t, s.DeleteGuidelineProfile(types.GuideLineStandardNetflix), "built-in guideline profile cannot be deleted: netflix")
+	require.NoError(t, s.DeleteGuidelineProfile(saved.ID))
+	_, err = s.GetGuidelineProfile(saved.ID)
+	assert.Error(t, err)
+}
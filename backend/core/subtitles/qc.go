@@ -0,0 +1,91 @@
+package subtitles
+
+import (
+	"CanMe/backend/pkg/qc"
+	"CanMe/backend/types"
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// ListQCRuleSets 列出质检规则集，内置规则集在前
+func (s *Service) ListQCRuleSets() ([]*types.QCRuleSet, error) {
+	return s.qcPresets().all()
+}
+
+// GetQCRuleSet 获取内置或自定义质检规则集，id 为空时返回默认规则集
+func (s *Service) GetQCRuleSet(id string) (*types.QCRuleSet, error) {
+	if id == "" {
+		id = qc.DefaultRuleSetID
+	}
+	return s.qcPresets().lookup(id)
+}
+
+// SaveQCRuleSet 校验并保存自定义质检规则集（ID 为空时创建），内置规则集不可修改
+func (s *Service) SaveQCRuleSet(set *types.QCRuleSet) (*types.QCRuleSet, error) {
+	return s.qcPresets().saveCustom(set, qc.Validate)
+}
+
+// DeleteQCRuleSet 删除自定义质检规则集
+func (s *Service) DeleteQCRuleSet(id string) error {
+	return s.qcPresets().deleteCustom(id)
+}
+
+// RunQC 按规则集质检项目，languages 为空时检查全部语言；
+// 未设置 Limit 的数值规则使用各片段所用字幕规范的限制
+func (s *Service) RunQC(id, ruleSetID string, languages []string) (*types.QCReport, error) {
+	project, err := s.loadProject("run qc", id)
+	if err != nil {
+		return nil, err
+	}
+	set, err := s.GetQCRuleSet(ruleSetID)
+	if err != nil {
+		return nil, s.handleError("run qc", err)
+	}
+	if len(languages) == 0 {
+		languages = segmentLanguages(project)
+	}
+	for _, lang := range languages {
+		if _, ok := project.LanguageMetadata[lang]; !ok {
+			return nil, s.handleError("run qc", fmt.Errorf("language %s not found", lang))
+		}
+	}
+
+	resolve := s.guidelineResolver()
+	limits := func(segment *types.SubtitleSegment, lang string) types.GuidelineLimits {
+		_, limits := resolve(segment, lang)
+		return limits
+	}
+	findings := qc.Check(project.Segments, languages, set.Rules, limits, projectFrameRate(project))
+	summary, passed := qc.Summarize(findings)
+
+	name := project.ProjectName
+	if name == "" {
+		name = project.Metadata.Name
+	}
+	return &types.QCReport{
+		ProjectID:   project.ID,
+		ProjectName: name,
+		RuleSetID:   set.ID,
+		RuleSetName: set.Name,
+		Languages:   languages,
+		Segments:    len(project.Segments),
+		Findings:    findings,
+		Summary:     summary,
+		Passed:      passed,
+		CheckedAt:   time.Now().Unix(),
+	}, nil
+}
+
+// ExportQCReport 质检项目并按格式（json、csv、html）输出报告
+func (s *Service) ExportQCReport(id, ruleSetID string, languages []string, format types.QCReportFormat) ([]byte, *types.QCReport, error) {
+	report, err := s.RunQC(id, ruleSetID, languages)
+	if err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	if err := qc.Write(&buf, report, format); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), report, nil
+}
@@ -1,21 +1,39 @@
 package subtitles
 
 import (
-    "CanMe/backend/pkg/textmetrics"
-    "CanMe/backend/types"
-    "math"
+	"CanMe/backend/pkg/guideline"
+	"CanMe/backend/types"
+	"time"
 )
 
 // QualityAssessor 字幕质量评估器
 type QualityAssessorImpl struct {
-	textCalculator *textmetrics.Calculator
+	// profile 按 ID 查找字幕规范，找不到时返回 nil
+	profile func(id types.GuideLineStandard) *types.GuidelineProfile
 }
 
-// NewQualityAssessor 创建新的质量评估器
-func NewQualityAssessor() QualityAssessor {
-	return &QualityAssessorImpl{
-		textCalculator: textmetrics.NewCalculator(),
+// NewQualityAssessor 创建新的质量评估器，profile 为空时只使用内置规范
+func NewQualityAssessor(profile func(id types.GuideLineStandard) *types.GuidelineProfile) QualityAssessor {
+	if profile == nil {
+		profile = func(id types.GuideLineStandard) *types.GuidelineProfile {
+			builtin, _ := guideline.Builtin(id)
+			return builtin
+		}
 	}
+	return &QualityAssessorImpl{profile: profile}
+}
+
+// Pass 返回缓存规范查找结果的评估器，用于逐片段评估整个项目；不可并发使用
+func (qa *QualityAssessorImpl) Pass() QualityAssessor {
+	profiles := make(map[types.GuideLineStandard]*types.GuidelineProfile)
+	return &QualityAssessorImpl{profile: func(id types.GuideLineStandard) *types.GuidelineProfile {
+		profile, ok := profiles[id]
+		if !ok {
+			profile = qa.profile(id)
+			profiles[id] = profile
+		}
+		return profile
+	}}
 }
 
 func (qa *QualityAssessorImpl) AssessSegmentQuality(segment *types.SubtitleSegment) *types.SubtitleSegment {
@@ -39,6 +57,7 @@ func (qa *QualityAssessorImpl) AssessSegmentQuality(segment *types.SubtitleSegme
 			seg.Text,
 			duration.Seconds(),
 			standard,
+			langCode,
 			segment.IsKidsContent,
 		)
 
@@ -49,63 +68,44 @@ func (qa *QualityAssessorImpl) AssessSegmentQuality(segment *types.SubtitleSegme
 	return segment
 }
 
-// AssessSubtitleQuality 评估字幕质量
+// AssessSubtitleQuality 按字幕规范评估字幕质量，规范不存在（如已删除的自定义规范）时使用 Netflix 标准
 func (qa *QualityAssessorImpl) AssessSubtitleQuality(
-    text string,
-    duration float64,
-    standard types.GuideLineStandard,
-    isKidsContent bool,
+	text string,
+	duration float64,
+	standard types.GuideLineStandard,
+	langCode string,
+	isKidsContent bool,
 ) *types.SubtitleGuideline {
-    // 获取推荐的阅读速度
-    maxCPS, maxWPM := qa.textCalculator.GetReadingSpeed(text, isKidsContent)
-
-    // 依据文本类型选择度量口径
-    isIdeographic := qa.textCalculator.IsPrimarilyIdeographic(text)
-
-    var charCount int
-    var maxLineLength int
-    if isIdeographic {
-        // 对表意文字：使用 UTF-8 字节数作为字符密度的近似
-        charCount = qa.textCalculator.CountCharactersBytes(text)
-        maxLineLength = qa.textCalculator.CountMaxLineLengthBytes(text)
-    } else {
-        // 非表意文字：使用码点数
-        charCount = qa.textCalculator.CountCharacters(text)
-        maxLineLength = qa.textCalculator.CountMaxLineLength(text)
-    }
-
-    // 计算 CPS：四舍五入
-    currentCPS := int(math.Round(float64(charCount) / duration))
-
-    // 计算 WPM
-    var currentWPM int
-    if isIdeographic {
-        // 表意文字：用每条字幕约等于 1 "word" 的等价指标，避免异常大值
-        currentWPM = int(math.Round(60.0 / duration))
-    } else {
-        wordCount := qa.textCalculator.CountWords(text)
-        currentWPM = int(math.Round(float64(wordCount) / duration * 60.0))
-    }
+	profile := qa.profile(standard)
+	if profile == nil {
+		profile, _ = guideline.Builtin(types.GuideLineStandardNetflix)
+	}
+	limits := guideline.Resolve(profile, langCode, isKidsContent)
+	m := guideline.Measure(text, time.Duration(duration*float64(time.Second)))
 
-    return &types.SubtitleGuideline{
-        CPS: &types.Guideline{
-            Current: currentCPS,
-            Level:   qa.evaluateLevel(currentCPS, maxCPS),
-        },
+	return &types.SubtitleGuideline{
+		CPS: &types.Guideline{
+			Current: m.CPS,
+			Level:   qa.evaluateLevel(m.CPS, limits.MaxCPS),
+		},
 		WPM: &types.Guideline{
-			Current: currentWPM,
-			Level:   qa.evaluateLevel(currentWPM, maxWPM),
+			Current: m.WPM,
+			Level:   qa.evaluateLevel(m.WPM, limits.MaxWPM),
 		},
 		CPL: &types.Guideline{
-			Current: maxLineLength,
-			Level:   qa.evaluateLevel(maxLineLength, 42), // Netflix标准
+			Current: m.CPL,
+			Level:   qa.evaluateLevel(m.CPL, limits.MaxCPL),
+		},
+		Lines: &types.Guideline{
+			Current: m.Lines,
+			Level:   qa.evaluateLevel(m.Lines, limits.MaxLines),
 		},
 	}
 }
 
-// evaluateLevel 评估级别（0=正常，1=警告，2=超标）
+// evaluateLevel 评估级别（0=正常，1=警告，2=超标），阈值为 0 表示不限制
 func (qa *QualityAssessorImpl) evaluateLevel(current, threshold int) int {
-	if current <= threshold {
+	if threshold <= 0 || current <= threshold {
 		return 0 // 正常
 	} else if current <= int(float64(threshold)*1.2) {
 		return 1 // 轻微超标
@@ -0,0 +1,65 @@
+package subtitles
+
+import (
+	"CanMe/backend/pkg/guideline"
+	"CanMe/backend/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func guidelineSegment(id string, texts map[string]string, standard types.GuideLineStandard) types.SubtitleSegment {
+	segment := types.SubtitleSegment{
+		ID:                id,
+		StartTime:         types.Timecode{Time: 0},
+		EndTime:           types.Timecode{Time: time.Second},
+		Languages:         map[string]types.LanguageContent{},
+		GuidelineStandard: map[string]types.GuideLineStandard{},
+	}
+	for lang, text := range texts {
+		segment.Languages[lang] = types.LanguageContent{Text: text}
+		segment.GuidelineStandard[lang] = standard
+	}
+	return segment
+}
+
+func TestQualityAssessorPassCachesProfiles(t *testing.T) {
+	lookups := map[types.GuideLineStandard]int{}
+	custom := &types.GuidelineProfile{ID: "custom", Default: types.GuidelineRule{Limits: types.GuidelineLimits{MaxCPS: 5}}}
+	qa := NewQualityAssessor(func(id types.GuideLineStandard) *types.GuidelineProfile {
+		lookups[id]++
+		if id == custom.ID {
+			return custom
+		}
+		builtin, _ := guideline.Builtin(id)
+		return builtin
+	})
+
+	segments := []types.SubtitleSegment{
+		guidelineSegment("s1", map[string]string{"en": "Hello there, friend", "fr": "Bonjour"}, custom.ID),
+		guidelineSegment("s2", map[string]string{"en": "Bye", "fr": "Au revoir"}, custom.ID),
+		guidelineSegment("s3", map[string]string{"en": "Again"}, "deleted"),
+	}
+	pass := qa.Pass()
+	for i := range segments {
+		pass.AssessSegmentQuality(&segments[i])
+	}
+	// 每个规范在一次评估中只查找一次；找不到的规范也不重复查找
+	assert.Equal(t, map[types.GuideLineStandard]int{"custom": 1, "deleted": 1}, lookups)
+
+	// 结果与逐次查找一致
+	cps := segments[0].Languages["en"].SubtitleGuideline.CPS
+	require.NotNil(t, cps)
+	assert.Equal(t, 2, cps.Level)
+	expected := *segments[0].Languages["en"].SubtitleGuideline
+	single := guidelineSegment("s1", map[string]string{"en": "Hello there, friend"}, custom.ID)
+	qa.AssessSegmentQuality(&single)
+	assert.Equal(t, expected, *single.Languages["en"].SubtitleGuideline)
+	assert.NotNil(t, segments[2].Languages["en"].SubtitleGuideline)
+
+	// 新的评估重新查找，自定义规范的修改随之生效
+	qa.Pass().AssessSegmentQuality(&segments[1])
+	assert.Equal(t, 3, lookups["custom"])
+}
@@ -0,0 +1,335 @@
+package subtitles
+
+import (
+	"CanMe/backend/pkg/segmenttext"
+	"CanMe/backend/types"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 自动选取插入位置时新片段的最长时长
+const defaultInsertDuration = 2 * time.Second
+
+// SplitSegment 将一个片段拆为两段：时间按拆分点切开，各语言文本按指定位置或时间比例切分，两段均生成新 ID
+func (s *Service) SplitSegment(id, segmentID string, req types.SegmentSplitRequest) (*types.SubtitleProject, error) {
+	project, err := s.loadProject("split segment", id)
+	if err != nil {
+		return nil, err
+	}
+	index := segmentIndex(project, segmentID)
+	if index < 0 {
+		return nil, fmt.Errorf("segment with ID %s not found", segmentID)
+	}
+	segment := project.Segments[index]
+	start, end := segment.StartTime.Time, segment.EndTime.Time
+	if end <= start {
+		return nil, errors.New("segment has no duration to split")
+	}
+
+	// 拆分时间点：显式指定 > 首个指定了切分位置的语言的字符比例 > 中点
+	ratio := 0.5
+	if req.AtMs > 0 {
+		at := time.Duration(req.AtMs) * time.Millisecond
+		if at <= start || at >= end {
+			return nil, errors.New("split point must be inside the segment")
+		}
+		ratio = float64(at-start) / float64(end-start)
+	} else {
+		for _, lang := range slices.Sorted(maps.Keys(req.Positions)) {
+			if length := len([]rune(segment.Languages[lang].Text)); length > 0 {
+				ratio = float64(min(max(req.Positions[lang], 0), length)) / float64(length)
+				break
+			}
+		}
+	}
+	at := start + time.Duration(ratio*float64(end-start))
+	if at <= start || at >= end {
+		return nil, errors.New("split point must be inside the segment")
+	}
+
+	first, second := cloneSegment(&segment), cloneSegment(&segment)
+	second.Notes = ""
+	frameRate := projectFrameRate(project)
+	first.EndTime = types.NewTimecode(at, frameRate)
+	second.StartTime = types.NewTimecode(at, frameRate)
+	for lang, content := range segment.Languages {
+		var a, b string
+		if pos, ok := req.Positions[lang]; ok {
+			a, b = segmenttext.SplitAt(content.Text, pos)
+		} else {
+			a, b = segmenttext.SplitProportional(content.Text, ratio)
+		}
+		first.Languages[lang] = editedContent(content, a)
+		second.Languages[lang] = editedContent(content, b)
+	}
+
+	change := types.SegmentChange{
+		Index:  index,
+		Before: []types.SubtitleSegment{segment},
+		After:  []types.SubtitleSegment{*s.qualityAssessor.AssessSegmentQuality(first), *s.qualityAssessor.AssessSegmentQuality(second)},
+	}
+	project.Segments = slices.Replace(project.Segments, index, index+1, change.After...)
+	return s.saveStructureChange("split segment", project, types.EditSplitSegment, change)
+}
+
+// MergeSegments 合并相邻的多个片段：时间取首尾，各语言文本按换行拼接，生成新 ID
+func (s *Service) MergeSegments(id string, segmentIDs []string) (*types.SubtitleProject, error) {
+	if len(segmentIDs) < 2 {
+		return nil, errors.New("at least two segments are required to merge")
+	}
+	project, err := s.loadProject("merge segments", id)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, 0, len(segmentIDs))
+	for _, segmentID := range segmentIDs {
+		index := segmentIndex(project, segmentID)
+		if index < 0 {
+			return nil, fmt.Errorf("segment with ID %s not found", segmentID)
+		}
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	indexes = slices.Compact(indexes)
+	for i := 1; i < len(indexes); i++ {
+		if indexes[i] != indexes[i-1]+1 {
+			return nil, errors.New("only adjacent segments can be merged")
+		}
+	}
+	if len(indexes) < 2 {
+		return nil, errors.New("at least two segments are required to merge")
+	}
+
+	from, to := indexes[0], indexes[len(indexes)-1]
+	group := slices.Clone(project.Segments[from : to+1])
+	merged := cloneSegment(&group[0])
+	merged.EndTime = group[0].EndTime
+	var notes []string
+	for _, segment := range group {
+		if segment.EndTime.Time > merged.EndTime.Time {
+			merged.EndTime = segment.EndTime
+		}
+		if merged.Speaker == "" {
+			merged.Speaker = segment.Speaker
+		}
+		merged.IsKidsContent = merged.IsKidsContent || segment.IsKidsContent
+		notes = append(notes, segment.Notes)
+		for lang, standard := range segment.GuidelineStandard {
+			if _, ok := merged.GuidelineStandard[lang]; !ok {
+				merged.GuidelineStandard[lang] = standard
+			}
+		}
+	}
+	merged.Notes = segmenttext.Join(notes...)
+	for _, lang := range segmentLanguages(project, group...) {
+		texts := make([]string, len(group))
+		var base types.LanguageContent
+		for i, segment := range group {
+			content, ok := segment.Languages[lang]
+			if ok && base.Style == nil && base.StyleID == "" && base.RegionID == "" {
+				base = content
+			}
+			texts[i] = content.Text
+		}
+		merged.Languages[lang] = editedContent(base, segmenttext.Join(texts...))
+	}
+
+	change := types.SegmentChange{
+		Index:  from,
+		Before: group,
+		After:  []types.SubtitleSegment{*s.qualityAssessor.AssessSegmentQuality(merged)},
+	}
+	project.Segments = slices.Replace(project.Segments, from, to+1, change.After...)
+	return s.saveStructureChange("merge segments", project, types.EditMergeSegments, change)
+}
+
+// InsertSegment 插入新片段，项目中的每种语言都会写入（未提供文本的为空）
+func (s *Service) InsertSegment(id string, req types.SegmentInsertRequest) (*types.SubtitleProject, error) {
+	project, err := s.loadProject("insert segment", id)
+	if err != nil {
+		return nil, err
+	}
+	index := 0
+	if req.AfterSegmentID != "" {
+		after := segmentIndex(project, req.AfterSegmentID)
+		if after < 0 {
+			return nil, fmt.Errorf("segment with ID %s not found", req.AfterSegmentID)
+		}
+		index = after + 1
+	}
+	var prev, next *types.SubtitleSegment
+	if index > 0 {
+		prev = &project.Segments[index-1]
+	}
+	if index < len(project.Segments) {
+		next = &project.Segments[index]
+	}
+
+	var start, end time.Duration
+	if req.StartMs == 0 && req.EndMs == 0 {
+		if prev != nil {
+			start = prev.EndTime.Time
+		}
+		end = start + defaultInsertDuration
+		if next != nil && next.StartTime.Time < end {
+			end = next.StartTime.Time
+		}
+		if end <= start {
+			return nil, errors.New("no gap to insert a segment, specify start_ms and end_ms")
+		}
+	} else {
+		start, end = time.Duration(req.StartMs)*time.Millisecond, time.Duration(req.EndMs)*time.Millisecond
+		if start < 0 || end <= start {
+			return nil, errors.New("end time must be after start time")
+		}
+	}
+
+	frameRate := projectFrameRate(project)
+	segment := &types.SubtitleSegment{
+		ID:                uuid.New().String(),
+		StartTime:         types.NewTimecode(start, frameRate),
+		EndTime:           types.NewTimecode(end, frameRate),
+		Speaker:           req.Speaker,
+		Languages:         make(map[string]types.LanguageContent),
+		GuidelineStandard: make(map[string]types.GuideLineStandard),
+	}
+	neighbour := prev
+	if neighbour == nil {
+		neighbour = next
+	}
+	langs := segmentLanguages(project)
+	for lang := range req.Texts {
+		if !slices.Contains(langs, lang) {
+			return nil, fmt.Errorf("language %s not found in project", lang)
+		}
+	}
+	for _, lang := range langs {
+		var base types.LanguageContent
+		standard := types.GuideLineStandardNetflix
+		if neighbour != nil {
+			base = neighbour.Languages[lang]
+			if neighbourStandard := neighbour.GuidelineStandard[lang]; neighbourStandard != "" {
+				standard = neighbourStandard
+			}
+			segment.IsKidsContent = neighbour.IsKidsContent
+		}
+		segment.Languages[lang] = editedContent(base, req.Texts[lang])
+		segment.GuidelineStandard[lang] = standard
+	}
+
+	change := types.SegmentChange{
+		Index: index,
+		After: []types.SubtitleSegment{*s.qualityAssessor.AssessSegmentQuality(segment)},
+	}
+	project.Segments = slices.Insert(project.Segments, index, change.After...)
+	return s.saveStructureChange("insert segment", project, types.EditInsertSegment, change)
+}
+
+// DeleteSegments 删除片段
+func (s *Service) DeleteSegments(id string, segmentIDs []string) (*types.SubtitleProject, error) {
+	if len(segmentIDs) == 0 {
+		return nil, errors.New("no segments to delete")
+	}
+	project, err := s.loadProject("delete segments", id)
+	if err != nil {
+		return nil, err
+	}
+	remove := make(map[string]bool, len(segmentIDs))
+	for _, segmentID := range segmentIDs {
+		if segmentIndex(project, segmentID) < 0 {
+			return nil, fmt.Errorf("segment with ID %s not found", segmentID)
+		}
+		remove[segmentID] = true
+	}
+	// 从后往前删除，每处删除记为一次变更，撤销时按相反顺序插回原位
+	var changes []types.SegmentChange
+	for i := len(project.Segments) - 1; i >= 0; i-- {
+		if remove[project.Segments[i].ID] {
+			changes = append(changes, types.SegmentChange{Index: i, Before: []types.SubtitleSegment{project.Segments[i]}})
+			project.Segments = slices.Delete(project.Segments, i, i+1)
+		}
+	}
+	return s.saveStructureChange("delete segments", project, types.EditDeleteSegments, changes...)
+}
+
+func (s *Service) loadProject(operation, id string) (*types.SubtitleProject, error) {
+	if id == "" {
+		return nil, s.handleError(operation, fmt.Errorf("id is empty"))
+	}
+	if s.boltStorage == nil {
+		return nil, s.handleError(operation, fmt.Errorf("bolt storage is nil"))
+	}
+	project, err := s.boltStorage.GetSubtitle(id)
+	if err != nil {
+		return nil, s.handleError(operation, err)
+	}
+	return project, nil
+}
+
+// saveStructureChange 为所有语言记录一次修订并保存，再写入操作日志
+func (s *Service) saveStructureChange(operation string, project *types.SubtitleProject, opType types.EditOperationType, changes ...types.SegmentChange) (*types.SubtitleProject, error) {
+	bumpRevisions(project, time.Now().Unix())
+	if err := s.boltStorage.SaveSubtitle(project); err != nil {
+		return nil, s.handleError(operation, err)
+	}
+	s.recordEdit(project.ID, opType, "", changes...)
+	return project, nil
+}
+
+// bumpRevisions 结构或时间轴变化影响所有语言，每种语言的修订号加一
+func bumpRevisions(project *types.SubtitleProject, now int64) {
+	for code, metadata := range project.LanguageMetadata {
+		metadata.Revision++
+		metadata.Status.LastUpdated = now
+		project.LanguageMetadata[code] = metadata
+	}
+	project.UpdatedAt = now
+}
+
+func segmentIndex(project *types.SubtitleProject, segmentID string) int {
+	return slices.IndexFunc(project.Segments, func(segment types.SubtitleSegment) bool {
+		return segment.ID == segmentID
+	})
+}
+
+// segmentLanguages 返回项目语言与给定片段中出现的语言（排序后）
+func segmentLanguages(project *types.SubtitleProject, segments ...types.SubtitleSegment) []string {
+	set := make(map[string]bool, len(project.LanguageMetadata))
+	for lang := range project.LanguageMetadata {
+		set[lang] = true
+	}
+	for _, segment := range segments {
+		for lang := range segment.Languages {
+			set[lang] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(set))
+}
+
+// cloneSegment 复制片段（map 独立）并分配新 ID
+func cloneSegment(segment *types.SubtitleSegment) *types.SubtitleSegment {
+	clone := *segment
+	clone.ID = uuid.New().String()
+	clone.Languages = make(map[string]types.LanguageContent, len(segment.Languages))
+	clone.GuidelineStandard = maps.Clone(segment.GuidelineStandard)
+	if clone.GuidelineStandard == nil {
+		clone.GuidelineStandard = make(map[string]types.GuideLineStandard)
+	}
+	return &clone
+}
+
+// editedContent 保留样式与区域，替换文本；审核状态、翻译记忆匹配与术语检查结果随文本失效
+func editedContent(base types.LanguageContent, text string) types.LanguageContent {
+	return types.LanguageContent{
+		Text:     text,
+		Style:    base.Style,
+		StyleID:  base.StyleID,
+		RegionID: base.RegionID,
+	}
+}
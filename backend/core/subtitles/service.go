@@ -4,14 +4,17 @@ import (
 	"CanMe/backend/pkg/events"
 	"CanMe/backend/pkg/proxy"
 	"CanMe/backend/pkg/zhconvert"
+	"CanMe/backend/services/preferences"
 	"CanMe/backend/storage"
 	"CanMe/backend/types"
 
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -30,18 +33,24 @@ type Service struct {
 	zhConverter *zhconvert.Converter
 	// 事件总线
 	eventBus events.EventBus
+	// 偏好设置（机器翻译）
+	pref *preferences.Service
+	// 进行中的机器翻译：projectID/lang -> context.CancelFunc
+	translations sync.Map
 }
 
-func NewService(boltStorage *storage.BoltStorage, proxyManager proxy.ProxyManager, eventBus events.EventBus) *Service {
-	return &Service{
+func NewService(boltStorage *storage.BoltStorage, proxyManager proxy.ProxyManager, eventBus events.EventBus, pref *preferences.Service) *Service {
+	s := &Service{
 		formatConverter: NewFormatConverter(),
 		textProcessor:   NewTextProcessor(),
-		qualityAssessor: NewQualityAssessor(),
 		boltStorage:     boltStorage,
 		proxyManager:    proxyManager,
 		zhConverter:     zhconvert.New(zhconvert.DefaultConfig(), proxyManager),
 		eventBus:        eventBus,
+		pref:            pref,
 	}
+	s.qualityAssessor = NewQualityAssessor(s.guidelineProfile)
+	return s
 }
 
 func (s *Service) SetContext(ctx context.Context) {
@@ -89,6 +98,12 @@ func (s *Service) UpdateExportConfig(id string, config types.ExportConfigs) (*ty
 		config.FCPXML.AutoFill()
 	}
 
+	if config.Bilingual != nil {
+		if err := config.Bilingual.Validate(); err != nil {
+			return nil, s.handleError("update export config", fmt.Errorf("invalid bilingual config: %w", err))
+		}
+	}
+
 	// 4. update export config
 	project.Metadata.ExportConfigs = config
 
@@ -102,7 +117,8 @@ func (s *Service) UpdateExportConfig(id string, config types.ExportConfigs) (*ty
 	return project, nil
 }
 
-func (s *Service) ConvertSubtile(id, langCode, targetFormat string) ([]byte, error) {
+// ConvertSubtile 将项目的一种语言转换为目标格式；secondLang 非空时导出 langCode 在上方的双语字幕（仅 SRT/VTT/ASS）
+func (s *Service) ConvertSubtile(id, langCode, targetFormat, secondLang string) ([]byte, error) {
 	// 1. get file info
 	if id == "" {
 		return nil, s.handleError("convert subtitle", fmt.Errorf("id is empty"))
@@ -116,6 +132,13 @@ func (s *Service) ConvertSubtile(id, langCode, targetFormat string) ([]byte, err
 	if err != nil {
 		return nil, s.handleError("convert subtitle", err)
 	}
+	if secondLang != "" {
+		data, err := convertBilingual(s.formatConverter, project, langCode, secondLang, targetFormat)
+		if err != nil {
+			return nil, s.handleError("convert subtitle", err)
+		}
+		return data, nil
+	}
 
 	// 3. convert to target format
 	switch strings.ToLower(targetFormat) {
@@ -284,10 +307,16 @@ func (s *Service) UpdateSubtitleProject(project *types.SubtitleProject) (*types.
 
 	project.UpdatedAt = time.Now().Unix()
 
+	// 整体替换项目时记录片段差异，避免之前的编辑无法撤销
+	old, oldErr := s.boltStorage.GetSubtitle(project.ID)
+
 	err := s.boltStorage.SaveSubtitle(project)
 	if err != nil {
 		return nil, s.handleError("update subtitle project", err)
 	}
+	if oldErr == nil {
+		s.recordBulkEdit(project.ID, types.EditUpdateProject, "", old.Segments, project.Segments)
+	}
 
 	return project, nil
 }
@@ -374,6 +403,8 @@ func (s *Service) UpdateProjectMetadata(id string, metadata types.ProjectMetadat
         }
     }
 
+    // 时间轴历史由服务端维护
+    metadata.TimingHistory = project.Metadata.TimingHistory
     project.Metadata = metadata
 	err = s.boltStorage.SaveSubtitle(project)
 	if err != nil {
@@ -410,19 +441,20 @@ func (s *Service) UpdateSubtitleSegment(id string, segmentID string, segment *ty
 	updatedSegment := s.qualityAssessor.AssessSegmentQuality(segment)
 
 	// 查找并更新片段
-	found := false
+	index := -1
 	for i, seg := range project.Segments {
 		if seg.ID == segmentID {
-			project.Segments[i] = *updatedSegment
-			found = true
+			index = i
 			break
 		}
 	}
 
-	if !found {
+	if index < 0 {
 		return nil, s.handleError("update subtitle segment", fmt.Errorf("segment with ID %s not found", segmentID))
 	}
 
+	before := project.Segments[index]
+	project.Segments[index] = *updatedSegment
 	project.UpdatedAt = time.Now().Unix()
 
 	err = s.boltStorage.SaveSubtitle(project)
@@ -430,6 +462,11 @@ func (s *Service) UpdateSubtitleSegment(id string, segmentID string, segment *ty
 		return nil, s.handleError("update subtitle segment", err)
 	}
 
+	s.recordEdit(id, types.EditUpdateSegment, "", types.SegmentChange{
+		Index:  index,
+		Before: []types.SubtitleSegment{before},
+		After:  []types.SubtitleSegment{project.Segments[index]},
+	})
 	return project, nil
 }
 
@@ -454,19 +491,23 @@ func (s *Service) UpdateLanguageContent(id string, segmentID string, langCode st
 	}
 
 	// 查找并更新语言内容
-	found := false
+	index := -1
+	var before types.SubtitleSegment
 	for i, seg := range project.Segments {
 		if seg.ID == segmentID {
+			// 保留编辑前的副本（Languages 会被原地修改）
+			before = seg
+			before.Languages = maps.Clone(seg.Languages)
 			if project.Segments[i].Languages == nil {
 				project.Segments[i].Languages = make(map[string]types.LanguageContent)
 			}
 			project.Segments[i].Languages[langCode] = content
-			found = true
+			index = i
 			break
 		}
 	}
 
-	if !found {
+	if index < 0 {
 		return nil, s.handleError("update language content", fmt.Errorf("segment with ID %s not found", segmentID))
 	}
 
@@ -477,6 +518,11 @@ func (s *Service) UpdateLanguageContent(id string, segmentID string, langCode st
 		return nil, s.handleError("update language content", err)
 	}
 
+	s.recordEdit(id, types.EditUpdateLanguageContent, langCode, types.SegmentChange{
+		Index:  index,
+		Before: []types.SubtitleSegment{before},
+		After:  []types.SubtitleSegment{project.Segments[index]},
+	})
 	return project, nil
 }
 
@@ -508,6 +554,15 @@ func (s *Service) processSubtitleText(project *types.SubtitleProject, options ty
 		return s.handleError("process subtitle text", fmt.Errorf("project is nil"))
 	}
 
+	// 未指定字幕规范时使用 Netflix 标准
+	if options.GuidelineStandard == "" {
+		options.GuidelineStandard = types.GuideLineStandardNetflix
+	}
+	if s.guidelineProfile(options.GuidelineStandard) == nil {
+		return s.handleError("process subtitle text", fmt.Errorf("guideline profile not found: %s", options.GuidelineStandard))
+	}
+
+	assessor := s.qualityAssessor.Pass()
 	for i := range project.Segments {
 		segment := &project.Segments[i]
 
@@ -545,7 +600,7 @@ func (s *Service) processSubtitleText(project *types.SubtitleProject, options ty
 		// 重新计算该片段的guideline指标
 		if options.ValidateGuidelines {
 			segment.IsKidsContent = options.IsKidsContent
-			s.qualityAssessor.AssessSegmentQuality(segment)
+			assessor.AssessSegmentQuality(segment)
 		}
 	}
 
@@ -0,0 +1,251 @@
+package subtitles
+
+import (
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/pkg/subdiff"
+	"CanMe/backend/types"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 每个项目保留的自动快照数量，手动快照不受限制
+const maxAutoSnapshots = 20
+
+// CreateSnapshot 为项目当前状态创建命名快照
+func (s *Service) CreateSnapshot(id, name string) (*types.SnapshotInfo, error) {
+	project, err := s.loadProject("create snapshot", id)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = time.Now().Format("2006-01-02 15:04:05")
+	}
+	snapshot, err := s.saveSnapshot(project, name, types.SnapshotManual)
+	if err != nil {
+		return nil, s.handleError("create snapshot", err)
+	}
+	info := snapshotInfo(snapshot)
+	return &info, nil
+}
+
+// ListSnapshots 列出项目快照（最新的在前）
+func (s *Service) ListSnapshots(id string) ([]types.SnapshotInfo, error) {
+	if _, err := s.loadProject("list snapshots", id); err != nil {
+		return nil, err
+	}
+	snapshots, err := s.boltStorage.ListSnapshots(id)
+	if err != nil {
+		return nil, s.handleError("list snapshots", err)
+	}
+	infos := make([]types.SnapshotInfo, 0, len(snapshots))
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		infos = append(infos, snapshotInfo(snapshots[i]))
+	}
+	return infos, nil
+}
+
+// DeleteSnapshot 删除快照
+func (s *Service) DeleteSnapshot(id, snapshotID string) error {
+	if id == "" || snapshotID == "" {
+		return s.handleError("delete snapshot", fmt.Errorf("id or snapshotID is empty"))
+	}
+	if err := s.boltStorage.DeleteSnapshot(id, snapshotID); err != nil {
+		return s.handleError("delete snapshot", err)
+	}
+	return nil
+}
+
+// DiffSnapshots 对比项目的两个快照，快照 ID 为空表示项目当前状态；languages 为空时对比所有语言
+func (s *Service) DiffSnapshots(id, fromSnapshotID, toSnapshotID string, languages []string) (*types.ProjectDiff, error) {
+	project, err := s.loadProject("diff snapshots", id)
+	if err != nil {
+		return nil, err
+	}
+	version := func(snapshotID string) ([]types.SubtitleSegment, error) {
+		if snapshotID == "" {
+			return project.Segments, nil
+		}
+		snapshot, err := s.boltStorage.GetSnapshot(id, snapshotID)
+		if err != nil {
+			return nil, err
+		}
+		return snapshot.Project.Segments, nil
+	}
+	from, err := version(fromSnapshotID)
+	if err != nil {
+		return nil, s.handleError("diff snapshots", err)
+	}
+	to, err := version(toSnapshotID)
+	if err != nil {
+		return nil, s.handleError("diff snapshots", err)
+	}
+	diff := subdiff.Diff(from, to, languages)
+	diff.From, diff.To = fromSnapshotID, toSnapshotID
+	return &diff, nil
+}
+
+// DiffProjects 对比两个项目的当前状态（片段按时间轴配对）
+func (s *Service) DiffProjects(fromID, toID string, languages []string) (*types.ProjectDiff, error) {
+	from, err := s.loadProject("diff projects", fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.loadProject("diff projects", toID)
+	if err != nil {
+		return nil, err
+	}
+	diff := subdiff.Diff(from.Segments, to.Segments, languages)
+	diff.From, diff.To = fromID, toID
+	return &diff, nil
+}
+
+// RestoreSnapshot 从快照恢复整个项目（langCode 为空）或单个语言；恢复前自动为当前状态创建快照，
+// 修订号在当前值基础上递增而不会回退
+func (s *Service) RestoreSnapshot(id, snapshotID, langCode string) (*types.SubtitleProject, error) {
+	project, err := s.loadProject("restore snapshot", id)
+	if err != nil {
+		return nil, err
+	}
+	if s.translationRunning(id) {
+		return nil, errors.New("cannot restore while a translation is running")
+	}
+	snapshot, err := s.boltStorage.GetSnapshot(id, snapshotID)
+	if err != nil {
+		return nil, s.handleError("restore snapshot", err)
+	}
+
+	restored := snapshot.Project
+	if langCode != "" {
+		metadata, ok := snapshot.Project.LanguageMetadata[langCode]
+		if !ok {
+			return nil, fmt.Errorf("language %s not found in snapshot", langCode)
+		}
+		restored = *project
+		restoreLanguage(&restored, &snapshot.Project, langCode)
+		restored.LanguageMetadata = maps.Clone(project.LanguageMetadata)
+		if restored.LanguageMetadata == nil {
+			restored.LanguageMetadata = make(map[string]types.LanguageMetadata)
+		}
+		restored.LanguageMetadata[langCode] = metadata
+	}
+	restored.ID = project.ID
+	for code, metadata := range restored.LanguageMetadata {
+		if current, ok := project.LanguageMetadata[code]; ok && current.Revision > metadata.Revision {
+			metadata.Revision = current.Revision
+			restored.LanguageMetadata[code] = metadata
+		}
+	}
+
+	s.autoSnapshot(project, fmt.Sprintf("Before restoring %q", snapshot.Name))
+	if langCode == "" {
+		bumpRevisions(&restored, time.Now().Unix())
+	} else {
+		metadata := restored.LanguageMetadata[langCode]
+		metadata.Revision++
+		metadata.Status.LastUpdated = time.Now().Unix()
+		restored.LanguageMetadata[langCode] = metadata
+		restored.UpdatedAt = metadata.Status.LastUpdated
+	}
+	if err := s.boltStorage.SaveSubtitle(&restored); err != nil {
+		return nil, s.handleError("restore snapshot", err)
+	}
+	s.recordBulkEdit(project.ID, types.EditRestoreSnapshot, langCode, project.Segments, restored.Segments)
+	return &restored, nil
+}
+
+// restoreLanguage 将快照中某语言的文本写回当前片段（按 ID 或时间轴配对），
+// 快照中没有对应片段的当前片段移除该语言
+func restoreLanguage(project, snapshot *types.SubtitleProject, langCode string) {
+	segments := slices.Clone(project.Segments)
+	for _, pair := range subdiff.Match(snapshot.Segments, segments) {
+		if pair.To < 0 {
+			continue
+		}
+		segment := &segments[pair.To]
+		segment.Languages = maps.Clone(segment.Languages)
+		if segment.Languages == nil {
+			segment.Languages = make(map[string]types.LanguageContent)
+		}
+		if pair.From >= 0 {
+			if content, ok := snapshot.Segments[pair.From].Languages[langCode]; ok {
+				segment.Languages[langCode] = content
+				continue
+			}
+		}
+		delete(segment.Languages, langCode)
+	}
+	project.Segments = segments
+}
+
+// autoSnapshot 批量操作前自动创建快照，失败只记录日志；超出数量的旧自动快照会被删除
+func (s *Service) autoSnapshot(project *types.SubtitleProject, name string) {
+	if _, err := s.saveSnapshot(project, name, types.SnapshotAuto); err != nil {
+		logger.Warn("failed to create snapshot", zap.String("id", project.ID), zap.String("name", name), zap.Error(err))
+		return
+	}
+	snapshots, err := s.boltStorage.ListSnapshots(project.ID)
+	if err != nil {
+		logger.Warn("failed to list snapshots", zap.String("id", project.ID), zap.Error(err))
+		return
+	}
+	auto := slices.DeleteFunc(snapshots, func(snapshot *types.ProjectSnapshot) bool {
+		return snapshot.Kind != types.SnapshotAuto
+	})
+	for len(auto) > maxAutoSnapshots {
+		if err := s.boltStorage.DeleteSnapshot(project.ID, auto[0].ID); err != nil {
+			logger.Warn("failed to prune snapshot", zap.String("id", project.ID), zap.Error(err))
+			return
+		}
+		auto = auto[1:]
+	}
+}
+
+func (s *Service) saveSnapshot(project *types.SubtitleProject, name string, kind types.SnapshotKind) (*types.ProjectSnapshot, error) {
+	// V7 UUID 按时间有序，同一秒内创建的快照在存储中也保持先后顺序
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &types.ProjectSnapshot{
+		ID:        id.String(),
+		ProjectID: project.ID,
+		Name:      name,
+		Kind:      kind,
+		Project:   *project,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := s.boltStorage.SaveSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// translationRunning 项目是否有正在进行的翻译
+func (s *Service) translationRunning(id string) bool {
+	running := false
+	s.translations.Range(func(key, _ any) bool {
+		running = strings.HasPrefix(key.(string), id+"/")
+		return !running
+	})
+	return running
+}
+
+func snapshotInfo(snapshot *types.ProjectSnapshot) types.SnapshotInfo {
+	return types.SnapshotInfo{
+		ID:        snapshot.ID,
+		ProjectID: snapshot.ProjectID,
+		Name:      snapshot.Name,
+		Kind:      snapshot.Kind,
+		Segments:  len(snapshot.Project.Segments),
+		Languages: slices.Sorted(maps.Keys(snapshot.Project.LanguageMetadata)),
+		CreatedAt: snapshot.CreatedAt,
+	}
+}
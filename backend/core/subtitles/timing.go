@@ -0,0 +1,167 @@
+package subtitles
+
+import (
+	"CanMe/backend/pkg/edithistory"
+	"CanMe/backend/pkg/subtiming"
+	"CanMe/backend/types"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 项目中保留的时间轴操作历史条数
+const maxTimingHistory = 50
+
+// 未配置帧率的项目按 25fps 计算 Frames（与 Timecode 字符串解析的默认值一致）
+const defaultFrameRate = 25.0
+
+// ApplyTimingOperation 对项目执行整体偏移、两点拉伸或帧率转换，同时更新 Timecode.Time 与 Frames，
+// 并为每种语言记录一次修订
+func (s *Service) ApplyTimingOperation(id string, op types.TimingOperation) (*types.SubtitleProject, error) {
+	if id == "" {
+		return nil, s.handleError("apply timing operation", fmt.Errorf("id is empty"))
+	}
+	project, err := s.boltStorage.GetSubtitle(id)
+	if err != nil {
+		return nil, s.handleError("apply timing operation", err)
+	}
+	if len(project.Segments) == 0 {
+		return nil, errors.New("project has no segments")
+	}
+
+	frameRate := projectFrameRate(project)
+	from, to := 0, len(project.Segments)-1
+	var mapTime func(time.Duration) time.Duration
+	var snapshotName string
+
+	switch op.Type {
+	case types.TimingOperationOffset:
+		if op.OffsetMs == 0 {
+			return nil, errors.New("offset is zero")
+		}
+		if from, to, err = segmentRange(project, op.FromSegmentID, op.ToSegmentID); err != nil {
+			return nil, err
+		}
+		delta := time.Duration(op.OffsetMs) * time.Millisecond
+		mapTime = func(t time.Duration) time.Duration { return subtiming.Offset(t, delta) }
+		snapshotName = fmt.Sprintf("Before timing offset %+dms", op.OffsetMs)
+
+	case types.TimingOperationStretch:
+		if op.First == nil || op.Second == nil {
+			return nil, errors.New("stretch needs two sync points")
+		}
+		stretch, err := subtiming.NewStretch(
+			time.Duration(op.First.FromMs)*time.Millisecond, time.Duration(op.First.ToMs)*time.Millisecond,
+			time.Duration(op.Second.FromMs)*time.Millisecond, time.Duration(op.Second.ToMs)*time.Millisecond)
+		if err != nil {
+			return nil, err
+		}
+		mapTime = stretch.Apply
+		snapshotName = "Before timing stretch"
+
+	case types.TimingOperationFrameRate:
+		if err := subtiming.ValidateFrameRate(op.FromFPS); err != nil {
+			return nil, err
+		}
+		if err := subtiming.ValidateFrameRate(op.ToFPS); err != nil {
+			return nil, err
+		}
+		fromFPS, toFPS := subtiming.NormalizeFrameRate(op.FromFPS), subtiming.NormalizeFrameRate(op.ToFPS)
+		if fromFPS == toFPS {
+			return nil, errors.New("source and target frame rates are the same")
+		}
+		op.FromFPS, op.ToFPS = fromFPS, toFPS
+		mapTime = func(t time.Duration) time.Duration { return subtiming.ConvertFrameRate(t, fromFPS, toFPS) }
+		snapshotName = fmt.Sprintf("Before frame rate %.3f → %.3f fps", fromFPS, toFPS)
+
+	default:
+		return nil, fmt.Errorf("unsupported timing operation: %s", op.Type)
+	}
+
+	// 参数校验通过后、修改项目前保存快照
+	s.autoSnapshot(project, snapshotName)
+	before := edithistory.Snapshot(project.Segments)
+	if op.Type == types.TimingOperationFrameRate {
+		frameRate = op.ToFPS
+		setProjectFrameRate(project, op.ToFPS)
+	}
+
+	for i := from; i <= to; i++ {
+		segment := &project.Segments[i]
+		start := mapTime(segment.StartTime.Time)
+		end := max(mapTime(segment.EndTime.Time), start)
+		segment.StartTime = types.NewTimecode(start, frameRate)
+		segment.EndTime = types.NewTimecode(end, frameRate)
+	}
+
+	op.Segments = to - from + 1
+	op.AppliedAt = time.Now().Unix()
+	project.Metadata.TimingHistory = append(project.Metadata.TimingHistory, op)
+	if n := len(project.Metadata.TimingHistory); n > maxTimingHistory {
+		project.Metadata.TimingHistory = project.Metadata.TimingHistory[n-maxTimingHistory:]
+	}
+	bumpRevisions(project, op.AppliedAt)
+
+	if err := s.boltStorage.SaveSubtitle(project); err != nil {
+		return nil, s.handleError("apply timing operation", err)
+	}
+	s.recordBulkEdit(project.ID, types.EditTiming, "", before, project.Segments)
+	return project, nil
+}
+
+// segmentRange 返回片段 ID 对应的下标区间（含两端），ID 为空表示从头/到尾
+func segmentRange(project *types.SubtitleProject, fromID, toID string) (int, int, error) {
+	from, to := 0, len(project.Segments)-1
+	find := func(segmentID string) (int, error) {
+		for i := range project.Segments {
+			if project.Segments[i].ID == segmentID {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("segment not found: %s", segmentID)
+	}
+	var err error
+	if fromID != "" {
+		if from, err = find(fromID); err != nil {
+			return 0, 0, err
+		}
+	}
+	if toID != "" {
+		if to, err = find(toID); err != nil {
+			return 0, 0, err
+		}
+	}
+	if from > to {
+		from, to = to, from
+	}
+	return from, to, nil
+}
+
+// projectFrameRate 项目视频帧率，用于计算 Timecode.Frames
+func projectFrameRate(project *types.SubtitleProject) float64 {
+	if config := project.Metadata.ExportConfigs.FCPXML; config != nil && config.FrameRate > 0 {
+		return config.FrameRate
+	}
+	if info := project.Metadata.SourceInfo; info != nil && info.OriginalFPS > 0 {
+		return info.OriginalFPS
+	}
+	if info := project.SourceFile; info != nil && info.OriginalFPS > 0 {
+		return info.OriginalFPS
+	}
+	return defaultFrameRate
+}
+
+// setProjectFrameRate 帧率转换后同步项目记录的视频帧率
+func setProjectFrameRate(project *types.SubtitleProject, fps float64) {
+	if config := project.Metadata.ExportConfigs.FCPXML; config != nil {
+		config.FrameRate = fps
+		config.FrameDuration = ""
+		config.AutoFill()
+	}
+	if info := project.Metadata.SourceInfo; info != nil {
+		info.OriginalFPS = fps
+	}
+	if info := project.SourceFile; info != nil {
+		info.OriginalFPS = fps
+	}
+}
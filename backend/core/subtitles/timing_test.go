@@ -0,0 +1,125 @@
+package subtitles
+
+import (
+	"CanMe/backend/storage"
+	"CanMe/backend/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStorageService 使用临时配置目录中的 bbolt 数据库创建服务
+func newStorageService(t *testing.T) *Service {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	bs, err := storage.NewBoltStorage()
+	require.NoError(t, err)
+	t.Cleanup(func() { bs.Close() })
+	return &Service{boltStorage: bs}
+}
+
+func timingProject(t *testing.T, s *Service) *types.SubtitleProject {
+	t.Helper()
+	project := &types.SubtitleProject{
+		ID:         "p1",
+		SourceFile: &types.SourceFileInfo{OriginalFPS: 25},
+		Segments: []types.SubtitleSegment{
+			{ID: "s1", StartTime: types.NewTimecode(time.Second, 25), EndTime: types.NewTimecode(2*time.Second, 25)},
+			{ID: "s2", StartTime: types.NewTimecode(3*time.Second, 25), EndTime: types.NewTimecode(4*time.Second, 25)},
+		},
+	}
+	require.NoError(t, s.boltStorage.SaveSubtitle(project))
+	return project
+}
+
+func TestApplyTimingOperationSnapshots(t *testing.T) {
+	s := newStorageService(t)
+	timingProject(t, s)
+
+	ops := []struct {
+		op   types.TimingOperation
+		name string
+	}{
+		{types.TimingOperation{Type: types.TimingOperationOffset, OffsetMs: 500}, "Before timing offset +500ms"},
+		{types.TimingOperation{Type: types.TimingOperationStretch,
+			First:  &types.TimingSyncPoint{FromMs: 1500, ToMs: 1000},
+			Second: &types.TimingSyncPoint{FromMs: 3500, ToMs: 4000}}, "Before timing stretch"},
+		{types.TimingOperation{Type: types.TimingOperationFrameRate, FromFPS: 25, ToFPS: 23.976}, "Before frame rate 25.000 → 23.976 fps"},
+	}
+	var before []time.Duration
+	for _, tt := range ops {
+		current, err := s.boltStorage.GetSubtitle("p1")
+		require.NoError(t, err)
+		before = append(before, current.Segments[0].StartTime.Time)
+		_, err = s.ApplyTimingOperation("p1", tt.op)
+		require.NoError(t, err, tt.name)
+	}
+
+	// 每次操作前保存一份修改前的项目快照
+	snapshots, err := s.boltStorage.ListSnapshots("p1")
+	require.NoError(t, err)
+	require.Len(t, snapshots, len(ops))
+	for i, snapshot := range snapshots {
+		assert.Equal(t, ops[i].name, snapshot.Name)
+		assert.Equal(t, types.SnapshotAuto, snapshot.Kind)
+		assert.Equal(t, before[i], snapshot.Project.Segments[0].StartTime.Time, snapshot.Name)
+	}
+	assert.Equal(t, 25.0, snapshots[2].Project.SourceFile.OriginalFPS)
+
+	project, err := s.boltStorage.GetSubtitle("p1")
+	require.NoError(t, err)
+	assert.InDelta(t, 23.976, project.SourceFile.OriginalFPS, 0.001)
+}
+
+func TestApplyTimingOperationInvalidNoSnapshot(t *testing.T) {
+	s := newStorageService(t)
+	timingProject(t, s)
+
+	for _, op := range []types.TimingOperation{
+		{Type: types.TimingOperationOffset},
+		{Type: types.TimingOperationStretch},
+		{Type: types.TimingOperationFrameRate, FromFPS: 25, ToFPS: 25},
+		{Type: "speed"},
+	} {
+		_, err := s.ApplyTimingOperation("p1", op)
+		assert.Error(t, err, op.Type)
+	}
+	snapshots, err := s.boltStorage.ListSnapshots("p1")
+	require.NoError(t, err)
+	assert.Empty(t, snapshots)
+}
+
+func TestUndoTimingOffset(t *testing.T) {
+	s := newStorageService(t)
+	timingProject(t, s)
+
+	// 先编辑文本，再整体偏移：两步都可依次撤销与重做
+	_, err := s.UpdateLanguageContent("p1", "s1", "en", types.LanguageContent{Text: "hello"})
+	require.NoError(t, err)
+	_, err = s.ApplyTimingOperation("p1", types.TimingOperation{Type: types.TimingOperationOffset, OffsetMs: 500})
+	require.NoError(t, err)
+
+	project, err := s.Undo("p1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, project.Segments[0].StartTime.Time)
+	assert.Equal(t, 3*time.Second, project.Segments[1].StartTime.Time)
+	assert.Equal(t, "hello", project.Segments[0].Languages["en"].Text)
+
+	project, err = s.Undo("p1")
+	require.NoError(t, err)
+	assert.Empty(t, project.Segments[0].Languages["en"].Text)
+
+	_, err = s.Redo("p1")
+	require.NoError(t, err)
+	project, err = s.Redo("p1")
+	require.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, project.Segments[0].StartTime.Time)
+
+	entries, err := s.ListEditHistory("p1")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, types.EditTiming, entries[0].Type)
+}
@@ -0,0 +1,263 @@
+package subtitles
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/pkg/edithistory"
+	"CanMe/backend/pkg/events"
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/types"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetTranslationProviders 返回可用的机器翻译提供商
+func (s *Service) GetTranslationProviders() []string {
+	return TranslationProviders()
+}
+
+// TranslateSubtitle 使用机器翻译将 origin 语言翻译为 targetLang，结果作为项目中的新语言写入。
+// 翻译在后台进行，进度通过 TopicSubtitleProgress 推送；provider 为空时使用偏好设置中的提供商
+func (s *Service) TranslateSubtitle(id, origin, targetLang, provider string) (*types.ConversionTask, error) {
+	targetLang = strings.TrimSpace(targetLang)
+	if id == "" {
+		return nil, errors.New("subtitle id is empty")
+	}
+	if origin == "" || targetLang == "" {
+		return nil, errors.New("origin and target language are required")
+	}
+	if origin == targetLang {
+		return nil, errors.New("target language must differ from origin")
+	}
+	if s.pref == nil {
+		return nil, errors.New("translation settings unavailable")
+	}
+
+	config := s.pref.GetTranslationConfig()
+	if provider == "" {
+		provider = config.Provider
+	}
+	var client *http.Client
+	if s.proxyManager != nil {
+		client = s.proxyManager.GetHTTPClient()
+	}
+	translator, err := newTranslator(provider, config, client)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := s.GetSubtitle(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := sub.LanguageMetadata[origin]; !ok {
+		return nil, errors.New("origin language not found")
+	}
+
+	key := translationKey(id, targetLang)
+	ctx, cancel := context.WithCancel(s.ctx)
+	if _, running := s.translations.LoadOrStore(key, cancel); running {
+		cancel()
+		return nil, fmt.Errorf("translation to %s is already running", targetLang)
+	}
+
+	// 空白字幕不送翻译；翻译记忆命中的片段直接预填
+	resources := s.loadTermResources(sub, origin, targetLang, config.TMThreshold)
+	var items []translationItem
+	prefilled := make(map[string]*types.TMMatch)
+	for _, segment := range sub.Segments {
+		text := strings.TrimSpace(segment.Languages[origin].Text)
+		if text == "" {
+			continue
+		}
+		if match := resources.lookup(text); match != nil {
+			prefilled[segment.ID] = match
+			continue
+		}
+		items = append(items, translationItem{segmentID: segment.ID, text: text})
+	}
+
+	model := ""
+	if provider == "openai" {
+		model = config.OpenAI.Model
+	}
+	taskID := uuid.NewString()
+	conversionTask := types.ConversionTask{
+		ID:                taskID,
+		Type:              "llm_translate",
+		Status:            types.ConversionStatusProcessing,
+		StartTime:         time.Now().Unix(),
+		SourceLang:        origin,
+		TargetLang:        targetLang,
+		ConverterName:     model,
+		Provider:          translator.Name(),
+		TotalSegments:     len(items) + len(prefilled),
+		ProcessedSegments: len(prefilled),
+	}
+	if conversionTask.TotalSegments > 0 {
+		conversionTask.Progress = float64(len(prefilled)) * 100 / float64(conversionTask.TotalSegments)
+	}
+
+	s.autoSnapshot(sub, fmt.Sprintf("Before translation %s → %s", origin, targetLang))
+
+	metadata, ok := sub.LanguageMetadata[targetLang]
+	if ok {
+		metadata.Revision++
+	} else {
+		metadata = types.LanguageMetadata{
+			DetectedLang: getLanguageCode(targetLang),
+			LanguageName: targetLang,
+			Quality:      "machine",
+		}
+	}
+	metadata.Translator = strings.Trim(translator.Name()+"/"+model, "/")
+	metadata.SyncStatus = "translating"
+	metadata.ActiveTaskID = taskID
+	metadata.Status.IsOriginal = false
+	metadata.Status.ConversionTasks = append(metadata.Status.ConversionTasks, conversionTask)
+	metadata.Status.LastUpdated = time.Now().Unix()
+	sub.LanguageMetadata[targetLang] = metadata
+
+	if err := s.boltStorage.SaveSubtitle(sub); err != nil {
+		s.translations.Delete(key)
+		cancel()
+		return nil, s.handleError("translate subtitle", err)
+	}
+	s.publishConversion(conversionTask)
+
+	go func() {
+		defer s.translations.Delete(key)
+		defer cancel()
+
+		progress := conversionTask
+		results, failed, err := translateItems(ctx, translator, items, origin, targetLang, config, resources, func(processed, failed int) {
+			progress.ProcessedSegments = processed + len(prefilled)
+			progress.FailedSegments = failed
+			if progress.TotalSegments > 0 {
+				progress.Progress = float64(progress.ProcessedSegments) * 100 / float64(progress.TotalSegments)
+			}
+			s.publishConversion(progress)
+		})
+		if err == nil && len(items) > 0 && failed == len(items) && len(prefilled) == 0 {
+			err = errors.New("all segments failed to translate")
+		}
+
+		// 翻译期间项目可能被编辑，重新读取后按片段 ID 写入
+		latest, getErr := s.GetSubtitle(id)
+		if getErr != nil {
+			logger.Error("translate subtitle: reload project failed", zap.String("id", id), zap.Error(getErr))
+			s.publishConversion(failedConversion(progress, getErr))
+			return
+		}
+		if _, ok := latest.LanguageMetadata[targetLang]; !ok {
+			s.publishConversion(failedConversion(progress, errors.New("target language was removed")))
+			return
+		}
+
+		var before []types.SubtitleSegment
+		if err == nil {
+			before = edithistory.Snapshot(latest.Segments)
+			s.applyTranslations(latest, origin, targetLang, results, prefilled, resources)
+		}
+
+		metadata := latest.LanguageMetadata[targetLang]
+		for i := range metadata.Status.ConversionTasks {
+			if metadata.Status.ConversionTasks[i].ID == taskID {
+				task := &metadata.Status.ConversionTasks[i]
+				task.ProcessedSegments = progress.ProcessedSegments
+				task.FailedSegments = progress.FailedSegments
+				task.Progress = progress.Progress
+				// 部分片段失败时任务仍为完成，ErrorMessage 记录失败条数
+				if err == nil && failed > 0 {
+					task.ErrorMessage = fmt.Sprintf("%d segments failed to translate", failed)
+				}
+			}
+		}
+		latest.LanguageMetadata[targetLang] = metadata
+
+		if err != nil {
+			logger.Warn("translate subtitle failed", zap.String("id", id), zap.String("target", targetLang), zap.Error(err))
+		}
+		s.handleSubtitleChange(latest, targetLang, err)
+		if err == nil {
+			s.recordBulkEdit(id, types.EditTranslate, targetLang, before, latest.Segments)
+		}
+	}()
+
+	return &conversionTask, nil
+}
+
+// CancelTranslation 取消进行中的机器翻译
+func (s *Service) CancelTranslation(id, targetLang string) error {
+	value, ok := s.translations.Load(translationKey(id, targetLang))
+	if !ok {
+		return fmt.Errorf("no running translation for %s", targetLang)
+	}
+	value.(context.CancelFunc)()
+	return nil
+}
+
+// applyTranslations 写入译文与翻译记忆预填结果，检查术语并重新评估片段质量；未翻译成功的片段保持原状
+func (s *Service) applyTranslations(sub *types.SubtitleProject, origin, targetLang string, results map[string]string,
+	prefilled map[string]*types.TMMatch, resources *termResources) {
+	assessor := s.qualityAssessor.Pass()
+	for i := range sub.Segments {
+		segment := &sub.Segments[i]
+		var content types.LanguageContent
+		if match, ok := prefilled[segment.ID]; ok {
+			content = types.LanguageContent{Text: match.Entry.Target, TMScore: match.Score}
+		} else if text, ok := results[segment.ID]; ok {
+			content = types.LanguageContent{Text: text}
+		} else {
+			continue
+		}
+		if segment.Languages == nil {
+			segment.Languages = make(map[string]types.LanguageContent)
+		}
+		if segment.GuidelineStandard == nil {
+			segment.GuidelineStandard = make(map[string]types.GuideLineStandard)
+		}
+		standard := segment.GuidelineStandard[origin]
+		if standard == "" {
+			standard = types.GuideLineStandardNetflix
+		}
+		resources.apply(segment.Languages[origin].Text, &content)
+		segment.Languages[targetLang] = content
+		segment.GuidelineStandard[targetLang] = standard
+		*segment = *assessor.AssessSegmentQuality(segment)
+	}
+	sub.UpdatedAt = time.Now().Unix()
+}
+
+func (s *Service) publishConversion(task types.ConversionTask) {
+	if s.eventBus == nil {
+		return
+	}
+	s.eventBus.Publish(s.ctx, &events.BaseEvent{
+		ID:        uuid.New().String(),
+		Type:      consts.TopicSubtitleProgress,
+		Source:    "subtitles",
+		Timestamp: time.Now(),
+		Data:      task,
+		Metadata: map[string]interface{}{
+			"progress": task,
+		},
+	})
+}
+
+func failedConversion(task types.ConversionTask, err error) types.ConversionTask {
+	task.Status = types.ConversionStatusFailed
+	task.ErrorMessage = err.Error()
+	task.EndTime = time.Now().Unix()
+	return task
+}
+
+func translationKey(id, lang string) string {
+	return id + "/" + lang
+}
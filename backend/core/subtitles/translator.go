@@ -0,0 +1,183 @@
+package subtitles
+
+import (
+	"CanMe/backend/types"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TranslationRequest 一批待翻译的字幕
+type TranslationRequest struct {
+	SourceLang string
+	TargetLang string
+	// Lines 需要翻译的字幕
+	Lines []string
+	// Before/After 前后相邻的字幕，仅作为上下文参考，不翻译
+	Before []string
+	After  []string
+	// Terms 本批原文中出现的术语，要求按术语表翻译
+	Terms []types.GlossaryTerm
+}
+
+// TranslatorFactory 按翻译设置创建提供商实例
+type TranslatorFactory func(config types.PreferencesTranslation, client *http.Client) (Translator, error)
+
+var (
+	translatorsMu       sync.RWMutex
+	translatorFactories = map[string]TranslatorFactory{
+		"openai": newOpenAITranslator,
+	}
+)
+
+// RegisterTranslator 注册翻译提供商，同名时覆盖
+func RegisterTranslator(name string, factory TranslatorFactory) {
+	translatorsMu.Lock()
+	defer translatorsMu.Unlock()
+	translatorFactories[strings.ToLower(name)] = factory
+}
+
+// TranslationProviders 返回已注册的翻译提供商名称
+func TranslationProviders() []string {
+	translatorsMu.RLock()
+	defer translatorsMu.RUnlock()
+	names := make([]string, 0, len(translatorFactories))
+	for name := range translatorFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newTranslator(name string, config types.PreferencesTranslation, client *http.Client) (Translator, error) {
+	translatorsMu.RLock()
+	factory, ok := translatorFactories[strings.ToLower(name)]
+	translatorsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported translation provider: %s", name)
+	}
+	return factory(config, client)
+}
+
+// permanentError 重试无意义的错误（认证失败、模型不存在等）
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+func isPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// translateRetryDelay 重试退避的基数，第 n 次重试前等待 n 倍
+var translateRetryDelay = time.Second
+
+// translateWithRetry 翻译一批字幕，失败或译文条数不符时按 retries 重试（线性退避）
+func translateWithRetry(ctx context.Context, translator Translator, req TranslationRequest, retries int) ([]string, error) {
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(attempt) * translateRetryDelay):
+			}
+		}
+		out, err := translator.Translate(ctx, req)
+		if err == nil && len(out) != len(req.Lines) {
+			err = fmt.Errorf("expected %d translations, got %d", len(req.Lines), len(out))
+		}
+		if err == nil {
+			return out, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil || isPermanent(err) {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// translationItem 待翻译的片段
+type translationItem struct {
+	segmentID string
+	text      string
+}
+
+// translateItems 按批翻译并附带上下文与相关术语；整批失败时逐条重试，仍失败的片段计入 failed。
+// 遇到不可重试的错误或任务取消时返回 err
+func translateItems(ctx context.Context, translator Translator, items []translationItem, sourceLang, targetLang string,
+	config types.PreferencesTranslation, resources *termResources, onProgress func(processed, failed int)) (results map[string]string, failed int, err error) {
+	results = make(map[string]string, len(items))
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = item.text
+	}
+	window := func(from, to int) []string {
+		from, to = max(from, 0), min(to, len(texts))
+		if from >= to {
+			return nil
+		}
+		return texts[from:to]
+	}
+
+	processed := 0
+	for start := 0; start < len(items); start += config.BatchSize {
+		end := min(start+config.BatchSize, len(items))
+		req := TranslationRequest{
+			SourceLang: sourceLang,
+			TargetLang: targetLang,
+			Lines:      texts[start:end],
+			Before:     window(start-config.ContextSize, start),
+			After:      window(end, end+config.ContextSize),
+			Terms:      resources.relevant(texts[start:end]),
+		}
+		out, batchErr := translateWithRetry(ctx, translator, req, config.MaxRetries)
+		if batchErr == nil {
+			for i, text := range out {
+				results[items[start+i].segmentID] = strings.TrimSpace(text)
+			}
+		} else {
+			if ctx.Err() != nil {
+				return results, failed, ctx.Err()
+			}
+			if isPermanent(batchErr) {
+				return results, failed, batchErr
+			}
+			// 整批失败（常见为译文条数不符），逐条翻译
+			for i := start; i < end; i++ {
+				single := TranslationRequest{
+					SourceLang: sourceLang,
+					TargetLang: targetLang,
+					Lines:      texts[i : i+1],
+					Before:     window(i-config.ContextSize, i),
+					After:      window(i+1, i+1+config.ContextSize),
+					Terms:      resources.relevant(texts[i : i+1]),
+				}
+				out, segErr := translateWithRetry(ctx, translator, single, config.MaxRetries)
+				if segErr != nil {
+					if ctx.Err() != nil {
+						return results, failed, ctx.Err()
+					}
+					if isPermanent(segErr) {
+						return results, failed, segErr
+					}
+					failed++
+					continue
+				}
+				results[items[i].segmentID] = strings.TrimSpace(out[0])
+			}
+		}
+		processed = end
+		if onProgress != nil {
+			onProgress(processed, failed)
+		}
+	}
+	return results, failed, nil
+}
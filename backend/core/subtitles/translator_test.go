@@ -0,0 +1,240 @@
+package subtitles
+
+import (
+	"CanMe/backend/types"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTranslator 记录每次请求并按 fn 返回译文
+type fakeTranslator struct {
+	mu    sync.Mutex
+	calls []TranslationRequest
+	fn    func(call int, req TranslationRequest) ([]string, error)
+}
+
+func (f *fakeTranslator) Name() string { return "fake" }
+
+func (f *fakeTranslator) Translate(ctx context.Context, req TranslationRequest) ([]string, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, req)
+	call := len(f.calls)
+	f.mu.Unlock()
+	return f.fn(call, req)
+}
+
+// upper 按行转大写作为“译文”
+func upper(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = " " + strings.ToUpper(line) + " "
+	}
+	return out
+}
+
+func noRetryDelay(t *testing.T) {
+	t.Helper()
+	old := translateRetryDelay
+	translateRetryDelay = 0
+	t.Cleanup(func() { translateRetryDelay = old })
+}
+
+func TestTranslateWithRetry(t *testing.T) {
+	noRetryDelay(t)
+	errRate := errors.New("openai: 429 Too Many Requests")
+	errAuth := &permanentError{errors.New("openai: 401 Unauthorized")}
+
+	tests := []struct {
+		name    string
+		retries int
+		fn      func(call int, req TranslationRequest) ([]string, error)
+		want    []string
+		err     string
+		calls   int
+	}{
+		{
+			name:    "success",
+			retries: 2,
+			fn:      func(int, TranslationRequest) ([]string, error) { return []string{"x", "y"}, nil },
+			want:    []string{"x", "y"},
+			calls:   1,
+		},
+		{
+			name:    "short reply retried",
+			retries: 2,
+			fn: func(call int, req TranslationRequest) ([]string, error) {
+				if call == 1 {
+					return []string{"x"}, nil
+				}
+				return []string{"x", "y"}, nil
+			},
+			want:  []string{"x", "y"},
+			calls: 2,
+		},
+		{
+			name:    "short reply exhausted",
+			retries: 1,
+			fn:      func(int, TranslationRequest) ([]string, error) { return []string{"x", "y", "z"}, nil },
+			err:     "expected 2 translations, got 3",
+			calls:   2,
+		},
+		{
+			name:    "retryable error",
+			retries: 2,
+			fn:      func(int, TranslationRequest) ([]string, error) { return nil, errRate },
+			err:     "429",
+			calls:   3,
+		},
+		{
+			name:    "permanent error",
+			retries: 2,
+			fn:      func(int, TranslationRequest) ([]string, error) { return nil, errAuth },
+			err:     "401",
+			calls:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &fakeTranslator{fn: tt.fn}
+			out, err := translateWithRetry(context.Background(), tr, TranslationRequest{Lines: []string{"a", "b"}}, tt.retries)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				assert.Nil(t, out)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, out)
+			}
+			assert.Len(t, tr.calls, tt.calls)
+		})
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		tr := &fakeTranslator{fn: func(int, TranslationRequest) ([]string, error) {
+			cancel()
+			return nil, errRate
+		}}
+		// 取消后不再重试，返回最后一次的错误
+		_, err := translateWithRetry(ctx, tr, TranslationRequest{Lines: []string{"a"}}, 3)
+		assert.ErrorIs(t, err, errRate)
+		assert.Len(t, tr.calls, 1)
+	})
+}
+
+func items(texts ...string) []translationItem {
+	out := make([]translationItem, len(texts))
+	for i, text := range texts {
+		out[i] = translationItem{segmentID: "s" + string(rune('1'+i)), text: text}
+	}
+	return out
+}
+
+func TestTranslateItemsBatches(t *testing.T) {
+	tr := &fakeTranslator{fn: func(_ int, req TranslationRequest) ([]string, error) { return upper(req.Lines), nil }}
+	config := types.PreferencesTranslation{BatchSize: 2, ContextSize: 1}
+	resources := &termResources{terms: []types.GlossaryTerm{{Source: "CanMe", Target: "CanMe", DoNotTranslate: true}}}
+
+	var progress [][2]int
+	results, failed, err := translateItems(context.Background(), tr, items("a", "b", "c canme", "d", "e"), "en", "zh",
+		config, resources, func(processed, failed int) { progress = append(progress, [2]int{processed, failed}) })
+	require.NoError(t, err)
+	assert.Zero(t, failed)
+	assert.Equal(t, map[string]string{"s1": "A", "s2": "B", "s3": "C CANME", "s4": "D", "s5": "E"}, results)
+	assert.Equal(t, [][2]int{{2, 0}, {4, 0}, {5, 0}}, progress)
+
+	// 每批附带前后各 ContextSize 条上下文，术语只随包含它的批次发送
+	require.Len(t, tr.calls, 3)
+	want := []TranslationRequest{
+		{Lines: []string{"a", "b"}, After: []string{"c canme"}},
+		{Lines: []string{"c canme", "d"}, Before: []string{"b"}, After: []string{"e"}, Terms: resources.terms},
+		{Lines: []string{"e"}, Before: []string{"d"}},
+	}
+	for i, call := range tr.calls {
+		assert.Equal(t, "en", call.SourceLang)
+		assert.Equal(t, "zh", call.TargetLang)
+		assert.Equal(t, want[i].Lines, call.Lines, "batch %d", i)
+		assert.Equal(t, want[i].Before, call.Before, "batch %d", i)
+		assert.Equal(t, want[i].After, call.After, "batch %d", i)
+		assert.Equal(t, want[i].Terms, call.Terms, "batch %d", i)
+	}
+}
+
+func TestTranslateItemsFallback(t *testing.T) {
+	noRetryDelay(t)
+	config := types.PreferencesTranslation{BatchSize: 3, ContextSize: 1, MaxRetries: 1}
+
+	t.Run("per segment", func(t *testing.T) {
+		// 整批回复条数不符时逐条翻译；单条仍失败的计入 failed
+		tr := &fakeTranslator{fn: func(_ int, req TranslationRequest) ([]string, error) {
+			if len(req.Lines) > 1 {
+				return []string{"merged"}, nil
+			}
+			if req.Lines[0] == "bad" {
+				return nil, errors.New("openai: 503 Service Unavailable")
+			}
+			return upper(req.Lines), nil
+		}}
+		var last [2]int
+		results, failed, err := translateItems(context.Background(), tr, items("a", "bad", "c"), "en", "zh",
+			config, &termResources{}, func(processed, failed int) { last = [2]int{processed, failed} })
+		require.NoError(t, err)
+		assert.Equal(t, 1, failed)
+		assert.Equal(t, map[string]string{"s1": "A", "s3": "C"}, results)
+		assert.Equal(t, [2]int{3, 1}, last)
+
+		// 批次 2 次 + 每条 1 次（bad 重试 1 次）
+		require.Len(t, tr.calls, 6)
+		single := tr.calls[2]
+		assert.Equal(t, []string{"a"}, single.Lines)
+		assert.Nil(t, single.Before)
+		assert.Equal(t, []string{"bad"}, single.After)
+		last3 := tr.calls[5]
+		assert.Equal(t, []string{"c"}, last3.Lines)
+		assert.Equal(t, []string{"bad"}, last3.Before)
+		assert.Nil(t, last3.After)
+	})
+
+	t.Run("permanent batch error", func(t *testing.T) {
+		tr := &fakeTranslator{fn: func(call int, req TranslationRequest) ([]string, error) {
+			if call > 1 {
+				return nil, &permanentError{errors.New("openai: 404 model not found")}
+			}
+			return upper(req.Lines), nil
+		}}
+		results, failed, err := translateItems(context.Background(), tr, items("a", "b", "c", "d"), "en", "zh",
+			config, &termResources{}, nil)
+		assert.ErrorContains(t, err, "model not found")
+		assert.Zero(t, failed)
+		assert.Equal(t, map[string]string{"s1": "A", "s2": "B", "s3": "C"}, results)
+		assert.Len(t, tr.calls, 2)
+	})
+
+	t.Run("permanent segment error", func(t *testing.T) {
+		tr := &fakeTranslator{fn: func(_ int, req TranslationRequest) ([]string, error) {
+			if len(req.Lines) > 1 {
+				return nil, errors.New("openai: timeout")
+			}
+			return nil, &permanentError{errors.New("openai: 401 Unauthorized")}
+		}}
+		_, _, err := translateItems(context.Background(), tr, items("a", "b"), "en", "zh", config, &termResources{}, nil)
+		assert.ErrorContains(t, err, "401")
+		assert.Len(t, tr.calls, 3)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		tr := &fakeTranslator{fn: func(int, TranslationRequest) ([]string, error) {
+			cancel()
+			return nil, context.Canceled
+		}}
+		_, _, err := translateItems(ctx, tr, items("a", "b"), "en", "zh", config, &termResources{}, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Len(t, tr.calls, 1)
+	})
+}
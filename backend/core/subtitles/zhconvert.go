@@ -2,10 +2,12 @@ package subtitles
 
 import (
 	"CanMe/backend/consts"
+	"CanMe/backend/pkg/edithistory"
 	"CanMe/backend/pkg/events"
 	"CanMe/backend/pkg/logger"
 	"CanMe/backend/pkg/zhconvert"
 	"CanMe/backend/types"
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -76,6 +78,8 @@ func (s *Service) ZHConvertSubtitle(id string, origin, converterString string) e
 		ProcessedSegments: 0,
 		FailedSegments:    0,
 	}
+	s.autoSnapshot(sub, fmt.Sprintf("Before zhconvert %s → %s", origin, converter.String()))
+
 	var beforeConvertMetadata types.LanguageMetadata
 	if metadata, ok := sub.LanguageMetadata[converter.String()]; ok {
 		metadata.Revision++
@@ -132,7 +136,7 @@ func (s *Service) ZHConvertSubtitle(id string, origin, converterString string) e
 		if err != nil {
             logger.Info("zhconvert failed", zap.Error(err))
 			// 保存转换失败的元数据
-			s.handleSubtitleChange(sub, converter, err)
+			s.handleSubtitleChange(sub, converter.String(), err)
 			// return
 			return
 		}
@@ -141,7 +145,7 @@ func (s *Service) ZHConvertSubtitle(id string, origin, converterString string) e
 		sub, err = s.GetSubtitle(id)
 		if err != nil {
 			// 保存转换失败的元数据
-			s.handleSubtitleChange(sub, converter, err)
+			s.handleSubtitleChange(sub, converter.String(), err)
 			// return
 			return
 		}
@@ -149,7 +153,7 @@ func (s *Service) ZHConvertSubtitle(id string, origin, converterString string) e
 		// 检查结果长度
 		if len(convertedSubs) != len(sub.Segments) {
 			err := fmt.Errorf("converted results count (%d) doesn't match segments count (%d)", len(convertedSubs), len(sub.Segments))
-			s.handleSubtitleChange(sub, converter, err)
+			s.handleSubtitleChange(sub, converter.String(), err)
 			// return
 			return
 		}
@@ -157,7 +161,7 @@ func (s *Service) ZHConvertSubtitle(id string, origin, converterString string) e
 		// 检查 segments 是否为空
 		if len(sub.Segments) == 0 {
 			err := fmt.Errorf("no segments found in subtitle")
-			s.handleSubtitleChange(sub, converter, err)
+			s.handleSubtitleChange(sub, converter.String(), err)
 			return
 		}
 
@@ -167,7 +171,12 @@ func (s *Service) ZHConvertSubtitle(id string, origin, converterString string) e
 			standard = types.GuideLineStandardNetflix
 		}
 
+		// 翻译记忆只采用完全匹配：繁简转换本身可靠，模糊匹配反而会引入错误
+		resources := s.loadTermResources(sub, origin, converter.String(), 1)
+
 		// 更新字幕，确保 map 已初始化
+		before := edithistory.Snapshot(sub.Segments)
+		assessor := s.qualityAssessor.Pass()
 		for i := 0; i < len(sub.Segments); i++ {
 			// 确保 map 已初始化
 			if sub.Segments[i].Languages == nil {
@@ -180,34 +189,44 @@ func (s *Service) ZHConvertSubtitle(id string, origin, converterString string) e
 			content := types.LanguageContent{
 				Text: convertedSubs[i],
 			}
+			source := sub.Segments[i].Languages[origin].Text
+			if match := resources.lookup(source); match != nil {
+				content.Text, content.TMScore = match.Entry.Target, match.Score
+			}
+			resources.apply(source, &content)
 			sub.Segments[i].Languages[converter.String()] = content
 			sub.Segments[i].GuidelineStandard[converter.String()] = standard
-			sub.Segments[i] = *s.qualityAssessor.AssessSegmentQuality(&sub.Segments[i])
+			sub.Segments[i] = *assessor.AssessSegmentQuality(&sub.Segments[i])
 		}
 
 		// validate
 		err = s.validateProject(sub)
 		if err != nil {
-			s.handleSubtitleChange(sub, converter, err)
+			s.handleSubtitleChange(sub, converter.String(), err)
 		}
 
 		// update metadata
-		s.handleSubtitleChange(sub, converter, nil)
+		s.handleSubtitleChange(sub, converter.String(), nil)
+		s.recordBulkEdit(sub.ID, types.EditZHConvert, converter.String(), before, sub.Segments)
 	}()
 
 	return nil
 }
 
-func (s *Service) handleSubtitleChange(sub *types.SubtitleProject, converter zhconvert.ConverterType, err error) {
+// handleSubtitleChange 结束 langCode 上的当前转换任务（zhconvert / llm_translate），保存并推送最终状态
+func (s *Service) handleSubtitleChange(sub *types.SubtitleProject, langCode string, err error) {
 	var status types.ConversionStatus
 	var errorMessage string
-	if err != nil {
+	if errors.Is(err, context.Canceled) {
+		status = types.ConversionStatusCancelled
+		errorMessage = "cancelled"
+	} else if err != nil {
 		status = types.ConversionStatusFailed
 		errorMessage = err.Error()
 	} else {
 		status = types.ConversionStatusCompleted
 	}
-	if metadata, ok := sub.LanguageMetadata[converter.String()]; ok {
+	if metadata, ok := sub.LanguageMetadata[langCode]; ok {
 		metadata.Revision++
 		// 处理转换任务失败
 		var tasks []types.ConversionTask
@@ -222,7 +241,9 @@ func (s *Service) handleSubtitleChange(sub *types.SubtitleProject, converter zhc
 
 		conversionTask.Status = status
 		conversionTask.EndTime = time.Now().Unix()
-		conversionTask.ErrorMessage = errorMessage
+		if errorMessage != "" {
+			conversionTask.ErrorMessage = errorMessage
+		}
 
 		// 添加到所有任务
 		tasks = append(tasks, conversionTask)
@@ -231,7 +252,7 @@ func (s *Service) handleSubtitleChange(sub *types.SubtitleProject, converter zhc
 		metadata.Status.ConversionTasks = tasks
 		metadata.Status.LastUpdated = time.Now().Unix()
 		metadata.SyncStatus = "done"
-		sub.LanguageMetadata[converter.String()] = metadata
+		sub.LanguageMetadata[langCode] = metadata
 
 		// 返回
 		err = s.boltStorage.SaveSubtitle(sub)
@@ -39,6 +39,8 @@ func (s *Service) Start(ctx context.Context) error {
 	s.svr.AddTool(s.videoDownloaderStatus(), s.downloadStatusHandler)
 	// list all tasks
 	s.svr.AddTool(s.listDownloadTasks(), s.listTasksHandler)
+	// download statistics
+	s.svr.AddTool(s.downloadStatistics(), s.downloadStatisticsHandler)
 	// Start the stdio server
 	if err := server.ServeStdio(s.svr); err != nil {
 		return fmt.Errorf("Server error: %v\n", err)
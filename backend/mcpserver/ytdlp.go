@@ -4,7 +4,9 @@ import (
 	"CanMe/backend/consts"
 	"CanMe/backend/types"
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -106,6 +108,12 @@ func (s *Service) downloadStatusHandler(ctx context.Context, request mcp.CallToo
 		statusString = fmt.Sprintf("Task %s is Cancelled. Info: %v", taskID, status.StageInfo)
 	case types.DtStageFailed:
 		statusString = fmt.Sprintf("Task %s failed. Error: %s", taskID, status.Error)
+		if info := status.ErrorInfo; info != nil {
+			statusString += fmt.Sprintf("\nError code: %s\nSuggested action: %s", info.Code, info.Action)
+			if info.Detail != "" && info.Detail != status.Error {
+				statusString += fmt.Sprintf("\nDetail: %s", info.Detail)
+			}
+		}
 	default:
 		statusString = fmt.Sprintf("Unknown stage for task %s", taskID)
 	}
@@ -143,6 +151,41 @@ func (s *Service) listTasksHandler(ctx context.Context, request mcp.CallToolRequ
 	return mcp.NewToolResultText(resultString), nil
 }
 
+func (s *Service) downloadStatistics() mcp.Tool {
+	return mcp.NewTool("download_statistics",
+		mcp.WithDescription("Summarize download history: bytes, counts, durations, success rate and average speed by period, site, task type and error class."),
+		mcp.WithNumber("days",
+			mcp.Description("Number of days to include, counted back from now (default 30)"),
+		),
+		mcp.WithString("period",
+			mcp.Description("Grouping period for the timeline"),
+			mcp.Enum("day", "week"),
+		),
+	)
+}
+
+func (s *Service) downloadStatisticsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := types.DtStatsQuery{}
+	if days, ok := request.Params.Arguments["days"].(float64); ok && days > 0 {
+		query.From = time.Now().Add(-time.Duration(days*24) * time.Hour).Unix()
+	}
+	query.Period, _ = request.Params.Arguments["period"].(string)
+
+	stats, err := s.downtask.GetStatistics(query)
+	if err != nil {
+		return nil, err
+	}
+	// 速度采样数据量较大，不返回给 LLM
+	stats.Throughput = nil
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
 func isTerminalState(stage types.DtTaskStage) bool {
 	switch stage {
 	case types.DtStageCompleted, types.DtStageFailed, types.DtStageCancelled:
@@ -0,0 +1,392 @@
+// Package autofix 按字幕规范自动修复片段：断行、最短/最长时长、最小间隔、重叠与过短字幕合并。
+package autofix
+
+import (
+	"CanMe/backend/pkg/segmenttext"
+	"CanMe/backend/pkg/textmetrics"
+	"CanMe/backend/types"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 报告中拆分/合并涉及多个片段时，各片段文本之间的分隔符
+const partSeparator = " | "
+
+// WithDefaults 为未设置的数值填入默认值
+func WithDefaults(opts types.AutoFixOptions) types.AutoFixOptions {
+	if opts.MaxLines <= 0 {
+		opts.MaxLines = 2
+	}
+	if opts.MaxCPL <= 0 {
+		opts.MaxCPL = 42
+	}
+	if opts.MaxCPLIdeographic <= 0 {
+		opts.MaxCPLIdeographic = 16
+	}
+	if opts.MinDurationMs <= 0 {
+		opts.MinDurationMs = 833
+	}
+	if opts.MaxDurationMs <= 0 {
+		opts.MaxDurationMs = 7000
+	}
+	if opts.MaxDurationMs < opts.MinDurationMs {
+		opts.MaxDurationMs = opts.MinDurationMs
+	}
+	if opts.MinGapFrames <= 0 {
+		opts.MinGapFrames = 2
+	}
+	if opts.MergeMaxGapMs <= 0 {
+		opts.MergeMaxGapMs = 1000
+	}
+	return opts
+}
+
+type fixer struct {
+	opts      types.AutoFixOptions
+	frameRate float64
+	minGap    time.Duration
+	minDur    time.Duration
+	maxDur    time.Duration
+	scope     map[string]bool // nil 表示全部片段
+	segments  []types.SubtitleSegment
+	changes   []types.AutoFixChange
+	metrics   *textmetrics.Calculator
+}
+
+// Apply 依次执行拆分、重叠、间隔、延长、合并与断行修复，返回修复后的片段（输入不会被修改）与修复记录
+func Apply(segments []types.SubtitleSegment, opts types.AutoFixOptions, frameRate float64) ([]types.SubtitleSegment, []types.AutoFixChange) {
+	opts = WithDefaults(opts)
+	f := &fixer{
+		opts:      opts,
+		frameRate: frameRate,
+		minGap:    time.Duration(math.Round(float64(opts.MinGapFrames) / frameRate * float64(time.Second))),
+		minDur:    time.Duration(opts.MinDurationMs) * time.Millisecond,
+		maxDur:    time.Duration(opts.MaxDurationMs) * time.Millisecond,
+		segments:  slices.Clone(segments),
+		changes:   []types.AutoFixChange{},
+		metrics:   textmetrics.NewCalculator(),
+	}
+	if len(opts.SegmentIDs) > 0 {
+		f.scope = make(map[string]bool, len(opts.SegmentIDs))
+		for _, id := range opts.SegmentIDs {
+			f.scope[id] = true
+		}
+	}
+
+	for _, step := range []struct {
+		fix types.AutoFixType
+		run func()
+	}{
+		{types.AutoFixSplit, f.splitLong},
+		{types.AutoFixOverlap, f.resolveOverlaps},
+		{types.AutoFixGap, f.enforceGaps},
+		{types.AutoFixExtend, f.extendShort},
+		{types.AutoFixMerge, f.mergeShort},
+		{types.AutoFixReflow, f.reflow},
+	} {
+		if len(opts.Fixes) == 0 || slices.Contains(opts.Fixes, step.fix) {
+			step.run()
+		}
+	}
+	return f.segments, f.changes
+}
+
+func (f *fixer) inScope(i int) bool {
+	return f.scope == nil || f.scope[f.segments[i].ID]
+}
+
+// splitLong 将超过最长时长的字幕等分为若干段，文本按比例切分
+func (f *fixer) splitLong() {
+	for i := 0; i < len(f.segments); i++ {
+		segment := f.segments[i]
+		duration := segment.EndTime.Time - segment.StartTime.Time
+		if !f.inScope(i) || duration <= f.maxDur {
+			continue
+		}
+		n := int(math.Ceil(float64(duration) / float64(f.maxDur)))
+		parts := make([]types.SubtitleSegment, n)
+		for k := range parts {
+			parts[k] = f.derive(segment)
+			parts[k].StartTime = f.timecode(segment.StartTime.Time + duration*time.Duration(k)/time.Duration(n))
+			parts[k].EndTime = f.timecode(segment.StartTime.Time + duration*time.Duration(k+1)/time.Duration(n))
+		}
+		texts := make(map[string]types.TextChange)
+		for lang, content := range segment.Languages {
+			pieces := splitText(content.Text, n)
+			for k, piece := range pieces {
+				parts[k].Languages[lang] = derivedContent(content, piece)
+			}
+			texts[lang] = types.TextChange{Before: content.Text, After: strings.Join(pieces, partSeparator)}
+		}
+
+		change := f.change(types.AutoFixSplit, segment, segment.StartTime.Time, segment.EndTime.Time)
+		change.Texts = texts
+		for _, part := range parts {
+			change.ResultIDs = append(change.ResultIDs, part.ID)
+			f.addScope(part.ID)
+		}
+		f.changes = append(f.changes, change)
+		f.segments = slices.Replace(f.segments, i, i+1, parts...)
+		i += n - 1
+	}
+}
+
+// resolveOverlaps 优先提前前一条的结束时间，不够时推迟后一条的开始时间
+func (f *fixer) resolveOverlaps() {
+	for i := 0; i+1 < len(f.segments); i++ {
+		if !f.inScope(i) && !f.inScope(i+1) {
+			continue
+		}
+		a, b := &f.segments[i], &f.segments[i+1]
+		if b.StartTime.Time >= a.EndTime.Time {
+			continue
+		}
+		if end := b.StartTime.Time - f.minGap; end > a.StartTime.Time {
+			f.changes = append(f.changes, f.change(types.AutoFixOverlap, *a, a.StartTime.Time, end))
+			a.EndTime = f.timecode(end)
+		} else if start := a.EndTime.Time + f.minGap; start < b.EndTime.Time {
+			f.changes = append(f.changes, f.change(types.AutoFixOverlap, *b, start, b.EndTime.Time))
+			b.StartTime = f.timecode(start)
+		} else {
+			change := f.change(types.AutoFixOverlap, *a, a.StartTime.Time, a.EndTime.Time)
+			change.Unresolved = true
+			f.changes = append(f.changes, change)
+		}
+	}
+}
+
+// enforceGaps 间隔小于最小帧数时提前前一条的结束时间
+func (f *fixer) enforceGaps() {
+	for i := 0; i+1 < len(f.segments); i++ {
+		if !f.inScope(i) && !f.inScope(i+1) {
+			continue
+		}
+		a, b := &f.segments[i], &f.segments[i+1]
+		gap := b.StartTime.Time - a.EndTime.Time
+		if gap < 0 || gap >= f.minGap {
+			continue
+		}
+		end := b.StartTime.Time - f.minGap
+		change := f.change(types.AutoFixGap, *a, a.StartTime.Time, end)
+		if end <= a.StartTime.Time {
+			change.ToEndMs, change.Unresolved = change.FromEndMs, true
+		} else {
+			a.EndTime = f.timecode(end)
+		}
+		f.changes = append(f.changes, change)
+	}
+}
+
+// extendShort 先向后延长，空间不足时再向前提前开始时间，均保留与相邻字幕的最小间隔
+func (f *fixer) extendShort() {
+	for i := range f.segments {
+		segment := &f.segments[i]
+		start, end := segment.StartTime.Time, segment.EndTime.Time
+		if !f.inScope(i) || end-start >= f.minDur {
+			continue
+		}
+		if limit := f.nextLimit(i); start+f.minDur <= limit {
+			end = start + f.minDur
+		} else {
+			end = max(end, limit)
+		}
+		if end-start < f.minDur {
+			start = min(start, max(end-f.minDur, f.prevLimit(i)))
+		}
+
+		// 仍然过短的标记为未解决，开启合并时会在下一步尝试与相邻字幕合并
+		change := f.change(types.AutoFixExtend, *segment, start, end)
+		change.Unresolved = end-start < f.minDur
+		segment.StartTime, segment.EndTime = f.timecode(start), f.timecode(end)
+		f.changes = append(f.changes, change)
+	}
+}
+
+// mergeShort 将仍短于最短时长的字幕与间隔更小的相邻字幕合并（合并后不得超过最长时长）
+func (f *fixer) mergeShort() {
+	for i := 0; i < len(f.segments); i++ {
+		segment := f.segments[i]
+		if !f.inScope(i) || segment.EndTime.Time-segment.StartTime.Time >= f.minDur {
+			continue
+		}
+		target, bestGap := -1, time.Duration(f.opts.MergeMaxGapMs)*time.Millisecond
+		for _, j := range []int{i - 1, i + 1} {
+			if j < 0 || j >= len(f.segments) {
+				continue
+			}
+			first, last := f.segments[min(i, j)], f.segments[max(i, j)]
+			gap := last.StartTime.Time - first.EndTime.Time
+			if gap <= bestGap && last.EndTime.Time-first.StartTime.Time <= f.maxDur {
+				target, bestGap = j, gap
+			}
+		}
+		if target < 0 {
+			continue
+		}
+
+		from := min(i, target)
+		first, last := f.segments[from], f.segments[from+1]
+		merged := f.derive(first)
+		merged.EndTime = last.EndTime
+		if merged.Speaker == "" {
+			merged.Speaker = last.Speaker
+		}
+		for lang, standard := range last.GuidelineStandard {
+			if _, ok := merged.GuidelineStandard[lang]; !ok {
+				merged.GuidelineStandard[lang] = standard
+			}
+		}
+		texts := make(map[string]types.TextChange)
+		for _, lang := range languages(first, last) {
+			base, ok := first.Languages[lang]
+			if !ok {
+				base = last.Languages[lang]
+			}
+			text := segmenttext.Join(first.Languages[lang].Text, last.Languages[lang].Text)
+			merged.Languages[lang] = derivedContent(base, text)
+			texts[lang] = types.TextChange{
+				Before: first.Languages[lang].Text + partSeparator + last.Languages[lang].Text,
+				After:  text,
+			}
+		}
+
+		change := f.change(types.AutoFixMerge, first, first.StartTime.Time, last.EndTime.Time)
+		change.SegmentIDs = append(change.SegmentIDs, last.ID)
+		change.FromEndMs = last.EndTime.Time.Milliseconds()
+		change.ResultIDs = []string{merged.ID}
+		change.Texts = texts
+		f.changes = append(f.changes, change)
+		f.addScope(merged.ID)
+		f.segments = slices.Replace(f.segments, from, from+2, merged)
+		// 合并后的字幕可能仍然过短，从其位置重新检查
+		i = from - 1
+	}
+}
+
+// reflow 对超出行数或每行字符数的文本重新断行
+func (f *fixer) reflow() {
+	for i := range f.segments {
+		if !f.inScope(i) {
+			continue
+		}
+		segment := &f.segments[i]
+		change := f.change(types.AutoFixReflow, *segment, segment.StartTime.Time, segment.EndTime.Time)
+		for _, lang := range languages(*segment) {
+			content := segment.Languages[lang]
+			maxCPL := f.opts.MaxCPL
+			if f.metrics.IsPrimarilyIdeographic(content.Text) {
+				maxCPL = f.opts.MaxCPLIdeographic
+			}
+			if fitsLayout(content.Text, f.opts.MaxLines, maxCPL) {
+				continue
+			}
+			text, fits := Reflow(content.Text, f.opts.MaxLines, maxCPL)
+			change.Unresolved = change.Unresolved || !fits
+			if text == content.Text {
+				continue
+			}
+			if change.Texts == nil {
+				change.Texts = make(map[string]types.TextChange)
+				segment.Languages = maps.Clone(segment.Languages)
+			}
+			change.Texts[lang] = types.TextChange{Before: content.Text, After: text}
+			content.Text = text
+			segment.Languages[lang] = content
+		}
+		if change.Texts != nil || change.Unresolved {
+			f.changes = append(f.changes, change)
+		}
+	}
+}
+
+// nextLimit 向后延长的上限：下一条开始前保留最小间隔
+func (f *fixer) nextLimit(i int) time.Duration {
+	if i+1 >= len(f.segments) {
+		return time.Duration(math.MaxInt64)
+	}
+	return f.segments[i+1].StartTime.Time - f.minGap
+}
+
+// prevLimit 向前提前的下限：上一条结束后保留最小间隔
+func (f *fixer) prevLimit(i int) time.Duration {
+	if i == 0 {
+		return 0
+	}
+	return f.segments[i-1].EndTime.Time + f.minGap
+}
+
+func (f *fixer) addScope(id string) {
+	if f.scope != nil {
+		f.scope[id] = true
+	}
+}
+
+func (f *fixer) timecode(d time.Duration) types.Timecode {
+	return types.NewTimecode(d, f.frameRate)
+}
+
+func (f *fixer) change(fix types.AutoFixType, segment types.SubtitleSegment, start, end time.Duration) types.AutoFixChange {
+	return types.AutoFixChange{
+		Type:        fix,
+		SegmentIDs:  []string{segment.ID},
+		FromStartMs: segment.StartTime.Time.Milliseconds(),
+		FromEndMs:   segment.EndTime.Time.Milliseconds(),
+		ToStartMs:   start.Milliseconds(),
+		ToEndMs:     end.Milliseconds(),
+	}
+}
+
+// derive 复制片段属性并分配新 ID，语言内容由调用方填写
+func (f *fixer) derive(segment types.SubtitleSegment) types.SubtitleSegment {
+	segment.ID = uuid.New().String()
+	segment.Languages = make(map[string]types.LanguageContent, len(segment.Languages))
+	segment.GuidelineStandard = maps.Clone(segment.GuidelineStandard)
+	if segment.GuidelineStandard == nil {
+		segment.GuidelineStandard = make(map[string]types.GuideLineStandard)
+	}
+	return segment
+}
+
+// derivedContent 保留样式与区域，审核状态等随文本变化失效
+func derivedContent(base types.LanguageContent, text string) types.LanguageContent {
+	return types.LanguageContent{Text: text, Style: base.Style, StyleID: base.StyleID, RegionID: base.RegionID}
+}
+
+// splitText 将文本按比例切成 n 段
+func splitText(text string, n int) []string {
+	pieces := make([]string, n)
+	rest := text
+	for k := 0; k < n-1; k++ {
+		pieces[k], rest = segmenttext.SplitProportional(rest, 1/float64(n-k))
+	}
+	pieces[n-1] = rest
+	return pieces
+}
+
+func fitsLayout(text string, maxLines, maxCPL int) bool {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) > maxLines {
+		return false
+	}
+	for _, line := range lines {
+		if len([]rune(line)) > maxCPL {
+			return false
+		}
+	}
+	return true
+}
+
+func languages(segments ...types.SubtitleSegment) []string {
+	set := make(map[string]bool)
+	for _, segment := range segments {
+		for lang := range segment.Languages {
+			set[lang] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(set))
+}
@@ -0,0 +1,119 @@
+package autofix
+
+import (
+	"CanMe/backend/types"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seg(id string, startMs, endMs int, text string) types.SubtitleSegment {
+	return types.SubtitleSegment{
+		ID:        id,
+		StartTime: types.Timecode{Time: time.Duration(startMs) * time.Millisecond},
+		EndTime:   types.Timecode{Time: time.Duration(endMs) * time.Millisecond},
+		Languages: map[string]types.LanguageContent{"en": {Text: text}},
+	}
+}
+
+func ms(segment types.SubtitleSegment) (int64, int64) {
+	return segment.StartTime.Time.Milliseconds(), segment.EndTime.Time.Milliseconds()
+}
+
+func only(fixes ...types.AutoFixType) types.AutoFixOptions {
+	return types.AutoFixOptions{Fixes: fixes}
+}
+
+func TestReflow(t *testing.T) {
+	text, fits := Reflow("I never wanted to come back here, but here I am again", 2, 42)
+	assert.True(t, fits)
+	assert.Equal(t, "I never wanted to come back here,\nbut here I am again", text)
+
+	text, fits = Reflow("Short\nline", 2, 42)
+	assert.True(t, fits)
+	assert.Equal(t, "Short line", text)
+
+	text, fits = Reflow("我从来没有想过，要再回到这个让我伤心的地方来", 2, 16)
+	assert.True(t, fits)
+	assert.Equal(t, "我从来没有想过，\n要再回到这个让我伤心的地方来", text)
+
+	_, fits = Reflow(strings.Repeat("word ", 30), 2, 42)
+	assert.False(t, fits)
+}
+
+func TestOverlapAndGap(t *testing.T) {
+	segments := []types.SubtitleSegment{
+		seg("a", 0, 2100, "one"),
+		seg("b", 2000, 4000, "two"),
+		seg("c", 4010, 6000, "three"),
+	}
+	fixed, changes := Apply(segments, only(types.AutoFixOverlap, types.AutoFixGap), 25)
+	require.Len(t, changes, 2)
+	// 2 帧 @25fps = 80ms
+	_, end := ms(fixed[0])
+	assert.EqualValues(t, 1920, end)
+	_, end = ms(fixed[1])
+	assert.EqualValues(t, 3930, end)
+	assert.Equal(t, types.AutoFixOverlap, changes[0].Type)
+	assert.Equal(t, types.AutoFixGap, changes[1].Type)
+
+	// 输入不被修改
+	_, end = ms(segments[0])
+	assert.EqualValues(t, 2100, end)
+}
+
+func TestExtendAndMerge(t *testing.T) {
+	segments := []types.SubtitleSegment{
+		seg("a", 0, 300, "Hi"),
+		seg("b", 5000, 5300, "Oh"),
+		seg("c", 5340, 6000, "no"),
+		seg("d", 6080, 8000, "Fine"),
+	}
+	fixed, changes := Apply(segments, only(types.AutoFixExtend, types.AutoFixMerge), 25)
+	require.Len(t, fixed, 3)
+
+	start, end := ms(fixed[0])
+	assert.EqualValues(t, 0, start)
+	assert.EqualValues(t, 833, end)
+
+	// b 前面有空间，向前提前开始时间；c 无法延长，与 b 合并
+	start, end = ms(fixed[1])
+	assert.EqualValues(t, 4467, start)
+	assert.EqualValues(t, 6000, end)
+	assert.Equal(t, "Oh\nno", fixed[1].Languages["en"].Text)
+
+	last := changes[len(changes)-1]
+	assert.Equal(t, types.AutoFixMerge, last.Type)
+	assert.Equal(t, []string{"b", "c"}, last.SegmentIDs)
+	assert.Equal(t, fixed[1].ID, last.ResultIDs[0])
+}
+
+func TestSplitLong(t *testing.T) {
+	segments := []types.SubtitleSegment{seg("a", 0, 10000, "The first half of it. And the second half")}
+	fixed, changes := Apply(segments, only(types.AutoFixSplit), 25)
+	require.Len(t, fixed, 2)
+	_, end := ms(fixed[0])
+	start, _ := ms(fixed[1])
+	assert.EqualValues(t, 5000, end)
+	assert.EqualValues(t, 5000, start)
+	assert.Equal(t, "The first half of it.", fixed[0].Languages["en"].Text)
+	assert.Equal(t, "And the second half", fixed[1].Languages["en"].Text)
+	require.Len(t, changes, 1)
+	assert.Len(t, changes[0].ResultIDs, 2)
+}
+
+func TestScope(t *testing.T) {
+	long := "This line is definitely much longer than forty-two characters in total"
+	segments := []types.SubtitleSegment{seg("a", 0, 3000, long), seg("b", 4000, 7000, long)}
+	opts := only(types.AutoFixReflow)
+	opts.SegmentIDs = []string{"b"}
+	fixed, changes := Apply(segments, opts, 25)
+	require.Len(t, changes, 1)
+	assert.Equal(t, long, fixed[0].Languages["en"].Text)
+	assert.Contains(t, fixed[1].Languages["en"].Text, "\n")
+	// 原片段的 Languages 未被修改
+	assert.Equal(t, long, segments[1].Languages["en"].Text)
+}
@@ -0,0 +1,122 @@
+package autofix
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 超出每行字符数的代价，远大于任何平衡性代价
+const overflowPenalty = 1_000_000
+
+// Reflow 将文本重新排成不超过 maxLines 行、每行不超过 maxCPL 个字符（含空格），
+// 在满足限制的前提下使用最少的行数，并尽量让各行长度均衡、在标点后断行。
+// 返回排好的文本以及是否满足限制
+func Reflow(text string, maxLines, maxCPL int) (string, bool) {
+	tokens, sep := tokenize(text)
+	if len(tokens) == 0 {
+		return "", true
+	}
+	if width(tokens, sep) <= maxCPL || maxLines <= 1 || len(tokens) == 1 {
+		return strings.Join(tokens, sep), width(tokens, sep) <= maxCPL
+	}
+
+	var best []int
+	for lines := 2; lines <= min(maxLines, len(tokens)); lines++ {
+		breaks, cost := layout(tokens, sep, lines, maxCPL)
+		best = breaks
+		if cost < overflowPenalty {
+			break
+		}
+	}
+	var out []string
+	var fits = true
+	start := 0
+	for _, end := range append(best, len(tokens)) {
+		line := tokens[start:end]
+		fits = fits && width(line, sep) <= maxCPL
+		out = append(out, strings.Join(line, sep))
+		start = end
+	}
+	return strings.Join(out, "\n"), fits
+}
+
+// tokenize 有空格的文本按词切分，无空格的中日文文本按字符切分
+func tokenize(text string) ([]string, string) {
+	text = strings.TrimSpace(text)
+	spaced := strings.ContainsFunc(text, func(r rune) bool { return r != '\n' && unicode.IsSpace(r) })
+	ideographic := strings.ContainsFunc(text, func(r rune) bool {
+		return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+	})
+	if spaced || !ideographic {
+		return strings.Fields(text), " "
+	}
+	var tokens []string
+	for _, r := range strings.ReplaceAll(text, "\n", "") {
+		tokens = append(tokens, string(r))
+	}
+	return tokens, ""
+}
+
+func width(tokens []string, sep string) int {
+	n := 0
+	for _, token := range tokens {
+		n += utf8.RuneCountInString(token)
+	}
+	return n + utf8.RuneCountInString(sep)*max(len(tokens)-1, 0)
+}
+
+// layout 用动态规划把 tokens 排成恰好 lines 行，返回每行的结束下标（不含最后一行）与总代价
+func layout(tokens []string, sep string, lines, maxCPL int) ([]int, float64) {
+	n := len(tokens)
+	// cost[k][i]：前 i 个 token 排成 k 行的最小代价；from 记录上一行的结束位置
+	cost := make([][]float64, lines+1)
+	from := make([][]int, lines+1)
+	for k := range cost {
+		cost[k] = make([]float64, n+1)
+		from[k] = make([]int, n+1)
+		for i := range cost[k] {
+			cost[k][i] = math.Inf(1)
+		}
+	}
+	cost[0][0] = 0
+	for k := 1; k <= lines; k++ {
+		for i := k; i <= n; i++ {
+			for j := k - 1; j < i; j++ {
+				if math.IsInf(cost[k-1][j], 1) {
+					continue
+				}
+				c := cost[k-1][j] + lineCost(tokens, sep, j, i, maxCPL)
+				if c < cost[k][i] {
+					cost[k][i], from[k][i] = c, j
+				}
+			}
+		}
+	}
+	breaks := make([]int, lines-1)
+	for k, i := lines, n; k > 1; k-- {
+		i = from[k][i]
+		breaks[k-2] = i
+	}
+	return breaks, cost[lines][n]
+}
+
+// lineCost 行长的平方使各行趋于均衡；在标点后断行有奖励，以标点开头的行有惩罚
+func lineCost(tokens []string, sep string, start, end, maxCPL int) float64 {
+	w := width(tokens[start:end], sep)
+	c := float64(w * w)
+	if w > maxCPL {
+		c += overflowPenalty * float64(w-maxCPL)
+	}
+	bonus := float64(4 * maxCPL)
+	if end < len(tokens) {
+		if last, _ := utf8.DecodeLastRuneInString(tokens[end-1]); unicode.IsPunct(last) {
+			c -= bonus
+		}
+	}
+	if first, _ := utf8.DecodeRuneInString(tokens[start]); start > 0 && unicode.IsPunct(first) {
+		c += bonus
+	}
+	return c
+}
@@ -18,4 +18,6 @@ type CookieManager interface {
 	GetCookiesByDomain(browser, domain string) ([]*http.Cookie, error)
 	// GetNetscapeCookiesByDomain returns a Netscape-formatted string of cookies for a given domain from a specific browser.
 	GetNetscapeCookiesByDomain(browser, domain string) (string, error)
+	// ImportNetscapeCookies saves the Netscape-formatted cookies that apply to host under the given browser name and returns how many were saved.
+	ImportNetscapeCookies(browser, host, netscapeData string) (int, error)
 }
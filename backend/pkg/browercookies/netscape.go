@@ -0,0 +1,65 @@
+package browercookies
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Netscape Cookie 文件中 HttpOnly 行的前缀
+const httpOnlyPrefix = "#HttpOnly_"
+
+// 超出 Go time JSON 序列化范围（年份 [0,9999]）的过期时间按会话 Cookie 处理
+const maxCookieExpiration = int64(253402300799) // 9999-12-31 23:59:59 UTC
+
+// parseNetscapeCookies 解析 Netscape 格式的 Cookie 内容，跳过注释与格式不正确的行
+func parseNetscapeCookies(data string) []*http.Cookie {
+	var cookies []*http.Cookie
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		httpOnly := false
+		if strings.HasPrefix(line, httpOnlyPrefix) {
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+			httpOnly = true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			continue
+		}
+		domain := strings.TrimSpace(fields[0])
+		name := strings.TrimSpace(fields[5])
+		if domain == "" || name == "" {
+			continue
+		}
+
+		cookie := &http.Cookie{
+			Name:     name,
+			Value:    fields[6],
+			Domain:   domain,
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+		}
+		if expiration, err := strconv.ParseInt(strings.TrimSpace(fields[4]), 10, 64); err == nil &&
+			expiration > 0 && expiration <= maxCookieExpiration {
+			cookie.Expires = time.Unix(expiration, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies
+}
+
+// cookieMatchesHost 判断 Cookie 的域名是否作用于 host（自身或其父域名）
+func cookieMatchesHost(cookieDomain, host string) bool {
+	d := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(cookieDomain), "."))
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+	if d == "" || host == "" {
+		return false
+	}
+	return host == d || strings.HasSuffix(host, "."+d)
+}
@@ -0,0 +1,48 @@
+package browercookies
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetscapeCookies(t *testing.T) {
+	data := "# Netscape HTTP Cookie File\r\n" +
+		"\n" +
+		".youtube.com\tTRUE\t/\tTRUE\t1893456000\tSID\tabc\r\n" +
+		"#HttpOnly_.youtube.com\tTRUE\t/\tTRUE\t0\tHSID\tdef\n" +
+		"www.youtube.com\tFALSE\t/watch\tFALSE\t99999999999999\tPREF\tf1=1\n" +
+		"malformed line\n" +
+		"\tFALSE\t/\tFALSE\t0\tNODOMAIN\tx\n"
+
+	cookies := parseNetscapeCookies(data)
+	require.Len(t, cookies, 3)
+
+	assert.Equal(t, ".youtube.com", cookies[0].Domain)
+	assert.Equal(t, "SID", cookies[0].Name)
+	assert.True(t, cookies[0].Secure)
+	assert.Equal(t, int64(1893456000), cookies[0].Expires.Unix())
+
+	assert.Equal(t, "HSID", cookies[1].Name)
+	assert.True(t, cookies[1].HttpOnly)
+	assert.True(t, cookies[1].Expires.IsZero())
+
+	assert.Equal(t, "/watch", cookies[2].Path)
+	assert.False(t, cookies[2].Secure)
+	assert.True(t, cookies[2].Expires.IsZero(), "out of range expiration becomes a session cookie")
+
+	grouped := groupCookiesByDomain(cookies)
+	assert.Len(t, grouped[".youtube.com"].Cookies, 2)
+	assert.Len(t, grouped["www.youtube.com"].Cookies, 1)
+}
+
+func TestCookieMatchesHost(t *testing.T) {
+	assert.True(t, cookieMatchesHost(".youtube.com", "www.youtube.com"))
+	assert.True(t, cookieMatchesHost("youtube.com", "youtube.com"))
+	assert.True(t, cookieMatchesHost("WWW.YouTube.com", "www.youtube.com."))
+	assert.False(t, cookieMatchesHost(".google.com", "www.youtube.com"))
+	assert.False(t, cookieMatchesHost("tube.com", "www.youtube.com"))
+	assert.False(t, cookieMatchesHost("www.youtube.com", "youtube.com"))
+	assert.False(t, cookieMatchesHost("", "youtube.com"))
+}
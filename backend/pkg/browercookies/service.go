@@ -12,7 +12,6 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
 	"time"
 
@@ -246,6 +245,52 @@ func (c *cookieManager) GetNetscapeCookiesByDomain(browser, domain string) (stri
 	return c.convertToNetscape(cookies), nil
 }
 
+// ImportNetscapeCookies saves cookies sent from outside (e.g. the browser bridge) under a synthetic browser.
+// Only cookies that apply to host are kept; domains present in the import replace the cached ones.
+func (c *cookieManager) ImportNetscapeCookies(browser, host, netscapeData string) (int, error) {
+	if c.storage == nil {
+		return 0, errors.New("storage is not initialized")
+	}
+	if browser == "" {
+		return 0, errors.New("browser must be specified")
+	}
+	if host == "" {
+		return 0, errors.New("domain must be specified")
+	}
+
+	var cookies []*http.Cookie
+	for _, cookie := range parseNetscapeCookies(netscapeData) {
+		if cookieMatchesHost(cookie.Domain, host) {
+			cookies = append(cookies, cookie)
+		}
+	}
+	if len(cookies) == 0 {
+		return 0, fmt.Errorf("no cookies for %s", host)
+	}
+
+	stored, err := c.storage.GetCookies(browser)
+	if err != nil || stored == nil {
+		stored = &types.BrowserCookies{Browser: browser, SyncFrom: []string{browser}}
+	}
+	if stored.DomainCookies == nil {
+		stored.DomainCookies = make(map[string]*types.DomainCookies)
+	}
+	for domain, dc := range groupCookiesByDomain(cookies) {
+		stored.DomainCookies[domain] = dc
+	}
+	stored.Status = "synced"
+	stored.StatusDescription = "cookies synced"
+	stored.LastSyncFrom = browser
+	stored.LastSyncTime = time.Now()
+	stored.LastSyncStatus = "success"
+
+	if err := c.storage.SaveCookies(browser, stored); err != nil {
+		return 0, err
+	}
+	logger.Debug("cookies imported", zap.String("browser", browser), zap.String("host", host), zap.Int("count", len(cookies)))
+	return len(cookies), nil
+}
+
 // readAllBrowserCookies reads all cookies from all supported browsers and groups them by browser name.
 // canme sync removed
 
@@ -609,80 +654,27 @@ func (c *cookieManager) convertToNetscape(cookies []*http.Cookie) string {
 	return b.String()
 }
 
-// convertFromNetscape parses a Netscape cookie format string and returns []*http.Cookie
+// convertFromNetscape reads a Netscape cookie file and groups its cookies by domain
 func (c *cookieManager) convertFromNetscape(netscapeData string) (map[string]*types.DomainCookies, error) {
 	byte, err := os.ReadFile(netscapeData)
 	if err != nil {
 		return nil, err
 	}
+	return groupCookiesByDomain(parseNetscapeCookies(string(byte))), nil
+}
 
+func groupCookiesByDomain(cookies []*http.Cookie) map[string]*types.DomainCookies {
 	domainCookies := make(map[string]*types.DomainCookies)
-	lines := strings.Split(string(byte), "\n")
-
-	for _, line := range lines {
-		// Skip empty lines and comments
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Split by tab characters
-		fields := strings.Split(line, "\t")
-		if len(fields) != 7 {
-			continue // Skip malformed lines
-		}
-
-		domain := fields[0]
-		// flag := fields[1]
-		path := fields[2]
-		secure := fields[3]
-		expirationStr := fields[4]
-		name := fields[5]
-		value := fields[6]
-
-		// Create http.Cookie
-		cookie := &http.Cookie{
-			Name:   name,
-			Value:  value,
-			Domain: domain,
-			Path:   path,
-		}
-
-		// Set secure flag
-		if secure == "TRUE" {
-			cookie.Secure = true
-		}
-
-		// Parse expiration time
-		if expirationStr != "0" {
-			if expiration, err := strconv.ParseInt(expirationStr, 10, 64); err == nil {
-				// 验证时间戳范围，避免超出 Go 时间范围
-				// Unix 时间戳范围：1970-01-01 到 2038-01-19 (32位) 或更大范围 (64位)
-				// 但 Go 的 time.Time JSON 序列化要求年份在 [0,9999] 范围内
-				minTimestamp := int64(0)            // 1970-01-01
-				maxTimestamp := int64(253402300799) // 9999-12-31 23:59:59 UTC
-
-				if expiration >= minTimestamp && expiration <= maxTimestamp {
-					cookie.Expires = time.Unix(expiration, 0)
-				} else {
-					// 可设置为零值（永不过期）
-					cookie.Expires = time.Time{} // 零值表示会话 cookie
-				}
-			}
-		}
-
-		if domainCookies[domain] == nil {
-			domainCookies[domain] = &types.DomainCookies{
-				Domain:  domain,
+	for _, cookie := range cookies {
+		if domainCookies[cookie.Domain] == nil {
+			domainCookies[cookie.Domain] = &types.DomainCookies{
+				Domain:  cookie.Domain,
 				Cookies: []*http.Cookie{},
 			}
-		} else {
-			domainCookies[domain].Cookies = append(domainCookies[domain].Cookies, cookie)
 		}
-
+		domainCookies[cookie.Domain].Cookies = append(domainCookies[cookie.Domain].Cookies, cookie)
 	}
-
-	return domainCookies, nil
+	return domainCookies
 }
 
 func (c *cookieManager) generateDomainLookups(hostname string) []string {
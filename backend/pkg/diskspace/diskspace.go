@@ -0,0 +1,40 @@
+package diskspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Available 返回 path 所在卷上当前用户可用的字节数。
+// path 不存在时向上查找最近的已存在目录（例如尚未创建的下载子目录）。
+func Available(path string) (uint64, error) {
+	dir, err := existingDir(path)
+	if err != nil {
+		return 0, err
+	}
+	return available(dir)
+}
+
+func existingDir(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("path is empty")
+	}
+	p, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if info, err := os.Stat(p); err == nil {
+			if info.IsDir() {
+				return p, nil
+			}
+			return filepath.Dir(p), nil
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return "", fmt.Errorf("no existing directory for path: %s", path)
+		}
+		p = parent
+	}
+}
@@ -0,0 +1,35 @@
+package diskspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvailable(t *testing.T) {
+	dir := t.TempDir()
+
+	avail, err := Available(dir)
+	require.NoError(t, err)
+	assert.Greater(t, avail, uint64(0))
+
+	// 不存在的子目录回退到最近的已存在目录
+	missing := filepath.Join(dir, "not", "created", "yet")
+	availMissing, err := Available(missing)
+	require.NoError(t, err)
+	assert.Greater(t, availMissing, uint64(0))
+
+	// 文件路径使用其所在目录
+	file := filepath.Join(dir, "file.bin")
+	require.NoError(t, os.WriteFile(file, []byte("x"), 0o644))
+	_, err = Available(file)
+	assert.NoError(t, err)
+}
+
+func TestAvailableEmptyPath(t *testing.T) {
+	_, err := Available("")
+	assert.Error(t, err)
+}
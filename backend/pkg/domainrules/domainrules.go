@@ -0,0 +1,131 @@
+// Package domainrules 按 URL 的域名选择下载规则，并将格式预设转换为 yt-dlp 格式表达式。
+package domainrules
+
+import (
+	"CanMe/backend/types"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Host 返回 URL 的小写主机名（不含端口），无法解析时返回空字符串
+func Host(rawURL string) string {
+	raw := strings.TrimSpace(rawURL)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
+
+// NormalizePattern 规范化域名模式（允许用户直接粘贴 URL）
+func NormalizePattern(pattern string) string {
+	p := strings.ToLower(strings.TrimSpace(pattern))
+	wildcard := strings.HasPrefix(p, "*.")
+	p = strings.TrimPrefix(p, "*.")
+	if h := Host(p); h != "" {
+		p = h
+	}
+	if wildcard {
+		return "*." + p
+	}
+	return p
+}
+
+// Match 判断主机名是否匹配域名模式，返回匹配的具体程度（域名长度），未匹配返回 0
+func Match(host, pattern string) int {
+	host = strings.ToLower(host)
+	p := NormalizePattern(pattern)
+	if host == "" || p == "" || p == "*." {
+		return 0
+	}
+	if strings.HasPrefix(p, "*.") {
+		domain := p[2:]
+		if strings.HasSuffix(host, "."+domain) {
+			return len(domain)
+		}
+		return 0
+	}
+	if host == p || strings.HasSuffix(host, "."+p) {
+		return len(p)
+	}
+	return 0
+}
+
+// Select 返回匹配 URL 的启用规则：优先级高者优先，其次为更具体的域名，再次为列表顺序
+func Select(rules []*types.DtDownloadRule, rawURL string) *types.DtDownloadRule {
+	host := Host(rawURL)
+	if host == "" {
+		return nil
+	}
+
+	var best *types.DtDownloadRule
+	bestScore := 0
+	for _, r := range rules {
+		if r == nil || !r.Enabled {
+			continue
+		}
+		score := 0
+		for _, d := range r.Domains {
+			if m := Match(host, d); m > score {
+				score = m
+			}
+		}
+		if score == 0 {
+			continue
+		}
+		if best == nil || r.Priority > best.Priority || (r.Priority == best.Priority && score > bestScore) {
+			best, bestScore = r, score
+		}
+	}
+	return best
+}
+
+var heightPresets = map[string]int{
+	"2160p": 2160,
+	"1440p": 1440,
+	"1080p": 1080,
+	"720p":  720,
+	"480p":  480,
+	"360p":  360,
+}
+
+// FormatSelector 将格式预设转换为 yt-dlp 格式表达式；best 与空值返回空字符串（使用 yt-dlp 默认），
+// 未知预设按原样作为格式表达式
+func FormatSelector(preset string) string {
+	p := strings.ToLower(strings.TrimSpace(preset))
+	switch p {
+	case "", "best":
+		return ""
+	case "audio":
+		return "ba/b"
+	}
+	if h, ok := heightPresets[p]; ok {
+		return fmt.Sprintf("bv*[height<=%d]+ba/b[height<=%d]", h, h)
+	}
+	return strings.TrimSpace(preset)
+}
+
+// ValidateProxy 校验规则中的代理设置
+func ValidateProxy(proxy string) error {
+	proxy = strings.TrimSpace(proxy)
+	if proxy == "" || strings.EqualFold(proxy, types.DtProxyDirect) {
+		return nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid proxy: %s", proxy)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "socks4", "socks4a", "socks5", "socks5h":
+		return nil
+	default:
+		return fmt.Errorf("unsupported proxy scheme: %s", u.Scheme)
+	}
+}
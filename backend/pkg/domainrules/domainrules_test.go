@@ -0,0 +1,52 @@
+package domainrules
+
+import (
+	"CanMe/backend/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	assert.Equal(t, len("youtube.com"), Match("www.youtube.com", "youtube.com"))
+	assert.Equal(t, len("youtube.com"), Match("youtube.com", "YouTube.com"))
+	assert.Equal(t, len("www.youtube.com"), Match("www.youtube.com", "https://www.youtube.com/watch?v=x"))
+	assert.Zero(t, Match("m.youtube.com", "www.youtube.com"))
+	assert.Zero(t, Match("notyoutube.com", "youtube.com"))
+	assert.Zero(t, Match("bilibili.com", "*.bilibili.com"))
+	assert.NotZero(t, Match("www.bilibili.com", "*.bilibili.com"))
+}
+
+func TestSelect(t *testing.T) {
+	rules := []*types.DtDownloadRule{
+		{ID: "yt", Enabled: true, Domains: []string{"youtube.com", "youtu.be"}},
+		{ID: "music", Enabled: true, Domains: []string{"music.youtube.com"}},
+		{ID: "bili", Enabled: false, Domains: []string{"bilibili.com"}},
+		{ID: "any", Enabled: true, Domains: []string{"com"}, Priority: -1},
+	}
+
+	assert.Equal(t, "yt", Select(rules, "https://www.youtube.com/watch?v=abc").ID)
+	assert.Equal(t, "yt", Select(rules, "youtu.be/abc").ID)
+	assert.Equal(t, "music", Select(rules, "https://music.youtube.com/watch?v=abc").ID)
+	assert.Equal(t, "any", Select(rules, "https://www.bilibili.com/video/BV1").ID)
+	assert.Nil(t, Select(rules, "https://example.org/"))
+	assert.Nil(t, Select(rules, ""))
+
+	rules[3].Priority = 10
+	assert.Equal(t, "any", Select(rules, "https://www.youtube.com/watch?v=abc").ID)
+}
+
+func TestFormatSelector(t *testing.T) {
+	assert.Equal(t, "", FormatSelector("best"))
+	assert.Equal(t, "bv*[height<=1080]+ba/b[height<=1080]", FormatSelector("1080P"))
+	assert.Equal(t, "ba/b", FormatSelector("audio"))
+	assert.Equal(t, "137+140", FormatSelector(" 137+140 "))
+}
+
+func TestValidateProxy(t *testing.T) {
+	assert.NoError(t, ValidateProxy(""))
+	assert.NoError(t, ValidateProxy("direct"))
+	assert.NoError(t, ValidateProxy("socks5://127.0.0.1:1080"))
+	assert.Error(t, ValidateProxy("ftp://127.0.0.1:21"))
+	assert.Error(t, ValidateProxy("127.0.0.1"))
+}
@@ -0,0 +1,152 @@
+// Package edithistory 维护字幕项目的撤销/重做操作日志。
+package edithistory
+
+import (
+	"CanMe/backend/types"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrConflict 片段在记录之后被其他途径修改（如并发的编辑或保存失败），无法安全撤销/重做
+	ErrConflict = errors.New("segments have changed since this edit")
+)
+
+// Record 追加一次操作：丢弃尚未重做的操作，超过 limit 时删除最早的记录
+func Record(history *types.EditHistory, op types.EditOperation, limit int) {
+	history.Operations = append(history.Operations[:history.Cursor], op)
+	if limit > 0 && len(history.Operations) > limit {
+		history.Operations = slices.Clone(history.Operations[len(history.Operations)-limit:])
+	}
+	history.Cursor = len(history.Operations)
+}
+
+// Undo 撤销最近一次操作，返回新的片段列表与被撤销的操作；失败时 history 与 segments 均不变
+func Undo(history *types.EditHistory, segments []types.SubtitleSegment) ([]types.SubtitleSegment, *types.EditOperation, error) {
+	if history.Cursor <= 0 {
+		return nil, nil, ErrNothingToUndo
+	}
+	op := &history.Operations[history.Cursor-1]
+	result := slices.Clone(segments)
+	var err error
+	for i := len(op.Changes) - 1; i >= 0; i-- {
+		change := op.Changes[i]
+		if result, err = replace(result, change.Index, change.After, change.Before); err != nil {
+			return nil, nil, err
+		}
+	}
+	history.Cursor--
+	return result, op, nil
+}
+
+// Redo 重做最近一次撤销的操作
+func Redo(history *types.EditHistory, segments []types.SubtitleSegment) ([]types.SubtitleSegment, *types.EditOperation, error) {
+	if history.Cursor >= len(history.Operations) {
+		return nil, nil, ErrNothingToRedo
+	}
+	op := &history.Operations[history.Cursor]
+	result := slices.Clone(segments)
+	var err error
+	for _, change := range op.Changes {
+		if result, err = replace(result, change.Index, change.Before, change.After); err != nil {
+			return nil, nil, err
+		}
+	}
+	history.Cursor++
+	return result, op, nil
+}
+
+// Entries 按时间倒序列出操作
+func Entries(history *types.EditHistory) []types.EditHistoryEntry {
+	entries := make([]types.EditHistoryEntry, 0, len(history.Operations))
+	for i := len(history.Operations) - 1; i >= 0; i-- {
+		op := history.Operations[i]
+		entries = append(entries, types.EditHistoryEntry{
+			ID:         op.ID,
+			Type:       op.Type,
+			SegmentIDs: op.SegmentIDs,
+			LangCode:   op.LangCode,
+			CreatedAt:  op.CreatedAt,
+			Undone:     i >= history.Cursor,
+		})
+	}
+	return entries
+}
+
+// Snapshot 深拷贝片段（按 JSON 往返，与日志的持久化方式一致），用于在批量修改前保存原状态
+func Snapshot(segments []types.SubtitleSegment) []types.SubtitleSegment {
+	data, err := json.Marshal(segments)
+	if err != nil {
+		return slices.Clone(segments)
+	}
+	var out []types.SubtitleSegment
+	if err := json.Unmarshal(data, &out); err != nil {
+		return slices.Clone(segments)
+	}
+	return out
+}
+
+// Diff 返回把 before 变为 after 的替换：跳过相同的首尾片段，片段数不变时只记录连续的不同片段
+func Diff(before, after []types.SubtitleSegment) []types.SegmentChange {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && sameSegment(before[prefix], after[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		sameSegment(before[len(before)-1-suffix], after[len(after)-1-suffix]) {
+		suffix++
+	}
+	from, to := before[prefix:len(before)-suffix], after[prefix:len(after)-suffix]
+	if len(from) == 0 && len(to) == 0 {
+		return nil
+	}
+	if len(from) != len(to) {
+		return []types.SegmentChange{{Index: prefix, Before: slices.Clone(from), After: slices.Clone(to)}}
+	}
+
+	var changes []types.SegmentChange
+	for i := 0; i < len(from); {
+		if sameSegment(from[i], to[i]) {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(from) && !sameSegment(from[j], to[j]) {
+			j++
+		}
+		changes = append(changes, types.SegmentChange{Index: prefix + i, Before: slices.Clone(from[i:j]), After: slices.Clone(to[i:j])})
+		i = j
+	}
+	return changes
+}
+
+// replace 确认 index 处的片段与 expect 完全一致后替换为 with
+func replace(segments []types.SubtitleSegment, index int, expect, with []types.SubtitleSegment) ([]types.SubtitleSegment, error) {
+	if index < 0 || index+len(expect) > len(segments) {
+		return nil, ErrConflict
+	}
+	for i := range expect {
+		if !sameSegment(segments[index+i], expect[i]) {
+			return nil, ErrConflict
+		}
+	}
+	return slices.Replace(segments, index, index+len(expect), with...), nil
+}
+
+// sameSegment 按 JSON 序列化结果比较（map 键有序，指针字段按值比较）
+func sameSegment(a, b types.SubtitleSegment) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(left, right)
+}
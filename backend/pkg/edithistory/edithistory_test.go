@@ -0,0 +1,127 @@
+package edithistory
+
+import (
+	"CanMe/backend/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seg(id, text string) types.SubtitleSegment {
+	return types.SubtitleSegment{ID: id, Languages: map[string]types.LanguageContent{"en": {Text: text}}}
+}
+
+func ids(segments []types.SubtitleSegment) []string {
+	out := make([]string, len(segments))
+	for i, s := range segments {
+		out[i] = s.ID
+	}
+	return out
+}
+
+func TestUndoRedo(t *testing.T) {
+	original := []types.SubtitleSegment{seg("a", "one"), seg("b", "two"), seg("c", "three")}
+	history := &types.EditHistory{}
+
+	// 编辑 b 的文本
+	edited := seg("b", "TWO")
+	Record(history, types.EditOperation{ID: "1", Changes: []types.SegmentChange{
+		{Index: 1, Before: []types.SubtitleSegment{original[1]}, After: []types.SubtitleSegment{edited}},
+	}}, 0)
+	current := []types.SubtitleSegment{original[0], edited, original[2]}
+
+	// 删除 a 与 c（逆序记录）
+	Record(history, types.EditOperation{ID: "2", Changes: []types.SegmentChange{
+		{Index: 2, Before: []types.SubtitleSegment{original[2]}},
+		{Index: 0, Before: []types.SubtitleSegment{original[0]}},
+	}}, 0)
+	current = []types.SubtitleSegment{edited}
+
+	current, op, err := Undo(history, current)
+	require.NoError(t, err)
+	assert.Equal(t, "2", op.ID)
+	assert.Equal(t, []string{"a", "b", "c"}, ids(current))
+
+	current, _, err = Undo(history, current)
+	require.NoError(t, err)
+	assert.Equal(t, "two", current[1].Languages["en"].Text)
+
+	_, _, err = Undo(history, current)
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	current, _, err = Redo(history, current)
+	require.NoError(t, err)
+	assert.Equal(t, "TWO", current[1].Languages["en"].Text)
+
+	entries := Entries(history)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "2", entries[0].ID)
+	assert.True(t, entries[0].Undone)
+	assert.False(t, entries[1].Undone)
+
+	// 新操作丢弃可重做的记录
+	Record(history, types.EditOperation{ID: "3"}, 0)
+	assert.Len(t, history.Operations, 2)
+	_, _, err = Redo(history, current)
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+}
+
+func TestUndoConflict(t *testing.T) {
+	history := &types.EditHistory{}
+	Record(history, types.EditOperation{Changes: []types.SegmentChange{
+		{Index: 0, Before: []types.SubtitleSegment{seg("a", "old")}, After: []types.SubtitleSegment{seg("a", "new")}},
+	}}, 0)
+
+	_, _, err := Undo(history, []types.SubtitleSegment{seg("a", "changed elsewhere")})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, 1, history.Cursor)
+
+	_, _, err = Undo(history, nil)
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestRecordLimit(t *testing.T) {
+	history := &types.EditHistory{}
+	for _, id := range []string{"1", "2", "3", "4"} {
+		Record(history, types.EditOperation{ID: id}, 3)
+	}
+	require.Len(t, history.Operations, 3)
+	assert.Equal(t, "2", history.Operations[0].ID)
+	assert.Equal(t, 3, history.Cursor)
+}
+
+func TestDiff(t *testing.T) {
+	before := []types.SubtitleSegment{seg("a", "one"), seg("b", "two"), seg("c", "three"), seg("d", "four")}
+	assert.Empty(t, Diff(before, Snapshot(before)))
+
+	// 片段数不变时只记录连续的不同片段
+	after := Snapshot(before)
+	after[1].Languages["en"] = types.LanguageContent{Text: "TWO"}
+	after[3].Languages["en"] = types.LanguageContent{Text: "FOUR"}
+	changes := Diff(before, after)
+	require.Len(t, changes, 2)
+	assert.Equal(t, 1, changes[0].Index)
+	assert.Equal(t, []string{"b"}, ids(changes[0].Before))
+	assert.Equal(t, 3, changes[1].Index)
+
+	// Snapshot 与原片段互不影响
+	assert.Equal(t, "two", before[1].Languages["en"].Text)
+
+	history := &types.EditHistory{}
+	Record(history, types.EditOperation{Changes: changes}, 0)
+	undone, _, err := Undo(history, after)
+	require.NoError(t, err)
+	assert.Empty(t, Diff(before, undone))
+	redone, _, err := Redo(history, undone)
+	require.NoError(t, err)
+	assert.Empty(t, Diff(after, redone))
+
+	// 片段数变化时记录为一次替换
+	inserted := []types.SubtitleSegment{before[0], seg("x", "new"), before[1], before[2], before[3]}
+	changes = Diff(before, inserted)
+	require.Len(t, changes, 1)
+	assert.Equal(t, 1, changes[0].Index)
+	assert.Empty(t, changes[0].Before)
+	assert.Equal(t, []string{"x"}, ids(changes[0].After))
+}
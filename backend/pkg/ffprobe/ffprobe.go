@@ -0,0 +1,180 @@
+// Package ffprobe 调用 ffprobe 读取媒体文件的容器与流信息。
+package ffprobe
+
+import (
+	"CanMe/backend/types"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Stream ffprobe 输出中的单个流
+type Stream struct {
+	Index        int    `json:"index"`
+	CodecType    string `json:"codec_type"`
+	CodecName    string `json:"codec_name"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	AvgFrameRate string `json:"avg_frame_rate,omitempty"`
+	RFrameRate   string `json:"r_frame_rate,omitempty"`
+	BitRate      string `json:"bit_rate,omitempty"`
+	Duration     string `json:"duration,omitempty"`
+}
+
+// Format ffprobe 输出中的容器信息
+type Format struct {
+	Filename       string `json:"filename"`
+	FormatName     string `json:"format_name"`
+	FormatLongName string `json:"format_long_name"`
+	Duration       string `json:"duration"`
+	Size           string `json:"size"`
+	BitRate        string `json:"bit_rate"`
+}
+
+// Result ffprobe -show_format -show_streams 的 JSON 结果
+type Result struct {
+	Streams []Stream `json:"streams"`
+	Format  Format   `json:"format"`
+}
+
+// Locate 查找 ffprobe：优先使用托管 FFmpeg 同目录下的 ffprobe，其次为 PATH
+func Locate(ffmpegPath string) (string, error) {
+	name := "ffprobe"
+	if runtime.GOOS == "windows" {
+		name = "ffprobe.exe"
+	}
+	if ffmpegPath != "" {
+		candidate := filepath.Join(filepath.Dir(ffmpegPath), name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return exec.LookPath(name)
+}
+
+// Args 返回读取 file 所用的 ffprobe 参数
+func Args(file string) []string {
+	return []string{
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		file,
+	}
+}
+
+// Run 运行 ffprobe 并返回原始 JSON 输出；失败时错误包含 ffprobe 的错误输出
+func Run(ctx context.Context, execPath, file string) ([]byte, error) {
+	cmd := createHiddenCommand(ctx, execPath, Args(file)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("ffprobe failed: %s", msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Probe 运行 ffprobe 并解析结果；文件无法被解析时返回错误（包含 ffprobe 的错误输出）
+func Probe(ctx context.Context, execPath, file string) (*Result, error) {
+	out, err := Run(ctx, execPath, file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(out)
+}
+
+// Parse 解析 ffprobe 的 JSON 输出
+func Parse(data []byte) (*Result, error) {
+	var r Result
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("invalid ffprobe output: %w", err)
+	}
+	return &r, nil
+}
+
+// MediaInfo 汇总为任务上展示的媒体信息（取第一个视频/音频流）
+func (r *Result) MediaInfo() *types.DtMediaInfo {
+	info := &types.DtMediaInfo{
+		Container: r.Format.FormatName,
+		Duration:  parseFloat(r.Format.Duration),
+		Size:      parseInt(r.Format.Size),
+		Bitrate:   parseInt(r.Format.BitRate),
+	}
+	for _, s := range r.Streams {
+		switch s.CodecType {
+		case "video":
+			// 封面图（mjpeg/png 单帧）不计为视频流
+			if s.CodecName == "mjpeg" || s.CodecName == "png" {
+				continue
+			}
+			info.VideoStreams++
+			if info.VideoCodec == "" {
+				info.VideoCodec = s.CodecName
+				info.Width = s.Width
+				info.Height = s.Height
+				info.FPS = parseRate(s.AvgFrameRate)
+				if info.FPS == 0 {
+					info.FPS = parseRate(s.RFrameRate)
+				}
+				info.VideoBitrate = parseInt(s.BitRate)
+			}
+		case "audio":
+			info.AudioStreams++
+			if info.AudioCodec == "" {
+				info.AudioCodec = s.CodecName
+				info.AudioBitrate = parseInt(s.BitRate)
+			}
+		case "subtitle":
+			info.SubtitleStreams++
+		}
+	}
+	if info.Duration == 0 {
+		for _, s := range r.Streams {
+			if d := parseFloat(s.Duration); d > info.Duration {
+				info.Duration = d
+			}
+		}
+	}
+	return info
+}
+
+func parseFloat(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func parseInt(s string) int64 {
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// parseRate 解析 "30000/1001" 形式的帧率
+func parseRate(s string) float64 {
+	num, den, ok := strings.Cut(s, "/")
+	if !ok {
+		return parseFloat(s)
+	}
+	n, d := parseFloat(num), parseFloat(den)
+	if d == 0 {
+		return 0
+	}
+	return n / d
+}
@@ -0,0 +1,38 @@
+package ffprobe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `{
+  "streams": [
+    {"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "avg_frame_rate": "30000/1001", "bit_rate": "4500000"},
+    {"index": 1, "codec_type": "audio", "codec_name": "aac", "bit_rate": "128000"},
+    {"index": 2, "codec_type": "video", "codec_name": "mjpeg", "width": 320, "height": 180, "avg_frame_rate": "0/0"}
+  ],
+  "format": {"filename": "a.mp4", "format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "212.345", "size": "123456789", "bit_rate": "4650000"}
+}`
+
+func TestMediaInfo(t *testing.T) {
+	r, err := Parse([]byte(sample))
+	require.NoError(t, err)
+
+	info := r.MediaInfo()
+	assert.Equal(t, "h264", info.VideoCodec)
+	assert.Equal(t, "aac", info.AudioCodec)
+	assert.Equal(t, 1920, info.Width)
+	assert.Equal(t, 1080, info.Height)
+	assert.InDelta(t, 29.97, info.FPS, 0.01)
+	assert.InDelta(t, 212.345, info.Duration, 0.001)
+	assert.Equal(t, int64(4650000), info.Bitrate)
+	assert.Equal(t, 1, info.VideoStreams) // cover art is ignored
+	assert.Equal(t, 1, info.AudioStreams)
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse([]byte("not json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+// Package guideline 定义字幕规范（Netflix、BBC 等内置规范与自定义规范），
+// 按语言与儿童内容解析阅读速度、行长、时长等限制，并检查片段是否超出限制。
+package guideline
+
+import (
+	"CanMe/backend/pkg/textmetrics"
+	"CanMe/backend/types"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 导入时的语言名称与繁简转换生成的语言名称
+var languageNames = map[string]string{
+	"chinese (simplified)":  "zh",
+	"chinese (traditional)": "zh",
+	"chinese":               "zh",
+	"simplified":            "zh",
+	"traditional":           "zh",
+	"china":                 "zh",
+	"hongkong":              "zh",
+	"taiwan":                "zh",
+	"wikisimplified":        "zh",
+	"wikitraditional":       "zh",
+	"english":               "en",
+	"japanese":              "ja",
+	"korean":                "ko",
+	"russian":               "ru",
+	"arabic":                "ar",
+	"thai":                  "th",
+	"hindi":                 "hi",
+	"hebrew":                "he",
+	"greek":                 "el",
+	"french":                "fr",
+	"german":                "de",
+	"spanish":               "es",
+	"italian":               "it",
+	"portuguese":            "pt",
+	"dutch":                 "nl",
+	"polish":                "pl",
+	"czech":                 "cs",
+	"vietnamese":            "vi",
+}
+
+// LanguageCode 将项目中的语言键（English、zh-Hans、pt_BR 等）归一为规范使用的语言代码
+func LanguageCode(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if code, ok := languageNames[lang]; ok {
+		return code
+	}
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+// Resolve 计算某种语言的最终限制：语言规则覆盖默认规则，儿童内容再叠加各自的 Kids 覆盖值
+func Resolve(profile *types.GuidelineProfile, lang string, kids bool) types.GuidelineLimits {
+	if profile == nil {
+		return types.GuidelineLimits{}
+	}
+	limits := profile.Default.Limits
+	rule, hasRule := profile.Languages[LanguageCode(lang)]
+	if hasRule {
+		limits = overlay(limits, rule.Limits)
+	}
+	if kids {
+		if profile.Default.Kids != nil {
+			limits = overlay(limits, *profile.Default.Kids)
+		}
+		if hasRule && rule.Kids != nil {
+			limits = overlay(limits, *rule.Kids)
+		}
+	}
+	return limits
+}
+
+// overlay 用 top 中的非零字段覆盖 base
+func overlay(base, top types.GuidelineLimits) types.GuidelineLimits {
+	if top.MaxCPS > 0 {
+		base.MaxCPS = top.MaxCPS
+	}
+	if top.MaxWPM > 0 {
+		base.MaxWPM = top.MaxWPM
+	}
+	if top.MaxCPL > 0 {
+		base.MaxCPL = top.MaxCPL
+	}
+	if top.MaxLines > 0 {
+		base.MaxLines = top.MaxLines
+	}
+	if top.MinDurationMs > 0 {
+		base.MinDurationMs = top.MinDurationMs
+	}
+	if top.MaxDurationMs > 0 {
+		base.MaxDurationMs = top.MaxDurationMs
+	}
+	if top.MinGapFrames > 0 {
+		base.MinGapFrames = top.MinGapFrames
+	}
+	return base
+}
+
+// Validate 检查规范配置的取值
+func Validate(profile *types.GuidelineProfile) error {
+	if profile == nil {
+		return errors.New("guideline profile is nil")
+	}
+	if strings.TrimSpace(profile.Name) == "" {
+		return errors.New("guideline profile name is required")
+	}
+	check := func(scope string, limits *types.GuidelineLimits) error {
+		if limits == nil {
+			return nil
+		}
+		if limits.MaxCPS < 0 || limits.MaxWPM < 0 || limits.MaxCPL < 0 || limits.MaxLines < 0 ||
+			limits.MinDurationMs < 0 || limits.MaxDurationMs < 0 || limits.MinGapFrames < 0 {
+			return fmt.Errorf("%s: limits cannot be negative", scope)
+		}
+		if limits.MinDurationMs > 0 && limits.MaxDurationMs > 0 && limits.MinDurationMs > limits.MaxDurationMs {
+			return fmt.Errorf("%s: min duration exceeds max duration", scope)
+		}
+		return nil
+	}
+	if err := check("default", &profile.Default.Limits); err != nil {
+		return err
+	}
+	if err := check("default kids", profile.Default.Kids); err != nil {
+		return err
+	}
+	for lang, rule := range profile.Languages {
+		if err := check(lang, &rule.Limits); err != nil {
+			return err
+		}
+		if err := check(lang+" kids", rule.Kids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Metrics 一条字幕的度量
+type Metrics struct {
+	CPS   int
+	WPM   int
+	CPL   int
+	Lines int
+}
+
+var calculator = textmetrics.NewCalculator()
+
+// Measure 计算字幕文本的阅读速度、最长行字符数与行数；时长为 0 时阅读速度记为 0
+func Measure(text string, duration time.Duration) Metrics {
+	var m Metrics
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		m.Lines++
+		m.CPL = max(m.CPL, utf8.RuneCountInString(line))
+	}
+
+	seconds := duration.Seconds()
+	if seconds <= 0 {
+		return m
+	}
+	m.CPS = int(math.Round(float64(calculator.CountCharacters(text)) / seconds))
+	if calculator.IsPrimarilyIdeographic(text) {
+		// 表意文字：每条字幕约等于 1 个词，避免异常大值
+		m.WPM = int(math.Round(60.0 / seconds))
+	} else {
+		m.WPM = int(math.Round(float64(calculator.CountWords(text)) / seconds * 60.0))
+	}
+	return m
+}
+
+// MinGap 将最小间隔帧数换算为毫秒
+func MinGap(frames int, frameRate float64) int64 {
+	if frames <= 0 || frameRate <= 0 {
+		return 0
+	}
+	return int64(math.Round(float64(frames) / frameRate * 1000))
+}
+
+// Check 按限制检查 segments[i] 的 lang 语言；间隔与下一个片段比较。返回的违规项未填写 Profile
+func Check(limits types.GuidelineLimits, segments []types.SubtitleSegment, i int, lang string, frameRate float64) []types.GuidelineViolation {
+	segment := segments[i]
+	content, ok := segment.Languages[lang]
+	if !ok || strings.TrimSpace(content.Text) == "" {
+		return nil
+	}
+
+	var violations []types.GuidelineViolation
+	add := func(rule types.GuidelineRuleType, value, limit int64) {
+		violations = append(violations, types.GuidelineViolation{
+			SegmentID: segment.ID, LangCode: lang, Rule: rule, Value: value, Limit: limit,
+		})
+	}
+
+	duration := segment.EndTime.Time - segment.StartTime.Time
+	m := Measure(content.Text, duration)
+	if limits.MaxCPS > 0 && m.CPS > limits.MaxCPS {
+		add(types.GuidelineRuleCPS, int64(m.CPS), int64(limits.MaxCPS))
+	}
+	if limits.MaxWPM > 0 && m.WPM > limits.MaxWPM {
+		add(types.GuidelineRuleWPM, int64(m.WPM), int64(limits.MaxWPM))
+	}
+	if limits.MaxCPL > 0 && m.CPL > limits.MaxCPL {
+		add(types.GuidelineRuleCPL, int64(m.CPL), int64(limits.MaxCPL))
+	}
+	if limits.MaxLines > 0 && m.Lines > limits.MaxLines {
+		add(types.GuidelineRuleLines, int64(m.Lines), int64(limits.MaxLines))
+	}
+
+	ms := duration.Milliseconds()
+	if limits.MinDurationMs > 0 && ms < limits.MinDurationMs {
+		add(types.GuidelineRuleMinDuration, ms, limits.MinDurationMs)
+	}
+	if limits.MaxDurationMs > 0 && ms > limits.MaxDurationMs {
+		add(types.GuidelineRuleMaxDuration, ms, limits.MaxDurationMs)
+	}
+
+	if minGap := MinGap(limits.MinGapFrames, frameRate); minGap > 0 && i+1 < len(segments) {
+		if gap := (segments[i+1].StartTime.Time - segment.EndTime.Time).Milliseconds(); gap < minGap {
+			add(types.GuidelineRuleGap, gap, minGap)
+		}
+	}
+	return violations
+}
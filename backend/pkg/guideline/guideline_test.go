@@ -0,0 +1,118 @@
+package guideline
+
+import (
+	"CanMe/backend/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seg(id string, startMs, endMs int, lang, text string) types.SubtitleSegment {
+	return types.SubtitleSegment{
+		ID:        id,
+		StartTime: types.Timecode{Time: time.Duration(startMs) * time.Millisecond},
+		EndTime:   types.Timecode{Time: time.Duration(endMs) * time.Millisecond},
+		Languages: map[string]types.LanguageContent{lang: {Text: text}},
+	}
+}
+
+func TestLanguageCode(t *testing.T) {
+	assert.Equal(t, "en", LanguageCode("English"))
+	assert.Equal(t, "zh", LanguageCode("Chinese (Simplified)"))
+	assert.Equal(t, "zh", LanguageCode("Taiwan"))
+	assert.Equal(t, "zh", LanguageCode("zh-Hans"))
+	assert.Equal(t, "pt", LanguageCode("pt_BR"))
+}
+
+func TestResolve(t *testing.T) {
+	netflix, ok := Builtin(types.GuideLineStandardNetflix)
+	require.True(t, ok)
+
+	en := Resolve(netflix, "English", false)
+	assert.Equal(t, 20, en.MaxCPS)
+	assert.Equal(t, 42, en.MaxCPL)
+	assert.Equal(t, 2, en.MaxLines)
+	assert.EqualValues(t, 7000, en.MaxDurationMs)
+
+	assert.Equal(t, 17, Resolve(netflix, "en", true).MaxCPS)
+
+	zh := Resolve(netflix, "Chinese (Simplified)", true)
+	assert.Equal(t, 7, zh.MaxCPS)
+	assert.Equal(t, 16, zh.MaxCPL)
+	// 语言规则未设置的字段沿用默认值，儿童覆盖值同样叠加
+	assert.Equal(t, 130, zh.MaxWPM)
+
+	fr := Resolve(netflix, "French", false)
+	assert.Equal(t, 17, fr.MaxCPS)
+
+	bbc, _ := Builtin(types.GuideLineStandardBBC)
+	assert.Equal(t, 37, Resolve(bbc, "English", false).MaxCPL)
+	assert.Equal(t, types.GuidelineLimits{}, Resolve(nil, "en", false))
+}
+
+func TestBuiltinsAreCopies(t *testing.T) {
+	netflix, _ := Builtin(types.GuideLineStandardNetflix)
+	netflix.Languages["zh"] = types.GuidelineRule{}
+	again, _ := Builtin(types.GuideLineStandardNetflix)
+	assert.Equal(t, 16, again.Languages["zh"].Limits.MaxCPL)
+}
+
+func TestValidate(t *testing.T) {
+	profile := &types.GuidelineProfile{Name: "Custom"}
+	assert.NoError(t, Validate(profile))
+
+	profile.Default.Limits = types.GuidelineLimits{MinDurationMs: 2000, MaxDurationMs: 1000}
+	assert.Error(t, Validate(profile))
+
+	profile.Default.Limits = types.GuidelineLimits{}
+	profile.Languages = map[string]types.GuidelineRule{"en": {Kids: &types.GuidelineLimits{MaxCPS: -1}}}
+	assert.Error(t, Validate(profile))
+
+	assert.Error(t, Validate(&types.GuidelineProfile{}))
+}
+
+func TestMeasure(t *testing.T) {
+	m := Measure("Hello there,\ngeneral Kenobi", 2*time.Second)
+	assert.Equal(t, 2, m.Lines)
+	assert.Equal(t, 14, m.CPL)
+	assert.Equal(t, 12, m.CPS)
+	assert.Equal(t, 120, m.WPM)
+
+	m = Measure("你好世界", time.Second)
+	assert.Equal(t, 4, m.CPL)
+	assert.Equal(t, 4, m.CPS)
+
+	m = Measure("text", 0)
+	assert.Equal(t, 0, m.CPS)
+	assert.Equal(t, 1, m.Lines)
+}
+
+func TestCheck(t *testing.T) {
+	limits := types.GuidelineLimits{MaxCPS: 17, MaxCPL: 10, MaxLines: 2, MinDurationMs: 833, MaxDurationMs: 7000, MinGapFrames: 2}
+	segments := []types.SubtitleSegment{
+		seg("a", 0, 500, "en", "one\ntwo\nthree"),
+		seg("b", 520, 8520, "en", "A rather long line"),
+	}
+
+	violations := Check(limits, segments, 0, "en", 25)
+	rules := map[types.GuidelineRuleType]types.GuidelineViolation{}
+	for _, v := range violations {
+		rules[v.Rule] = v
+	}
+	assert.Len(t, violations, 4)
+	assert.EqualValues(t, 3, rules[types.GuidelineRuleLines].Value)
+	assert.EqualValues(t, 500, rules[types.GuidelineRuleMinDuration].Value)
+	assert.EqualValues(t, 20, rules[types.GuidelineRuleGap].Value)
+	assert.EqualValues(t, 80, rules[types.GuidelineRuleGap].Limit)
+	assert.Contains(t, rules, types.GuidelineRuleCPS)
+
+	violations = Check(limits, segments, 1, "en", 25)
+	require.Len(t, violations, 2)
+	assert.Equal(t, types.GuidelineRuleCPL, violations[0].Rule)
+	assert.Equal(t, types.GuidelineRuleMaxDuration, violations[1].Rule)
+	assert.Equal(t, "b", violations[1].SegmentID)
+
+	assert.Empty(t, Check(limits, segments, 0, "fr", 25))
+}
@@ -0,0 +1,82 @@
+package guideline
+
+import "CanMe/backend/types"
+
+// cjkRules 中日韩文本按字计算，每行字数与阅读速度远低于拼音文字
+func cjkRules() map[string]types.GuidelineRule {
+	return map[string]types.GuidelineRule{
+		"zh": {
+			Limits: types.GuidelineLimits{MaxCPS: 9, MaxCPL: 16},
+			Kids:   &types.GuidelineLimits{MaxCPS: 7},
+		},
+		"ja": {
+			Limits: types.GuidelineLimits{MaxCPS: 4, MaxCPL: 13},
+			Kids:   &types.GuidelineLimits{MaxCPS: 4},
+		},
+		"ko": {
+			Limits: types.GuidelineLimits{MaxCPS: 12, MaxCPL: 16},
+			Kids:   &types.GuidelineLimits{MaxCPS: 9},
+		},
+	}
+}
+
+// Builtins 内置的 Netflix、BBC 与 ADE 规范，每次调用返回新的副本
+func Builtins() []types.GuidelineProfile {
+	netflix := types.GuidelineProfile{
+		ID:      types.GuideLineStandardNetflix,
+		Name:    "Netflix",
+		BuiltIn: true,
+		Default: types.GuidelineRule{
+			Limits: types.GuidelineLimits{
+				MaxCPS: 17, MaxWPM: 160, MaxCPL: 42, MaxLines: 2,
+				MinDurationMs: 833, MaxDurationMs: 7000, MinGapFrames: 2,
+			},
+			Kids: &types.GuidelineLimits{MaxCPS: 13, MaxWPM: 130},
+		},
+		Languages: cjkRules(),
+	}
+	netflix.Languages["en"] = types.GuidelineRule{
+		Limits: types.GuidelineLimits{MaxCPS: 20, MaxWPM: 200},
+		Kids:   &types.GuidelineLimits{MaxCPS: 17, MaxWPM: 160},
+	}
+
+	bbc := types.GuidelineProfile{
+		ID:      types.GuideLineStandardBBC,
+		Name:    "BBC",
+		BuiltIn: true,
+		Default: types.GuidelineRule{
+			Limits: types.GuidelineLimits{
+				MaxCPS: 15, MaxWPM: 180, MaxCPL: 37, MaxLines: 2,
+				MinDurationMs: 1000, MaxDurationMs: 8000, MinGapFrames: 2,
+			},
+			Kids: &types.GuidelineLimits{MaxCPS: 12, MaxWPM: 140},
+		},
+		Languages: cjkRules(),
+	}
+
+	ade := types.GuidelineProfile{
+		ID:      types.GuideLineStandardADE,
+		Name:    "ADE",
+		BuiltIn: true,
+		Default: types.GuidelineRule{
+			Limits: types.GuidelineLimits{
+				MaxCPS: 15, MaxWPM: 150, MaxCPL: 40, MaxLines: 2,
+				MinDurationMs: 1000, MaxDurationMs: 6000, MinGapFrames: 2,
+			},
+			Kids: &types.GuidelineLimits{MaxCPS: 12, MaxWPM: 120},
+		},
+		Languages: cjkRules(),
+	}
+
+	return []types.GuidelineProfile{netflix, bbc, ade}
+}
+
+// Builtin 按 ID 查找内置规范
+func Builtin(id types.GuideLineStandard) (*types.GuidelineProfile, bool) {
+	for _, profile := range Builtins() {
+		if profile.ID == id {
+			return &profile, true
+		}
+	}
+	return nil, false
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync" // 用于在重新初始化期间保护对全局变量的并发访问
@@ -17,8 +18,17 @@ var (
 	currentConfig     *Config                                               // 保存当前配置用于比较
 	configMutex       sync.RWMutex
 	initOnce          sync.Once // 懒初始化默认配置
+	// 控制台日志输出目标（命令行模式下改为 stderr 或丢弃，避免污染标准输出）
+	consoleWriter zapcore.WriteSyncer = zapcore.AddSync(os.Stdout)
 )
 
+// SetConsoleWriter 设置控制台日志的输出目标，需在 InitLogger 之前调用
+func SetConsoleWriter(w io.Writer) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	consoleWriter = zapcore.AddSync(w)
+}
+
 // InitLogger 初始化或重新初始化日志记录器
 func InitLogger(cfg *Config) error {
 	configMutex.Lock()
@@ -83,7 +93,7 @@ func InitLogger(cfg *Config) error {
 
 	if cfg.EnableConsole {
 		consoleEncoder := zapcore.NewConsoleEncoder(encoderConfig)
-		consoleCore := zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), globalAtomicLevel)
+		consoleCore := zapcore.NewCore(consoleEncoder, consoleWriter, globalAtomicLevel)
 		cores = append(cores, consoleCore)
 	}
 
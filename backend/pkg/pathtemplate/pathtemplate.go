@@ -0,0 +1,85 @@
+// Package pathtemplate 将 {uploader}/{upload_year}/{title} [{id}] 这类模板渲染为安全的相对路径。
+package pathtemplate
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxSegmentLength = 120 // 单个目录名的最大字节数
+
+var placeholderRe = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Windows 保留的设备名，不区分大小写，带扩展名（如 CON.txt）同样不可用
+var reservedNameRe = regexp.MustCompile(`(?i)^(con|prn|aux|nul|com[0-9¹²³]|lpt[0-9¹²³])( *\.|$)`)
+
+// Validate 检查模板中的占位符是否都受支持
+func Validate(template string, known []string) error {
+	if strings.TrimSpace(template) == "" {
+		return fmt.Errorf("template is empty")
+	}
+	allowed := map[string]bool{}
+	for _, k := range known {
+		allowed[k] = true
+	}
+	for _, m := range placeholderRe.FindAllStringSubmatch(template, -1) {
+		if !allowed[m[1]] {
+			return fmt.Errorf("unknown placeholder: {%s}", m[1])
+		}
+	}
+	return nil
+}
+
+// Render 渲染模板，返回使用系统分隔符的相对路径。
+// 模板中的 "/" 作为目录分隔符，变量中的分隔符与非法字符会被替换，空目录名被丢弃。
+func Render(template string, values map[string]string, fallback string) string {
+	var segments []string
+	for _, part := range strings.Split(strings.ReplaceAll(template, "\\", "/"), "/") {
+		rendered := placeholderRe.ReplaceAllStringFunc(part, func(m string) string {
+			v := strings.TrimSpace(values[m[1:len(m)-1]])
+			if v == "" {
+				v = fallback
+			}
+			return strings.NewReplacer("/", "_", "\\", "_").Replace(v)
+		})
+		if seg := SanitizeSegment(rendered); seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	return filepath.Join(segments...)
+}
+
+// SanitizeSegment 清理单个路径段：去除跨平台非法字符、控制字符及首尾的点和空格，
+// Windows 保留名（CON、NUL、COM1…）后追加 "_"
+func SanitizeSegment(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			continue
+		case strings.ContainsRune(`<>:"/\|?*`, r):
+			b.WriteRune('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	out := strings.Join(strings.Fields(b.String()), " ")
+	out = strings.Trim(out, ". ")
+	if out == "" || out == ".." {
+		return ""
+	}
+	if len(out) > maxSegmentLength {
+		out = out[:maxSegmentLength]
+		for !utf8.ValidString(out) {
+			out = out[:len(out)-1]
+		}
+		out = strings.TrimRight(out, ". ")
+	}
+	if m := reservedNameRe.FindStringSubmatch(out); m != nil {
+		out = m[1] + "_" + out[len(m[1]):]
+	}
+	return out
+}
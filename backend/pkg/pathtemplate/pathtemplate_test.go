@@ -0,0 +1,57 @@
+package pathtemplate
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	values := map[string]string{
+		"uploader":    "AC/DC Channel",
+		"upload_year": "2024",
+		"title":       `What? "Live" at: Wembley...`,
+		"id":          "abc123",
+	}
+	got := Render("{uploader}/{upload_year}/{title} [{id}]", values, "unknown")
+	assert.Equal(t, filepath.Join("AC_DC Channel", "2024", "What_ _Live_ at_ Wembley... [abc123]"), got)
+
+	got = Render("{uploader}/{upload_year}/{title}", map[string]string{"title": ".."}, "unknown")
+	assert.Equal(t, filepath.Join("unknown", "unknown"), got)
+}
+
+func TestSanitizeSegmentLength(t *testing.T) {
+	long := strings.Repeat("字", 100)
+	seg := SanitizeSegment(long)
+	assert.LessOrEqual(t, len(seg), maxSegmentLength)
+	assert.True(t, strings.HasPrefix(long, seg))
+}
+
+func TestSanitizeSegment(t *testing.T) {
+	tests := map[string]string{
+		"CON":           "CON_",
+		"nul":           "nul_",
+		"Com1":          "Com1_",
+		"LPT9.mp4":      "LPT9_.mp4",
+		"CON .txt":      "CON_ .txt",
+		"Console":       "Console",
+		"COM10":         "COM10",
+		"title. . ":     "title",
+		" ..hidden.. ":  "hidden",
+		"a\tb\x00c":     "abc",
+		`What? "x" y|z`: "What_ _x_ y_z",
+		"...":           "",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, SanitizeSegment(in), in)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	known := []string{"uploader", "title", "id"}
+	assert.NoError(t, Validate("{uploader}/{title} [{id}]", known))
+	assert.Error(t, Validate("{uploader}/{nope}", known))
+	assert.Error(t, Validate("  ", known))
+}
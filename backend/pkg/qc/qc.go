@@ -0,0 +1,255 @@
+// Package qc 按规则集对字幕项目做交付前质检（行数、行长、阅读速度、时长、间隔、重叠、禁用字符、标签等），
+// 汇总各级别的问题并导出 JSON/CSV/HTML 报告。
+package qc
+
+import (
+	"CanMe/backend/pkg/guideline"
+	"CanMe/backend/types"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultRuleSetID 内置交付规则集
+const DefaultRuleSetID = "default"
+
+var ruleTypes = []types.QCRuleType{
+	types.QCRuleMaxLines, types.QCRuleMaxCPL, types.QCRuleMaxCPS,
+	types.QCRuleMinDuration, types.QCRuleMaxDuration, types.QCRuleMinGap, types.QCRuleOverlap,
+	types.QCRuleForbiddenChars, types.QCRuleUnbalancedTags, types.QCRuleEmptyText,
+}
+
+var severities = []types.QCSeverity{types.QCSeverityError, types.QCSeverityWarning, types.QCSeverityInfo}
+
+// Builtins 内置规则集，每次调用返回新的副本
+func Builtins() []types.QCRuleSet {
+	return []types.QCRuleSet{{
+		ID:      DefaultRuleSetID,
+		Name:    "Default delivery",
+		BuiltIn: true,
+		Rules: []types.QCRule{
+			{Type: types.QCRuleEmptyText, Severity: types.QCSeverityError},
+			{Type: types.QCRuleMaxLines, Severity: types.QCSeverityError},
+			{Type: types.QCRuleMaxDuration, Severity: types.QCSeverityError},
+			{Type: types.QCRuleOverlap, Severity: types.QCSeverityError},
+			{Type: types.QCRuleForbiddenChars, Severity: types.QCSeverityError},
+			{Type: types.QCRuleUnbalancedTags, Severity: types.QCSeverityError},
+			{Type: types.QCRuleMinDuration, Severity: types.QCSeverityWarning},
+			{Type: types.QCRuleMinGap, Severity: types.QCSeverityWarning},
+			{Type: types.QCRuleMaxCPL, Severity: types.QCSeverityWarning},
+			{Type: types.QCRuleMaxCPS, Severity: types.QCSeverityWarning},
+		},
+	}}
+}
+
+// Builtin 按 ID 查找内置规则集
+func Builtin(id string) (*types.QCRuleSet, bool) {
+	for _, set := range Builtins() {
+		if set.ID == id {
+			return &set, true
+		}
+	}
+	return nil, false
+}
+
+// Validate 检查规则集的规则类型、级别与取值
+func Validate(set *types.QCRuleSet) error {
+	if set == nil {
+		return errors.New("qc rule set is nil")
+	}
+	if strings.TrimSpace(set.Name) == "" {
+		return errors.New("qc rule set name is required")
+	}
+	if len(set.Rules) == 0 {
+		return errors.New("qc rule set has no rules")
+	}
+	for _, rule := range set.Rules {
+		if !slices.Contains(ruleTypes, rule.Type) {
+			return fmt.Errorf("unknown qc rule: %s", rule.Type)
+		}
+		if !slices.Contains(severities, rule.Severity) {
+			return fmt.Errorf("rule %s: unknown severity %q", rule.Type, rule.Severity)
+		}
+		if rule.Limit < 0 {
+			return fmt.Errorf("rule %s: limit cannot be negative", rule.Type)
+		}
+	}
+	return nil
+}
+
+// LimitsFunc 返回片段某种语言所用字幕规范的限制，lang 为空时用于时长、间隔等片段级规则
+type LimitsFunc func(segment *types.SubtitleSegment, lang string) types.GuidelineLimits
+
+// Check 按规则检查各片段，结果按片段顺序排列；limits 为空时 Limit 为 0 的数值规则不生效
+func Check(segments []types.SubtitleSegment, languages []string, rules []types.QCRule, limits LimitsFunc, frameRate float64) []types.QCFinding {
+	if limits == nil {
+		limits = func(*types.SubtitleSegment, string) types.GuidelineLimits { return types.GuidelineLimits{} }
+	}
+	findings := []types.QCFinding{}
+	for i := range segments {
+		segment := &segments[i]
+		var next *types.SubtitleSegment
+		if i+1 < len(segments) {
+			next = &segments[i+1]
+		}
+		report := func(rule types.QCRule, lang, text string, value, limit int64, message string) {
+			findings = append(findings, types.QCFinding{
+				Index:     i + 1,
+				SegmentID: segment.ID,
+				StartMs:   segment.StartTime.Time.Milliseconds(),
+				EndMs:     segment.EndTime.Time.Milliseconds(),
+				LangCode:  lang,
+				Rule:      rule.Type,
+				Severity:  rule.Severity,
+				Value:     value,
+				Limit:     limit,
+				Message:   message,
+				Text:      text,
+			})
+		}
+
+		segmentLimits := limits(segment, "")
+		for _, rule := range rules {
+			checkTiming(rule, segment, next, segmentLimits, frameRate, report)
+		}
+		for _, lang := range languages {
+			langLimits := limits(segment, lang)
+			text := segment.Languages[lang].Text
+			for _, rule := range rules {
+				checkText(rule, lang, text, segment, langLimits, report)
+			}
+		}
+	}
+	return findings
+}
+
+type reportFunc func(rule types.QCRule, lang, text string, value, limit int64, message string)
+
+// limitOr 规则未设置 Limit 时使用字幕规范的值
+func limitOr(rule types.QCRule, fallback int64) int64 {
+	if rule.Limit > 0 {
+		return rule.Limit
+	}
+	return fallback
+}
+
+func checkTiming(rule types.QCRule, segment, next *types.SubtitleSegment, limits types.GuidelineLimits, frameRate float64, report reportFunc) {
+	duration := (segment.EndTime.Time - segment.StartTime.Time).Milliseconds()
+	switch rule.Type {
+	case types.QCRuleMinDuration:
+		if limit := limitOr(rule, limits.MinDurationMs); limit > 0 && duration < limit {
+			report(rule, "", "", duration, limit, fmt.Sprintf("duration %dms is shorter than %dms", duration, limit))
+		}
+	case types.QCRuleMaxDuration:
+		if limit := limitOr(rule, limits.MaxDurationMs); limit > 0 && duration > limit {
+			report(rule, "", "", duration, limit, fmt.Sprintf("duration %dms is longer than %dms", duration, limit))
+		}
+	case types.QCRuleMinGap:
+		if next == nil {
+			return
+		}
+		minGap := guideline.MinGap(int(limitOr(rule, int64(limits.MinGapFrames))), frameRate)
+		// 重叠由 overlap 规则报告
+		if gap := (next.StartTime.Time - segment.EndTime.Time).Milliseconds(); minGap > 0 && gap >= 0 && gap < minGap {
+			report(rule, "", "", gap, minGap, fmt.Sprintf("gap to next cue %dms is shorter than %dms", gap, minGap))
+		}
+	case types.QCRuleOverlap:
+		if next == nil {
+			return
+		}
+		if overlap := (segment.EndTime.Time - next.StartTime.Time).Milliseconds(); overlap > 0 {
+			report(rule, "", "", overlap, 0, fmt.Sprintf("overlaps next cue by %dms", overlap))
+		}
+	}
+}
+
+func checkText(rule types.QCRule, lang, text string, segment *types.SubtitleSegment, limits types.GuidelineLimits, report reportFunc) {
+	if strings.TrimSpace(text) == "" {
+		if rule.Type == types.QCRuleEmptyText {
+			report(rule, lang, "", 0, 0, "no text")
+		}
+		return
+	}
+
+	m := guideline.Measure(text, segment.EndTime.Time-segment.StartTime.Time)
+	switch rule.Type {
+	case types.QCRuleMaxLines:
+		if limit := limitOr(rule, int64(limits.MaxLines)); limit > 0 && int64(m.Lines) > limit {
+			report(rule, lang, text, int64(m.Lines), limit, fmt.Sprintf("%d lines, max %d", m.Lines, limit))
+		}
+	case types.QCRuleMaxCPL:
+		if limit := limitOr(rule, int64(limits.MaxCPL)); limit > 0 && int64(m.CPL) > limit {
+			report(rule, lang, text, int64(m.CPL), limit, fmt.Sprintf("%d characters per line, max %d", m.CPL, limit))
+		}
+	case types.QCRuleMaxCPS:
+		if limit := limitOr(rule, int64(limits.MaxCPS)); limit > 0 && int64(m.CPS) > limit {
+			report(rule, lang, text, int64(m.CPS), limit, fmt.Sprintf("%d characters per second, max %d", m.CPS, limit))
+		}
+	case types.QCRuleForbiddenChars:
+		if found := forbiddenChars(text, rule.Chars); len(found) > 0 {
+			quoted := make([]string, len(found))
+			for i, r := range found {
+				quoted[i] = fmt.Sprintf("%+q", r)
+			}
+			report(rule, lang, text, int64(len(found)), 0, "forbidden characters: "+strings.Join(quoted, ", "))
+		}
+	case types.QCRuleUnbalancedTags:
+		if unbalanced := unbalancedTags(text); len(unbalanced) > 0 {
+			report(rule, lang, text, int64(len(unbalanced)), 0, "unbalanced tags: "+strings.Join(unbalanced, ", "))
+		}
+	}
+}
+
+// forbiddenChars 返回文本中出现的禁用字符（去重，按出现顺序）
+func forbiddenChars(text, extra string) []rune {
+	var found []rune
+	for _, r := range text {
+		forbidden := r == unicode.ReplacementChar ||
+			(unicode.IsControl(r) && r != '\n' && r != '\t') ||
+			(r >= 0x200B && r <= 0x200D) || r == 0xFEFF ||
+			strings.ContainsRune(extra, r)
+		if forbidden && !slices.Contains(found, r) {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+var tagPattern = regexp.MustCompile(`(?i)<\s*(/?)\s*(i|b|u|font)\b[^>]*>`)
+
+// unbalancedTags 返回未配对的格式标签，如 <i>、</b>
+func unbalancedTags(text string) []string {
+	var open, unbalanced []string
+	for _, match := range tagPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.ToLower(match[2])
+		if match[1] == "" {
+			open = append(open, name)
+			continue
+		}
+		if len(open) > 0 && open[len(open)-1] == name {
+			open = open[:len(open)-1]
+			continue
+		}
+		unbalanced = append(unbalanced, "</"+name+">")
+	}
+	for _, name := range open {
+		unbalanced = append(unbalanced, "<"+name+">")
+	}
+	return unbalanced
+}
+
+// Summarize 按级别统计问题数，无 error 级问题即为通过
+func Summarize(findings []types.QCFinding) (map[types.QCSeverity]int, bool) {
+	summary := map[types.QCSeverity]int{
+		types.QCSeverityError:   0,
+		types.QCSeverityWarning: 0,
+		types.QCSeverityInfo:    0,
+	}
+	for _, finding := range findings {
+		summary[finding.Severity]++
+	}
+	return summary, summary[types.QCSeverityError] == 0
+}
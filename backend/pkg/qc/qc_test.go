@@ -0,0 +1,137 @@
+package qc
+
+import (
+	"CanMe/backend/types"
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seg(id string, startMs, endMs int, text string) types.SubtitleSegment {
+	return types.SubtitleSegment{
+		ID:        id,
+		StartTime: types.Timecode{Time: time.Duration(startMs) * time.Millisecond},
+		EndTime:   types.Timecode{Time: time.Duration(endMs) * time.Millisecond},
+		Languages: map[string]types.LanguageContent{"en": {Text: text}},
+	}
+}
+
+func byRule(findings []types.QCFinding) map[types.QCRuleType]types.QCFinding {
+	out := map[types.QCRuleType]types.QCFinding{}
+	for _, f := range findings {
+		out[f.Rule] = f
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	segments := []types.SubtitleSegment{
+		seg("a", 0, 2000, "one\ntwo\nthree"),
+		seg("b", 1500, 9500, "<i>Italic without end"),
+		seg("c", 9520, 11000, "Bad​char�"),
+		seg("d", 12000, 13000, ""),
+	}
+	set, ok := Builtin(DefaultRuleSetID)
+	require.True(t, ok)
+	limits := func(*types.SubtitleSegment, string) types.GuidelineLimits {
+		return types.GuidelineLimits{MaxLines: 2, MaxCPL: 42, MaxDurationMs: 7000, MinGapFrames: 2}
+	}
+
+	findings := Check(segments, []string{"en"}, set.Rules, limits, 25)
+
+	a := byRule(filterSegment(findings, "a"))
+	assert.Contains(t, a, types.QCRuleOverlap)
+	assert.EqualValues(t, 500, a[types.QCRuleOverlap].Value)
+	assert.EqualValues(t, 3, a[types.QCRuleMaxLines].Value)
+	assert.Equal(t, types.QCSeverityError, a[types.QCRuleMaxLines].Severity)
+	assert.Equal(t, 1, a[types.QCRuleMaxLines].Index)
+
+	b := byRule(filterSegment(findings, "b"))
+	assert.EqualValues(t, 8000, b[types.QCRuleMaxDuration].Value)
+	assert.Equal(t, "unbalanced tags: <i>", b[types.QCRuleUnbalancedTags].Message)
+	// 20ms 间隔小于 2 帧 @25fps
+	assert.EqualValues(t, 80, b[types.QCRuleMinGap].Limit)
+
+	c := byRule(filterSegment(findings, "c"))
+	assert.EqualValues(t, 2, c[types.QCRuleForbiddenChars].Value)
+
+	d := filterSegment(findings, "d")
+	require.Len(t, d, 1)
+	assert.Equal(t, types.QCRuleEmptyText, d[0].Rule)
+
+	// 规则的 Limit 优先于字幕规范
+	rules := []types.QCRule{{Type: types.QCRuleMaxLines, Severity: types.QCSeverityInfo, Limit: 3}}
+	assert.Empty(t, Check(segments, []string{"en"}, rules, limits, 25))
+	// 没有字幕规范时 Limit 为 0 的数值规则不生效
+	rules[0].Limit = 0
+	assert.Empty(t, Check(segments, []string{"en"}, rules, nil, 25))
+}
+
+func filterSegment(findings []types.QCFinding, id string) []types.QCFinding {
+	var out []types.QCFinding
+	for _, f := range findings {
+		if f.SegmentID == id {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func TestUnbalancedTags(t *testing.T) {
+	assert.Empty(t, unbalancedTags("<i>fine</i> and <B>bold</b> <font color=\"red\">x</font>"))
+	assert.Equal(t, []string{"</i>"}, unbalancedTags("text</i>"))
+	assert.Equal(t, []string{"</i>", "<i>"}, unbalancedTags("<i><b>x</i></b>"))
+}
+
+func TestValidate(t *testing.T) {
+	set := &types.QCRuleSet{Name: "Custom", Rules: []types.QCRule{{Type: types.QCRuleOverlap, Severity: types.QCSeverityError}}}
+	assert.NoError(t, Validate(set))
+
+	set.Rules[0].Severity = "fatal"
+	assert.Error(t, Validate(set))
+
+	set.Rules[0] = types.QCRule{Type: "spelling", Severity: types.QCSeverityInfo}
+	assert.Error(t, Validate(set))
+
+	assert.Error(t, Validate(&types.QCRuleSet{Name: "Empty"}))
+}
+
+func TestWrite(t *testing.T) {
+	findings := []types.QCFinding{{
+		Index: 1, SegmentID: "a", StartMs: 3723004, EndMs: 3725000, LangCode: "en",
+		Rule: types.QCRuleMaxLines, Severity: types.QCSeverityError, Value: 3, Limit: 2,
+		Message: "3 lines, max 2", Text: "<one>\ntwo\nthree",
+	}}
+	summary, passed := Summarize(findings)
+	assert.False(t, passed)
+	report := &types.QCReport{
+		ProjectName: "Pilot", RuleSetName: "Default delivery", Languages: []string{"en"},
+		Segments: 1, Findings: findings, Summary: summary, Passed: passed,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, report, types.QCReportCSV))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "01:02:03.004", rows[1][2])
+	assert.Equal(t, "<one>\ntwo\nthree", rows[1][10])
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, report, types.QCReportHTML))
+	html := buf.String()
+	assert.Contains(t, html, "Failed")
+	assert.Contains(t, html, "1 errors")
+	assert.Contains(t, html, "&lt;one&gt;")
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, report, types.QCReportJSON))
+	assert.True(t, strings.HasPrefix(buf.String(), "{"))
+
+	assert.Error(t, Write(&buf, report, "pdf"))
+}
@@ -0,0 +1,106 @@
+package qc
+
+import (
+	"CanMe/backend/types"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Write 按格式输出质检报告
+func Write(w io.Writer, report *types.QCReport, format types.QCReportFormat) error {
+	switch format {
+	case types.QCReportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case types.QCReportCSV:
+		return writeCSV(w, report)
+	case types.QCReportHTML:
+		return htmlReport.Execute(w, report)
+	default:
+		return fmt.Errorf("unsupported qc report format: %s", format)
+	}
+}
+
+func writeCSV(w io.Writer, report *types.QCReport) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"index", "segment_id", "start", "end", "language", "rule", "severity", "value", "limit", "message", "text"})
+	for _, f := range report.Findings {
+		out.Write([]string{
+			strconv.Itoa(f.Index), f.SegmentID, FormatTime(f.StartMs), FormatTime(f.EndMs), f.LangCode,
+			string(f.Rule), string(f.Severity), strconv.FormatInt(f.Value, 10), strconv.FormatInt(f.Limit, 10),
+			f.Message, f.Text,
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// FormatTime 将毫秒格式化为 HH:MM:SS.mmm
+func FormatTime(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, ms%1000)
+}
+
+var htmlReport = template.Must(template.New("qc").Funcs(template.FuncMap{
+	"time": FormatTime,
+	"date": func(unix int64) string { return time.Unix(unix, 0).Format("2006-01-02 15:04:05") },
+	"count": func(summary map[types.QCSeverity]int, severity string) int {
+		return summary[types.QCSeverity(severity)]
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>QC report – {{.ProjectName}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, "PingFang SC", sans-serif; margin: 2rem; color: #222; }
+h1 { font-size: 1.4rem; margin-bottom: .25rem; }
+.meta { color: #666; margin-bottom: 1.5rem; }
+.summary span { display: inline-block; padding: .3rem .8rem; margin-right: .5rem; border-radius: 4px; font-weight: 600; }
+.passed { background: #e6f4ea; color: #1e7e34; }
+.failed { background: #fdecea; color: #b3261e; }
+.error { color: #b3261e; }
+.warning { color: #a15c00; }
+.info { color: #1a73e8; }
+table { border-collapse: collapse; width: 100%; margin-top: 1.5rem; font-size: .9rem; }
+th, td { border-bottom: 1px solid #e0e0e0; padding: .4rem .6rem; text-align: left; vertical-align: top; }
+th { background: #f5f5f5; }
+td.text { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>QC report – {{.ProjectName}}</h1>
+<div class="meta">Rule set: {{.RuleSetName}} · Languages: {{range $i, $l := .Languages}}{{if $i}}, {{end}}{{$l}}{{end}} · {{.Segments}} cues · {{date .CheckedAt}}</div>
+<div class="summary">
+{{if .Passed}}<span class="passed">Passed</span>{{else}}<span class="failed">Failed</span>{{end}}
+<span class="error">{{count .Summary "error"}} errors</span>
+<span class="warning">{{count .Summary "warning"}} warnings</span>
+<span class="info">{{count .Summary "info"}} info</span>
+</div>
+{{if .Findings}}
+<table>
+<thead><tr><th>#</th><th>Time</th><th>Language</th><th>Severity</th><th>Rule</th><th>Issue</th><th>Text</th></tr></thead>
+<tbody>
+{{range .Findings}}<tr>
+<td>{{.Index}}</td>
+<td>{{time .StartMs}} → {{time .EndMs}}</td>
+<td>{{.LangCode}}</td>
+<td class="{{.Severity}}">{{.Severity}}</td>
+<td>{{.Rule}}</td>
+<td>{{.Message}}</td>
+<td class="text">{{.Text}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+{{else}}
+<p>No issues found.</p>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,88 @@
+// Package segmenttext 处理字幕片段拆分与合并时的文本切分和拼接。
+package segmenttext
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// SplitAt 在第 pos 个字符（rune）处切分文本，两段各自去掉首尾空白
+func SplitAt(text string, pos int) (string, string) {
+	runes := []rune(text)
+	pos = min(max(pos, 0), len(runes))
+	return strings.TrimSpace(string(runes[:pos])), strings.TrimSpace(string(runes[pos:]))
+}
+
+// SplitProportional 按比例（0-1）切分文本，并就近对齐到断点：
+// 有空格的文本只在空白处切分，两类文本都优先在标点后切分
+func SplitProportional(text string, ratio float64) (string, string) {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) < 2 {
+		if ratio < 0.5 {
+			return "", string(runes)
+		}
+		return string(runes), ""
+	}
+	target := int(math.Round(ratio * float64(len(runes))))
+	target = min(max(target, 1), len(runes)-1)
+
+	// 原有的换行离目标不远时优先在换行处切分
+	best := -1
+	for i := 1; i < len(runes); i++ {
+		if runes[i] == '\n' && abs(i-target) <= len(runes)/4 && (best < 0 || abs(i-target) < abs(best-target)) {
+			best = i
+		}
+	}
+	if best > 0 {
+		return SplitAt(string(runes), best)
+	}
+
+	spaced := strings.IndexFunc(string(runes), unicode.IsSpace) >= 0
+	if spaced {
+		// 有空格的文本优先在离目标不远的标点后的空白处切分
+		for i := 1; i < len(runes); i++ {
+			if unicode.IsSpace(runes[i]) && unicode.IsPunct(runes[i-1]) && abs(i-target) <= len(runes)/4 &&
+				(best < 0 || abs(i-target) < abs(best-target)) {
+				best = i
+			}
+		}
+		if best > 0 {
+			return SplitAt(string(runes), best)
+		}
+	}
+	for i := 1; i < len(runes); i++ {
+		var isBreak bool
+		if spaced {
+			isBreak = unicode.IsSpace(runes[i])
+		} else {
+			isBreak = unicode.IsPunct(runes[i-1]) && !unicode.IsPunct(runes[i])
+		}
+		if isBreak && (best < 0 || abs(i-target) < abs(best-target)) {
+			best = i
+		}
+	}
+	// 无空格文本中离目标太远的标点不采用，直接按比例切分
+	if best < 0 || (!spaced && abs(best-target) > len(runes)/4) {
+		best = target
+	}
+	return SplitAt(string(runes), best)
+}
+
+// Join 按换行拼接非空文本
+func Join(parts ...string) string {
+	kept := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,57 @@
+package segmenttext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitAt(t *testing.T) {
+	a, b := SplitAt("Hello there, world", 12)
+	assert.Equal(t, "Hello there,", a)
+	assert.Equal(t, "world", b)
+
+	a, b = SplitAt("你好世界", 2)
+	assert.Equal(t, "你好", a)
+	assert.Equal(t, "世界", b)
+
+	a, b = SplitAt("abc", 10)
+	assert.Equal(t, "abc", a)
+	assert.Equal(t, "", b)
+}
+
+func TestSplitProportional(t *testing.T) {
+	a, b := SplitProportional("I never wanted\nto come back here", 0.5)
+	assert.Equal(t, "I never wanted", a)
+	assert.Equal(t, "to come back here", b)
+
+	// 不会在单词中间切分
+	a, b = SplitProportional("Absolutely unbelievable", 0.3)
+	assert.Equal(t, "Absolutely", a)
+	assert.Equal(t, "unbelievable", b)
+
+	a, b = SplitProportional("This is a very long cue that goes on. And on for far too long.", 0.5)
+	assert.Equal(t, "This is a very long cue that goes on.", a)
+	assert.Equal(t, "And on for far too long.", b)
+
+	a, b = SplitProportional("我从来没想过，要再回到这里来", 0.5)
+	assert.Equal(t, "我从来没想过，", a)
+	assert.Equal(t, "要再回到这里来", b)
+
+	a, b = SplitProportional("我从来没想过要再回到这里来", 0.5)
+	assert.Equal(t, "我从来没想过要", a)
+	assert.Equal(t, "再回到这里来", b)
+
+	a, b = SplitProportional("Hmm", 0.9)
+	assert.Equal(t, "Hm", a)
+	assert.Equal(t, "m", b)
+
+	a, b = SplitProportional("", 0.5)
+	assert.Empty(t, a)
+	assert.Empty(t, b)
+}
+
+func TestJoin(t *testing.T) {
+	assert.Equal(t, "One\nTwo", Join(" One ", "", "Two"))
+	assert.Equal(t, "", Join("", " "))
+}
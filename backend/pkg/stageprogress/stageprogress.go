@@ -0,0 +1,60 @@
+// Package stageprogress 按权重汇总多阶段任务的总体进度。
+//
+// 阶段按执行顺序排列：某一阶段开始上报进度时，之前的阶段视为已完成，
+// 避免总体进度在阶段切换时回退。
+package stageprogress
+
+import "CanMe/backend/types"
+
+// Advance 更新指定阶段的进度，并将其之前的阶段置为完成；阶段不存在时返回 false
+func Advance(stages []types.DtStageProgress, name string, percentage float64) bool {
+	idx := -1
+	for i := range stages {
+		if stages[i].Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return false
+	}
+	for i := 0; i < idx; i++ {
+		stages[i].Percentage = 100
+	}
+	stages[idx].Percentage = clamp(percentage)
+	return true
+}
+
+// Complete 将所有阶段置为完成
+func Complete(stages []types.DtStageProgress) {
+	for i := range stages {
+		stages[i].Percentage = 100
+	}
+}
+
+// Overall 返回加权总进度（0-100）；没有阶段或权重时返回 0
+func Overall(stages []types.DtStageProgress) float64 {
+	var total, done float64
+	for _, s := range stages {
+		if s.Weight <= 0 {
+			continue
+		}
+		total += s.Weight
+		done += s.Weight * clamp(s.Percentage)
+	}
+	if total == 0 {
+		return 0
+	}
+	return done / total
+}
+
+func clamp(p float64) float64 {
+	switch {
+	case p < 0:
+		return 0
+	case p > 100:
+		return 100
+	default:
+		return p
+	}
+}
@@ -0,0 +1,46 @@
+package stageprogress
+
+import (
+	"CanMe/backend/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func plan() []types.DtStageProgress {
+	return []types.DtStageProgress{
+		{Name: types.DtSubStageVideo, Weight: 60},
+		{Name: types.DtSubStageAudio, Weight: 20},
+		{Name: types.DtSubStageMerge, Weight: 20},
+	}
+}
+
+func TestAdvanceCompletesEarlierStages(t *testing.T) {
+	stages := plan()
+
+	assert.True(t, Advance(stages, types.DtSubStageVideo, 50))
+	assert.InDelta(t, 30, Overall(stages), 0.001)
+
+	// 音频流从 0 重新开始，总进度不回退
+	assert.True(t, Advance(stages, types.DtSubStageAudio, 0))
+	assert.InDelta(t, 60, Overall(stages), 0.001)
+
+	assert.True(t, Advance(stages, types.DtSubStageMerge, 50))
+	assert.InDelta(t, 90, Overall(stages), 0.001)
+}
+
+func TestAdvanceUnknownStage(t *testing.T) {
+	stages := plan()
+	assert.False(t, Advance(stages, types.DtSubStageEmbed, 10))
+	assert.Zero(t, Overall(stages))
+}
+
+func TestCompleteAndClamp(t *testing.T) {
+	stages := plan()
+	Advance(stages, types.DtSubStageVideo, 150)
+	assert.Equal(t, 100.0, stages[0].Percentage)
+
+	Complete(stages)
+	assert.InDelta(t, 100, Overall(stages), 0.001)
+	assert.Zero(t, Overall(nil))
+}
@@ -0,0 +1,158 @@
+// Package subdiff 对比两组字幕片段，按片段给出新增、删除以及文本和时间轴的变化。
+package subdiff
+
+import (
+	"CanMe/backend/types"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// 按时间轴配对时要求的最小重叠比例（交集/并集）
+const minOverlap = 0.5
+
+// Pair 两组片段中相互对应的下标，From 或 To 为 -1 表示该片段只存在于一侧
+type Pair struct {
+	From int
+	To   int
+}
+
+// Match 配对两组片段：先按 ID 配对（同一项目的不同版本），
+// 剩余的按时间轴重叠度配对（拆分/合并后 ID 变化或不同项目之间）
+func Match(from, to []types.SubtitleSegment) []Pair {
+	toByID := make(map[string]int, len(to))
+	for i, segment := range to {
+		toByID[segment.ID] = i
+	}
+	fromMatched := make([]int, len(from))
+	toMatched := make([]bool, len(to))
+	for i, segment := range from {
+		fromMatched[i] = -1
+		if j, ok := toByID[segment.ID]; ok && segment.ID != "" && !toMatched[j] {
+			fromMatched[i] = j
+			toMatched[j] = true
+		}
+	}
+
+	for i := range from {
+		if fromMatched[i] >= 0 {
+			continue
+		}
+		best, bestOverlap := -1, minOverlap
+		for j := range to {
+			if toMatched[j] {
+				continue
+			}
+			if overlap := overlapRatio(from[i], to[j]); overlap >= bestOverlap {
+				best, bestOverlap = j, overlap
+			}
+		}
+		if best >= 0 {
+			fromMatched[i] = best
+			toMatched[best] = true
+		}
+	}
+
+	pairs := make([]Pair, 0, len(from)+len(to))
+	for i, j := range fromMatched {
+		pairs = append(pairs, Pair{From: i, To: j})
+	}
+	for j, matched := range toMatched {
+		if !matched {
+			pairs = append(pairs, Pair{From: -1, To: j})
+		}
+	}
+	// 按时间排序，优先使用新版本中的时间
+	start := func(p Pair) time.Duration {
+		if p.To >= 0 {
+			return to[p.To].StartTime.Time
+		}
+		return from[p.From].StartTime.Time
+	}
+	sort.SliceStable(pairs, func(a, b int) bool { return start(pairs[a]) < start(pairs[b]) })
+	return pairs
+}
+
+// Diff 对比两组片段；languages 为空时对比两侧出现的所有语言
+func Diff(from, to []types.SubtitleSegment, languages []string) types.ProjectDiff {
+	diff := types.ProjectDiff{Segments: []types.SegmentDiff{}}
+	for _, pair := range Match(from, to) {
+		var entry types.SegmentDiff
+		var before, after *types.SubtitleSegment
+		if pair.From >= 0 {
+			before = &from[pair.From]
+			entry.FromID = before.ID
+			entry.FromStartMs, entry.FromEndMs = before.StartTime.Time.Milliseconds(), before.EndTime.Time.Milliseconds()
+		}
+		if pair.To >= 0 {
+			after = &to[pair.To]
+			entry.ToID = after.ID
+			entry.ToStartMs, entry.ToEndMs = after.StartTime.Time.Milliseconds(), after.EndTime.Time.Milliseconds()
+		}
+
+		switch {
+		case before == nil:
+			entry.Type = types.SegmentDiffAdded
+			diff.Added++
+		case after == nil:
+			entry.Type = types.SegmentDiffRemoved
+			diff.Removed++
+		default:
+			entry.TimingChanged = before.StartTime.Time != after.StartTime.Time || before.EndTime.Time != after.EndTime.Time
+		}
+
+		for _, lang := range diffLanguages(before, after, languages) {
+			var beforeText, afterText string
+			if before != nil {
+				beforeText = before.Languages[lang].Text
+			}
+			if after != nil {
+				afterText = after.Languages[lang].Text
+			}
+			if beforeText != afterText {
+				if entry.Texts == nil {
+					entry.Texts = make(map[string]types.TextChange)
+				}
+				entry.Texts[lang] = types.TextChange{Before: beforeText, After: afterText}
+			}
+		}
+
+		if entry.Type == "" {
+			if !entry.TimingChanged && len(entry.Texts) == 0 {
+				diff.Unchanged++
+				continue
+			}
+			entry.Type = types.SegmentDiffChanged
+			diff.Changed++
+		}
+		diff.Segments = append(diff.Segments, entry)
+	}
+	return diff
+}
+
+func diffLanguages(before, after *types.SubtitleSegment, languages []string) []string {
+	if len(languages) > 0 {
+		return languages
+	}
+	set := make(map[string]bool)
+	for _, segment := range []*types.SubtitleSegment{before, after} {
+		if segment == nil {
+			continue
+		}
+		for lang := range segment.Languages {
+			set[lang] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(set))
+}
+
+func overlapRatio(a, b types.SubtitleSegment) float64 {
+	start := max(a.StartTime.Time, b.StartTime.Time)
+	end := min(a.EndTime.Time, b.EndTime.Time)
+	union := max(a.EndTime.Time, b.EndTime.Time) - min(a.StartTime.Time, b.StartTime.Time)
+	if end <= start || union <= 0 {
+		return 0
+	}
+	return float64(end-start) / float64(union)
+}
@@ -0,0 +1,74 @@
+package subdiff
+
+import (
+	"CanMe/backend/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seg(id string, startMs, endMs int, texts map[string]string) types.SubtitleSegment {
+	segment := types.SubtitleSegment{
+		ID:        id,
+		StartTime: types.Timecode{Time: time.Duration(startMs) * time.Millisecond},
+		EndTime:   types.Timecode{Time: time.Duration(endMs) * time.Millisecond},
+		Languages: make(map[string]types.LanguageContent),
+	}
+	for lang, text := range texts {
+		segment.Languages[lang] = types.LanguageContent{Text: text}
+	}
+	return segment
+}
+
+func TestDiffByID(t *testing.T) {
+	from := []types.SubtitleSegment{
+		seg("a", 0, 1000, map[string]string{"en": "Hello", "zh": "你好"}),
+		seg("b", 1000, 2000, map[string]string{"en": "Bye"}),
+		seg("c", 3000, 4000, map[string]string{"en": "Gone"}),
+	}
+	to := []types.SubtitleSegment{
+		seg("a", 0, 1000, map[string]string{"en": "Hello", "zh": "您好"}),
+		seg("b", 1200, 2000, map[string]string{"en": "Bye"}),
+		seg("d", 5000, 6000, map[string]string{"en": "New"}),
+	}
+
+	diff := Diff(from, to, nil)
+	assert.Equal(t, 1, diff.Added)
+	assert.Equal(t, 1, diff.Removed)
+	assert.Equal(t, 2, diff.Changed)
+	assert.Equal(t, 0, diff.Unchanged)
+	require.Len(t, diff.Segments, 4)
+
+	assert.Equal(t, types.SegmentDiffChanged, diff.Segments[0].Type)
+	assert.False(t, diff.Segments[0].TimingChanged)
+	assert.Equal(t, types.TextChange{Before: "你好", After: "您好"}, diff.Segments[0].Texts["zh"])
+	assert.NotContains(t, diff.Segments[0].Texts, "en")
+
+	assert.True(t, diff.Segments[1].TimingChanged)
+	assert.Empty(t, diff.Segments[1].Texts)
+
+	assert.Equal(t, types.SegmentDiffRemoved, diff.Segments[2].Type)
+	assert.Equal(t, "Gone", diff.Segments[2].Texts["en"].Before)
+	assert.Equal(t, types.SegmentDiffAdded, diff.Segments[3].Type)
+
+	// 只对比指定语言
+	diff = Diff(from, to, []string{"en"})
+	assert.Equal(t, 1, diff.Changed)
+	assert.Equal(t, 1, diff.Unchanged)
+}
+
+func TestMatchByTiming(t *testing.T) {
+	// 不同项目之间 ID 不同，按时间轴重叠配对
+	from := []types.SubtitleSegment{
+		seg("x1", 0, 1000, nil),
+		seg("x2", 2000, 3000, nil),
+	}
+	to := []types.SubtitleSegment{
+		seg("y2", 2100, 3000, nil),
+		seg("y1", 0, 400, nil), // 重叠不足一半
+	}
+	pairs := Match(from, to)
+	assert.Equal(t, []Pair{{From: 0, To: -1}, {From: -1, To: 1}, {From: 1, To: 0}}, pairs)
+}
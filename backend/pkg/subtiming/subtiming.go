@@ -0,0 +1,91 @@
+// Package subtiming 提供字幕时间轴的整体偏移、两点线性拉伸与帧率转换计算。
+package subtiming
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 常用的 NTSC 帧率（x/1001），输入 23.976、29.97 等近似值时归一为精确值
+var ntscRates = []float64{24000.0 / 1001, 30000.0 / 1001, 48000.0 / 1001, 60000.0 / 1001, 120000.0 / 1001}
+
+// NormalizeFrameRate 将 23.976/23.98、29.97 等近似值归一为 x/1001 精确帧率
+func NormalizeFrameRate(fps float64) float64 {
+	for _, rate := range ntscRates {
+		if math.Abs(fps-rate) < 0.01 {
+			return rate
+		}
+	}
+	return fps
+}
+
+// ParseFrameRate 解析帧率，支持小数（23.976）与分数（24000/1001）写法
+func ParseFrameRate(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	var fps float64
+	if num, den, ok := strings.Cut(value, "/"); ok {
+		n, err1 := strconv.ParseFloat(strings.TrimSpace(num), 64)
+		d, err2 := strconv.ParseFloat(strings.TrimSpace(den), 64)
+		if err1 != nil || err2 != nil || d == 0 {
+			return 0, fmt.Errorf("invalid frame rate: %s", value)
+		}
+		fps = n / d
+	} else {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid frame rate: %s", value)
+		}
+		fps = f
+	}
+	if err := ValidateFrameRate(fps); err != nil {
+		return 0, err
+	}
+	return NormalizeFrameRate(fps), nil
+}
+
+// ValidateFrameRate 检查帧率是否在合理范围内
+func ValidateFrameRate(fps float64) error {
+	if fps <= 0 || fps > 240 || math.IsNaN(fps) {
+		return fmt.Errorf("invalid frame rate: %v", fps)
+	}
+	return nil
+}
+
+// Offset 整体平移，结果不早于 0
+func Offset(t, delta time.Duration) time.Duration {
+	return max(t+delta, 0)
+}
+
+// Stretch 按两个同步点做线性映射：from1→to1、from2→to2，同步点之外按同一比例外推
+type Stretch struct {
+	from1, to1 time.Duration
+	scale      float64
+}
+
+// NewStretch 创建两点拉伸，两个同步点的原时间不能相同
+func NewStretch(from1, to1, from2, to2 time.Duration) (*Stretch, error) {
+	if from1 == from2 {
+		return nil, errors.New("sync points must have different source times")
+	}
+	scale := float64(to2-to1) / float64(from2-from1)
+	if scale <= 0 {
+		return nil, errors.New("sync points must keep their order")
+	}
+	return &Stretch{from1: from1, to1: to1, scale: scale}, nil
+}
+
+// Apply 返回映射后的时间，结果不早于 0
+func (s *Stretch) Apply(t time.Duration) time.Duration {
+	mapped := float64(s.to1) + float64(t-s.from1)*s.scale
+	return max(time.Duration(math.Round(mapped)), 0)
+}
+
+// ConvertFrameRate 按播放速度变化换算时间：以 fromFPS 制作的字幕用于以 toFPS 播放的视频
+// （如 23.976→25 的 PAL 加速，时间缩短为原来的 23.976/25）
+func ConvertFrameRate(t time.Duration, fromFPS, toFPS float64) time.Duration {
+	return time.Duration(math.Round(float64(t) * fromFPS / toFPS))
+}
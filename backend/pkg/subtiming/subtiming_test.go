@@ -0,0 +1,57 @@
+package subtiming
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFrameRate(t *testing.T) {
+	fps, err := ParseFrameRate("23.976")
+	require.NoError(t, err)
+	assert.Equal(t, 24000.0/1001, fps)
+
+	fps, err = ParseFrameRate("30000/1001")
+	require.NoError(t, err)
+	assert.Equal(t, 30000.0/1001, fps)
+
+	fps, err = ParseFrameRate("25")
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, fps)
+
+	for _, bad := range []string{"", "abc", "0", "-24", "24/0", "1000"} {
+		_, err := ParseFrameRate(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestOffset(t *testing.T) {
+	assert.Equal(t, 3*time.Second, Offset(time.Second, 2*time.Second))
+	assert.Equal(t, time.Duration(0), Offset(time.Second, -2*time.Second))
+}
+
+func TestStretch(t *testing.T) {
+	// 10s→12s，110s→122s：比例 1.1
+	s, err := NewStretch(10*time.Second, 12*time.Second, 110*time.Second, 122*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Second, s.Apply(10*time.Second))
+	assert.Equal(t, 122*time.Second, s.Apply(110*time.Second))
+	assert.Equal(t, 67*time.Second, s.Apply(60*time.Second))
+	assert.Equal(t, 133*time.Second, s.Apply(120*time.Second))
+	assert.Equal(t, 1*time.Second, s.Apply(0))
+
+	_, err = NewStretch(time.Second, time.Second, time.Second, 2*time.Second)
+	assert.Error(t, err)
+	_, err = NewStretch(time.Second, 5*time.Second, 2*time.Second, 4*time.Second)
+	assert.Error(t, err)
+}
+
+func TestConvertFrameRate(t *testing.T) {
+	ntsc := NormalizeFrameRate(23.976)
+	// PAL 加速：一小时的 23.976 素材在 25fps 下约 57:32.5
+	assert.Equal(t, 3452547*time.Millisecond, ConvertFrameRate(time.Hour, ntsc, 25).Round(time.Millisecond))
+	assert.Equal(t, time.Hour, ConvertFrameRate(ConvertFrameRate(time.Hour, 24, 25), 25, 24))
+	assert.Equal(t, 1001*time.Millisecond, ConvertFrameRate(time.Second, 24, ntsc))
+}
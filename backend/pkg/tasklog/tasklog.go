@@ -0,0 +1,200 @@
+// Package tasklog 按任务保存外部命令（yt-dlp/FFmpeg）的命令行与输出，单个任务的日志按大小轮转。
+package tasklog
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxSize    = 2 * 1024 * 1024 // 单个日志文件大小上限
+	DefaultMaxBackups = 2               // 每个任务保留的轮转文件数
+)
+
+// Line 一条日志记录
+type Line struct {
+	TaskID string `json:"id"`
+	Stream string `json:"stream"` // cmd | stdout | stderr | info
+	Text   string `json:"text"`
+	Time   int64  `json:"time"` // unix 毫秒
+}
+
+// Store 基于文件的任务日志存储
+type Store struct {
+	dir        string
+	maxSize    int64
+	maxBackups int
+
+	mu       sync.Mutex
+	listener func(Line)
+}
+
+// DefaultDir 返回默认日志目录 ~/.canme/logs/tasks
+func DefaultDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".canme", "logs", "tasks")
+}
+
+func New(dir string, maxSize int64, maxBackups int) *Store {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if maxBackups < 0 {
+		maxBackups = 0
+	}
+	return &Store{dir: dir, maxSize: maxSize, maxBackups: maxBackups}
+}
+
+// SetListener 注册新日志行的回调（用于实时推送），回调在写入完成后同步调用
+func (s *Store) SetListener(fn func(Line)) {
+	s.mu.Lock()
+	s.listener = fn
+	s.mu.Unlock()
+}
+
+// Append 追加文本，多行文本按行拆分
+func (s *Store) Append(taskID, stream, text string) error {
+	path, err := s.path(taskID)
+	if err != nil {
+		return err
+	}
+	text = strings.TrimRight(text, "\r\n")
+	if text == "" {
+		return nil
+	}
+
+	now := time.Now()
+	var lines []Line
+	var b strings.Builder
+	for _, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		lines = append(lines, Line{TaskID: taskID, Stream: stream, Text: raw, Time: now.UnixMilli()})
+		fmt.Fprintf(&b, "%s [%s] %s\n", now.Format("2006-01-02 15:04:05.000"), stream, raw)
+	}
+
+	s.mu.Lock()
+	err = s.write(path, b.String())
+	listener := s.listener
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if listener != nil {
+		for _, l := range lines {
+			listener(l)
+		}
+	}
+	return nil
+}
+
+// Read 返回任务的完整日志（由旧到新），不存在时返回空字符串
+func (s *Store) Read(taskID string) (string, error) {
+	path, err := s.path(taskID)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b strings.Builder
+	for i := s.maxBackups; i >= 0; i-- {
+		data, err := os.ReadFile(backupName(path, i))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+		b.Write(data)
+	}
+	return b.String(), nil
+}
+
+// Tail 返回最后 n 行
+func (s *Store) Tail(taskID string, n int) ([]string, error) {
+	content, err := s.Read(taskID)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, scanner.Err()
+}
+
+// Delete 删除任务的全部日志文件
+func (s *Store) Delete(taskID string) error {
+	path, err := s.path(taskID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i <= s.maxBackups; i++ {
+		if err := os.Remove(backupName(path, i)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) write(path, data string) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(data)) > s.maxSize {
+		s.rotate(path)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(data)
+	return err
+}
+
+// rotate 将 <id>.log 依次移动为 <id>.log.1、<id>.log.2…，超出数量的最旧文件被删除
+func (s *Store) rotate(path string) {
+	if s.maxBackups == 0 {
+		_ = os.Remove(path)
+		return
+	}
+	_ = os.Remove(backupName(path, s.maxBackups))
+	for i := s.maxBackups - 1; i >= 0; i-- {
+		_ = os.Rename(backupName(path, i), backupName(path, i+1))
+	}
+}
+
+func (s *Store) path(taskID string) (string, error) {
+	if taskID == "" || taskID != filepath.Base(taskID) || strings.ContainsAny(taskID, `/\:`) || strings.HasPrefix(taskID, ".") {
+		return "", fmt.Errorf("invalid task id: %q", taskID)
+	}
+	return filepath.Join(s.dir, taskID+".log"), nil
+}
+
+func backupName(path string, i int) string {
+	if i == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s.%d", path, i)
+}
@@ -0,0 +1,52 @@
+package tasklog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendReadRotateDelete(t *testing.T) {
+	store := New(t.TempDir(), 200, 1)
+
+	var received []Line
+	store.SetListener(func(l Line) { received = append(received, l) })
+
+	require.NoError(t, store.Append("task-1", "cmd", "yt-dlp --newline https://example.com/watch"))
+	require.NoError(t, store.Append("task-1", "stderr", "line one\nline two\n"))
+	assert.Len(t, received, 3)
+	assert.Equal(t, "line two", received[2].Text)
+
+	content, err := store.Read("task-1")
+	require.NoError(t, err)
+	assert.Contains(t, content, "[cmd] yt-dlp --newline")
+	assert.Contains(t, content, "[stderr] line two")
+
+	// exceed the size limit a few times: oldest content is dropped, newest kept
+	for i := 0; i < 5; i++ {
+		require.NoError(t, store.Append("task-1", "stdout", strings.Repeat("x", 80)))
+	}
+	require.NoError(t, store.Append("task-1", "stdout", "latest"))
+	content, err = store.Read("task-1")
+	require.NoError(t, err)
+	assert.NotContains(t, content, "[cmd]")
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(content), "latest"))
+
+	tail, err := store.Tail("task-1", 1)
+	require.NoError(t, err)
+	require.Len(t, tail, 1)
+	assert.Contains(t, tail[0], "latest")
+
+	require.NoError(t, store.Delete("task-1"))
+	content, err = store.Read("task-1")
+	require.NoError(t, err)
+	assert.Empty(t, content)
+}
+
+func TestInvalidTaskID(t *testing.T) {
+	store := New(t.TempDir(), 0, 0)
+	assert.Error(t, store.Append("../escape", "info", "x"))
+	assert.Error(t, store.Append("", "info", "x"))
+}
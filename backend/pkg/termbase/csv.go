@@ -0,0 +1,106 @@
+package termbase
+
+import (
+	"CanMe/backend/types"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSV 列：前两列为原文与译文，表头可写 source/target 或直接写语言标识（如 en,zh-Hans）
+var csvColumns = []string{"source", "target", "case_sensitive", "do_not_translate", "note"}
+
+// ParseCSV 读取 CSV 术语表，表头前两列为语言标识时作为术语表语言返回
+func ParseCSV(r io.Reader) (*types.Glossary, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("empty CSV")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(header) < 2 {
+		return nil, errors.New("CSV needs at least source and target columns")
+	}
+
+	glossary := &types.Glossary{Terms: []types.GlossaryTerm{}}
+	index := map[string]int{"source": 0, "target": 1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if i >= 2 {
+			index[name] = i
+		}
+	}
+	if first := strings.TrimSpace(strings.TrimPrefix(header[0], "\ufeff")); !strings.EqualFold(first, "source") {
+		glossary.SourceLang = first
+	}
+	if second := strings.TrimSpace(header[1]); !strings.EqualFold(second, "target") {
+		glossary.TargetLang = second
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := index[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		term := types.GlossaryTerm{
+			Source:         field(record, "source"),
+			Target:         field(record, "target"),
+			CaseSensitive:  parseBool(field(record, "case_sensitive")),
+			DoNotTranslate: parseBool(field(record, "do_not_translate")),
+			Note:           field(record, "note"),
+		}
+		if term.Source == "" || (term.Target == "" && !term.DoNotTranslate) {
+			continue
+		}
+		glossary.Terms = append(glossary.Terms, term)
+	}
+	return glossary, nil
+}
+
+// WriteCSV 写出 CSV 术语表，术语表有语言时表头前两列使用语言标识
+func WriteCSV(w io.Writer, glossary *types.Glossary) error {
+	header := append([]string{}, csvColumns...)
+	if glossary.SourceLang != "" {
+		header[0] = glossary.SourceLang
+	}
+	if glossary.TargetLang != "" {
+		header[1] = glossary.TargetLang
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, term := range glossary.Terms {
+		record := []string{
+			term.Source,
+			term.Target,
+			strconv.FormatBool(term.CaseSensitive),
+			strconv.FormatBool(term.DoNotTranslate),
+			term.Note,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,211 @@
+package termbase
+
+import (
+	"CanMe/backend/types"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// 未指定语言的术语表导出时使用的占位语言标识
+const (
+	placeholderSource = "x-source"
+	placeholderTarget = "x-target"
+)
+
+// 术语属性使用自定义类型（TBX 未定义大小写与不翻译的标准字段）
+const (
+	tbxCaseSensitive  = "x-caseSensitive"
+	tbxDoNotTranslate = "x-doNotTranslate"
+)
+
+// tbxDocument 兼容 TBX 2（martif/termEntry/langSet/tig）与 TBX 3（tbx/conceptEntry/langSec/termSec）
+type tbxDocument struct {
+	Lang     string     `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Entries  []tbxEntry `xml:"text>body>termEntry"`
+	Concepts []tbxEntry `xml:"text>body>conceptEntry"`
+}
+
+type tbxEntry struct {
+	Notes     []string      `xml:"note"`
+	Descrips  []tbxProperty `xml:"descrip"`
+	DescGrps  []tbxProperty `xml:"descripGrp>descrip"`
+	LangSets  []tbxLangSet  `xml:"langSet"`
+	LangSecs  []tbxLangSet  `xml:"langSec"`
+	TermNotes []tbxProperty `xml:"termNote"`
+}
+
+type tbxLangSet struct {
+	Lang     string    `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Tigs     []tbxTerm `xml:"tig"`
+	TermSecs []tbxTerm `xml:"termSec"`
+	Ntigs    []tbxTerm `xml:"ntig>termGrp"`
+}
+
+type tbxTerm struct {
+	Term      string        `xml:"term"`
+	TermNotes []tbxProperty `xml:"termNote"`
+	Notes     []string      `xml:"note"`
+}
+
+type tbxProperty struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (l tbxLangSet) terms() []tbxTerm {
+	terms := append(append(append([]tbxTerm{}, l.Tigs...), l.TermSecs...), l.Ntigs...)
+	out := terms[:0]
+	for _, t := range terms {
+		if strings.TrimSpace(t.Term) != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// ParseTBX 读取 TBX 术语表。sourceLang/targetLang 为空时分别取文档语言（或首个语言）与首个不同的语言
+func ParseTBX(r io.Reader, sourceLang, targetLang string) (*types.Glossary, error) {
+	var doc tbxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid TBX: %w", err)
+	}
+	entries := append(doc.Entries, doc.Concepts...)
+
+	if sourceLang == "" {
+		sourceLang = doc.Lang
+	}
+	for _, entry := range entries {
+		for _, set := range entry.langSets() {
+			if sourceLang == "" {
+				sourceLang = set.Lang
+			} else if targetLang == "" && !SameLanguage(set.Lang, sourceLang) {
+				targetLang = set.Lang
+			}
+		}
+		if sourceLang != "" && targetLang != "" {
+			break
+		}
+	}
+
+	glossary := &types.Glossary{
+		SourceLang: placeholderToEmpty(sourceLang),
+		TargetLang: placeholderToEmpty(targetLang),
+		Terms:      []types.GlossaryTerm{},
+	}
+	for _, entry := range entries {
+		var source, target *tbxTerm
+		for _, set := range entry.langSets() {
+			terms := set.terms()
+			if len(terms) == 0 {
+				continue
+			}
+			if source == nil && SameLanguage(set.Lang, sourceLang) {
+				source = &terms[0]
+			} else if target == nil && targetLang != "" && SameLanguage(set.Lang, targetLang) {
+				target = &terms[0]
+			}
+		}
+		if source == nil {
+			continue
+		}
+
+		props := append(append(append([]tbxProperty{}, entry.Descrips...), entry.DescGrps...), entry.TermNotes...)
+		props = append(props, source.TermNotes...)
+		term := types.GlossaryTerm{Source: strings.TrimSpace(source.Term)}
+		for _, prop := range props {
+			switch prop.Type {
+			case tbxCaseSensitive:
+				term.CaseSensitive = parseBool(prop.Value)
+			case tbxDoNotTranslate:
+				term.DoNotTranslate = parseBool(prop.Value)
+			}
+		}
+		notes := append(append([]string{}, entry.Notes...), source.Notes...)
+		term.Note = strings.TrimSpace(strings.Join(notes, "\n"))
+		if target != nil {
+			term.Target = strings.TrimSpace(target.Term)
+		}
+		if term.Target == "" && !term.DoNotTranslate {
+			continue
+		}
+		glossary.Terms = append(glossary.Terms, term)
+	}
+	return glossary, nil
+}
+
+func (e tbxEntry) langSets() []tbxLangSet {
+	return append(append([]tbxLangSet{}, e.LangSets...), e.LangSecs...)
+}
+
+// WriteTBX 以 TBX-Basic（martif）格式写出术语表
+func WriteTBX(w io.Writer, glossary *types.Glossary) error {
+	sourceLang := orPlaceholder(glossary.SourceLang, placeholderSource)
+	targetLang := orPlaceholder(glossary.TargetLang, placeholderTarget)
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, "<martif type=\"TBX\" xml:lang=\"%s\">\n", escape(sourceLang))
+	b.WriteString("  <martifHeader>\n    <fileDesc>\n      <sourceDesc>\n")
+	fmt.Fprintf(&b, "        <p>%s</p>\n", escape(glossary.Name))
+	b.WriteString("      </sourceDesc>\n    </fileDesc>\n  </martifHeader>\n")
+	b.WriteString("  <text>\n    <body>\n")
+	for i, term := range glossary.Terms {
+		fmt.Fprintf(&b, "      <termEntry id=\"t%d\">\n", i+1)
+		if term.CaseSensitive {
+			fmt.Fprintf(&b, "        <descrip type=\"%s\">true</descrip>\n", tbxCaseSensitive)
+		}
+		if term.DoNotTranslate {
+			fmt.Fprintf(&b, "        <descrip type=\"%s\">true</descrip>\n", tbxDoNotTranslate)
+		}
+		if term.Note != "" {
+			fmt.Fprintf(&b, "        <note>%s</note>\n", escape(term.Note))
+		}
+		writeLangSet(&b, sourceLang, term.Source)
+		if term.Target != "" {
+			writeLangSet(&b, targetLang, term.Target)
+		}
+		b.WriteString("      </termEntry>\n")
+	}
+	b.WriteString("    </body>\n  </text>\n</martif>\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func writeLangSet(b *bytes.Buffer, lang, term string) {
+	fmt.Fprintf(b, "        <langSet xml:lang=\"%s\">\n", escape(lang))
+	fmt.Fprintf(b, "          <tig>\n            <term>%s</term>\n          </tig>\n", escape(term))
+	b.WriteString("        </langSet>\n")
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func orPlaceholder(lang, placeholder string) string {
+	if strings.TrimSpace(lang) == "" {
+		return placeholder
+	}
+	return lang
+}
+
+func placeholderToEmpty(lang string) string {
+	if lang == placeholderSource || lang == placeholderTarget {
+		return ""
+	}
+	return lang
+}
+
+func parseBool(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "y", "yes", "x", "on":
+		return true
+	}
+	b, _ := strconv.ParseBool(strings.TrimSpace(value))
+	return b
+}
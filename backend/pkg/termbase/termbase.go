@@ -0,0 +1,178 @@
+// Package termbase 提供术语表匹配检查、翻译记忆相似度计算，以及术语表的 TBX/CSV 读写。
+package termbase
+
+import (
+	"CanMe/backend/types"
+	"strings"
+	"unicode"
+)
+
+// SameLanguage 判断两个语言标识是否指同一语言（忽略大小写与地区子标签，空值视为任意语言）
+func SameLanguage(a, b string) bool {
+	a, b = normalizeLang(a), normalizeLang(b)
+	if a == "" || b == "" || a == b {
+		return true
+	}
+	baseA, _, _ := strings.Cut(a, "-")
+	baseB, _, _ := strings.Cut(b, "-")
+	// x- 为私有标识，需完全一致
+	return baseA == baseB && baseA != "x"
+}
+
+func normalizeLang(lang string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+}
+
+// NormalizeText 规范化用于翻译记忆比较的文本：小写、去标点、合并空白
+func NormalizeText(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r):
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// Similarity 返回两段文本规范化后基于编辑距离的相似度（0-1）
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(NormalizeText(a)), []rune(NormalizeText(b))
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	longest := max(len(ra), len(rb))
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// Relevant 返回原文中出现的术语
+func Relevant(terms []types.GlossaryTerm, source string) []types.GlossaryTerm {
+	text := []rune(source)
+	var out []types.GlossaryTerm
+	for _, term := range terms {
+		if len(findTerm(text, []rune(term.Source), !term.CaseSensitive)) > 0 {
+			out = append(out, term)
+		}
+	}
+	return out
+}
+
+// Check 检查译文是否遵守原文中出现的术语
+func Check(terms []types.GlossaryTerm, source, target string) []types.GlossaryIssue {
+	text := []rune(target)
+	var issues []types.GlossaryIssue
+	for _, term := range Relevant(terms, source) {
+		expected := expectedTarget(term)
+		if expected == "" {
+			continue
+		}
+		if len(findTerm(text, []rune(expected), !term.CaseSensitive)) > 0 {
+			continue
+		}
+		issueType := types.GlossaryIssueMissing
+		if term.DoNotTranslate {
+			issueType = types.GlossaryIssueTranslated
+		}
+		issues = append(issues, types.GlossaryIssue{Type: issueType, Source: term.Source, Target: expected})
+	}
+	return issues
+}
+
+// Enforce 将译文中大小写不符的术语改为术语表中的写法（仅处理区分大小写或不翻译的术语）
+func Enforce(terms []types.GlossaryTerm, source, target string) string {
+	text := []rune(target)
+	for _, term := range Relevant(terms, source) {
+		if !term.CaseSensitive && !term.DoNotTranslate {
+			continue
+		}
+		expected := []rune(expectedTarget(term))
+		if len(expected) == 0 {
+			continue
+		}
+		for _, pos := range findTerm(text, expected, true) {
+			copy(text[pos:], expected)
+		}
+	}
+	return string(text)
+}
+
+// expectedTarget 译文中应出现的写法，不翻译的术语为原文本身
+func expectedTarget(term types.GlossaryTerm) string {
+	if term.DoNotTranslate {
+		return term.Source
+	}
+	return term.Target
+}
+
+// findTerm 返回 term 在 text 中的起始位置（按 rune）。
+// 以字母数字开头或结尾的术语要求词边界，中日韩文字不要求
+func findTerm(text, term []rune, fold bool) []int {
+	if len(term) == 0 || len(term) > len(text) {
+		return nil
+	}
+	var positions []int
+	for i := 0; i+len(term) <= len(text); i++ {
+		if !runesEqual(text[i:i+len(term)], term, fold) {
+			continue
+		}
+		if needsBoundary(term[0]) && i > 0 && isWordRune(text[i-1]) {
+			continue
+		}
+		end := i + len(term)
+		if needsBoundary(term[len(term)-1]) && end < len(text) && isWordRune(text[end]) {
+			continue
+		}
+		positions = append(positions, i)
+	}
+	return positions
+}
+
+func runesEqual(a, b []rune, fold bool) bool {
+	for i := range a {
+		if a[i] == b[i] {
+			continue
+		}
+		if !fold || !strings.EqualFold(string(a[i]), string(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func needsBoundary(r rune) bool {
+	if !isWordRune(r) {
+		return false
+	}
+	return !unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Thai)
+}
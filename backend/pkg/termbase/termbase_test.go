@@ -0,0 +1,123 @@
+package termbase
+
+import (
+	"CanMe/backend/types"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSameLanguage(t *testing.T) {
+	assert.True(t, SameLanguage("en", "en-US"))
+	assert.True(t, SameLanguage("zh_Hans", "ZH-hans"))
+	assert.True(t, SameLanguage("", "ja"))
+	assert.False(t, SameLanguage("en", "zh"))
+	assert.False(t, SameLanguage("x-source", "x-target"))
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("Hello, world!", "hello world"))
+	assert.InDelta(t, 0.9, Similarity("Where is Walter?", "Where is Walt?"), 0.1)
+	assert.Less(t, Similarity("Good morning", "See you tomorrow"), 0.5)
+	assert.Equal(t, 1.0, Similarity("", "..."))
+}
+
+func TestCheckAndEnforce(t *testing.T) {
+	terms := []types.GlossaryTerm{
+		{Source: "Heisenberg", DoNotTranslate: true, CaseSensitive: true},
+		{Source: "blue sky", Target: "蓝天"},
+		{Source: "cook", Target: "烹制"},
+		{Source: "Jesse", Target: "JESSE", CaseSensitive: true},
+	}
+
+	assert.Len(t, Relevant(terms, "Say my name. HEISENBERG."), 0)
+	assert.Len(t, Relevant(terms, "They cooked it."), 0)
+	assert.Len(t, Relevant(terms, "Blue Sky is the product."), 1)
+
+	issues := Check(terms, "Heisenberg wants Blue Sky.", "海森堡想要蓝天。")
+	require.Len(t, issues, 1)
+	assert.Equal(t, types.GlossaryIssueTranslated, issues[0].Type)
+	assert.Equal(t, "Heisenberg", issues[0].Target)
+
+	issues = Check(terms, "We cook.", "我们做饭。")
+	require.Len(t, issues, 1)
+	assert.Equal(t, types.GlossaryIssueMissing, issues[0].Type)
+	assert.Empty(t, Check(terms, "We cook.", "我们烹制。"))
+
+	assert.Equal(t, "Heisenberg 来了，JESSE。", Enforce(terms, "Heisenberg is here, Jesse.", "heisenberg 来了，Jesse。"))
+	assert.Empty(t, Check(terms, "Jesse!", Enforce(terms, "Jesse!", "jesse!")))
+}
+
+func TestTBXRoundTrip(t *testing.T) {
+	glossary := &types.Glossary{
+		Name:       "Series <A>",
+		SourceLang: "en",
+		TargetLang: "zh-Hans",
+		Terms: []types.GlossaryTerm{
+			{Source: "Walter & Co", Target: "沃尔特公司", Note: "company"},
+			{Source: "Heisenberg", DoNotTranslate: true, CaseSensitive: true},
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, WriteTBX(&buf, glossary))
+
+	parsed, err := ParseTBX(&buf, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "en", parsed.SourceLang)
+	assert.Equal(t, "zh-Hans", parsed.TargetLang)
+	assert.Equal(t, glossary.Terms, parsed.Terms)
+
+	// 未指定语言的术语表
+	buf.Reset()
+	require.NoError(t, WriteTBX(&buf, &types.Glossary{Terms: glossary.Terms}))
+	parsed, err = ParseTBX(&buf, "", "")
+	require.NoError(t, err)
+	assert.Empty(t, parsed.SourceLang)
+	assert.Equal(t, glossary.Terms, parsed.Terms)
+}
+
+func TestParseTBX3(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<tbx type="TBX-Basic" style="dca" xml:lang="en" xmlns="urn:iso:std:iso:30042:ed-2">
+  <text><body>
+    <conceptEntry id="c1">
+      <langSec xml:lang="en"><termSec><term>spaceship</term></termSec></langSec>
+      <langSec xml:lang="de"><termSec><term>Raumschiff</term></termSec></langSec>
+      <langSec xml:lang="fr"><termSec><term>vaisseau</term></termSec></langSec>
+    </conceptEntry>
+    <conceptEntry id="c2">
+      <langSec xml:lang="en"><termSec><term>orphan</term></termSec></langSec>
+    </conceptEntry>
+  </body></text>
+</tbx>`
+	parsed, err := ParseTBX(strings.NewReader(doc), "", "fr")
+	require.NoError(t, err)
+	assert.Equal(t, "en", parsed.SourceLang)
+	assert.Equal(t, []types.GlossaryTerm{{Source: "spaceship", Target: "vaisseau"}}, parsed.Terms)
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	glossary := &types.Glossary{
+		SourceLang: "en",
+		TargetLang: "ja",
+		Terms: []types.GlossaryTerm{
+			{Source: "Jedi, Knight", Target: "ジェダイの騎士"},
+			{Source: "R2-D2", DoNotTranslate: true, Note: "droid"},
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, glossary))
+	assert.True(t, strings.HasPrefix(buf.String(), "en,ja,case_sensitive"))
+
+	parsed, err := ParseCSV(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, glossary, parsed)
+
+	parsed, err = ParseCSV(strings.NewReader("\ufeffSource,Target,Note\nfoo,bar,x\n,skipped\nbaz,\n"))
+	require.NoError(t, err)
+	assert.Empty(t, parsed.SourceLang)
+	assert.Equal(t, []types.GlossaryTerm{{Source: "foo", Target: "bar", Note: "x"}}, parsed.Terms)
+}
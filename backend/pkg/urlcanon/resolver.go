@@ -0,0 +1,103 @@
+package urlcanon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxRedirects = 5
+
+// 需要联网展开的已知短链接域名
+var shortenerHosts = []string{"b23.tv", "bili2233.cn", "vm.tiktok.com", "vt.tiktok.com", "t.co"}
+
+// IsShortLink 判断链接是否为需要联网展开的已知短链接
+func IsShortLink(raw string) bool {
+	u := parse(raw)
+	return u != nil && hostIn(u.Hostname(), shortenerHosts)
+}
+
+// Resolver 展开已知短链接（仅请求短链接域名本身，不访问目标站点），结果在内存中缓存
+type Resolver struct {
+	client func() *http.Client
+	cache  sync.Map // 短链接 -> 规范化后的目标
+}
+
+// NewResolver 创建短链接解析器；client 每次解析时调用以获取当前（可能带代理的）客户端，为空时使用默认客户端
+func NewResolver(client func() *http.Client) *Resolver {
+	return &Resolver{client: client}
+}
+
+// httpClient 复制客户端并禁止自动跳转，逐跳读取 Location，只跟随仍为短链接的跳转
+func (r *Resolver) httpClient() *http.Client {
+	var c http.Client
+	if r.client != nil {
+		if base := r.client(); base != nil {
+			c = *base
+		}
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 10 * time.Second
+	}
+	c.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &c
+}
+
+// Resolve 返回规范化后的目标链接；非短链接直接返回 Canonicalize 的结果
+func (r *Resolver) Resolve(ctx context.Context, raw string) (string, error) {
+	if !IsShortLink(raw) {
+		return Canonicalize(raw), nil
+	}
+	key := Canonicalize(raw)
+	if v, ok := r.cache.Load(key); ok {
+		return v.(string), nil
+	}
+
+	client := r.httpClient()
+	current := key
+	for i := 0; i < maxRedirects && IsShortLink(current); i++ {
+		next, err := location(ctx, client, current)
+		if err != nil {
+			return key, err
+		}
+		current = next
+	}
+	resolved := Canonicalize(current)
+	r.cache.Store(key, resolved)
+	return resolved, nil
+}
+
+func location(ctx context.Context, client *http.Client, raw string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, raw, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return "", fmt.Errorf("short link %s did not redirect (status %d)", raw, resp.StatusCode)
+	}
+	loc := strings.TrimSpace(resp.Header.Get("Location"))
+	if loc == "" {
+		return "", fmt.Errorf("short link %s returned an empty location", raw)
+	}
+	base, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	target, err := base.Parse(loc)
+	if err != nil {
+		return "", err
+	}
+	return target.String(), nil
+}
@@ -0,0 +1,248 @@
+// Package urlcanon 将视频链接规范化为稳定的形式，用于重复检测、缓存键与搜索。
+//
+// Canonicalize 完全离线：按站点规则去除跟踪参数、统一移动端/嵌入/短视频等变体。
+// 需要联网才能展开的短链接（如 b23.tv）由 Resolver 按需解析。
+package urlcanon
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// 通用跟踪参数（小写）
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true,
+	"mc_cid": true, "mc_eid": true, "igshid": true, "igsh": true,
+	"ref": true, "ref_src": true, "ref_url": true, "referrer": true,
+	"spm": true, "spm_id_from": true, "from_spmid": true,
+	"share_source": true, "share_medium": true, "share_plat": true, "share_session_id": true,
+	"share_tag": true, "share_from": true, "share_id": true, "share_app_id": true,
+	"vd_source": true, "unique_k": true, "bbid": true, "ts": true,
+	"_r": true, "_t": true, "is_from_webapp": true, "sender_device": true,
+}
+
+type siteRule struct {
+	hosts     []string // 主机名（含子域名匹配）
+	canonical func(u *url.URL) bool
+}
+
+var sites = []siteRule{
+	{hosts: []string{"youtube.com", "youtube-nocookie.com", "youtu.be"}, canonical: canonicalYouTube},
+	{hosts: []string{"bilibili.com"}, canonical: canonicalBilibili},
+	{hosts: []string{"twitter.com", "x.com"}, canonical: canonicalTwitter},
+	{hosts: []string{"tiktok.com"}, canonical: canonicalTikTok},
+	{hosts: []string{"instagram.com"}, canonical: canonicalPathOnly("www.instagram.com")},
+	// 未公开视频的访问哈希 h 是链接身份的一部分
+	{hosts: []string{"vimeo.com"}, canonical: canonicalPathOnly("vimeo.com", "h")},
+}
+
+// Canonicalize 返回规范化后的 URL；无法解析时返回去除首尾空白的原文
+func Canonicalize(raw string) string {
+	u := parse(raw)
+	if u == nil {
+		return strings.TrimSpace(raw)
+	}
+	for _, site := range sites {
+		if hostIn(u.Hostname(), site.hosts) && site.canonical(u) {
+			return u.String()
+		}
+	}
+	canonicalGeneric(u)
+	return u.String()
+}
+
+// IsURL 判断文本是否为带主机名的 http(s) 链接
+func IsURL(raw string) bool {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(strings.ToLower(raw), "http://") && !strings.HasPrefix(strings.ToLower(raw), "https://") {
+		return false
+	}
+	u, err := url.Parse(raw)
+	return err == nil && u.Host != ""
+}
+
+// YouTubeID 返回 YouTube 链接中的视频 ID，非 YouTube 视频链接返回空字符串
+func YouTubeID(raw string) string {
+	u := parse(raw)
+	if u == nil || !hostIn(u.Hostname(), sites[0].hosts) {
+		return ""
+	}
+	if !canonicalYouTube(u) {
+		return ""
+	}
+	return u.Query().Get("v")
+}
+
+func parse(raw string) *url.URL {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+	u.Host = host
+	return u
+}
+
+func hostIn(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalGeneric 去除跟踪参数、排序查询参数并去掉末尾斜杠
+func canonicalGeneric(u *url.URL) {
+	if u.RawQuery != "" {
+		q := u.Query()
+		for key := range q {
+			lk := strings.ToLower(key)
+			if strings.HasPrefix(lk, "utm_") || trackingParams[lk] {
+				q.Del(key)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	trimSlash(u)
+}
+
+func trimSlash(u *url.URL) {
+	if len(u.Path) > 1 && strings.HasSuffix(u.Path, "/") {
+		u.Path = strings.TrimRight(u.Path, "/")
+	}
+	u.RawPath = ""
+}
+
+var youtubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// canonicalYouTube 统一为 https://www.youtube.com/watch?v=ID
+// （youtu.be、shorts、embed、live、music、m. 等变体），非视频页面仅做通用处理
+func canonicalYouTube(u *url.URL) bool {
+	id := ""
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case u.Hostname() == "youtu.be" || strings.HasSuffix(u.Hostname(), ".youtu.be"):
+		id = segments[0]
+	case len(segments) >= 2 && (segments[0] == "shorts" || segments[0] == "embed" || segments[0] == "live" || segments[0] == "v" || segments[0] == "e"):
+		id = segments[1]
+	case segments[0] == "watch":
+		id = u.Query().Get("v")
+	}
+	if !youtubeIDPattern.MatchString(id) {
+		u.Host = "www.youtube.com"
+		if strings.HasPrefix(u.Path, "/playlist") {
+			// 播放列表仅保留 list 参数
+			list := u.Query().Get("list")
+			u.RawQuery = ""
+			if list != "" {
+				u.RawQuery = url.Values{"list": {list}}.Encode()
+			}
+			trimSlash(u)
+			return true
+		}
+		q := u.Query()
+		for _, key := range []string{"si", "feature", "pp"} {
+			q.Del(key)
+		}
+		u.RawQuery = q.Encode()
+		canonicalGeneric(u)
+		return true
+	}
+	u.Host = "www.youtube.com"
+	u.Path = "/watch"
+	u.RawPath = ""
+	u.RawQuery = url.Values{"v": {id}}.Encode()
+	return true
+}
+
+var bvidPattern = regexp.MustCompile(`(?i)^(BV[0-9A-Za-z]{10}|av\d+)$`)
+
+// canonicalBilibili 视频页统一为 https://www.bilibili.com/video/<BV号>，仅保留分P参数（p>1）
+func canonicalBilibili(u *url.URL) bool {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) >= 2 && segments[0] == "video" && bvidPattern.MatchString(segments[1]) {
+		page := u.Query().Get("p")
+		u.Host = "www.bilibili.com"
+		u.Path = "/video/" + segments[1]
+		u.RawPath = ""
+		u.RawQuery = ""
+		if page != "" && page != "1" {
+			u.RawQuery = url.Values{"p": {page}}.Encode()
+		}
+		return true
+	}
+	if u.Hostname() == "m.bilibili.com" {
+		u.Host = "www.bilibili.com"
+	}
+	canonicalGeneric(u)
+	return true
+}
+
+// canonicalTwitter 统一为 x.com 且去除全部查询参数
+func canonicalTwitter(u *url.URL) bool {
+	u.Host = "x.com"
+	keepQuery(u)
+	trimSlash(u)
+	return true
+}
+
+// canonicalTikTok 视频页统一为 www.tiktok.com 且去除全部查询参数；短链接保持原样等待解析
+func canonicalTikTok(u *url.URL) bool {
+	switch u.Hostname() {
+	case "vm.tiktok.com", "vt.tiktok.com":
+		keepQuery(u)
+		trimSlash(u)
+		return true
+	}
+	u.Host = "www.tiktok.com"
+	keepQuery(u)
+	trimSlash(u)
+	return true
+}
+
+// canonicalPathOnly 统一主机名，查询参数只保留标识视频的 keep
+func canonicalPathOnly(host string, keep ...string) func(u *url.URL) bool {
+	return func(u *url.URL) bool {
+		if u.Hostname() != "player.vimeo.com" {
+			u.Host = host
+		}
+		keepQuery(u, keep...)
+		trimSlash(u)
+		return true
+	}
+}
+
+// keepQuery 只保留指定的非空查询参数（按键排序），未指定时清空查询
+func keepQuery(u *url.URL, keys ...string) {
+	if len(keys) == 0 || u.RawQuery == "" {
+		u.RawQuery = ""
+		return
+	}
+	q := u.Query()
+	kept := url.Values{}
+	for _, key := range keys {
+		if v := q.Get(key); v != "" {
+			kept.Set(key, v)
+		}
+	}
+	u.RawQuery = kept.Encode()
+}
@@ -0,0 +1,99 @@
+package urlcanon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalizeYouTube(t *testing.T) {
+	want := "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+	for _, raw := range []string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ&feature=share&t=42",
+		"https://youtu.be/dQw4w9WgXcQ?si=abc",
+		"https://m.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://music.youtube.com/watch?v=dQw4w9WgXcQ&list=RDAMVM",
+		"https://www.youtube.com/shorts/dQw4w9WgXcQ",
+		"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ?autoplay=1",
+		"http://youtube.com/live/dQw4w9WgXcQ#chat",
+		"  www.youtube.com/watch?v=dQw4w9WgXcQ  ",
+	} {
+		assert.Equal(t, want, Canonicalize(raw), raw)
+	}
+	assert.Equal(t, "https://www.youtube.com/playlist?list=PL123", Canonicalize("https://m.youtube.com/playlist?list=PL123&si=x"))
+	assert.Equal(t, "dQw4w9WgXcQ", YouTubeID("https://youtu.be/dQw4w9WgXcQ"))
+	assert.Equal(t, "", YouTubeID("https://www.bilibili.com/video/BV1xx411c7mD"))
+}
+
+func TestCanonicalizeBilibili(t *testing.T) {
+	assert.Equal(t, "https://www.bilibili.com/video/BV1xx411c7mD",
+		Canonicalize("https://m.bilibili.com/video/BV1xx411c7mD/?spm_id_from=333.1007&vd_source=abc"))
+	assert.Equal(t, "https://www.bilibili.com/video/BV1xx411c7mD?p=2",
+		Canonicalize("https://www.bilibili.com/video/BV1xx411c7mD?p=2&share_source=copy_web"))
+	assert.Equal(t, "https://b23.tv/AbCdEf", Canonicalize("https://b23.tv/AbCdEf?share_medium=android"))
+}
+
+func TestCanonicalizeVimeo(t *testing.T) {
+	assert.Equal(t, "https://vimeo.com/76979871", Canonicalize("https://www.vimeo.com/76979871/?share=copy"))
+	// 未公开视频：保留访问哈希 h，去掉其它参数
+	assert.Equal(t, "https://vimeo.com/76979871?h=8272103f6e",
+		Canonicalize("https://vimeo.com/76979871?share=copy&h=8272103f6e&utm_source=x"))
+	assert.Equal(t, "https://player.vimeo.com/video/76979871?h=8272103f6e",
+		Canonicalize("https://player.vimeo.com/video/76979871?h=8272103f6e&badge=0&autopause=0"))
+	assert.Equal(t, "https://vimeo.com/76979871/8272103f6e", Canonicalize("https://vimeo.com/76979871/8272103f6e?share=copy"))
+	// 哈希不同即为不同链接
+	assert.NotEqual(t, Canonicalize("https://vimeo.com/76979871?h=aaaa"), Canonicalize("https://vimeo.com/76979871?h=bbbb"))
+	assert.Equal(t, "https://vimeo.com/76979871", Canonicalize("https://vimeo.com/76979871?h="))
+}
+
+func TestCanonicalizeGeneric(t *testing.T) {
+	assert.Equal(t, "https://x.com/user/status/1", Canonicalize("https://mobile.twitter.com/user/status/1?s=20"))
+	assert.Equal(t, "https://example.com/a?id=1&z=2",
+		Canonicalize("HTTP://Example.COM:443/a/?z=2&utm_source=x&id=1&fbclid=y"))
+	assert.Equal(t, "not a url", Canonicalize(" not a url "))
+	assert.True(t, IsURL("https://example.com/x"))
+	assert.False(t, IsURL("example"))
+}
+
+func TestResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://www.bilibili.com/video/BV1xx411c7mD/?share_source=copy", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	client := srv.Client()
+	client.Transport = rewriteTransport{target: srv.URL, base: http.DefaultTransport}
+	r := NewResolver(func() *http.Client { return client })
+
+	got, err := r.Resolve(context.Background(), "https://b23.tv/AbCdEf")
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.bilibili.com/video/BV1xx411c7mD", got)
+
+	// 非短链接不发起请求
+	got, err = r.Resolve(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.youtube.com/watch?v=dQw4w9WgXcQ", got)
+	assert.True(t, IsShortLink("b23.tv/x"))
+	assert.False(t, IsShortLink("https://youtu.be/x"))
+}
+
+// rewriteTransport 将短链接请求转发到测试服务器
+type rewriteTransport struct {
+	target string
+	base   http.RoundTripper
+}
+
+func (rt rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := *req.URL
+	u.Scheme = "http"
+	u.Host = strings.TrimPrefix(rt.target, "http://")
+	clone := req.Clone(req.Context())
+	clone.URL = &u
+	clone.Host = u.Host
+	return rt.base.RoundTrip(clone)
+}
@@ -0,0 +1,274 @@
+// Package ytdlperrors 将 yt-dlp / FFmpeg 的错误输出归类为稳定的错误编码，
+// 并提供本地化的说明与建议操作。
+package ytdlperrors
+
+import (
+	"CanMe/backend/types"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxDetailLength = 500
+
+// rule 文本中包含任一 pattern（小写）时归为 code
+type rule struct {
+	code     types.DtErrorCode
+	patterns []string
+}
+
+// rules 按顺序匹配，越具体的规则越靠前（例如年龄限制的提示中也包含 "sign in"）。
+// DtErrCorruptOutput 由输出校验直接给出（见 New），不按文本匹配
+var rules = []rule{
+	{types.DtErrFFmpegMissing, []string{
+		"ffmpeg not found",
+		"ffprobe not found",
+		"ffmpeg is not installed",
+		"ffprobe and ffmpeg not found",
+		"ffmpeg could not be found",
+	}},
+	{types.DtErrGeoRestricted, []string{
+		"not available in your country",
+		"not available in your location",
+		"not made this video available in your country",
+		"geo restricted",
+		"geo-restricted",
+		"georestricted",
+		"geo restriction",
+	}},
+	{types.DtErrAgeRestricted, []string{
+		"confirm your age",
+		"age-restricted",
+		"age restricted",
+		"inappropriate for some users",
+	}},
+	// 私有视频的提示中也包含 "--cookies-from-browser"，须在登录规则之前匹配
+	{types.DtErrUnavailable, []string{
+		"private video",
+		"video is private",
+	}},
+	{types.DtErrLoginRequired, []string{
+		"not a bot",
+		"login required",
+		"requires authentication",
+		"only available for registered users",
+		"please log in",
+		"members-only",
+		"join this channel",
+		"use --cookies",
+		"--cookies-from-browser",
+		"premium members",
+		"account cookies",
+	}},
+	{types.DtErrRateLimited, []string{
+		"http error 429",
+		"too many requests",
+		"rate-limit",
+		"rate limit",
+		"ratelimit",
+	}},
+	{types.DtErrUnavailable, []string{
+		"video unavailable",
+		"has been removed",
+		"no longer available",
+		"has been terminated",
+		"does not exist",
+		"http error 404",
+		"this video is unavailable",
+		"been deleted",
+	}},
+	{types.DtErrUnsupportedURL, []string{
+		"unsupported url",
+		"is not a valid url",
+		"no suitable extractor",
+	}},
+	{types.DtErrNetwork, []string{
+		"unable to download webpage",
+		"unable to connect",
+		"connection refused",
+		"connection reset",
+		"connection aborted",
+		"timed out",
+		"name or service not known",
+		"temporary failure in name resolution",
+		"getaddrinfo failed",
+		"no such host",
+		"network is unreachable",
+		"ssl:",
+		"eof occurred in violation of protocol",
+		"proxyerror",
+		"remote end closed connection",
+	}},
+}
+
+// Classify 根据错误文本返回错误编码，无法识别时返回 DtErrUnknown。
+// 先只匹配 "ERROR:" 行，WARNING 中的 cookies 等提示不影响归类；无法识别时再匹配全文
+func Classify(text string) types.DtErrorCode {
+	var errorLines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.Contains(line, "ERROR:") {
+			errorLines = append(errorLines, line)
+		}
+	}
+	if len(errorLines) > 0 {
+		if code := match(strings.Join(errorLines, "\n")); code != types.DtErrUnknown {
+			return code
+		}
+	}
+	return match(text)
+}
+
+func match(text string) types.DtErrorCode {
+	lower := strings.ToLower(text)
+	for _, r := range rules {
+		for _, p := range r.patterns {
+			if strings.Contains(lower, p) {
+				return r.code
+			}
+		}
+	}
+	return types.DtErrUnknown
+}
+
+// Parse 将原始错误文本解析为结构化错误，lang 为界面语言（zh 或其他）
+func Parse(text, lang string) *types.DtTaskError {
+	return New(Classify(text), text, lang)
+}
+
+// New 以已知的错误编码构造结构化错误，用于不来自 yt-dlp 输出的错误
+func New(code types.DtErrorCode, text, lang string) *types.DtTaskError {
+	message, action := Describe(code, lang)
+	return &types.DtTaskError{
+		Code:    code,
+		Message: message,
+		Action:  action,
+		Detail:  Detail(text),
+	}
+}
+
+// Detail 提取最有用的一行（最后一个 "ERROR:" 行），否则返回截断后的原文
+func Detail(text string) string {
+	text = strings.TrimSpace(text)
+	detail := text
+	lines := strings.Split(text, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if idx := strings.Index(line, "ERROR:"); idx >= 0 {
+			detail = strings.TrimSpace(line[idx+len("ERROR:"):])
+			break
+		}
+	}
+	if len(detail) > maxDetailLength {
+		// 按字符边界截断，避免截断多字节字符
+		cut := maxDetailLength
+		for cut > 0 && !utf8.RuneStart(detail[cut]) {
+			cut--
+		}
+		detail = detail[:cut] + "..."
+	}
+	return detail
+}
+
+type description struct {
+	message string
+	action  string
+}
+
+var descriptions = map[string]map[types.DtErrorCode]description{
+	"en": {
+		types.DtErrGeoRestricted: {
+			"This video is not available in your region.",
+			"Configure a proxy located in a supported region in Settings, then retry.",
+		},
+		types.DtErrLoginRequired: {
+			"The site requires you to be signed in to access this video.",
+			"Sync cookies from a browser where you are signed in (e.g. Chrome) in Settings > Cookies, then retry.",
+		},
+		types.DtErrAgeRestricted: {
+			"This video is age-restricted.",
+			"Sign in with an age-verified account in your browser, sync its cookies (e.g. from Chrome), then retry.",
+		},
+		types.DtErrUnavailable: {
+			"This video is private, removed, or otherwise unavailable.",
+			"Check the link in a browser. If it is private, sync cookies from an account that has access.",
+		},
+		types.DtErrRateLimited: {
+			"The site is rate-limiting requests.",
+			"Wait a few minutes before retrying, or switch to a different proxy.",
+		},
+		types.DtErrUnsupportedURL: {
+			"This URL is not supported.",
+			"Make sure the link points to a single video or playlist page from a supported site.",
+		},
+		types.DtErrFFmpegMissing: {
+			"FFmpeg is required but was not found.",
+			"Install or repair FFmpeg in Settings > Dependencies, then retry.",
+		},
+		types.DtErrNetwork: {
+			"A network error occurred while contacting the site.",
+			"Check your internet connection and proxy settings, then retry.",
+		},
+		types.DtErrCorruptOutput: {
+			"The downloaded file appears to be incomplete or corrupt.",
+			"Delete the task files and download again; if it keeps failing, try a different format.",
+		},
+		types.DtErrUnknown: {
+			"The download failed.",
+			"Update yt-dlp in Settings > Dependencies and retry. If the problem persists, check the task log.",
+		},
+	},
+	"zh": {
+		types.DtErrGeoRestricted: {
+			"该视频在您所在的地区不可用。",
+			"请在设置中配置位于可用地区的代理后重试。",
+		},
+		types.DtErrLoginRequired: {
+			"该网站需要登录后才能访问此视频。",
+			"请在 设置 > Cookies 中从已登录的浏览器（如 Chrome）同步 Cookies 后重试。",
+		},
+		types.DtErrAgeRestricted: {
+			"该视频有年龄限制。",
+			"请在浏览器中登录已验证年龄的账号，同步其 Cookies（如 Chrome）后重试。",
+		},
+		types.DtErrUnavailable: {
+			"该视频为私有、已被删除或不可用。",
+			"请在浏览器中确认链接；如为私有视频，请同步有访问权限账号的 Cookies。",
+		},
+		types.DtErrRateLimited: {
+			"请求过于频繁，已被网站限流。",
+			"请稍等几分钟后重试，或更换代理。",
+		},
+		types.DtErrUnsupportedURL: {
+			"不支持该链接。",
+			"请确认链接指向受支持网站的单个视频或播放列表页面。",
+		},
+		types.DtErrFFmpegMissing: {
+			"需要 FFmpeg，但未找到。",
+			"请在 设置 > 依赖 中安装或修复 FFmpeg 后重试。",
+		},
+		types.DtErrNetwork: {
+			"连接网站时发生网络错误。",
+			"请检查网络连接与代理设置后重试。",
+		},
+		types.DtErrCorruptOutput: {
+			"下载的文件不完整或已损坏。",
+			"请删除任务文件后重新下载；若仍失败，请尝试其他格式。",
+		},
+		types.DtErrUnknown: {
+			"下载失败。",
+			"请在 设置 > 依赖 中更新 yt-dlp 后重试；若仍失败，请查看任务日志。",
+		},
+	},
+}
+
+// Describe 返回错误编码对应的本地化说明与建议操作
+func Describe(code types.DtErrorCode, lang string) (message, action string) {
+	table := descriptions["en"]
+	if strings.HasPrefix(strings.ToLower(lang), "zh") {
+		table = descriptions["zh"]
+	}
+	d, ok := table[code]
+	if !ok {
+		d = table[types.DtErrUnknown]
+	}
+	return d.message, d.action
+}
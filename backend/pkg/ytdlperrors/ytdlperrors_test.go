@@ -0,0 +1,70 @@
+package ytdlperrors
+
+import (
+	"CanMe/backend/types"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	cases := map[string]types.DtErrorCode{
+		"ERROR: [youtube] abc: The uploader has not made this video available in your country":  types.DtErrGeoRestricted,
+		"ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate":    types.DtErrAgeRestricted,
+		"ERROR: [youtube] abc: Sign in to confirm you’re not a bot. Use --cookies-from-browser": types.DtErrLoginRequired,
+		"ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video. Use --cookies-from-browser or --cookies for the authentication. See  https://github.com/yt-dlp/yt-dlp/wiki/FAQ#how-do-i-pass-cookies-to-yt-dlp  for how to manually pass cookies. Also see  https://github.com/yt-dlp/yt-dlp/wiki/Extractors#exporting-youtube-cookies  for tips on effectively exporting YouTube cookies": types.DtErrUnavailable,
+		"ERROR: [vimeo] 123: This video is private. Use --cookies-from-browser or --cookies for the authentication": types.DtErrUnavailable,
+		"ERROR: unable to download video data: HTTP Error 429: Too Many Requests":                                   types.DtErrRateLimited,
+		"ERROR: Unsupported URL: https://example.com/":                                                              types.DtErrUnsupportedURL,
+		"ERROR: You have requested merging of multiple formats but ffmpeg is not installed":                         types.DtErrFFmpegMissing,
+		"ERROR: [generic] Unable to download webpage: <urlopen error [Errno 111] Connection refused>":               types.DtErrNetwork,
+		"something unexpected": types.DtErrUnknown,
+	}
+	for text, want := range cases {
+		assert.Equal(t, want, Classify(text), text)
+	}
+
+	// WARNING 行中的 cookies 提示不影响 ERROR 行的归类
+	stderr := "WARNING: [youtube] Use --cookies-from-browser or --cookies for the authentication\n" +
+		"ERROR: [youtube] abc: HTTP Error 429: Too Many Requests"
+	assert.Equal(t, types.DtErrRateLimited, Classify(stderr))
+	// ERROR 行无法识别时回退到全文
+	stderr = "WARNING: [youtube] Sign in to confirm you’re not a bot\nERROR: [youtube] abc: Requested format is not available"
+	assert.Equal(t, types.DtErrLoginRequired, Classify(stderr))
+}
+
+func TestDetailTruncatesOnRuneBoundary(t *testing.T) {
+	text := "ERROR: " + strings.Repeat("a", maxDetailLength-1) + "视频"
+	detail := Detail(text)
+	assert.True(t, utf8.ValidString(detail))
+	assert.Equal(t, strings.Repeat("a", maxDetailLength-1)+"...", detail)
+
+	short := "ERROR: 视频不可用"
+	assert.Equal(t, "视频不可用", Detail(short))
+}
+
+func TestParse(t *testing.T) {
+	stderr := "WARNING: [youtube] some warning\nERROR: [youtube] abc: Video unavailable\n"
+
+	parsed := Parse("exit code 1: "+stderr, "zh")
+	assert.Equal(t, types.DtErrUnavailable, parsed.Code)
+	assert.Equal(t, "[youtube] abc: Video unavailable", parsed.Detail)
+	assert.NotEmpty(t, parsed.Action)
+
+	enMsg, _ := Describe(types.DtErrUnavailable, "en")
+	zhMsg, _ := Describe(types.DtErrUnavailable, "zh")
+	assert.NotEqual(t, enMsg, zhMsg)
+	assert.Equal(t, zhMsg, parsed.Message)
+}
+
+func TestNew(t *testing.T) {
+	parsed := New(types.DtErrCorruptOutput, "media verification failed: no audio stream", "en")
+	assert.Equal(t, types.DtErrCorruptOutput, parsed.Code)
+	assert.Equal(t, "media verification failed: no audio stream", parsed.Detail)
+
+	msg, action := Describe(types.DtErrCorruptOutput, "en")
+	assert.Equal(t, msg, parsed.Message)
+	assert.Equal(t, action, parsed.Action)
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package ytdlprun
+
+import (
+	"context"
+	"os/exec"
+)
+
+// createCommand 创建命令（Unix/Linux/macOS）
+func createCommand(ctx context.Context, executable string, args []string) *exec.Cmd {
+	return exec.CommandContext(ctx, executable, args...)
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package ytdlprun
+
+import (
+	"context"
+	"os/exec"
+
+	"golang.org/x/sys/windows"
+)
+
+// createCommand 创建隐藏窗口的命令（Windows专用）
+func createCommand(ctx context.Context, executable string, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, executable, args...)
+	cmd.SysProcAttr = &windows.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: windows.CREATE_NO_WINDOW,
+	}
+	return cmd
+}
@@ -0,0 +1,125 @@
+package ytdlprun
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lrstanley/go-ytdlp"
+)
+
+// 进度行前缀，模板 %()j 输出包含 info 与 progress 的完整 JSON
+const (
+	progressPrefix   = "canme-progress:"
+	progressTemplate = "download:" + progressPrefix + "%()j"
+)
+
+// ProgressFunc 启用进度输出，yt-dlp 每隔 frequency（不少于 100ms）输出一行进度并回调 fn
+func (c *Command) ProgressFunc(frequency time.Duration, fn ytdlp.ProgressCallbackFunc) *Command {
+	frequency = max(frequency, 100*time.Millisecond)
+	c.Set("--progress").
+		Set("--newline").
+		Set("--progress-delta", strconv.FormatFloat(frequency.Seconds(), 'f', -1, 64)).
+		Set("--progress-template", progressTemplate)
+	c.progress = newProgressTracker(fn)
+	return c
+}
+
+type progressLine struct {
+	Info     *json.RawMessage `json:"info"`
+	Progress struct {
+		Status             ytdlp.ProgressStatus `json:"status"`
+		TotalBytes         int                  `json:"total_bytes,omitempty"`
+		TotalBytesEstimate float64              `json:"total_bytes_estimate,omitempty"`
+		DownloadedBytes    int                  `json:"downloaded_bytes"`
+		Filename           string               `json:"filename,omitempty"`
+		TmpFilename        string               `json:"tmpfilename,omitempty"`
+		FragmentIndex      int                  `json:"fragment_index,omitempty"`
+		FragmentCount      int                  `json:"fragment_count,omitempty"`
+	} `json:"progress"`
+}
+
+// progressTracker 解析进度行，并按文件记录开始与完成时间（用于计算 ETA）
+type progressTracker struct {
+	fn ytdlp.ProgressCallbackFunc
+
+	mu       sync.Mutex
+	started  map[string]time.Time
+	finished map[string]time.Time
+}
+
+func newProgressTracker(fn ytdlp.ProgressCallbackFunc) *progressTracker {
+	return &progressTracker{
+		fn:       fn,
+		started:  make(map[string]time.Time),
+		finished: make(map[string]time.Time),
+	}
+}
+
+func (t *progressTracker) parse(data []byte) {
+	var line progressLine
+	if err := json.Unmarshal(data, &line); err != nil {
+		return
+	}
+
+	update := ytdlp.ProgressUpdate{
+		Status:          line.Progress.Status,
+		TotalBytes:      line.Progress.TotalBytes,
+		DownloadedBytes: line.Progress.DownloadedBytes,
+		FragmentIndex:   line.Progress.FragmentIndex,
+		FragmentCount:   line.Progress.FragmentCount,
+		Filename:        line.Progress.Filename,
+	}
+	if line.Info != nil {
+		info, err := ytdlp.ParseExtractedInfo(line.Info)
+		if err != nil {
+			return
+		}
+		update.Info = info
+	}
+	if update.TotalBytes == 0 {
+		update.TotalBytes = int(line.Progress.TotalBytesEstimate)
+	}
+	if update.Filename == "" {
+		if line.Progress.TmpFilename != "" {
+			update.Filename = line.Progress.TmpFilename
+		} else if update.Info != nil && update.Info.Filename != nil {
+			update.Filename = *update.Info.Filename
+		}
+	}
+
+	key := progressKey(&update)
+	t.mu.Lock()
+	started, ok := t.started[key]
+	if !ok {
+		started = time.Now()
+		t.started[key] = started
+	}
+	update.Started = started
+	finished, ok := t.finished[key]
+	if !ok && update.Status.IsCompletedType() {
+		finished = time.Now()
+		t.finished[key] = finished
+	}
+	update.Finished = finished
+	t.mu.Unlock()
+
+	t.fn(update)
+}
+
+// progressKey 区分同一次调用中的多个下载（分离的音视频、播放列表条目）
+func progressKey(update *ytdlp.ProgressUpdate) string {
+	parts := []string{update.Filename}
+	if info := update.Info; info != nil {
+		parts = append(parts, info.ID)
+		if info.PlaylistID != nil {
+			parts = append(parts, *info.PlaylistID)
+		}
+		if info.PlaylistIndex != nil {
+			parts = append(parts, strconv.Itoa(*info.PlaylistIndex))
+		}
+	}
+	return strings.Join(parts, ":")
+}
@@ -0,0 +1,183 @@
+// Package ytdlprun 拼接并运行 yt-dlp 命令，在 yt-dlp 输出时逐行回调。
+// 命令行参数由应用自己维护；go-ytdlp 只提供解析输出用的数据类型（ExtractedInfo、ProgressUpdate）。
+package ytdlprun
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// --verbose 调试输出的行前缀（用户的 yt-dlp 配置文件可能启用 --verbose）
+const debugPrefix = "[debug] "
+
+// LineFunc 输出行回调，stream 为 stdout 或 stderr
+type LineFunc func(stream, line string)
+
+type option struct {
+	name   string
+	values []string
+}
+
+// Command 一次 yt-dlp 调用的可执行文件、工作目录、环境变量与选项。
+// 由创建它的 goroutine 构建并运行，不能并发修改
+type Command struct {
+	executable string
+	dir        string
+	env        map[string]string
+	options    []option
+	progress   *progressTracker
+}
+
+// New 创建命令，executable 为 yt-dlp 可执行文件路径
+func New(executable string) *Command {
+	return &Command{executable: executable, env: map[string]string{}}
+}
+
+// SetWorkDir 设置工作目录
+func (c *Command) SetWorkDir(dir string) *Command {
+	c.dir = dir
+	return c
+}
+
+// SetEnvVar 设置环境变量。与此前使用的 go-ytdlp 一致：设置了环境变量时进程只获得这些变量
+func (c *Command) SetEnvVar(key, value string) *Command {
+	c.env[key] = value
+	return c
+}
+
+// Set 设置选项（如 "--format"），替换已有的同名选项；不带值时为开关选项
+func (c *Command) Set(name string, values ...string) *Command {
+	c.Unset(name)
+	c.options = append(c.options, option{name: name, values: values})
+	return c
+}
+
+// Unset 移除选项
+func (c *Command) Unset(name string) *Command {
+	c.options = slices.DeleteFunc(c.options, func(o option) bool { return o.name == name })
+	return c
+}
+
+// Args 返回命令行参数，extra 追加在最后（通常为 URL）
+func (c *Command) Args(extra ...string) []string {
+	var args []string
+	for _, o := range c.options {
+		args = append(args, o.name)
+		args = append(args, o.values...)
+	}
+	return append(args, extra...)
+}
+
+// Executable 返回 yt-dlp 可执行文件路径
+func (c *Command) Executable() string {
+	return c.executable
+}
+
+// Result 一次运行的结果，Stdout 不含进度行，Stderr 不含 --verbose 的调试行
+type Result struct {
+	Executable string
+	Args       []string
+	ExitCode   int
+	Stdout     string
+	Stderr     string
+}
+
+// ExitError yt-dlp 无法启动或以非零退出码结束
+type ExitError struct {
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+// Error 附带 stderr，便于按错误文本分类
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit code %d: %v\n\n%s", e.ExitCode, e.Err, e.Stderr)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Run 运行命令，每输出一行回调一次 onLine（进度行除外，可能从两个输出流的 goroutine 并发调用）。
+// --verbose 的 [debug] 行只交给 onLine，不计入结果与错误信息：其中的命令行、代理等参数会干扰错误分类，也不应写入应用日志。
+// 进程已启动时即使失败也返回结果
+func (c *Command) Run(ctx context.Context, onLine LineFunc, args ...string) (*Result, error) {
+	if c.executable == "" {
+		return nil, errors.New("yt-dlp executable is not set")
+	}
+
+	var stdoutLines, stderrLines []string
+	stdout := &lineWriter{handle: func(line string) {
+		if data, ok := strings.CutPrefix(line, progressPrefix); ok && c.progress != nil {
+			c.progress.parse([]byte(data))
+			return
+		}
+		stdoutLines = append(stdoutLines, line)
+		if onLine != nil {
+			onLine("stdout", line)
+		}
+	}}
+	stderr := &lineWriter{handle: func(line string) {
+		if !strings.HasPrefix(line, debugPrefix) {
+			stderrLines = append(stderrLines, line)
+		}
+		if onLine != nil {
+			onLine("stderr", line)
+		}
+	}}
+
+	cmd := createCommand(ctx, c.executable, c.Args(args...))
+	cmd.Dir = c.dir
+	for k, v := range c.env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	stdout.flush()
+	stderr.flush()
+
+	result := &Result{
+		Executable: cmd.Path,
+		Args:       cmd.Args[1:],
+		ExitCode:   cmd.ProcessState.ExitCode(),
+		Stdout:     strings.Join(stdoutLines, "\n"),
+		Stderr:     strings.Join(stderrLines, "\n"),
+	}
+	if err != nil {
+		return result, &ExitError{ExitCode: result.ExitCode, Stderr: result.Stderr, Err: err}
+	}
+	return result, nil
+}
+
+// lineWriter 按行切分写入的数据，去掉行尾空白后回调；每个输出流各用一个，不需要加锁
+type lineWriter struct {
+	buf    []byte
+	handle func(line string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.handle(string(bytes.TrimRightFunc(w.buf[:i], unicode.IsSpace)))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush 输出最后一行未以换行结尾的内容
+func (w *lineWriter) flush() {
+	if len(w.buf) > 0 {
+		w.handle(string(bytes.TrimRightFunc(w.buf, unicode.IsSpace)))
+		w.buf = nil
+	}
+}
@@ -0,0 +1,151 @@
+//go:build !windows
+
+package ytdlprun
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lrstanley/go-ytdlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeYtdlp 写入一个模拟 yt-dlp 的可执行脚本
+func fakeYtdlp(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "yt-dlp")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755))
+	return path
+}
+
+func TestCommandArgs(t *testing.T) {
+	dl := New("/usr/bin/yt-dlp").
+		Set("--no-playlist").
+		Set("--format", "bv*+ba/b").
+		Set("--output", "%(title)s.%(ext)s").
+		Set("--format", "b")
+	assert.Equal(t, []string{"--no-playlist", "--output", "%(title)s.%(ext)s", "--format", "b", "https://example.com/v"}, dl.Args("https://example.com/v"))
+
+	dl.Unset("--format").Set("--proxy", "")
+	assert.Equal(t, []string{"--no-playlist", "--output", "%(title)s.%(ext)s", "--proxy", ""}, dl.Args())
+
+	dl.ProgressFunc(10*time.Millisecond, func(ytdlp.ProgressUpdate) {})
+	assert.Equal(t, []string{"--progress", "--newline", "--progress-delta", "0.1", "--progress-template", "download:canme-progress:%()j"}, dl.Args()[5:])
+	assert.Equal(t, "/usr/bin/yt-dlp", dl.Executable())
+
+	_, err := New("").Run(context.Background(), nil)
+	assert.EqualError(t, err, "yt-dlp executable is not set")
+}
+
+func TestRunUsesWorkDirAndEnv(t *testing.T) {
+	exe := fakeYtdlp(t, `pwd; echo "$PYTHONUTF8"; echo "$@"`+"\n")
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	result, err := New(exe).SetWorkDir(dir).SetEnvVar("PYTHONUTF8", "1").Set("--no-playlist").Run(context.Background(), nil, "https://example.com/v")
+	require.NoError(t, err)
+	assert.Equal(t, dir+"\n1\n--no-playlist https://example.com/v", result.Stdout)
+	assert.Equal(t, []string{"--no-playlist", "https://example.com/v"}, result.Args)
+}
+
+func TestRunStreamsLines(t *testing.T) {
+	release := filepath.Join(t.TempDir(), "release")
+	exe := fakeYtdlp(t, `
+echo "[youtube] abc: Downloading webpage"
+echo 'canme-progress:{"info":{"id":"abc","title":"none"},"progress":{"status":"downloading","downloaded_bytes":50,"total_bytes":100,"filename":"a.mp4"}}'
+echo "WARNING: slow" >&2
+while [ ! -f "`+release+`" ]; do sleep 0.01; done
+printf 'canme-progress:{"info":{"id":"abc"},"progress":{"status":"finished","downloaded_bytes":100,"total_bytes_estimate":100,"filename":"a.mp4"}}\n'
+printf "[Merger] done"
+`)
+
+	var mu sync.Mutex
+	var lines []string
+	var updates []ytdlp.ProgressUpdate
+	onLine := func(stream, line string) {
+		mu.Lock()
+		lines = append(lines, stream+": "+line)
+		mu.Unlock()
+	}
+	dl := New(exe).ProgressFunc(250*time.Millisecond, func(update ytdlp.ProgressUpdate) {
+		mu.Lock()
+		updates = append(updates, update)
+		mu.Unlock()
+	})
+	snapshot := func() ([]string, []ytdlp.ProgressUpdate) {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, lines...), append([]ytdlp.ProgressUpdate{}, updates...)
+	}
+
+	done := make(chan *Result)
+	go func() {
+		result, err := dl.Run(context.Background(), onLine)
+		assert.NoError(t, err)
+		done <- result
+	}()
+
+	// 进程仍在运行时已收到输出
+	require.Eventually(t, func() bool {
+		l, u := snapshot()
+		return len(l) == 2 && len(u) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	l, u := snapshot()
+	assert.ElementsMatch(t, []string{"stdout: [youtube] abc: Downloading webpage", "stderr: WARNING: slow"}, l)
+	assert.Equal(t, 50.0, u[0].Percent())
+	assert.Equal(t, "a.mp4", u[0].Filename)
+	require.NotNil(t, u[0].Info)
+	assert.Equal(t, "abc", u[0].Info.ID)
+	require.NoError(t, os.WriteFile(release, nil, 0o644))
+
+	result := <-done
+	l, u = snapshot()
+	require.Len(t, u, 2)
+	assert.Equal(t, 100, u[1].TotalBytes)
+	assert.Equal(t, u[0].Started, u[1].Started)
+	assert.False(t, u[1].Finished.IsZero())
+	assert.Equal(t, "stdout: [Merger] done", l[len(l)-1])
+
+	// 结果中不含进度行
+	assert.Equal(t, "[youtube] abc: Downloading webpage\n[Merger] done", result.Stdout)
+	assert.Equal(t, "WARNING: slow", result.Stderr)
+	assert.Equal(t, 0, result.ExitCode)
+}
+
+func TestRunError(t *testing.T) {
+	exe := fakeYtdlp(t, `
+echo "[debug] Command-line config: ['--cookies', 'c.txt']" >&2
+echo "ERROR: [youtube] abc: Video unavailable" >&2
+exit 1
+`)
+	result, err := New(exe).Run(context.Background(), nil)
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 1, exitErr.ExitCode)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Equal(t, "ERROR: [youtube] abc: Video unavailable", result.Stderr)
+	// 错误信息保留 ERROR 行，去掉 debug 行
+	assert.True(t, strings.HasPrefix(err.Error(), "exit code 1: "), err.Error())
+	assert.Contains(t, err.Error(), "ERROR: [youtube] abc: Video unavailable")
+	assert.NotContains(t, err.Error(), "--cookies")
+
+	result, err = New(filepath.Join(t.TempDir(), "missing")).Run(context.Background(), nil)
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, -1, result.ExitCode)
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := &lineWriter{handle: func(line string) { lines = append(lines, line) }}
+	w.Write([]byte("a\r\nb"))
+	w.Write([]byte("c  \n\nd"))
+	assert.Equal(t, []string{"a", "bc", ""}, lines)
+	w.flush()
+	assert.Equal(t, []string{"a", "bc", "", "d"}, lines)
+}
@@ -0,0 +1,51 @@
+package restapi
+
+import (
+	"CanMe/backend/types"
+	"errors"
+	"net/http"
+)
+
+// withCORS 允许书签脚本与浏览器扩展从任意页面调用；接口只认令牌不认 Cookie，因此放开来源
+func withCORS(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Access-Control-Allow-Origin", "*")
+		h.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		h.Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		h.Set("Access-Control-Max-Age", "600")
+		// Chrome Private Network Access：公网页面访问 127.0.0.1 需要预检放行
+		if r.Header.Get("Access-Control-Request-Private-Network") == "true" {
+			h.Set("Access-Control-Allow-Private-Network", "true")
+		}
+		next(w, r)
+	}
+}
+
+func handlePreflight(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /bridge/presets 返回可选预设，也用于浏览器端验证配对
+func (s *Service) handleBridgePresets(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.downtasks.BridgePresets())
+}
+
+// POST /bridge/send 接收页面 URL、该域名的 Cookies 与预设名称并创建任务
+func (s *Service) handleBridgeSend(w http.ResponseWriter, r *http.Request) {
+	var request types.DtBridgeRequest
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if request.URL == "" {
+		writeError(w, http.StatusBadRequest, errors.New("url is required"))
+		return
+	}
+	resp, err := s.downtasks.BridgeSend(&request)
+	if err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, resp)
+}
@@ -0,0 +1,51 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBridgeAuth(t *testing.T) {
+	h := newTestService().routes()
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodGet, "/bridge/presets"},
+		{http.MethodPost, "/bridge/send"},
+	} {
+		for name, token := range map[string]string{"missing": "", "wrong": "nope", "api": testToken} {
+			rec := serve(h, route.method, basePath+route.path, token)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code, "%s %s", route.path, name)
+			// 401 也需带 CORS 头，浏览器端才能读到错误
+			assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"), "%s %s", route.path, name)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodOptions, basePath+"/bridge/send", nil)
+	req.Header.Set("Access-Control-Request-Private-Network", "true")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Private-Network"))
+}
+
+func TestBridgeSendValidation(t *testing.T) {
+	h := newTestService().routes()
+
+	for body, want := range map[string]int{
+		`{}`:                   http.StatusBadRequest,
+		`{"url": 1}`:           http.StatusBadRequest,
+		`{"url": "x", "a": 1}`: http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodPost, basePath+"/bridge/send", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testBridgeToken)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, want, rec.Code, body)
+	}
+}
@@ -0,0 +1,63 @@
+package restapi
+
+import (
+	"CanMe/backend/types"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type installDependencyRequest struct {
+	Version string `json:"version"`
+	Mirror  string `json:"mirror"`
+	Force   bool   `json:"force"`
+}
+
+func (s *Service) handleListDependencies(w http.ResponseWriter, r *http.Request) {
+	deps, err := s.downtasks.ListDependencies()
+	if err != nil {
+		writeServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, deps)
+}
+
+func (s *Service) handleCheckUpdates(w http.ResponseWriter, r *http.Request) {
+	deps, err := s.downtasks.CheckDependencyUpdates()
+	if err != nil {
+		writeServiceError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, deps)
+}
+
+// POST /dependencies/{type}/install 同步安装，进度通过 installing 事件推送
+func (s *Service) handleInstallDependency(w http.ResponseWriter, r *http.Request) {
+	var depType types.DependencyType
+	switch r.PathValue("type") {
+	case string(types.DependencyYTDLP):
+		depType = types.DependencyYTDLP
+	case string(types.DependencyFFmpeg):
+		depType = types.DependencyFFmpeg
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unsupported dependency type: %s", r.PathValue("type")))
+		return
+	}
+
+	var request installDependencyRequest
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	info, err := s.downtasks.InstallDependency(depType, types.DownloadConfig{
+		Version:     request.Version,
+		Mirror:      request.Mirror,
+		ForceUpdate: request.Force,
+		Timeout:     30 * time.Minute,
+	})
+	if err != nil {
+		writeServiceError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
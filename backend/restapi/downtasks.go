@@ -0,0 +1,229 @@
+package restapi
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/types"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GET /tasks?stage=&type=&keyword= 多个值以逗号分隔
+func (s *Service) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := types.DtTaskFilter{
+		Types:     splitQuery(q.Get("type")),
+		Extractor: q.Get("extractor"),
+		Keyword:   q.Get("keyword"),
+	}
+	for _, st := range splitQuery(q.Get("stage")) {
+		filter.Stages = append(filter.Stages, types.DtTaskStage(st))
+	}
+	writeJSON(w, http.StatusOK, s.downtasks.FilterTasks(filter))
+}
+
+// POST /tasks 自定义下载
+func (s *Service) handleDownload(w http.ResponseWriter, r *http.Request) {
+	var request types.DtDownloadRequest
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if request.URL == "" {
+		writeError(w, http.StatusBadRequest, errors.New("url is required"))
+		return
+	}
+	resp, err := s.downtasks.Download(&request)
+	if err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+// POST /tasks/quick 快速下载；video 为空时按下载规则或 yt-dlp 默认格式
+func (s *Service) handleQuickDownload(w http.ResponseWriter, r *http.Request) {
+	var request types.DtQuickDownloadRequest
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if request.URL == "" {
+		writeError(w, http.StatusBadRequest, errors.New("url is required"))
+		return
+	}
+	request.Type = consts.TASK_TYPE_QUICK
+	resp, err := s.downtasks.QuickDownload(&request)
+	if err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+func (s *Service) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	task, err := s.downtasks.GetTaskStatus(r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+// DELETE /tasks/{id}?mode=record|files|trash
+func (s *Service) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
+	mode := types.DtDeleteMode(r.URL.Query().Get("mode"))
+	if err := s.downtasks.DeleteTaskWithMode(r.PathValue("id"), mode); err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Service) handleResumeTask(w http.ResponseWriter, r *http.Request) {
+	task, err := s.downtasks.ResumeTask(r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Service) handleCancelTask(w http.ResponseWriter, r *http.Request) {
+	if err := s.downtasks.CancelTask(r.PathValue("id")); err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Service) handleVerifyTask(w http.ResponseWriter, r *http.Request) {
+	task, err := s.downtasks.VerifyTask(r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+// GET /tasks/{id}/log?lines=N 指定 lines 时返回最后 N 行（JSON），否则返回完整日志文本
+func (s *Service) handleTaskLog(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if raw := r.URL.Query().Get("lines"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, errors.New("lines must be a positive integer"))
+			return
+		}
+		lines, err := s.downtasks.TailTaskLog(id, n)
+		if err != nil {
+			writeServiceError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string][]string{"lines": lines})
+		return
+	}
+	content, err := s.downtasks.GetTaskLog(id)
+	if err != nil {
+		writeServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(content))
+}
+
+// GET /content?url=&browser= 解析视频信息
+func (s *Service) handleGetContent(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("url") == "" {
+		writeError(w, http.StatusBadRequest, errors.New("url is required"))
+		return
+	}
+	info, err := s.downtasks.ParseURL(q.Get("url"), q.Get("browser"))
+	if err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Service) handleGetFormats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.downtasks.GetFormats())
+}
+
+// GET /statistics?from=&to=&period=day|week（unix 秒）
+func (s *Service) handleStatistics(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := types.DtStatsQuery{Period: q.Get("period")}
+	var err error
+	if query.From, err = parseUnix(q.Get("from")); err == nil {
+		query.To, err = parseUnix(q.Get("to"))
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	stats, err := s.downtasks.GetStatistics(query)
+	if err != nil {
+		writeServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (s *Service) handleListTrash(w http.ResponseWriter, r *http.Request) {
+	items, err := s.downtasks.ListTrash()
+	if err != nil {
+		writeServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (s *Service) handleRestoreTrash(w http.ResponseWriter, r *http.Request) {
+	task, err := s.downtasks.RestoreTrash(r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Service) handlePurgeTrash(w http.ResponseWriter, r *http.Request) {
+	if err := s.downtasks.PurgeTrash(r.PathValue("id")); err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Service) handleFindDuplicates(w http.ResponseWriter, r *http.Request) {
+	report, err := s.downtasks.FindDuplicates()
+	if err != nil {
+		writeServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func splitQuery(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func parseUnix(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errors.New("timestamps must be unix seconds")
+	}
+	return v, nil
+}
@@ -0,0 +1,175 @@
+package restapi
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/pkg/events"
+	"CanMe/backend/pkg/tasklog"
+	"CanMe/backend/types"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 对外的事件名与事件总线主题的对应关系
+var eventTopics = map[string]string{
+	"progress":   consts.TopicDowntasksProgress,
+	"signal":     consts.TopicDowntasksSignal,
+	"stage":      consts.TopicDowntasksStage,
+	"log":        consts.TopicDowntasksLog,
+	"installing": consts.TopicDowntasksInstalling,
+	"cookies":    consts.TopicDowntasksCookieSync,
+	"subtitle":   consts.TopicSubtitleProgress,
+}
+
+// 未指定 events 参数时推送的事件
+var defaultEvents = []string{"progress", "signal", "stage"}
+
+const (
+	sseBufferSize  = 64
+	sseKeepAlive   = 15 * time.Second
+	sseRetryMillis = 3000
+)
+
+type sseMessage struct {
+	event string
+	data  []byte
+}
+
+type sseClient struct {
+	events map[string]bool
+	taskID string
+	ch     chan sseMessage
+}
+
+// sseHub 每个主题只订阅一次事件总线，再分发给所有连接
+type sseHub struct {
+	once    sync.Once
+	mu      sync.RWMutex
+	clients map[*sseClient]struct{}
+}
+
+func newSSEHub() *sseHub {
+	return &sseHub{clients: map[*sseClient]struct{}{}}
+}
+
+func (h *sseHub) subscribe(bus events.EventBus) {
+	h.once.Do(func() {
+		for name, topic := range eventTopics {
+			name := name
+			bus.Subscribe(topic, events.HandlerFunc(func(ctx context.Context, event events.Event) error {
+				h.broadcast(name, event.GetData())
+				return nil
+			}))
+		}
+	})
+}
+
+// broadcast 推送给订阅了该事件的连接；连接消费过慢时丢弃，避免阻塞事件总线
+func (h *sseHub) broadcast(name string, data any) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.clients) == 0 {
+		return
+	}
+
+	taskID := eventTaskID(data)
+	var payload []byte
+	for c := range h.clients {
+		if !c.events[name] || (c.taskID != "" && c.taskID != taskID) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(data); err != nil {
+				return
+			}
+		}
+		select {
+		case c.ch <- sseMessage{event: name, data: payload}:
+		default:
+		}
+	}
+}
+
+func (h *sseHub) add(c *sseClient) {
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+}
+
+func (h *sseHub) remove(c *sseClient) {
+	h.mu.Lock()
+	delete(h.clients, c)
+	h.mu.Unlock()
+}
+
+// eventTaskID 取事件关联的任务ID，用于 taskId 过滤
+func eventTaskID(data any) string {
+	switch d := data.(type) {
+	case *types.DtProgress:
+		return d.ID
+	case *types.DTSignal:
+		return d.ID
+	case *types.DTStageEvent:
+		return d.ID
+	case *tasklog.Line:
+		return d.TaskID
+	case types.ConversionTask:
+		return d.ID
+	}
+	return ""
+}
+
+// GET /events?events=progress,stage&taskId= 以 Server-Sent Events 推送事件
+func (s *Service) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming unsupported"))
+		return
+	}
+
+	names := splitQuery(r.URL.Query().Get("events"))
+	if len(names) == 0 {
+		names = defaultEvents
+	}
+	client := &sseClient{
+		events: map[string]bool{},
+		taskID: r.URL.Query().Get("taskId"),
+		ch:     make(chan sseMessage, sseBufferSize),
+	}
+	for _, name := range names {
+		if _, ok := eventTopics[name]; !ok {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unknown event: %s", name))
+			return
+		}
+		client.events[name] = true
+	}
+
+	s.hub.add(client)
+	defer s.hub.remove(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case msg := <-client.ch:
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.event, msg.data)
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,95 @@
+package restapi
+
+import (
+	"CanMe/backend/types"
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openStream 连接事件流并读完首个 retry 块
+func openStream(t *testing.T, server *httptest.Server, query string) *bufio.Reader {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+basePath+"/events?"+query, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "retry: 3000", readBlock(t, reader))
+	return reader
+}
+
+// readBlock 读取一个以空行结尾的 SSE 消息块
+func readBlock(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "\n")
+		}
+		lines = append(lines, line)
+	}
+}
+
+// waitClients 等待连接注册到 hub，避免广播早于订阅
+func waitClients(t *testing.T, hub *sseHub, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		hub.mu.RLock()
+		defer hub.mu.RUnlock()
+		return len(hub.clients) == n
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestEventStream(t *testing.T) {
+	s := newTestService()
+	server := httptest.NewServer(s.routes())
+	defer server.Close()
+
+	all := openStream(t, server, "access_token="+testToken)
+	filtered := openStream(t, server, "access_token="+testToken+"&events=stage,progress&taskId=t2")
+	waitClients(t, s.hub, 2)
+
+	// 默认事件不含 log；taskId 过滤掉其他任务
+	s.hub.broadcast("log", &types.DtProgress{ID: "t1"})
+	s.hub.broadcast("progress", &types.DtProgress{ID: "t1", Percentage: 42})
+	s.hub.broadcast("stage", &types.DTStageEvent{ID: "t2", Kind: "download", Action: "complete"})
+
+	block := readBlock(t, all)
+	assert.True(t, strings.HasPrefix(block, "event: progress\ndata: {"), block)
+	assert.Contains(t, block, `"id":"t1"`)
+	block = readBlock(t, all)
+	assert.True(t, strings.HasPrefix(block, "event: stage\n"), block)
+
+	block = readBlock(t, filtered)
+	assert.True(t, strings.HasPrefix(block, "event: stage\n"), block)
+	assert.Contains(t, block, `"id":"t2"`)
+
+	// 断开后从 hub 移除
+	server.CloseClientConnections()
+	waitClients(t, s.hub, 0)
+}
+
+func TestEventStreamUnknownEvent(t *testing.T) {
+	s := newTestService()
+	rec := serve(s.routes(), http.MethodGet, basePath+"/events?events=progress,bogus", testToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unknown event: bogus")
+	assert.Empty(t, s.hub.clients)
+}
@@ -0,0 +1,34 @@
+package restapi
+
+import (
+	"CanMe/backend/consts"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+)
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// GET /openapi.json 返回 OpenAPI 3 文档（版本号与服务器地址按运行时填充）
+func (s *Service) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	var doc map[string]any
+	if err := json.Unmarshal(openAPIDocument, &doc); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if info, ok := doc["info"].(map[string]any); ok {
+		info["version"] = consts.APP_VERSION
+	}
+	doc["servers"] = []map[string]string{{"url": "http://" + r.Host + basePath}}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// GET /health 无需令牌，用于探测接口是否可用
+func (s *Service) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  "ok",
+		"name":    consts.APP_NAME,
+		"version": consts.APP_VERSION,
+	})
+}
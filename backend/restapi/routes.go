@@ -0,0 +1,145 @@
+package restapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// 请求体大小上限
+const maxBodyBytes = 1 << 20
+
+// routes 注册所有接口；除健康检查与 OpenAPI 文档外均需令牌
+func (s *Service) routes() http.Handler {
+	mux := http.NewServeMux()
+	public := func(pattern string, h http.HandlerFunc) {
+		method, path, _ := strings.Cut(pattern, " ")
+		mux.HandleFunc(method+" "+basePath+path, h)
+	}
+	private := func(pattern string, h http.HandlerFunc) {
+		public(pattern, s.requireToken(h))
+	}
+
+	public("GET /health", s.handleHealth)
+	public("GET /openapi.json", s.handleOpenAPI)
+	private("GET /events", s.handleEvents)
+
+	// downtasks
+	private("GET /tasks", s.handleListTasks)
+	private("POST /tasks", s.handleDownload)
+	private("POST /tasks/quick", s.handleQuickDownload)
+	private("GET /tasks/{id}", s.handleGetTask)
+	private("DELETE /tasks/{id}", s.handleDeleteTask)
+	private("POST /tasks/{id}/resume", s.handleResumeTask)
+	private("POST /tasks/{id}/cancel", s.handleCancelTask)
+	private("POST /tasks/{id}/verify", s.handleVerifyTask)
+	private("GET /tasks/{id}/log", s.handleTaskLog)
+	private("GET /content", s.handleGetContent)
+	private("GET /formats", s.handleGetFormats)
+	private("GET /statistics", s.handleStatistics)
+	private("GET /trash", s.handleListTrash)
+	private("POST /trash/{id}/restore", s.handleRestoreTrash)
+	private("DELETE /trash/{id}", s.handlePurgeTrash)
+	private("GET /duplicates", s.handleFindDuplicates)
+
+	// subtitles
+	private("GET /subtitles", s.handleListSubtitles)
+	private("POST /subtitles", s.handleImportSubtitle)
+	private("GET /subtitles/{id}", s.handleGetSubtitle)
+	private("DELETE /subtitles/{id}", s.handleDeleteSubtitle)
+	private("GET /subtitles/{id}/export", s.handleExportSubtitle)
+	private("GET /translation/providers", s.handleTranslationProviders)
+	private("POST /subtitles/{id}/translate", s.handleTranslateSubtitle)
+	private("POST /subtitles/{id}/translate/cancel", s.handleCancelTranslation)
+
+	// dependencies
+	private("GET /dependencies", s.handleListDependencies)
+	private("GET /dependencies/updates", s.handleCheckUpdates)
+	private("POST /dependencies/{type}/install", s.handleInstallDependency)
+
+	// browser bridge（配对令牌，允许跨域）
+	bridge := func(pattern string, h http.HandlerFunc) {
+		public(pattern, withCORS(s.requireBridgeToken(h)))
+	}
+	public("OPTIONS /bridge/", withCORS(handlePreflight))
+	bridge("GET /bridge/presets", s.handleBridgePresets)
+	bridge("POST /bridge/send", s.handleBridgeSend)
+
+	return mux
+}
+
+// requireToken 校验 Authorization: Bearer <token>；事件流额外接受 access_token 查询参数（EventSource 无法设置请求头）
+func (s *Service) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		expected, _ := s.token.Load().(string)
+		provided := bearerToken(r)
+		if provided == "" && strings.HasSuffix(r.URL.Path, "/events") {
+			provided = r.URL.Query().Get("access_token")
+		}
+		if !tokenMatches(provided, expected) {
+			writeUnauthorized(w)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requireBridgeToken 校验浏览器桥接的配对令牌
+func (s *Service) requireBridgeToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		expected, _ := s.bridgeToken.Load().(string)
+		if !tokenMatches(bearerToken(r), expected) {
+			writeUnauthorized(w)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+func tokenMatches(provided, expected string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) == 1
+}
+
+func writeUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="CanMe"`)
+	writeError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeServiceError 服务层错误："not found" 返回 404，其余使用给定状态码
+func writeServiceError(w http.ResponseWriter, status int, err error) {
+	if strings.Contains(strings.ToLower(err.Error()), "not found") {
+		status = http.StatusNotFound
+	}
+	writeError(w, status, err)
+}
+
+// decodeBody 解析 JSON 请求体；空请求体保持零值
+func decodeBody(r *http.Request, v any) error {
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testToken       = "api-token"
+	testBridgeToken = "bridge-token"
+)
+
+func newTestService() *Service {
+	s := &Service{hub: newSSEHub()}
+	s.token.Store(testToken)
+	s.bridgeToken.Store(testBridgeToken)
+	return s
+}
+
+func serve(h http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPublicRoutes(t *testing.T) {
+	h := newTestService().routes()
+
+	rec := serve(h, http.MethodGet, basePath+"/health", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"ok"`)
+
+	rec = serve(h, http.MethodGet, basePath+"/openapi.json", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, "http://example.com"+basePath, doc["servers"].([]any)[0].(map[string]any)["url"])
+}
+
+func TestBearerToken(t *testing.T) {
+	h := newTestService().routes()
+	path := basePath + "/tasks"
+
+	for name, token := range map[string]string{"missing": "", "wrong": "nope", "bridge": testBridgeToken} {
+		rec := serve(h, http.MethodGet, path, token)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+		assert.Equal(t, `Bearer realm="CanMe"`, rec.Header().Get("WWW-Authenticate"), name)
+	}
+
+	// 前缀不区分大小写
+	req := httptest.NewRequest(http.MethodGet, basePath+"/events?events=bogus", nil)
+	req.Header.Set("Authorization", "bearer "+testToken)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// 查询参数中的令牌只对事件流有效
+	rec = serve(h, http.MethodGet, basePath+"/events?events=bogus&access_token="+testToken, "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serve(h, http.MethodGet, path+"?access_token="+testToken, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// 未生成令牌时拒绝所有请求
+	s := &Service{hub: newSSEHub()}
+	rec = serve(s.routes(), http.MethodGet, path, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// 文档中的每个接口都已注册：公开接口直接可用，其余接口未带令牌时返回 401 而不是 404/405
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	h := newTestService().routes()
+
+	var doc struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(openAPIDocument, &doc))
+	require.NotEmpty(t, doc.Paths)
+
+	public := map[string]bool{"/health": true, "/openapi.json": true}
+	for path, methods := range doc.Paths {
+		concrete := strings.NewReplacer("{id}", "x1", "{type}", "ffmpeg").Replace(path)
+		for method := range methods {
+			rec := serve(h, strings.ToUpper(method), basePath+concrete, "")
+			if public[path] {
+				assert.Equal(t, http.StatusOK, rec.Code, "%s %s", method, path)
+			} else {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code, "%s %s", method, path)
+			}
+		}
+	}
+
+	rec := serve(h, http.MethodGet, basePath+"/nope", testToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serve(h, http.MethodPut, basePath+"/tasks", testToken)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
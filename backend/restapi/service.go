@@ -0,0 +1,147 @@
+// Package restapi 在本机（127.0.0.1）提供带版本号的 HTTP/JSON 接口，
+// 供脚本、Raycast/Alfred 工作流等不使用 MCP 的工具创建下载任务并查询状态。
+package restapi
+
+import (
+	"CanMe/backend/core/downtasks"
+	"CanMe/backend/core/subtitles"
+	"CanMe/backend/pkg/events"
+	"CanMe/backend/pkg/logger"
+	"CanMe/backend/services/preferences"
+	"CanMe/backend/types"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 接口路径前缀，不兼容变更时递增版本号
+const basePath = "/api/v1"
+
+type Service struct {
+	ctx       context.Context
+	downtasks *downtasks.Service
+	subtitles *subtitles.Service
+	eventBus  events.EventBus
+	pref      *preferences.Service
+
+	// 事件推送
+	hub *sseHub
+
+	// 当前访问令牌与浏览器桥接配对令牌，设置变更后立即生效
+	token       atomic.Value
+	bridgeToken atomic.Value
+
+	mu     sync.Mutex
+	server *http.Server
+	cancel context.CancelFunc
+	addr   string
+}
+
+func NewService(downtasks *downtasks.Service, subtitles *subtitles.Service, eventBus events.EventBus, pref *preferences.Service) *Service {
+	return &Service{
+		downtasks: downtasks,
+		subtitles: subtitles,
+		eventBus:  eventBus,
+		pref:      pref,
+		hub:       newSSEHub(),
+	}
+}
+
+// Start 订阅事件并按偏好设置启动接口；设置变更时自动重启或关闭
+func (s *Service) Start(ctx context.Context) error {
+	s.ctx = ctx
+	s.hub.subscribe(s.eventBus)
+	s.pref.OnLocalAPIChanged(s.apply)
+	s.apply(s.pref.GetLocalAPIConfig())
+	return nil
+}
+
+// Stop 关闭接口
+func (s *Service) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopLocked()
+}
+
+// apply 应用本机接口与浏览器桥接设置：任一启用时监听端口，未启用的一方令牌为空，请求一律返回 401
+func (s *Service) apply(config types.PreferencesLocalAPI) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bridge := s.pref.GetBridgeConfig()
+	if !config.Enabled && !bridge.Enabled {
+		if err := s.stopLocked(); err != nil {
+			logger.Warn("Error stopping local API", zap.Error(err))
+		}
+		return
+	}
+
+	var token string
+	if config.Enabled {
+		token = config.Token
+		if token == "" {
+			var err error
+			if token, err = s.pref.EnsureLocalAPIToken(); err != nil {
+				logger.Error("Local API token unavailable", zap.Error(err))
+			}
+		}
+	}
+	s.token.Store(token)
+
+	var bridgeToken string
+	if bridge.Enabled {
+		var err error
+		if bridgeToken, err = s.pref.EnsureBridgeToken(); err != nil {
+			logger.Warn("Bridge pairing token unavailable", zap.Error(err))
+		}
+	}
+	s.bridgeToken.Store(bridgeToken)
+
+	addr := fmt.Sprintf("127.0.0.1:%d", config.Port)
+	if s.server != nil && s.addr == addr {
+		return
+	}
+	if err := s.stopLocked(); err != nil {
+		logger.Warn("Error stopping local API", zap.Error(err))
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Error("Local API listen failed", zap.String("addr", addr), zap.Error(err))
+		return
+	}
+
+	// 关闭时取消 baseCtx，结束仍在推送的事件流
+	baseCtx, cancel := context.WithCancel(s.ctx)
+	server := &http.Server{
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return baseCtx },
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Error("Local API server error", zap.Error(err))
+		}
+	}()
+	s.server, s.cancel, s.addr = server, cancel, addr
+	logger.Info("Local API started", zap.String("addr", addr), zap.Bool("api", config.Enabled), zap.Bool("bridge", bridge.Enabled))
+}
+
+func (s *Service) stopLocked() error {
+	if s.server == nil {
+		return nil
+	}
+	s.cancel()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	err := s.server.Shutdown(ctx)
+	s.server, s.cancel, s.addr = nil, nil, ""
+	logger.Info("Local API stopped")
+	return err
+}
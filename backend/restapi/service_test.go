@@ -0,0 +1,56 @@
+package restapi
+
+import (
+	"CanMe/backend/services/preferences"
+	"CanMe/backend/types"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 只启用浏览器桥接时也监听端口，但本机接口的请求一律拒绝
+func TestApplyBridgeWithoutLocalAPI(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	pref := preferences.New()
+	require.True(t, pref.SetLocalAPIConfig(types.PreferencesLocalAPI{Port: port}).Success)
+	require.True(t, pref.SetBridgeEnabled(true).Success)
+
+	s := &Service{ctx: context.Background(), hub: newSSEHub(), pref: pref}
+	s.apply(pref.GetLocalAPIConfig())
+	t.Cleanup(func() { s.Stop() })
+	require.NotNil(t, s.server)
+
+	bridgeToken, _ := s.bridgeToken.Load().(string)
+	assert.NotEmpty(t, bridgeToken)
+	assert.Empty(t, s.token.Load())
+
+	base := fmt.Sprintf("http://127.0.0.1:%d%s", port, basePath)
+	resp, err := http.Get(base + "/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodGet, base+"/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+bridgeToken)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// 两者都关闭时停止监听
+	require.True(t, pref.SetBridgeEnabled(false).Success)
+	s.apply(pref.GetLocalAPIConfig())
+	assert.Nil(t, s.server)
+}
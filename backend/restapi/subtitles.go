@@ -0,0 +1,138 @@
+package restapi
+
+import (
+	"CanMe/backend/types"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// 导出格式对应的 Content-Type
+var subtitleContentTypes = map[string]string{
+	"srt":    "application/x-subrip; charset=utf-8",
+	"vtt":    "text/vtt; charset=utf-8",
+	"ass":    "text/x-ssa; charset=utf-8",
+	"ssa":    "text/x-ssa; charset=utf-8",
+	"itt":    "application/ttml+xml; charset=utf-8",
+	"fcpxml": "application/xml; charset=utf-8",
+}
+
+type translateSubtitleRequest struct {
+	Origin     string `json:"origin"`
+	TargetLang string `json:"targetLang"`
+	Provider   string `json:"provider"`
+}
+
+type importSubtitleRequest struct {
+	FilePath string                      `json:"filePath"`
+	Options  types.TextProcessingOptions `json:"options"`
+}
+
+func (s *Service) handleListSubtitles(w http.ResponseWriter, r *http.Request) {
+	projects, err := s.subtitles.ListSubtitles()
+	if err != nil {
+		writeServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, projects)
+}
+
+// POST /subtitles 按本机文件路径导入字幕
+func (s *Service) handleImportSubtitle(w http.ResponseWriter, r *http.Request) {
+	var request importSubtitleRequest
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !filepath.IsAbs(request.FilePath) {
+		writeError(w, http.StatusBadRequest, errors.New("filePath must be an absolute path"))
+		return
+	}
+	project, err := s.subtitles.ImportSubtitle(request.FilePath, request.Options)
+	if err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, project)
+}
+
+func (s *Service) handleGetSubtitle(w http.ResponseWriter, r *http.Request) {
+	project, err := s.subtitles.GetSubtitle(r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Service) handleDeleteSubtitle(w http.ResponseWriter, r *http.Request) {
+	if err := s.subtitles.DeleteSubtitle(r.PathValue("id")); err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /subtitles/{id}/export?lang=&format=&secondary= 返回转换后的字幕文件内容；
+// 指定 secondary 时导出双语字幕（lang 在上方）
+func (s *Service) handleExportSubtitle(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	lang := r.URL.Query().Get("lang")
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "srt"
+	}
+	if lang == "" {
+		writeError(w, http.StatusBadRequest, errors.New("lang is required"))
+		return
+	}
+	name := lang
+	secondary := r.URL.Query().Get("secondary")
+	if secondary != "" {
+		name += "." + secondary
+	}
+	data, err := s.subtitles.ConvertSubtile(id, lang, format, secondary)
+	if err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if ct, ok := subtitleContentTypes[format]; ok {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s.%s"`, id, name, format))
+	w.Write(data)
+}
+
+func (s *Service) handleTranslationProviders(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.subtitles.GetTranslationProviders())
+}
+
+// POST /subtitles/{id}/translate 启动机器翻译，进度通过 subtitle 事件推送
+func (s *Service) handleTranslateSubtitle(w http.ResponseWriter, r *http.Request) {
+	var request translateSubtitleRequest
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	task, err := s.subtitles.TranslateSubtitle(r.PathValue("id"), request.Origin, request.TargetLang, request.Provider)
+	if err != nil {
+		writeServiceError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, task)
+}
+
+func (s *Service) handleCancelTranslation(w http.ResponseWriter, r *http.Request) {
+	var request translateSubtitleRequest
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.subtitles.CancelTranslation(r.PathValue("id"), request.TargetLang); err != nil {
+		writeServiceError(w, http.StatusConflict, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
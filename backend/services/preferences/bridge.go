@@ -0,0 +1,83 @@
+package preferences
+
+import (
+	"CanMe/backend/consts"
+	"CanMe/backend/types"
+	"fmt"
+	"strings"
+)
+
+// GetBridgeConfig 获取浏览器桥接设置，无有效预设时使用内置预设
+func (s *Service) GetBridgeConfig() types.PreferencesBridge {
+	pref := s.pref.GetPreferences()
+	config := pref.Bridge
+
+	presets := make([]types.DtBridgePreset, 0, len(config.Presets))
+	for _, preset := range config.Presets {
+		preset.Name = strings.TrimSpace(preset.Name)
+		if preset.Name == "" {
+			continue
+		}
+		if preset.Type != consts.TASK_TYPE_CUSTOM {
+			preset.Type = consts.TASK_TYPE_QUICK
+		}
+		presets = append(presets, preset)
+	}
+	if len(presets) == 0 {
+		presets = types.DefaultBridgePresets()
+	}
+	config.Presets = presets
+	return config
+}
+
+// SetBridgeEnabled 启用或关闭浏览器桥接，变更后重启接口服务
+func (s *Service) SetBridgeEnabled(enabled bool) (resp types.JSResp) {
+	pref := s.pref.GetPreferences()
+	if pref.Bridge.Enabled == enabled {
+		resp.Success = true
+		return
+	}
+	pref.Bridge.Enabled = enabled
+	if err := s.pref.SetPreferences(&pref); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	s.triggerLocalAPIChangedCallbacks()
+	resp.Success = true
+	return
+}
+
+// EnsureBridgeToken 返回配对令牌，未设置时生成并保存
+func (s *Service) EnsureBridgeToken() (string, error) {
+	if token := s.GetBridgeConfig().Token; token != "" {
+		return token, nil
+	}
+	return s.saveNewBridgeToken()
+}
+
+// RegenerateBridgeToken 重新生成配对令牌，已配对的浏览器需要重新配对
+func (s *Service) RegenerateBridgeToken() (resp types.JSResp) {
+	token, err := s.saveNewBridgeToken()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	s.triggerLocalAPIChangedCallbacks()
+	resp.Data = token
+	resp.Success = true
+	return
+}
+
+func (s *Service) saveNewBridgeToken() (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	pref := s.pref.GetPreferences()
+	pref.Bridge.Token = token
+	if err := s.pref.SetPreferences(&pref); err != nil {
+		return "", fmt.Errorf("save failed: %v", err)
+	}
+	return token, nil
+}
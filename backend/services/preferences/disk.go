@@ -0,0 +1,27 @@
+package preferences
+
+import (
+	"CanMe/backend/types"
+	"strings"
+)
+
+// GetDiskConfig 获取磁盘空间保护设置，非法值回退为默认值
+func (s *Service) GetDiskConfig() types.PreferencesDisk {
+	pref := s.pref.GetPreferences()
+	config := pref.Disk
+	defaults := types.DefaultPreferencesDisk()
+
+	if config.MinFreeMB < 0 {
+		config.MinFreeMB = 0
+	}
+	if config.HeadroomFactor < 1 {
+		config.HeadroomFactor = defaults.HeadroomFactor
+	}
+	switch strings.ToLower(config.OnInsufficient) {
+	case "refuse", "queue":
+		config.OnInsufficient = strings.ToLower(config.OnInsufficient)
+	default:
+		config.OnInsufficient = defaults.OnInsufficient
+	}
+	return config
+}
@@ -0,0 +1,21 @@
+package preferences
+
+import (
+	"CanMe/backend/types"
+	"strings"
+)
+
+// GetDuplicatesConfig 获取内容去重设置，非法值回退为默认值
+func (s *Service) GetDuplicatesConfig() types.PreferencesDuplicates {
+	pref := s.pref.GetPreferences()
+	config := pref.Duplicates
+	defaults := types.DefaultPreferencesDuplicates()
+
+	switch strings.ToLower(config.OnDuplicate) {
+	case "warn", "skip", "off":
+		config.OnDuplicate = strings.ToLower(config.OnDuplicate)
+	default:
+		config.OnDuplicate = defaults.OnDuplicate
+	}
+	return config
+}
@@ -0,0 +1,25 @@
+package preferences
+
+import (
+	"CanMe/backend/types"
+	"strings"
+)
+
+// GetLibraryConfig 获取媒体库整理设置，非法值回退为默认值
+func (s *Service) GetLibraryConfig() types.PreferencesLibrary {
+	pref := s.pref.GetPreferences()
+	config := pref.Library
+	defaults := types.DefaultPreferencesLibrary()
+
+	config.Root = strings.TrimSpace(config.Root)
+	if strings.TrimSpace(config.Template) == "" {
+		config.Template = defaults.Template
+	}
+	switch strings.ToLower(config.OnConflict) {
+	case "rename", "skip":
+		config.OnConflict = strings.ToLower(config.OnConflict)
+	default:
+		config.OnConflict = defaults.OnConflict
+	}
+	return config
+}
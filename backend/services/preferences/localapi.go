@@ -0,0 +1,100 @@
+package preferences
+
+import (
+	"CanMe/backend/types"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// 本机接口设置变更回调函数类型
+type LocalAPIChangedCallback func(types.PreferencesLocalAPI)
+
+// 本机接口设置变更回调列表
+var localAPIChangedCallbacks []LocalAPIChangedCallback
+
+// OnLocalAPIChanged 注册本机接口设置变更回调
+func (s *Service) OnLocalAPIChanged(callback LocalAPIChangedCallback) {
+	localAPIChangedCallbacks = append(localAPIChangedCallbacks, callback)
+}
+
+// triggerLocalAPIChangedCallbacks 触发所有本机接口设置变更回调
+func (s *Service) triggerLocalAPIChangedCallbacks() {
+	config := s.GetLocalAPIConfig()
+	for _, callback := range localAPIChangedCallbacks {
+		callback(config)
+	}
+}
+
+// GetLocalAPIConfig 获取本机接口设置，非法端口回退为默认值
+func (s *Service) GetLocalAPIConfig() types.PreferencesLocalAPI {
+	pref := s.pref.GetPreferences()
+	config := pref.LocalAPI
+	defaults := types.DefaultPreferencesLocalAPI()
+
+	if config.Port < 1024 || config.Port > 65535 {
+		config.Port = defaults.Port
+	}
+	return config
+}
+
+// SetLocalAPIConfig 保存本机接口设置（启用、端口、令牌），变更后重启接口服务
+func (s *Service) SetLocalAPIConfig(config types.PreferencesLocalAPI) (resp types.JSResp) {
+	pref := s.pref.GetPreferences()
+	if pref.LocalAPI == config {
+		resp.Success = true
+		return
+	}
+	pref.LocalAPI = config
+	if err := s.pref.SetPreferences(&pref); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	s.triggerLocalAPIChangedCallbacks()
+	resp.Success = true
+	return
+}
+
+// EnsureLocalAPIToken 返回访问令牌，未设置时生成并保存
+func (s *Service) EnsureLocalAPIToken() (string, error) {
+	if token := s.GetLocalAPIConfig().Token; token != "" {
+		return token, nil
+	}
+	return s.saveNewLocalAPIToken()
+}
+
+// RegenerateLocalAPIToken 重新生成访问令牌，旧令牌立即失效
+func (s *Service) RegenerateLocalAPIToken() (resp types.JSResp) {
+	token, err := s.saveNewLocalAPIToken()
+	if err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	s.triggerLocalAPIChangedCallbacks()
+	resp.Data = token
+	resp.Success = true
+	return
+}
+
+func (s *Service) saveNewLocalAPIToken() (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	pref := s.pref.GetPreferences()
+	pref.LocalAPI.Token = token
+	if err := s.pref.SetPreferences(&pref); err != nil {
+		return "", fmt.Errorf("save failed: %v", err)
+	}
+	return token, nil
+}
+
+// newToken 生成 32 字节随机令牌（十六进制）
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
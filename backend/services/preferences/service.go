@@ -84,10 +84,12 @@ func (p *Service) GetPreferences() (resp types.JSResp) {
 	return
 }
 
+// SetPreferences 保存设置页的配置节，其余配置节保留原值（见 mergeSettings）
 func (p *Service) SetPreferences(pf types.Preferences) (resp types.JSResp) {
     // Detect logger config change before saving
     old := p.pref.GetPreferences()
     loggerChanged := !reflect.DeepEqual(old.Logger, pf.Logger)
+    pf = mergeSettings(old, pf)
 
     err := p.pref.SetPreferences(&pf)
     if err != nil {
@@ -109,6 +111,18 @@ func (p *Service) SetPreferences(pf types.Preferences) (resp types.JSResp) {
     return
 }
 
+// mergeSettings 设置页只提交 behavior、general、proxy、download、logger，
+// 其余配置节（回收站、磁盘空间、校验、去重、本机接口、翻译等）由各自的接口或配置文件维护，沿用 old 中的值
+func mergeSettings(old, pf types.Preferences) types.Preferences {
+	merged := old
+	merged.Behavior = pf.Behavior
+	merged.General = pf.General
+	merged.Proxy = pf.Proxy
+	merged.Download = pf.Download
+	merged.Logger = pf.Logger
+	return merged
+}
+
 func (p *Service) UpdatePreferences(value map[string]any) (resp types.JSResp) {
 	err := p.pref.UpdatePreferences(value)
 	if err != nil {
@@ -0,0 +1,59 @@
+package preferences
+
+import (
+	"CanMe/backend/storage"
+	"CanMe/backend/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPreferencesKeepsUnsentSections(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("LANG", "")
+	s := &Service{pref: storage.NewPreferences()}
+
+	pref := s.pref.GetPreferences()
+	pref.Disk.MinFreeMB = 2048
+	pref.LocalAPI = types.PreferencesLocalAPI{Enabled: true, Port: 9999, Token: "api-token"}
+	pref.Bridge.Token = "bridge-token"
+	pref.Translation.OpenAI.APIKey = "sk-test"
+	require.NoError(t, s.pref.SetPreferences(&pref))
+
+	fired := 0
+	localAPIChangedCallbacks = []LocalAPIChangedCallback{func(types.PreferencesLocalAPI) { fired++ }}
+	t.Cleanup(func() { localAPIChangedCallbacks = nil })
+
+	// 设置页只提交部分配置节，其余字段反序列化为零值
+	partial := types.Preferences{
+		Behavior: pref.Behavior,
+		General:  pref.General,
+		Proxy:    pref.Proxy,
+		Download: pref.Download,
+		Logger:   pref.Logger,
+	}
+	partial.General.Theme = "green"
+	resp := s.SetPreferences(partial)
+	require.True(t, resp.Success, resp.Msg)
+
+	saved := s.pref.GetPreferences()
+	assert.Equal(t, "green", saved.General.Theme)
+	assert.Equal(t, 30, saved.Trash.AutoPurgeDays)
+	assert.Equal(t, int64(2048), int64(saved.Disk.MinFreeMB))
+	assert.True(t, saved.Verify.Enabled)
+	assert.True(t, saved.Duplicates.HashFiles)
+	assert.Equal(t, pref.LocalAPI, saved.LocalAPI)
+	assert.Equal(t, "bridge-token", saved.Bridge.Token)
+	assert.Equal(t, "sk-test", saved.Translation.OpenAI.APIKey)
+	assert.Zero(t, fired)
+
+	// 本机接口设置通过专用接口修改并触发回调
+	config := saved.LocalAPI
+	config.Enabled = false
+	require.True(t, s.SetLocalAPIConfig(config).Success)
+	assert.False(t, s.pref.GetPreferences().LocalAPI.Enabled)
+	assert.Equal(t, 1, fired)
+	require.True(t, s.SetLocalAPIConfig(config).Success)
+	assert.Equal(t, 1, fired)
+}
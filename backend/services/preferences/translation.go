@@ -0,0 +1,44 @@
+package preferences
+
+import (
+	"CanMe/backend/types"
+	"strings"
+)
+
+// GetTranslationConfig 获取机器翻译设置，缺省或非法的值回退为默认值
+func (s *Service) GetTranslationConfig() types.PreferencesTranslation {
+	pref := s.pref.GetPreferences()
+	config := pref.Translation
+	defaults := types.DefaultPreferencesTranslation()
+
+	config.Provider = strings.ToLower(strings.TrimSpace(config.Provider))
+	if config.Provider == "" {
+		config.Provider = defaults.Provider
+	}
+	config.OpenAI.BaseURL = strings.TrimRight(strings.TrimSpace(config.OpenAI.BaseURL), "/")
+	if config.OpenAI.BaseURL == "" {
+		config.OpenAI.BaseURL = defaults.OpenAI.BaseURL
+	}
+	if strings.TrimSpace(config.OpenAI.Model) == "" {
+		config.OpenAI.Model = defaults.OpenAI.Model
+	}
+	if config.OpenAI.Temperature < 0 || config.OpenAI.Temperature > 2 {
+		config.OpenAI.Temperature = defaults.OpenAI.Temperature
+	}
+	if config.OpenAI.TimeoutSeconds <= 0 {
+		config.OpenAI.TimeoutSeconds = defaults.OpenAI.TimeoutSeconds
+	}
+	if config.BatchSize <= 0 || config.BatchSize > 200 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.ContextSize < 0 || config.ContextSize > 20 {
+		config.ContextSize = defaults.ContextSize
+	}
+	if config.MaxRetries < 0 || config.MaxRetries > 10 {
+		config.MaxRetries = defaults.MaxRetries
+	}
+	if config.TMThreshold <= 0 || config.TMThreshold > 1 {
+		config.TMThreshold = defaults.TMThreshold
+	}
+	return config
+}
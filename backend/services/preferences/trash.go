@@ -0,0 +1,14 @@
+package preferences
+
+import (
+	"CanMe/backend/types"
+)
+
+// GetTrashConfig 获取回收站设置
+func (s *Service) GetTrashConfig() types.PreferencesTrash {
+	pref := s.pref.GetPreferences()
+	if pref.Trash.AutoPurgeDays < 0 {
+		pref.Trash.AutoPurgeDays = 0
+	}
+	return pref.Trash
+}
@@ -0,0 +1,15 @@
+package preferences
+
+import "CanMe/backend/types"
+
+// GetURLConfig 获取链接规范化设置，非法值回退为默认值
+func (s *Service) GetURLConfig() types.PreferencesURL {
+	pref := s.pref.GetPreferences()
+	config := pref.URL
+	defaults := types.DefaultPreferencesURL()
+
+	if config.ResolveTimeoutSec <= 0 || config.ResolveTimeoutSec > 60 {
+		config.ResolveTimeoutSec = defaults.ResolveTimeoutSec
+	}
+	return config
+}
@@ -0,0 +1,24 @@
+package preferences
+
+import (
+	"CanMe/backend/types"
+	"strings"
+)
+
+// GetVerifyConfig 获取媒体校验设置，非法值回退为默认值
+func (s *Service) GetVerifyConfig() types.PreferencesVerify {
+	pref := s.pref.GetPreferences()
+	config := pref.Verify
+	defaults := types.DefaultPreferencesVerify()
+
+	switch strings.ToLower(config.OnCorrupt) {
+	case "flag", "fail":
+		config.OnCorrupt = strings.ToLower(config.OnCorrupt)
+	default:
+		config.OnCorrupt = defaults.OnCorrupt
+	}
+	if config.DurationTolerance <= 0 || config.DurationTolerance >= 1 {
+		config.DurationTolerance = defaults.DurationTolerance
+	}
+	return config
+}
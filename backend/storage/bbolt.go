@@ -3,6 +3,7 @@ package storage
 import (
 	"CanMe/backend/consts"
 	"CanMe/backend/types"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -16,11 +17,22 @@ import (
 
 var (
 	taskBucket       = []byte("tasks")
-	imageBucket      = []byte("images")       // 用于存储图片的桶
-	formatBucket     = []byte("formats")      // 用于存储格式的桶
-	subtitleBucket   = []byte("subtitles")    // 用于存储字幕的桶
-	dependencyBucket = []byte("dependencies") // 用于存储依赖信息的桶
-	cookiesBucket    = []byte("cookies")      // 用于存储浏览器Cookie的桶
+	imageBucket      = []byte("images")         // 用于存储图片的桶
+	formatBucket     = []byte("formats")        // 用于存储格式的桶
+	subtitleBucket   = []byte("subtitles")      // 用于存储字幕的桶
+	dependencyBucket = []byte("dependencies")   // 用于存储依赖信息的桶
+	cookiesBucket    = []byte("cookies")        // 用于存储浏览器Cookie的桶
+	trashBucket      = []byte("trash")          // 用于存储回收站条目的桶
+	hookBucket       = []byte("hooks")          // 用于存储任务钩子的桶
+	hookLogBucket    = []byte("hook_logs")      // 用于存储钩子执行记录的桶
+	throughputBucket = []byte("throughput")     // 用于存储下载速度采样的桶
+	ruleBucket       = []byte("download_rules") // 用于存储按域名下载规则的桶
+	glossaryBucket   = []byte("glossaries")     // 用于存储术语表的桶
+	tmBucket         = []byte("tm_entries")     // 用于存储翻译记忆的桶
+	editBucket       = []byte("edit_history")   // 用于存储字幕编辑操作日志的桶
+	snapshotBucket   = []byte("snapshots")      // 用于存储字幕项目快照的桶
+	guidelineBucket  = []byte("guidelines")     // 用于存储自定义字幕规范的桶
+	qcRuleBucket     = []byte("qc_rule_sets")   // 用于存储字幕质检规则集的桶
 	// other buckets...
 )
 
@@ -81,6 +93,44 @@ func NewBoltStorage() (*BoltStorage, error) {
 		if _, err := tx.CreateBucketIfNotExists(cookiesBucket); err != nil {
 			return err
 		}
+		// create trash bucket
+		if _, err := tx.CreateBucketIfNotExists(trashBucket); err != nil {
+			return err
+		}
+		// create hook buckets
+		if _, err := tx.CreateBucketIfNotExists(hookBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(hookLogBucket); err != nil {
+			return err
+		}
+		// create throughput buckets
+		if _, err := tx.CreateBucketIfNotExists(throughputBucket); err != nil {
+			return err
+		}
+		// create download rule buckets
+		if _, err := tx.CreateBucketIfNotExists(ruleBucket); err != nil {
+			return err
+		}
+		// create glossary and translation memory buckets
+		if _, err := tx.CreateBucketIfNotExists(glossaryBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(tmBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(editBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(snapshotBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(guidelineBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(qcRuleBucket); err != nil {
+			return err
+		}
 		// create other buckets...
 		return nil
 	})
@@ -385,21 +435,32 @@ func (s *BoltStorage) ListSubtitles() ([]*types.SubtitleProject, error) {
 	return subs, nil
 }
 
-// DeleteSubtitle deletes a subtitle from the storage
+// DeleteSubtitle deletes a subtitle with its edit history and snapshots from the storage
 func (s *BoltStorage) DeleteSubtitle(id string) error {
 	return s.db.Update(func(tx *bbolt.Tx) error {
+		if err := tx.Bucket(editBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+		if err := deletePrefix(tx.Bucket(snapshotBucket), snapshotPrefix(id)); err != nil {
+			return err
+		}
 		b := tx.Bucket(subtitleBucket)
 		return b.Delete([]byte(id))
 	})
 }
 
-// DeleteAllSubtitle deletes all subtitles from the storage
+// DeleteAllSubtitle deletes all subtitles, edit histories and snapshots from the storage
 func (s *BoltStorage) DeleteAllSubtitle() error {
 	return s.db.Update(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(subtitleBucket)
-		return b.ForEach(func(k, v []byte) error {
-			return b.Delete(k)
-		})
+		for _, name := range [][]byte{editBucket, snapshotBucket, subtitleBucket} {
+			b := tx.Bucket(name)
+			if err := b.ForEach(func(k, v []byte) error {
+				return b.Delete(k)
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 }
 
@@ -570,3 +631,741 @@ func (s *BoltStorage) DeleteCookies(browser string) error {
 		return b.Delete([]byte(browser))
 	})
 }
+
+// SaveTrashItem 保存回收站条目
+func (s *BoltStorage) SaveTrashItem(item *types.DtTrashItem) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(trashBucket)
+
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to marshal trash item %s: %w", item.ID, err)
+		}
+
+		return b.Put([]byte(item.ID), encoded)
+	})
+}
+
+// GetTrashItem 根据ID获取回收站条目
+func (s *BoltStorage) GetTrashItem(id string) (*types.DtTrashItem, error) {
+	var item types.DtTrashItem
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(trashBucket)
+		data := b.Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("trash item not found: %s", id)
+		}
+
+		return json.Unmarshal(data, &item)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// ListTrashItems 获取所有回收站条目，按删除时间降序排列
+func (s *BoltStorage) ListTrashItems() ([]*types.DtTrashItem, error) {
+	var items []*types.DtTrashItem
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(trashBucket)
+
+		return b.ForEach(func(k, v []byte) error {
+			var item types.DtTrashItem
+			if err := json.Unmarshal(v, &item); err != nil {
+				return err
+			}
+			items = append(items, &item)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt > items[j].DeletedAt
+	})
+
+	return items, nil
+}
+
+// DeleteTrashItem 删除回收站条目
+func (s *BoltStorage) DeleteTrashItem(id string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(trashBucket)
+		return b.Delete([]byte(id))
+	})
+}
+
+// SaveHook 保存任务钩子
+func (s *BoltStorage) SaveHook(hook *types.TaskHook) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(hookBucket)
+
+		hook.UpdatedAt = time.Now().Unix()
+		encoded, err := json.Marshal(hook)
+		if err != nil {
+			return fmt.Errorf("failed to marshal hook %s: %w", hook.ID, err)
+		}
+
+		return b.Put([]byte(hook.ID), encoded)
+	})
+}
+
+// GetHook 根据ID获取任务钩子
+func (s *BoltStorage) GetHook(id string) (*types.TaskHook, error) {
+	var hook types.TaskHook
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(hookBucket)
+		data := b.Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("hook not found: %s", id)
+		}
+
+		return json.Unmarshal(data, &hook)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &hook, nil
+}
+
+// ListHooks 获取所有任务钩子，按创建时间升序排列
+func (s *BoltStorage) ListHooks() ([]*types.TaskHook, error) {
+	var hooks []*types.TaskHook
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(hookBucket)
+
+		return b.ForEach(func(k, v []byte) error {
+			var hook types.TaskHook
+			if err := json.Unmarshal(v, &hook); err != nil {
+				return err
+			}
+			hooks = append(hooks, &hook)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].CreatedAt < hooks[j].CreatedAt
+	})
+
+	return hooks, nil
+}
+
+// DeleteHook 删除任务钩子
+func (s *BoltStorage) DeleteHook(id string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(hookBucket)
+		return b.Delete([]byte(id))
+	})
+}
+
+// hookLogKey 以时间戳为前缀，保证按时间顺序遍历
+func hookLogKey(exec *types.HookExecution) []byte {
+	return []byte(fmt.Sprintf("%020d-%s", exec.StartedAt, exec.ID))
+}
+
+// SaveHookExecution 保存钩子执行记录，并只保留最近 maxEntries 条
+func (s *BoltStorage) SaveHookExecution(exec *types.HookExecution, maxEntries int) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(hookLogBucket)
+
+		encoded, err := json.Marshal(exec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal hook execution %s: %w", exec.ID, err)
+		}
+		if err := b.Put(hookLogKey(exec), encoded); err != nil {
+			return err
+		}
+
+		if maxEntries <= 0 {
+			return nil
+		}
+		excess := b.Stats().KeyN - maxEntries
+		if excess <= 0 {
+			return nil
+		}
+		// 先收集再删除，避免遍历时修改
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && len(keys) < excess; k, _ = c.Next() {
+			keys = append(keys, append([]byte{}, k...))
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListHookExecutions 获取最近的钩子执行记录（按时间降序），hookID/taskID 为空表示不过滤
+func (s *BoltStorage) ListHookExecutions(hookID, taskID string, limit int) ([]*types.HookExecution, error) {
+	execs := []*types.HookExecution{}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(hookLogBucket).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var exec types.HookExecution
+			if err := json.Unmarshal(v, &exec); err != nil {
+				return err
+			}
+			if hookID != "" && exec.HookID != hookID {
+				continue
+			}
+			if taskID != "" && exec.TaskID != taskID {
+				continue
+			}
+			execs = append(execs, &exec)
+			if limit > 0 && len(execs) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return execs, nil
+}
+
+// throughputKey 按时间排序的速度采样键
+func throughputKey(sample types.DtThroughputSample) []byte {
+	return []byte(fmt.Sprintf("%020d-%s", sample.Time, sample.TaskID))
+}
+
+// SaveThroughputSamples 批量保存速度采样，并删除早于 cutoff（unix 秒）的采样；cutoff<=0 表示不清理
+func (s *BoltStorage) SaveThroughputSamples(samples []types.DtThroughputSample, cutoff int64) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(throughputBucket)
+
+		for _, sample := range samples {
+			encoded, err := json.Marshal(sample)
+			if err != nil {
+				return fmt.Errorf("failed to marshal throughput sample: %w", err)
+			}
+			if err := b.Put(throughputKey(sample), encoded); err != nil {
+				return err
+			}
+		}
+
+		if cutoff <= 0 {
+			return nil
+		}
+		limit := []byte(fmt.Sprintf("%020d", cutoff))
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, limit) < 0; k, _ = c.Next() {
+			keys = append(keys, append([]byte{}, k...))
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListThroughputSamples 获取 [from, to] 时间范围内的速度采样（按时间升序），to<=0 表示不限
+func (s *BoltStorage) ListThroughputSamples(from, to int64) ([]types.DtThroughputSample, error) {
+	samples := []types.DtThroughputSample{}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(throughputBucket).Cursor()
+		for k, v := c.Seek([]byte(fmt.Sprintf("%020d", from))); k != nil; k, v = c.Next() {
+			var sample types.DtThroughputSample
+			if err := json.Unmarshal(v, &sample); err != nil {
+				return err
+			}
+			if to > 0 && sample.Time > to {
+				break
+			}
+			samples = append(samples, sample)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
+
+// SaveDownloadRule 保存按域名下载规则
+func (s *BoltStorage) SaveDownloadRule(rule *types.DtDownloadRule) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(ruleBucket)
+
+		rule.UpdatedAt = time.Now().Unix()
+		encoded, err := json.Marshal(rule)
+		if err != nil {
+			return fmt.Errorf("failed to marshal download rule %s: %w", rule.ID, err)
+		}
+
+		return b.Put([]byte(rule.ID), encoded)
+	})
+}
+
+// GetDownloadRule 根据ID获取下载规则
+func (s *BoltStorage) GetDownloadRule(id string) (*types.DtDownloadRule, error) {
+	var rule types.DtDownloadRule
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(ruleBucket)
+		data := b.Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("download rule not found: %s", id)
+		}
+
+		return json.Unmarshal(data, &rule)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// ListDownloadRules 获取所有下载规则，按创建时间升序排列
+func (s *BoltStorage) ListDownloadRules() ([]*types.DtDownloadRule, error) {
+	rules := []*types.DtDownloadRule{}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(ruleBucket)
+
+		return b.ForEach(func(k, v []byte) error {
+			var rule types.DtDownloadRule
+			if err := json.Unmarshal(v, &rule); err != nil {
+				return err
+			}
+			rules = append(rules, &rule)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].CreatedAt < rules[j].CreatedAt
+	})
+
+	return rules, nil
+}
+
+// DeleteDownloadRule 删除下载规则
+func (s *BoltStorage) DeleteDownloadRule(id string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(ruleBucket)
+		return b.Delete([]byte(id))
+	})
+}
+
+// SaveGlossary 保存术语表
+func (s *BoltStorage) SaveGlossary(glossary *types.Glossary) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(glossaryBucket)
+
+		glossary.UpdatedAt = time.Now().Unix()
+		encoded, err := json.Marshal(glossary)
+		if err != nil {
+			return fmt.Errorf("failed to marshal glossary %s: %w", glossary.ID, err)
+		}
+
+		return b.Put([]byte(glossary.ID), encoded)
+	})
+}
+
+// GetGlossary 根据ID获取术语表
+func (s *BoltStorage) GetGlossary(id string) (*types.Glossary, error) {
+	var glossary types.Glossary
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(glossaryBucket)
+		data := b.Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("glossary not found: %s", id)
+		}
+
+		return json.Unmarshal(data, &glossary)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &glossary, nil
+}
+
+// ListGlossaries 获取所有术语表，按创建时间升序排列
+func (s *BoltStorage) ListGlossaries() ([]*types.Glossary, error) {
+	glossaries := []*types.Glossary{}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(glossaryBucket)
+
+		return b.ForEach(func(k, v []byte) error {
+			var glossary types.Glossary
+			if err := json.Unmarshal(v, &glossary); err != nil {
+				return err
+			}
+			glossaries = append(glossaries, &glossary)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(glossaries, func(i, j int) bool {
+		return glossaries[i].CreatedAt < glossaries[j].CreatedAt
+	})
+
+	return glossaries, nil
+}
+
+// DeleteGlossary 删除术语表
+func (s *BoltStorage) DeleteGlossary(id string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(glossaryBucket)
+		return b.Delete([]byte(id))
+	})
+}
+
+// SaveTMEntries 批量保存翻译记忆条目（相同 ID 覆盖）
+func (s *BoltStorage) SaveTMEntries(entries []*types.TMEntry) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(tmBucket)
+
+		now := time.Now().Unix()
+		for _, entry := range entries {
+			if existing := b.Get([]byte(entry.ID)); existing != nil {
+				var old types.TMEntry
+				if err := json.Unmarshal(existing, &old); err == nil {
+					entry.CreatedAt = old.CreatedAt
+				}
+			}
+			if entry.CreatedAt == 0 {
+				entry.CreatedAt = now
+			}
+			entry.UpdatedAt = now
+			encoded, err := json.Marshal(entry)
+			if err != nil {
+				return fmt.Errorf("failed to marshal translation memory entry %s: %w", entry.ID, err)
+			}
+			if err := b.Put([]byte(entry.ID), encoded); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListTMEntries 获取翻译记忆条目，filter 返回 false 的条目被跳过（nil 表示不过滤）
+func (s *BoltStorage) ListTMEntries(filter func(entry *types.TMEntry) bool) ([]*types.TMEntry, error) {
+	entries := []*types.TMEntry{}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(tmBucket)
+
+		return b.ForEach(func(k, v []byte) error {
+			var entry types.TMEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			if filter == nil || filter(&entry) {
+				entries = append(entries, &entry)
+			}
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// DeleteTMEntry 删除翻译记忆条目
+func (s *BoltStorage) DeleteTMEntry(id string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(tmBucket)
+		return b.Delete([]byte(id))
+	})
+}
+
+// SaveEditHistory 保存项目的编辑操作日志
+func (s *BoltStorage) SaveEditHistory(history *types.EditHistory) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(editBucket)
+
+		encoded, err := json.Marshal(history)
+		if err != nil {
+			return fmt.Errorf("failed to marshal edit history %s: %w", history.ProjectID, err)
+		}
+
+		return b.Put([]byte(history.ProjectID), encoded)
+	})
+}
+
+// GetEditHistory 获取项目的编辑操作日志，尚无记录时返回空日志
+func (s *BoltStorage) GetEditHistory(projectID string) (*types.EditHistory, error) {
+	history := types.EditHistory{ProjectID: projectID}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(editBucket).Get([]byte(projectID))
+		if data == nil {
+			return nil
+		}
+		return json.Unmarshal(data, &history)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+// snapshotPrefix 快照按 项目ID/快照ID 存储，便于按项目前缀遍历
+func snapshotPrefix(projectID string) []byte {
+	return []byte(projectID + "/")
+}
+
+// deletePrefix 删除桶中指定前缀的所有键
+func deletePrefix(b *bbolt.Bucket, prefix []byte) error {
+	var keys [][]byte
+	c := b.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		keys = append(keys, append([]byte{}, k...))
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveSnapshot 保存项目快照
+func (s *BoltStorage) SaveSnapshot(snapshot *types.ProjectSnapshot) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(snapshotBucket)
+
+		encoded, err := json.Marshal(snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to marshal snapshot %s: %w", snapshot.ID, err)
+		}
+
+		return b.Put(append(snapshotPrefix(snapshot.ProjectID), snapshot.ID...), encoded)
+	})
+}
+
+// GetSnapshot 获取项目快照
+func (s *BoltStorage) GetSnapshot(projectID, snapshotID string) (*types.ProjectSnapshot, error) {
+	var snapshot types.ProjectSnapshot
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(snapshotBucket).Get(append(snapshotPrefix(projectID), snapshotID...))
+		if data == nil {
+			return fmt.Errorf("snapshot not found: %s", snapshotID)
+		}
+		return json.Unmarshal(data, &snapshot)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// ListSnapshots 获取项目的所有快照，按创建时间升序排列
+func (s *BoltStorage) ListSnapshots(projectID string) ([]*types.ProjectSnapshot, error) {
+	snapshots := []*types.ProjectSnapshot{}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		prefix := snapshotPrefix(projectID)
+		c := tx.Bucket(snapshotBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var snapshot types.ProjectSnapshot
+			if err := json.Unmarshal(v, &snapshot); err != nil {
+				return err
+			}
+			snapshots = append(snapshots, &snapshot)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt < snapshots[j].CreatedAt
+	})
+	return snapshots, nil
+}
+
+// DeleteSnapshot 删除项目快照
+func (s *BoltStorage) DeleteSnapshot(projectID, snapshotID string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(snapshotBucket).Delete(append(snapshotPrefix(projectID), snapshotID...))
+	})
+}
+
+// SaveGuidelineProfile 保存自定义字幕规范
+func (s *BoltStorage) SaveGuidelineProfile(profile *types.GuidelineProfile) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(guidelineBucket)
+
+		profile.UpdatedAt = time.Now().Unix()
+		encoded, err := json.Marshal(profile)
+		if err != nil {
+			return fmt.Errorf("failed to marshal guideline profile %s: %w", profile.ID, err)
+		}
+
+		return b.Put([]byte(profile.ID), encoded)
+	})
+}
+
+// GetGuidelineProfile 根据ID获取自定义字幕规范
+func (s *BoltStorage) GetGuidelineProfile(id types.GuideLineStandard) (*types.GuidelineProfile, error) {
+	var profile types.GuidelineProfile
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(guidelineBucket).Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("guideline profile not found: %s", id)
+		}
+		return json.Unmarshal(data, &profile)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// ListGuidelineProfiles 获取所有自定义字幕规范，按创建时间升序排列
+func (s *BoltStorage) ListGuidelineProfiles() ([]*types.GuidelineProfile, error) {
+	profiles := []*types.GuidelineProfile{}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(guidelineBucket).ForEach(func(k, v []byte) error {
+			var profile types.GuidelineProfile
+			if err := json.Unmarshal(v, &profile); err != nil {
+				return err
+			}
+			profiles = append(profiles, &profile)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].CreatedAt < profiles[j].CreatedAt
+	})
+	return profiles, nil
+}
+
+// DeleteGuidelineProfile 删除自定义字幕规范
+func (s *BoltStorage) DeleteGuidelineProfile(id types.GuideLineStandard) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(guidelineBucket).Delete([]byte(id))
+	})
+}
+
+// SaveQCRuleSet 保存质检规则集
+func (s *BoltStorage) SaveQCRuleSet(set *types.QCRuleSet) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(qcRuleBucket)
+
+		set.UpdatedAt = time.Now().Unix()
+		encoded, err := json.Marshal(set)
+		if err != nil {
+			return fmt.Errorf("failed to marshal qc rule set %s: %w", set.ID, err)
+		}
+
+		return b.Put([]byte(set.ID), encoded)
+	})
+}
+
+// GetQCRuleSet 根据ID获取质检规则集
+func (s *BoltStorage) GetQCRuleSet(id string) (*types.QCRuleSet, error) {
+	var set types.QCRuleSet
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(qcRuleBucket).Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("qc rule set not found: %s", id)
+		}
+		return json.Unmarshal(data, &set)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &set, nil
+}
+
+// ListQCRuleSets 获取所有质检规则集，按创建时间升序排列
+func (s *BoltStorage) ListQCRuleSets() ([]*types.QCRuleSet, error) {
+	sets := []*types.QCRuleSet{}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(qcRuleBucket).ForEach(func(k, v []byte) error {
+			var set types.QCRuleSet
+			if err := json.Unmarshal(v, &set); err != nil {
+				return err
+			}
+			sets = append(sets, &set)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].CreatedAt < sets[j].CreatedAt
+	})
+	return sets, nil
+}
+
+// DeleteQCRuleSet 删除质检规则集
+func (s *BoltStorage) DeleteQCRuleSet(id string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(qcRuleBucket).Delete([]byte(id))
+	})
+}
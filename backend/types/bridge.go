@@ -0,0 +1,51 @@
+package types
+
+// DtBridgeBrowser 桥接传入的 Cookies 保存在该虚拟浏览器名下
+const DtBridgeBrowser = "bridge"
+
+// DtBridgePreset 浏览器桥接的下载预设
+type DtBridgePreset struct {
+	Name string `json:"name" yaml:"name"`
+	// Type 创建的任务类型：quick | custom
+	Type string `json:"type" yaml:"type"`
+	// Format 格式预设：best | 2160p | 1440p | 1080p | 720p | 480p | 360p | audio | yt-dlp 格式表达式
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// 字幕（quick 仅使用 DownloadSubs，下载最佳字幕）
+	DownloadSubs bool     `json:"downloadSubs,omitempty" yaml:"download_subs,omitempty"`
+	SubLangs     []string `json:"subLangs,omitempty" yaml:"sub_langs,omitempty"`
+	SubFormat    string   `json:"subFormat,omitempty" yaml:"sub_format,omitempty"`
+	TranslateTo  string   `json:"translateTo,omitempty" yaml:"translate_to,omitempty"`
+
+	RecodeFormatNumber int `json:"recodeFormatNumber,omitempty" yaml:"recode_format_number,omitempty"`
+}
+
+// DefaultBridgePresets 未配置预设时使用的内置预设
+func DefaultBridgePresets() []DtBridgePreset {
+	return []DtBridgePreset{
+		{Name: "quick", Type: "quick", Format: "best"},
+		{Name: "audio", Type: "quick", Format: "audio"},
+		{Name: "1080p", Type: "quick", Format: "1080p"},
+		{Name: "subtitles", Type: "custom", Format: "best", DownloadSubs: true, SubFormat: "srt"},
+	}
+}
+
+// DtBridgeRequest 浏览器端发送的页面信息
+type DtBridgeRequest struct {
+	URL string `json:"url"`
+	// Cookies 该页面域名下的 Netscape 格式 Cookies（可选）
+	Cookies string `json:"cookies,omitempty"`
+	// Preset 预设名称，为空时使用第一个预设
+	Preset string `json:"preset,omitempty"`
+}
+
+type DtBridgeResponse struct {
+	ID     string      `json:"id"`
+	Type   string      `json:"type"`
+	Preset string      `json:"preset"`
+	Status DtTaskStage `json:"status"`
+	// 保存的 Cookies 数量
+	Cookies     int    `json:"cookies"`
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+	Skipped     bool   `json:"skipped,omitempty"`
+}
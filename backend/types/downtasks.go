@@ -26,6 +26,9 @@ type DtDownloadRequest struct {
 type DtDownloadResponse struct {
 	ID     string      `json:"id"`
 	Status DtTaskStage `json:"status"`
+	// 命中已完成的重复任务时为该任务ID；Skipped 表示按设置跳过了下载，ID 即为已有任务
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+	Skipped     bool   `json:"skipped,omitempty"`
 }
 
 type DtQuickDownloadRequest struct {
@@ -41,6 +44,9 @@ type DtQuickDownloadRequest struct {
 type DtQuickDownloadResponse struct {
 	ID     string      `json:"id"`
 	Status DtTaskStage `json:"status"`
+	// 同 DtDownloadResponse
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+	Skipped     bool   `json:"skipped,omitempty"`
 }
 
 type DownloadVideoRequest struct {
@@ -61,6 +67,8 @@ type DownloadVideoRequest struct {
 	// translate options
 	TranslateTo   string `json:"translateTo"`
 	SubtitleStyle string `json:"subtitleStyle"`
+	// 任务级代理（来自下载规则）：为空沿用全局设置，direct 表示直连
+	Proxy string `json:"proxy,omitempty"`
 }
 
 // DtTaskStage 定义处理阶段
@@ -74,6 +82,8 @@ const (
 	DtStageCompleted    DtTaskStage = "completed"    // 处理完成
 	DtStageFailed       DtTaskStage = "failed"       // 处理失败
 	DtStageCancelled    DtTaskStage = "cancelled"    // 处理取消
+	DtStageQueued       DtTaskStage = "queued"       // 等待资源（如磁盘空间）
+	DtStagePaused       DtTaskStage = "paused"       // 已暂停，可恢复
 	DtStageInstalling   DtTaskStage = "installing"   // 安装阶段
 	DtStageInstalled    DtTaskStage = "installed"    // 安装完成
 	DtStageUpdating     DtTaskStage = "updating"     // 更新阶段
@@ -99,9 +109,10 @@ type DtProgress struct {
 	Type string `json:"type"`
 
 	// 状态
-	Stage     DtTaskStage `json:"stage"`               // 当前处理阶段
-	StageInfo string      `json:"stageInfo,omitempty"` // 当前阶段的额外信息
-	Error     string      `json:"error,omitempty"`     // 错误信息
+	Stage     DtTaskStage  `json:"stage"`               // 当前处理阶段
+	StageInfo string       `json:"stageInfo,omitempty"` // 当前阶段的额外信息
+	Error     string       `json:"error,omitempty"`     // 错误信息
+	ErrorInfo *DtTaskError `json:"errorInfo,omitempty"` // 结构化错误（编码/说明/建议）
 
 	// 进度信息
 	Percentage    float64 `json:"percentage"`              // 当前阶段的进度百分比
@@ -109,6 +120,11 @@ type DtProgress struct {
 	Downloaded    string  `json:"downloaded,omitempty"`    // 已下载大小（仅下载阶段有效）
 	TotalSize     string  `json:"totalSize,omitempty"`     // 总大小（仅下载阶段有效）
 	EstimatedTime string  `json:"estimatedTime,omitempty"` // 预计剩余时间
+
+	// 加权总进度
+	SubStage string            `json:"subStage,omitempty"` // 上报进度的子阶段（video/audio/merge/...）
+	Overall  float64           `json:"overall"`            // 所有子阶段的加权总进度
+	Stages   []DtStageProgress `json:"stages,omitempty"`   // 各子阶段进度
 }
 
 // DtTaskStatus 用于在数据库中存储任务状态
@@ -131,9 +147,10 @@ type DtTaskStatus struct {
 	RecodeExtention    string `json:"recodeExtention"`
 
 	// 状态
-	Stage     DtTaskStage `json:"stage"`               // 当前处理阶段
-	StageInfo string      `json:"stageInfo,omitempty"` // 当前阶段的额外信息
-	Error     string      `json:"error,omitempty"`     // 错误信息
+	Stage     DtTaskStage  `json:"stage"`               // 当前处理阶段
+	StageInfo string       `json:"stageInfo,omitempty"` // 当前阶段的额外信息
+	Error     string       `json:"error,omitempty"`     // 错误信息
+	ErrorInfo *DtTaskError `json:"errorInfo,omitempty"` // 结构化错误（编码/说明/建议）
 
 	// 文件存储
 	OutputDir          string   `json:"outputDir,omitempty"`          // 输出目录
@@ -149,9 +166,12 @@ type DtTaskStatus struct {
 	Title      string  `json:"title,omitempty"`      // 视频标题
 	Thumbnail  string  `json:"thumbnail,omitempty"`  // 缩略图URL
 	URL        string  `json:"url,omitempty"`        // 原始视频URL
+	CanonicalURL string `json:"canonicalUrl,omitempty"` // 规范化后的URL（用于重复检测与搜索）
 	FormatID   string  `json:"formatId,omitempty"`   // 视频质量
 	Resolution string  `json:"resolution,omitempty"` // 视频分辨率
 	Uploader   string  `json:"uploader,omitempty"`   // 作者/频道名
+	VideoID    string  `json:"videoId,omitempty"`    // 站点内的视频ID
+	UploadDate string  `json:"uploadDate,omitempty"` // 上传日期 YYYYMMDD
 	Duration   float64 `json:"duration,omitempty"`   // 视频时长（秒`)
 	FileSize   int64   `json:"fileSize,omitempty"`   // 文件大小（字节）
 	Format     string  `json:"format,omitempty"`     // 视频格式
@@ -162,6 +182,10 @@ type DtTaskStatus struct {
 	Speed         string  `json:"speed,omitempty"`         // 下载速度
 	EstimatedTime string  `json:"estimatedTime,omitempty"` // 预计剩余时间
 
+	// 加权总进度与各子阶段进度
+	OverallPercentage float64           `json:"overallPercentage"`
+	StageProgress     []DtStageProgress `json:"stageProgress,omitempty"`
+
     // 时间戳
     CreatedAt int64 `json:"createdAt"`
     UpdatedAt int64 `json:"updatedAt"`
@@ -170,6 +194,20 @@ type DtTaskStatus struct {
     DownloadProcess DownloadProcess `json:"downloadProcess,omitempty"`
     SubtitleProcess SubtitleProcess `json:"subtitleProcess,omitempty"`
     TranscodeProcess TranscodeProcess `json:"transcodeProcess,omitempty"`
+
+    // 原始下载请求，用于暂停后恢复
+    DownloadRequest *DownloadVideoRequest `json:"downloadRequest,omitempty"`
+
+    // 输出文件的媒体信息与校验结果（ffprobe）
+    MediaInfo    *DtMediaInfo    `json:"mediaInfo,omitempty"`
+    Verification *DtVerification `json:"verification,omitempty"`
+
+    // 下载统计（字节数、耗时、平均/峰值速度）
+    Stats *DtDownloadStats `json:"stats,omitempty"`
+
+    // 内容去重：主媒体文件的 SHA-256 与命中的已有任务
+    ContentHash string `json:"contentHash,omitempty"`
+    DuplicateOf string `json:"duplicateOf,omitempty"`
 }
 
 // DownloadProcess 持久化下载阶段状态
@@ -228,6 +266,9 @@ func (t *DtTaskStatus) UpdateFromProgress(progress *DtProgress) {
 	if progress.Error != "" {
 		t.Error = progress.Error
 	}
+	if progress.ErrorInfo != nil {
+		t.ErrorInfo = progress.ErrorInfo
+	}
 }
 
 type FillTaskInfo struct {
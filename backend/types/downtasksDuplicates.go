@@ -0,0 +1,59 @@
+package types
+
+// DtDuplicateKind 重复的判定依据
+type DtDuplicateKind string
+
+const (
+	DtDuplicateByVideoID DtDuplicateKind = "video_id" // 相同站点 + 视频ID
+	DtDuplicateByHash    DtDuplicateKind = "hash"     // 相同文件内容（SHA-256）
+)
+
+// DtDuplicateAction 处理重复文件的方式
+type DtDuplicateAction string
+
+const (
+	DtDuplicateHardLink DtDuplicateAction = "hardlink" // 以硬链接替换重复文件（仅限内容相同）
+	DtDuplicateDelete   DtDuplicateAction = "delete"   // 删除重复任务
+)
+
+// DtDuplicateEntry 重复组中的单个任务
+type DtDuplicateEntry struct {
+	TaskID    string `json:"taskId"`
+	Title     string `json:"title,omitempty"`
+	URL       string `json:"url,omitempty"`
+	File      string `json:"file,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+	Hash      string `json:"hash,omitempty"`
+	Linked    bool   `json:"linked,omitempty"` // 文件已与组内第一个任务的文件硬链接
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// DtDuplicateGroup 一组互为重复的已完成任务（按创建时间升序，第一个为最早的任务）
+type DtDuplicateGroup struct {
+	Kind             DtDuplicateKind    `json:"kind"`
+	Key              string             `json:"key"`
+	Tasks            []DtDuplicateEntry `json:"tasks"`
+	ReclaimableBytes int64              `json:"reclaimableBytes"`
+}
+
+// DtDuplicateReport 重复任务报告
+type DtDuplicateReport struct {
+	Groups           []DtDuplicateGroup `json:"groups"`
+	ReclaimableBytes int64              `json:"reclaimableBytes"`
+}
+
+// DtDuplicateResolveRequest 处理重复任务：保留 KeepTaskID，对 TaskIDs 执行 Action
+type DtDuplicateResolveRequest struct {
+	KeepTaskID string            `json:"keepTaskId"`
+	TaskIDs    []string          `json:"taskIds"`
+	Action     DtDuplicateAction `json:"action"`
+	// Action 为 delete 时的删除方式，默认移入回收站
+	DeleteMode DtDeleteMode `json:"deleteMode,omitempty"`
+}
+
+// DtDuplicateResolveResult 处理结果
+type DtDuplicateResolveResult struct {
+	Resolved       []string          `json:"resolved"`
+	Failed         map[string]string `json:"failed,omitempty"` // taskID -> error
+	ReclaimedBytes int64             `json:"reclaimedBytes"`
+}
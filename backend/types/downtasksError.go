@@ -0,0 +1,25 @@
+package types
+
+// DtErrorCode 任务失败原因的稳定编码（前端/MCP 可据此展示或处理）
+type DtErrorCode string
+
+const (
+	DtErrGeoRestricted  DtErrorCode = "geo_restricted"  // 地区限制
+	DtErrLoginRequired  DtErrorCode = "login_required"  // 需要登录/Cookies
+	DtErrAgeRestricted  DtErrorCode = "age_restricted"  // 年龄限制
+	DtErrUnavailable    DtErrorCode = "unavailable"     // 私有或已删除
+	DtErrRateLimited    DtErrorCode = "rate_limited"    // 请求过于频繁
+	DtErrUnsupportedURL DtErrorCode = "unsupported_url" // 不支持的链接
+	DtErrFFmpegMissing  DtErrorCode = "ffmpeg_missing"  // 缺少 FFmpeg
+	DtErrNetwork        DtErrorCode = "network"         // 网络错误
+	DtErrCorruptOutput  DtErrorCode = "corrupt_output"  // 输出文件校验失败
+	DtErrUnknown        DtErrorCode = "unknown"         // 未识别
+)
+
+// DtTaskError 结构化的任务错误
+type DtTaskError struct {
+	Code    DtErrorCode `json:"code"`
+	Message string      `json:"message"`          // 本地化的说明
+	Action  string      `json:"action,omitempty"` // 建议的处理方式
+	Detail  string      `json:"detail,omitempty"` // 原始错误中的关键行（已截断）
+}
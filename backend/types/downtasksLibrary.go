@@ -0,0 +1,8 @@
+package types
+
+// DtOrganizeResult 批量整理媒体库的结果
+type DtOrganizeResult struct {
+	Organized []string          `json:"organized"`
+	Skipped   map[string]string `json:"skipped,omitempty"` // taskID -> reason
+	Failed    map[string]string `json:"failed,omitempty"`  // taskID -> error
+}
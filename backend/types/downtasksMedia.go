@@ -0,0 +1,37 @@
+package types
+
+// DtMediaInfo 由 ffprobe 读取的输出文件媒体信息
+type DtMediaInfo struct {
+	Container       string  `json:"container,omitempty"`
+	Duration        float64 `json:"duration,omitempty"` // 秒
+	Size            int64   `json:"size,omitempty"`     // 字节
+	Bitrate         int64   `json:"bitrate,omitempty"`  // 总码率 bit/s
+	VideoCodec      string  `json:"videoCodec,omitempty"`
+	VideoBitrate    int64   `json:"videoBitrate,omitempty"`
+	Width           int     `json:"width,omitempty"`
+	Height          int     `json:"height,omitempty"`
+	FPS             float64 `json:"fps,omitempty"`
+	AudioCodec      string  `json:"audioCodec,omitempty"`
+	AudioBitrate    int64   `json:"audioBitrate,omitempty"`
+	VideoStreams    int     `json:"videoStreams"`
+	AudioStreams    int     `json:"audioStreams"`
+	SubtitleStreams int     `json:"subtitleStreams"`
+}
+
+// DtVerifyStatus 输出文件校验结果
+type DtVerifyStatus string
+
+const (
+	DtVerifyOK      DtVerifyStatus = "ok"      // 校验通过
+	DtVerifyWarning DtVerifyStatus = "warning" // 可疑（如缺少音频流）
+	DtVerifyCorrupt DtVerifyStatus = "corrupt" // 无法解析、无可用流或时长明显不符
+	DtVerifySkipped DtVerifyStatus = "skipped" // 未找到 ffprobe 或无可校验文件
+)
+
+// DtVerification 输出文件校验记录
+type DtVerification struct {
+	Status    DtVerifyStatus `json:"status"`
+	File      string         `json:"file,omitempty"`
+	Issues    []string       `json:"issues,omitempty"`
+	CheckedAt int64          `json:"checkedAt"`
+}
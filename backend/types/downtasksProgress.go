@@ -0,0 +1,19 @@
+package types
+
+// 任务内的子阶段（用于计算加权总进度）
+const (
+	DtSubStageVideo     = "video"     // 视频流下载
+	DtSubStageAudio     = "audio"     // 音频流下载（分离格式）
+	DtSubStageMerge     = "merge"     // 音视频合并
+	DtSubStageTranscode = "transcode" // 重编码
+	DtSubStageSubtitles = "subtitles" // 字幕下载
+	DtSubStageTranslate = "translate" // 字幕翻译
+	DtSubStageEmbed     = "embed"     // 字幕嵌入
+)
+
+// DtStageProgress 单个子阶段的权重与进度
+type DtStageProgress struct {
+	Name       string  `json:"name"`
+	Weight     float64 `json:"weight"`
+	Percentage float64 `json:"percentage"`
+}
@@ -0,0 +1,25 @@
+package types
+
+// DtProxyDirect 规则中表示不使用代理（直连）
+const DtProxyDirect = "direct"
+
+// DtDownloadRule 按域名匹配的下载默认值，仅在请求未指定对应字段时生效
+type DtDownloadRule struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// 域名模式：example.com 匹配自身及所有子域名，*.example.com 仅匹配子域名
+	Domains []string `json:"domains"`
+	// 多条规则命中时优先级高者生效，相同时取更具体的域名
+	Priority int `json:"priority,omitempty"`
+
+	// 代理：为空表示沿用全局设置，direct 表示直连，其他为代理地址（覆盖全局代理）
+	Proxy        string   `json:"proxy,omitempty"`
+	Browser      string   `json:"browser,omitempty"`      // Cookies 来源浏览器
+	OutputDir    string   `json:"outputDir,omitempty"`    // 输出目录
+	FormatPreset string   `json:"formatPreset,omitempty"` // best | 2160p | 1440p | 1080p | 720p | 480p | 360p | audio | yt-dlp 格式表达式
+	SubLangs     []string `json:"subLangs,omitempty"`     // 字幕语言
+
+	CreatedAt int64 `json:"createdAt"`
+	UpdatedAt int64 `json:"updatedAt"`
+}
@@ -0,0 +1,51 @@
+package types
+
+// DtDownloadStats 单个任务的下载统计（来自下载过程中的速度平滑器）
+type DtDownloadStats struct {
+	StartedAt  int64   `json:"startedAt"`  // unix 秒
+	FinishedAt int64   `json:"finishedAt"` // unix 秒
+	Bytes      int64   `json:"bytes"`
+	AvgBps     float64 `json:"avgBps"`
+	PeakBps    float64 `json:"peakBps"`
+}
+
+// DtThroughputSample 下载过程中按固定间隔采样的速度
+type DtThroughputSample struct {
+	Time   int64   `json:"time"` // unix 秒
+	TaskID string  `json:"taskId"`
+	Bps    float64 `json:"bps"`
+}
+
+// DtStatsQuery 统计查询条件
+type DtStatsQuery struct {
+	From   int64  `json:"from,omitempty"`   // unix 秒，0 表示默认（最近 30 天）
+	To     int64  `json:"to,omitempty"`     // unix 秒，0 表示当前
+	Period string `json:"period,omitempty"` // day | week
+}
+
+// DtStatsBucket 一组任务的聚合结果
+type DtStatsBucket struct {
+	Key         string  `json:"key"`
+	Tasks       int     `json:"tasks"`
+	Completed   int     `json:"completed"`
+	Failed      int     `json:"failed"`
+	Cancelled   int     `json:"cancelled"`
+	Bytes       int64   `json:"bytes"`
+	MediaSec    float64 `json:"mediaSec"`    // 已完成任务的媒体总时长
+	DownloadSec float64 `json:"downloadSec"` // 下载耗时总和
+	AvgBps      float64 `json:"avgBps"`      // 有下载统计的任务的字节数 / DownloadSec
+	SuccessRate float64 `json:"successRate"` // completed / (completed + failed)
+}
+
+// DtStatistics 下载统计
+type DtStatistics struct {
+	From         int64                `json:"from"`
+	To           int64                `json:"to"`
+	Period       string               `json:"period"`
+	Total        DtStatsBucket        `json:"total"`
+	ByPeriod     []DtStatsBucket      `json:"byPeriod"`
+	ByExtractor  []DtStatsBucket      `json:"byExtractor"`
+	ByType       []DtStatsBucket      `json:"byType"`
+	ByErrorClass []DtStatsBucket      `json:"byErrorClass"`
+	Throughput   []DtThroughputSample `json:"throughput,omitempty"`
+}
@@ -0,0 +1,47 @@
+package types
+
+// DtDeleteMode 定义删除任务时对文件的处理方式
+type DtDeleteMode string
+
+const (
+	DtDeleteRecordOnly DtDeleteMode = "record" // 仅删除任务记录，保留文件
+	DtDeleteWithFiles  DtDeleteMode = "files"  // 删除任务记录与所有产生的文件
+	DtDeleteToTrash    DtDeleteMode = "trash"  // 删除任务记录，文件移入应用回收站
+)
+
+// DtTaskFilter 用于批量选择任务（所有条件为 AND 关系，空值表示不限制）
+type DtTaskFilter struct {
+	IDs           []string      `json:"ids,omitempty"`
+	Stages        []DtTaskStage `json:"stages,omitempty"`
+	Types         []string      `json:"types,omitempty"`     // custom, quick, mcp
+	Extractor     string        `json:"extractor,omitempty"` // 精确匹配（忽略大小写）
+	Keyword       string        `json:"keyword,omitempty"`   // 匹配标题/URL/上传者
+	CreatedBefore int64         `json:"createdBefore,omitempty"`
+	CreatedAfter  int64         `json:"createdAfter,omitempty"`
+}
+
+// DtBulkDeleteResult 批量删除结果
+type DtBulkDeleteResult struct {
+	Deleted []string          `json:"deleted"`
+	Failed  map[string]string `json:"failed,omitempty"` // taskID -> error
+}
+
+// DtTrashFile 回收站中的单个文件
+type DtTrashFile struct {
+	OriginalPath string `json:"originalPath"`
+	TrashPath    string `json:"trashPath"`
+	Size         int64  `json:"size"`
+}
+
+// DtTrashItem 回收站条目，保存被删除任务的快照以便恢复
+type DtTrashItem struct {
+	ID        string        `json:"id"`
+	TaskID    string        `json:"taskId"`
+	Title     string        `json:"title,omitempty"`
+	URL       string        `json:"url,omitempty"`
+	Thumbnail string        `json:"thumbnail,omitempty"`
+	Files     []DtTrashFile `json:"files"`
+	TotalSize int64         `json:"totalSize"`
+	DeletedAt int64         `json:"deletedAt"`
+	Task      *DtTaskStatus `json:"task"`
+}
@@ -0,0 +1,58 @@
+package types
+
+// HookAction 定义钩子的执行方式
+type HookAction string
+
+const (
+	HookActionCommand HookAction = "command" // 执行本地命令
+	HookActionWebhook HookAction = "webhook" // POST 到 Webhook URL
+)
+
+// TaskHook 在任务阶段变更（完成/失败/取消）时触发的钩子
+type TaskHook struct {
+	ID      string     `json:"id"`
+	Name    string     `json:"name"`
+	Enabled bool       `json:"enabled"`
+	Action  HookAction `json:"action"`
+	// 触发阶段：completed | failed | cancelled；为空表示全部
+	Stages []DtTaskStage `json:"stages,omitempty"`
+
+	// 过滤条件（为空表示不限制）
+	TaskTypes  []string `json:"taskTypes,omitempty"`  // custom, quick, mcp
+	Extractors []string `json:"extractors,omitempty"` // 例如 youtube, BiliBili（忽略大小写）
+
+	// command：直接执行（不经过 shell），任务字段通过 CANME_* 环境变量暴露，stdin 为任务 JSON
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	WorkDir string   `json:"workDir,omitempty"`
+
+	// webhook：POST DtTaskStatus JSON，Secret 非空时附带 "时间戳.请求体" 的 HMAC-SHA256 签名
+	URL     string            `json:"url,omitempty"`
+	Secret  string            `json:"secret,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// 失败后的重试次数，未设置时为 3，0 表示不重试
+	MaxRetries *int `json:"maxRetries,omitempty"`
+
+	TimeoutSec int   `json:"timeoutSec,omitempty"`
+	CreatedAt  int64 `json:"createdAt"`
+	UpdatedAt  int64 `json:"updatedAt"`
+}
+
+// HookExecution 钩子执行记录
+type HookExecution struct {
+	ID         string      `json:"id"`
+	HookID     string      `json:"hookId"`
+	HookName   string      `json:"hookName"`
+	Action     HookAction  `json:"action"`
+	TaskID     string      `json:"taskId"`
+	TaskTitle  string      `json:"taskTitle,omitempty"`
+	Stage      DtTaskStage `json:"stage"`
+	Success    bool        `json:"success"`
+	Attempts   int         `json:"attempts"`
+	StatusCode int         `json:"statusCode,omitempty"` // webhook 响应码
+	ExitCode   int         `json:"exitCode,omitempty"`   // command 退出码
+	Output     string      `json:"output,omitempty"`     // 截断的输出/响应体
+	Error      string      `json:"error,omitempty"`
+	StartedAt  int64       `json:"startedAt"`
+	FinishedAt int64       `json:"finishedAt"`
+}
@@ -8,12 +8,21 @@ import (
 )
 
 type Preferences struct {
-	Behavior    PreferencesBehavior `json:"behavior" yaml:"behavior"`
-	General     PreferencesGeneral  `json:"general" yaml:"general"`
-	Proxy       proxy.Config        `json:"proxy" yaml:"proxy"`
-	Download    downinfo.Config     `json:"download" yaml:"download"`
-	Logger      logger.Config       `json:"logger" yaml:"logger"`
-	ListendInfo ListendInfo         `json:"listendInfo" yaml:"listend_info"`
+	Behavior    PreferencesBehavior    `json:"behavior" yaml:"behavior"`
+	General     PreferencesGeneral     `json:"general" yaml:"general"`
+	Proxy       proxy.Config           `json:"proxy" yaml:"proxy"`
+	Download    downinfo.Config        `json:"download" yaml:"download"`
+	Logger      logger.Config          `json:"logger" yaml:"logger"`
+	ListendInfo ListendInfo            `json:"listendInfo" yaml:"listend_info"`
+	Trash       PreferencesTrash       `json:"trash" yaml:"trash"`
+	Disk        PreferencesDisk        `json:"disk" yaml:"disk"`
+	Library     PreferencesLibrary     `json:"library" yaml:"library"`
+	Verify      PreferencesVerify      `json:"verify" yaml:"verify"`
+	URL         PreferencesURL         `json:"url" yaml:"url"`
+	Duplicates  PreferencesDuplicates  `json:"duplicates" yaml:"duplicates"`
+	LocalAPI    PreferencesLocalAPI    `json:"localApi" yaml:"local_api"`
+	Bridge      PreferencesBridge      `json:"bridge" yaml:"bridge"`
+	Translation PreferencesTranslation `json:"translation" yaml:"translation"`
 }
 
 func NewPreferences() Preferences {
@@ -37,6 +46,17 @@ func NewPreferences() Preferences {
 		},
 		Logger:      *logger.DefaultConfig(),
 		ListendInfo: DefaultListendInfo(),
+		Trash: PreferencesTrash{
+			AutoPurgeDays: 30,
+		},
+		Disk:       DefaultPreferencesDisk(),
+		Library:    DefaultPreferencesLibrary(),
+		Verify:     DefaultPreferencesVerify(),
+		URL:        DefaultPreferencesURL(),
+		Duplicates: DefaultPreferencesDuplicates(),
+		LocalAPI:   DefaultPreferencesLocalAPI(),
+		Bridge:      DefaultPreferencesBridge(),
+		Translation: DefaultPreferencesTranslation(),
 	}
 }
 
@@ -61,6 +81,171 @@ type PreferencesGeneral struct {
     SkipVersion string `json:"skipVersion" yaml:"skip_version,omitempty"`
 }
 
+// PreferencesTrash 回收站设置
+type PreferencesTrash struct {
+	// AutoPurgeDays 回收站条目保留天数，超过后自动彻底删除；0 表示不自动清理
+	AutoPurgeDays int `json:"autoPurgeDays" yaml:"auto_purge_days"`
+}
+
+// PreferencesDisk 磁盘空间保护设置
+type PreferencesDisk struct {
+	// MinFreeMB 下载目录所在卷需保留的最小可用空间（MB），低于该值时暂停下载
+	MinFreeMB int `json:"minFreeMB" yaml:"min_free_mb"`
+	// HeadroomFactor 需要合并或转码时，对预估大小乘以的余量系数
+	HeadroomFactor float64 `json:"headroomFactor" yaml:"headroom_factor"`
+	// OnInsufficient 空间不足时的处理方式：refuse（直接失败）| queue（等待空间释放）
+	OnInsufficient string `json:"onInsufficient" yaml:"on_insufficient"`
+}
+
+func DefaultPreferencesDisk() PreferencesDisk {
+	return PreferencesDisk{
+		MinFreeMB:      1024,
+		HeadroomFactor: 2.0,
+		OnInsufficient: "refuse",
+	}
+}
+
+// PreferencesLibrary 媒体库整理设置
+type PreferencesLibrary struct {
+	// Enabled 任务完成后自动按模板整理文件
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Root 媒体库根目录，为空时使用下载目录
+	Root string `json:"root" yaml:"root,omitempty"`
+	// Template 路径模板，"/" 分隔目录，最后一段为文件名（保留扩展名与字幕语言后缀），例如 {uploader}/{upload_year}/{title} [{id}]
+	Template string `json:"template" yaml:"template"`
+	// OnConflict 目标文件已存在时的处理方式：rename（追加序号）| skip（保留原位置）
+	OnConflict string `json:"onConflict" yaml:"on_conflict"`
+}
+
+func DefaultPreferencesLibrary() PreferencesLibrary {
+	return PreferencesLibrary{
+		Enabled:    false,
+		Template:   "{uploader}/{upload_year}/{title} [{id}]",
+		OnConflict: "rename",
+	}
+}
+
+// PreferencesVerify 下载完成后的媒体校验设置
+type PreferencesVerify struct {
+	// Enabled 下载完成后使用 ffprobe 校验输出文件
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// OnCorrupt 文件损坏时的处理方式：flag（仅标记）| fail（任务失败）
+	OnCorrupt string `json:"onCorrupt" yaml:"on_corrupt"`
+	// DurationTolerance 实际时长与元数据时长允许的相对误差（0.05 表示 5%）
+	DurationTolerance float64 `json:"durationTolerance" yaml:"duration_tolerance"`
+}
+
+func DefaultPreferencesVerify() PreferencesVerify {
+	return PreferencesVerify{
+		Enabled:           true,
+		OnCorrupt:         "flag",
+		DurationTolerance: 0.05,
+	}
+}
+
+// PreferencesURL 链接规范化设置
+type PreferencesURL struct {
+	// ResolveShortLinks 联网展开已知短链接（如 b23.tv），仅请求短链接域名本身
+	ResolveShortLinks bool `json:"resolveShortLinks" yaml:"resolve_short_links"`
+	// ResolveTimeoutSec 展开短链接的超时时间（秒）
+	ResolveTimeoutSec int `json:"resolveTimeoutSec" yaml:"resolve_timeout_sec"`
+}
+
+func DefaultPreferencesURL() PreferencesURL {
+	return PreferencesURL{
+		ResolveShortLinks: false,
+		ResolveTimeoutSec: 5,
+	}
+}
+
+// PreferencesDuplicates 内容去重设置
+type PreferencesDuplicates struct {
+	// OnDuplicate 新请求与已完成任务为同一视频时的处理方式：warn（仍下载并标记）| skip（不下载）| off
+	OnDuplicate string `json:"onDuplicate" yaml:"on_duplicate"`
+	// HashFiles 下载完成后在后台比对主媒体文件，大小与开头内容和已完成任务相同时计算 SHA-256，用于发现不同链接的相同内容
+	HashFiles bool `json:"hashFiles" yaml:"hash_files"`
+}
+
+func DefaultPreferencesDuplicates() PreferencesDuplicates {
+	return PreferencesDuplicates{
+		OnDuplicate: "warn",
+		HashFiles:   true,
+	}
+}
+
+// PreferencesLocalAPI 本机 HTTP/JSON 接口设置（仅监听 127.0.0.1）
+type PreferencesLocalAPI struct {
+	// Enabled 是否启动本机接口
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Port 监听端口
+	Port int `json:"port" yaml:"port"`
+	// Token 访问令牌（Authorization: Bearer <token>），为空时启动接口前自动生成
+	Token string `json:"token" yaml:"token,omitempty"`
+}
+
+func DefaultPreferencesLocalAPI() PreferencesLocalAPI {
+	return PreferencesLocalAPI{
+		Enabled: false,
+		Port:    consts.LOCAL_API_PORT,
+	}
+}
+
+// PreferencesBridge 浏览器“发送到 CanMe”桥接设置，与本机接口共用端口，可单独启用
+type PreferencesBridge struct {
+	// Enabled 是否提供桥接接口；未启用本机接口时也会在 local_api.port 上监听，但只响应桥接请求
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Token 配对令牌，仅能调用桥接接口，为空时启动接口前自动生成
+	Token string `json:"token" yaml:"token,omitempty"`
+	// Presets 可供浏览器端选择的下载预设，为空时使用内置预设
+	Presets []DtBridgePreset `json:"presets" yaml:"presets,omitempty"`
+}
+
+func DefaultPreferencesBridge() PreferencesBridge {
+	return PreferencesBridge{}
+}
+
+// PreferencesTranslation 字幕机器翻译设置
+type PreferencesTranslation struct {
+	// Provider 默认翻译提供商（openai）
+	Provider string `json:"provider" yaml:"provider"`
+	// OpenAI 兼容接口（OpenAI、本地 Ollama/LM Studio 等）
+	OpenAI TranslationOpenAIConfig `json:"openai" yaml:"openai"`
+	// BatchSize 每次请求翻译的字幕条数
+	BatchSize int `json:"batchSize" yaml:"batch_size"`
+	// ContextSize 每批前后附带的参考字幕条数（不翻译）
+	ContextSize int `json:"contextSize" yaml:"context_size"`
+	// MaxRetries 单次请求失败后的重试次数
+	MaxRetries int `json:"maxRetries" yaml:"max_retries"`
+	// TMThreshold 翻译记忆模糊匹配的最低相似度（0-1），达到时直接预填译文
+	TMThreshold float64 `json:"tmThreshold" yaml:"tm_threshold"`
+}
+
+// TranslationOpenAIConfig OpenAI 兼容 chat/completions 接口设置
+type TranslationOpenAIConfig struct {
+	BaseURL     string  `json:"baseUrl" yaml:"base_url"`
+	APIKey      string  `json:"apiKey" yaml:"api_key,omitempty"`
+	Model       string  `json:"model" yaml:"model"`
+	Temperature float64 `json:"temperature" yaml:"temperature"`
+	// TimeoutSeconds 单次请求超时（秒）
+	TimeoutSeconds int `json:"timeoutSeconds" yaml:"timeout_seconds"`
+}
+
+func DefaultPreferencesTranslation() PreferencesTranslation {
+	return PreferencesTranslation{
+		Provider: "openai",
+		OpenAI: TranslationOpenAIConfig{
+			BaseURL:        "https://api.openai.com/v1",
+			Model:          "gpt-4o-mini",
+			Temperature:    0.2,
+			TimeoutSeconds: 120,
+		},
+		BatchSize:   20,
+		ContextSize: 3,
+		MaxRetries:  2,
+		TMThreshold: 0.85,
+	}
+}
+
 type ListendInfo struct {
 	WS  ListendInfoDetails `json:"ws" yaml:"ws"`
 	MCP ListendInfoDetails `json:"mcp" yaml:"mcp"`
@@ -0,0 +1,43 @@
+package types
+
+import "fmt"
+
+// BilingualPosition 双语 ASS 中一种语言的位置
+type BilingualPosition string
+
+const (
+	BilingualPositionBottom BilingualPosition = "bottom" // 底部，两种语言都在底部时按上下顺序堆叠
+	BilingualPositionTop    BilingualPosition = "top"    // 顶部
+)
+
+// BilingualExportConfig 双语导出配置，ASS 中每种语言使用单独的样式
+type BilingualExportConfig struct {
+	// Styles 按语言配置样式，未配置时上方语言 48 号、下方语言 36 号，均在底部
+	Styles map[string]BilingualStyle `json:"styles,omitempty"`
+}
+
+// BilingualStyle 双语 ASS 中一种语言的样式
+type BilingualStyle struct {
+	FontName string            `json:"font_name,omitempty"`
+	FontSize float64           `json:"font_size,omitempty"`
+	Color    string            `json:"color,omitempty"` // #RRGGBB
+	Position BilingualPosition `json:"position,omitempty"`
+	MarginV  int               `json:"margin_v,omitempty"`
+}
+
+func (c *BilingualExportConfig) Validate() error {
+	for lang, style := range c.Styles {
+		switch style.Position {
+		case "", BilingualPositionBottom, BilingualPositionTop:
+		default:
+			return fmt.Errorf("language %s: invalid position %q", lang, style.Position)
+		}
+		if style.FontSize < 0 || style.MarginV < 0 {
+			return fmt.Errorf("language %s: font size and margin cannot be negative", lang)
+		}
+		if style.Color != "" && (len(style.Color) != 7 || style.Color[0] != '#') {
+			return fmt.Errorf("language %s: color must be #RRGGBB", lang)
+		}
+	}
+	return nil
+}
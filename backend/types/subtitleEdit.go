@@ -0,0 +1,21 @@
+package types
+
+// SegmentSplitRequest 拆分片段参数
+type SegmentSplitRequest struct {
+	// AtMs 拆分时间点（毫秒，须在片段内）；为 0 时按 Positions 中首个语言（按语言代码排序）的切分比例推算，仍缺省则取中点
+	AtMs int64 `json:"at_ms,omitempty"`
+	// Positions 各语言的切分字符位置（按 rune 计），未指定的语言按时间比例就近在断点处切分
+	Positions map[string]int `json:"positions,omitempty"`
+}
+
+// SegmentInsertRequest 插入片段参数
+type SegmentInsertRequest struct {
+	// AfterSegmentID 插入到该片段之后，为空时插入到开头
+	AfterSegmentID string `json:"after_segment_id,omitempty"`
+	// StartMs/EndMs 均为 0 时在前后片段之间的空隙中自动选取（最长 2 秒）
+	StartMs int64  `json:"start_ms,omitempty"`
+	EndMs   int64  `json:"end_ms,omitempty"`
+	Speaker string `json:"speaker,omitempty"`
+	// Texts 各语言文本，项目中未指定的语言写入空文本
+	Texts map[string]string `json:"texts,omitempty"`
+}
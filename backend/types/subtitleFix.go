@@ -0,0 +1,53 @@
+package types
+
+// AutoFixType 自动修复项
+type AutoFixType string
+
+const (
+	AutoFixReflow  AutoFixType = "reflow"  // 按行数与每行字符数重新断行
+	AutoFixExtend  AutoFixType = "extend"  // 延长过短的字幕
+	AutoFixSplit   AutoFixType = "split"   // 拆分过长的字幕
+	AutoFixGap     AutoFixType = "gap"     // 保证字幕间最小间隔
+	AutoFixOverlap AutoFixType = "overlap" // 消除时间重叠
+	AutoFixMerge   AutoFixType = "merge"   // 合并无法延长的过短字幕
+)
+
+// AutoFixOptions 自动修复参数，数值为 0 时使用默认值（Netflix 风格）
+type AutoFixOptions struct {
+	// Fixes 要执行的修复项，为空表示全部
+	Fixes []AutoFixType `json:"fixes,omitempty"`
+	// SegmentIDs 只修复这些片段（间隔与重叠会同时调整相邻片段），为空表示全部
+	SegmentIDs []string `json:"segment_ids,omitempty"`
+	DryRun     bool     `json:"dry_run"`
+
+	MaxLines int `json:"max_lines,omitempty"` // 默认 2
+	MaxCPL   int `json:"max_cpl,omitempty"`   // 每行字符数（含空格），默认 42
+	// MaxCPLIdeographic 中日韩文本的每行字符数，默认 16
+	MaxCPLIdeographic int   `json:"max_cpl_ideographic,omitempty"`
+	MinDurationMs     int64 `json:"min_duration_ms,omitempty"` // 默认 833（5/6 秒）
+	MaxDurationMs     int64 `json:"max_duration_ms,omitempty"` // 默认 7000
+	MinGapFrames      int   `json:"min_gap_frames,omitempty"`  // 默认 2
+	// MergeMaxGapMs 与相邻字幕间隔不超过该值才合并，默认 1000
+	MergeMaxGapMs int64 `json:"merge_max_gap_ms,omitempty"`
+}
+
+// AutoFixChange 一项修复；时间字段为修复前后的起止时间，Texts 为断行或拆分合并带来的文本变化
+type AutoFixChange struct {
+	Type        AutoFixType           `json:"type"`
+	SegmentIDs  []string              `json:"segment_ids"`          // 修复前的片段
+	ResultIDs   []string              `json:"result_ids,omitempty"` // 拆分/合并后生成的片段
+	FromStartMs int64                 `json:"from_start_ms"`
+	FromEndMs   int64                 `json:"from_end_ms"`
+	ToStartMs   int64                 `json:"to_start_ms"`
+	ToEndMs     int64                 `json:"to_end_ms"`
+	Texts       map[string]TextChange `json:"texts,omitempty"`
+	// Unresolved 已尽力但仍未满足要求（如空间不足无法延长、文本无法在限定行数内排下）
+	Unresolved bool `json:"unresolved,omitempty"`
+}
+
+// AutoFixReport 自动修复结果，DryRun 时项目不会被修改
+type AutoFixReport struct {
+	DryRun  bool                `json:"dry_run"`
+	Changes []AutoFixChange     `json:"changes"`
+	Summary map[AutoFixType]int `json:"summary"`
+}
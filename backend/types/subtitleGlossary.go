@@ -0,0 +1,75 @@
+package types
+
+// DefaultWorkspace 未指定工作区的项目、术语表与翻译记忆归入默认工作区
+const DefaultWorkspace = "default"
+
+// GlossaryFormat 术语表导入导出格式
+type GlossaryFormat string
+
+const (
+	GlossaryFormatTBX GlossaryFormat = "tbx"
+	GlossaryFormatCSV GlossaryFormat = "csv"
+)
+
+// Glossary 术语表，按工作区（如同一剧集）共享给该工作区内的所有项目
+type Glossary struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Workspace string `json:"workspace"`
+	// 语言为空表示适用于任意语言
+	SourceLang string         `json:"source_lang"`
+	TargetLang string         `json:"target_lang"`
+	Terms      []GlossaryTerm `json:"terms"`
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// GlossaryTerm 术语条目
+type GlossaryTerm struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// CaseSensitive 区分大小写匹配原文，并强制译文使用术语的大小写
+	CaseSensitive bool `json:"case_sensitive,omitempty"`
+	// DoNotTranslate 译文中必须原样保留原文术语（人名、品牌等）
+	DoNotTranslate bool   `json:"do_not_translate,omitempty"`
+	Note           string `json:"note,omitempty"`
+}
+
+// GlossaryIssueType 术语检查问题类型
+type GlossaryIssueType string
+
+const (
+	GlossaryIssueMissing    GlossaryIssueType = "missing"    // 原文含术语，译文缺少目标术语
+	GlossaryIssueTranslated GlossaryIssueType = "translated" // 不翻译的术语在译文中被改动
+)
+
+// GlossaryIssue 片段译文中未遵守术语表的位置
+type GlossaryIssue struct {
+	Type   GlossaryIssueType `json:"type"`
+	Source string            `json:"source"`
+	Target string            `json:"target"`
+}
+
+// TMEntry 翻译记忆条目，来自审核通过的片段
+type TMEntry struct {
+	ID         string `json:"id"`
+	Workspace  string `json:"workspace"`
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+
+	// 来源项目与片段（导入的条目为空）
+	ProjectID string `json:"project_id,omitempty"`
+	SegmentID string `json:"segment_id,omitempty"`
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// TMMatch 翻译记忆匹配结果
+type TMMatch struct {
+	Entry *TMEntry `json:"entry"`
+	Score float64  `json:"score"` // 0-1，1 为完全匹配
+}
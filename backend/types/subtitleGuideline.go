@@ -0,0 +1,53 @@
+package types
+
+// GuidelineLimits 一组字幕规范限制，数值为 0 表示不限制
+type GuidelineLimits struct {
+	MaxCPS        int   `json:"max_cps,omitempty"`         // 每秒字符数（不含空格）
+	MaxWPM        int   `json:"max_wpm,omitempty"`         // 每分钟词数
+	MaxCPL        int   `json:"max_cpl,omitempty"`         // 每行字符数（含空格）
+	MaxLines      int   `json:"max_lines,omitempty"`       // 每条字幕的行数
+	MinDurationMs int64 `json:"min_duration_ms,omitempty"` // 最短显示时长
+	MaxDurationMs int64 `json:"max_duration_ms,omitempty"` // 最长显示时长
+	MinGapFrames  int   `json:"min_gap_frames,omitempty"`  // 相邻字幕的最小间隔（帧）
+}
+
+// GuidelineRule 一种语言的限制，Kids 为儿童内容的覆盖值（为 0 的字段沿用 Limits）
+type GuidelineRule struct {
+	Limits GuidelineLimits  `json:"limits"`
+	Kids   *GuidelineLimits `json:"kids,omitempty"`
+}
+
+// GuidelineProfile 字幕规范配置；Languages 按语言代码（如 en、zh）覆盖 Default 中的非零字段
+type GuidelineProfile struct {
+	ID        GuideLineStandard        `json:"id"`
+	Name      string                   `json:"name"`
+	BuiltIn   bool                     `json:"built_in"`
+	Default   GuidelineRule            `json:"default"`
+	Languages map[string]GuidelineRule `json:"languages,omitempty"`
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// GuidelineRuleType 违反的规范项
+type GuidelineRuleType string
+
+const (
+	GuidelineRuleCPS         GuidelineRuleType = "cps"
+	GuidelineRuleWPM         GuidelineRuleType = "wpm"
+	GuidelineRuleCPL         GuidelineRuleType = "cpl"
+	GuidelineRuleLines       GuidelineRuleType = "lines"
+	GuidelineRuleMinDuration GuidelineRuleType = "min_duration"
+	GuidelineRuleMaxDuration GuidelineRuleType = "max_duration"
+	GuidelineRuleGap         GuidelineRuleType = "gap"
+)
+
+// GuidelineViolation 片段中超出规范的一项；时长与间隔以毫秒为单位
+type GuidelineViolation struct {
+	SegmentID string            `json:"segment_id"`
+	LangCode  string            `json:"lang_code"`
+	Profile   GuideLineStandard `json:"profile"`
+	Rule      GuidelineRuleType `json:"rule"`
+	Value     int64             `json:"value"`
+	Limit     int64             `json:"limit"`
+}
@@ -0,0 +1,55 @@
+package types
+
+// EditOperationType 可撤销的字幕编辑类型
+type EditOperationType string
+
+const (
+	EditUpdateSegment         EditOperationType = "update_segment"
+	EditUpdateLanguageContent EditOperationType = "update_language_content"
+	EditSplitSegment          EditOperationType = "split_segment"
+	EditMergeSegments         EditOperationType = "merge_segments"
+	EditInsertSegment         EditOperationType = "insert_segment"
+	EditDeleteSegments        EditOperationType = "delete_segments"
+	EditAutoFix               EditOperationType = "auto_fix"
+	EditTranslate             EditOperationType = "translate"
+	EditZHConvert             EditOperationType = "zhconvert"
+	EditTiming                EditOperationType = "timing"
+	EditApplyGuideline        EditOperationType = "apply_guideline"
+	EditApproveSegments       EditOperationType = "approve_segments"
+	EditRestoreSnapshot       EditOperationType = "restore_snapshot"
+	EditUpdateProject         EditOperationType = "update_project"
+)
+
+// SegmentChange 将从 Index 开始的 len(Before) 个片段替换为 After；交换 Before 与 After 即为逆操作
+type SegmentChange struct {
+	Index  int               `json:"index"`
+	Before []SubtitleSegment `json:"before,omitempty"`
+	After  []SubtitleSegment `json:"after,omitempty"`
+}
+
+// EditOperation 一次编辑，Changes 按顺序执行，撤销时逆序执行逆操作
+type EditOperation struct {
+	ID         string            `json:"id"`
+	Type       EditOperationType `json:"type"`
+	SegmentIDs []string          `json:"segment_ids,omitempty"` // 编辑前涉及的片段
+	LangCode   string            `json:"lang_code,omitempty"`
+	Changes    []SegmentChange   `json:"changes"`
+	CreatedAt  int64             `json:"created_at"`
+}
+
+// EditHistory 项目的操作日志：Operations[:Cursor] 可撤销，Operations[Cursor:] 可重做
+type EditHistory struct {
+	ProjectID  string          `json:"project_id"`
+	Operations []EditOperation `json:"operations"`
+	Cursor     int             `json:"cursor"`
+}
+
+// EditHistoryEntry 操作日志列表项（不含片段内容）
+type EditHistoryEntry struct {
+	ID         string            `json:"id"`
+	Type       EditOperationType `json:"type"`
+	SegmentIDs []string          `json:"segment_ids,omitempty"`
+	LangCode   string            `json:"lang_code,omitempty"`
+	CreatedAt  int64             `json:"created_at"`
+	Undone     bool              `json:"undone"` // 已撤销、可重做
+}
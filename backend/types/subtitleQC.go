@@ -0,0 +1,83 @@
+package types
+
+// QCSeverity 质检问题级别，存在 error 级问题时质检不通过
+type QCSeverity string
+
+const (
+	QCSeverityError   QCSeverity = "error"
+	QCSeverityWarning QCSeverity = "warning"
+	QCSeverityInfo    QCSeverity = "info"
+)
+
+// QCRuleType 质检规则
+type QCRuleType string
+
+const (
+	QCRuleMaxLines       QCRuleType = "max_lines"       // 每条字幕的行数
+	QCRuleMaxCPL         QCRuleType = "max_cpl"         // 每行字符数（含空格）
+	QCRuleMaxCPS         QCRuleType = "max_cps"         // 每秒字符数
+	QCRuleMinDuration    QCRuleType = "min_duration"    // 最短显示时长（毫秒）
+	QCRuleMaxDuration    QCRuleType = "max_duration"    // 最长显示时长（毫秒）
+	QCRuleMinGap         QCRuleType = "min_gap"         // 与下一条字幕的最小间隔（帧）
+	QCRuleOverlap        QCRuleType = "overlap"         // 与下一条字幕时间重叠
+	QCRuleForbiddenChars QCRuleType = "forbidden_chars" // 禁用字符（控制字符、替换字符、零宽字符及 Chars 中的字符）
+	QCRuleUnbalancedTags QCRuleType = "unbalanced_tags" // <i>、<b>、<u>、<font> 标签未配对
+	QCRuleEmptyText      QCRuleType = "empty_text"      // 缺少该语言的文本
+)
+
+// QCRule 一条质检规则。数值规则的 Limit 为 0 时使用片段所用字幕规范的限制
+type QCRule struct {
+	Type     QCRuleType `json:"type"`
+	Severity QCSeverity `json:"severity"`
+	Limit    int64      `json:"limit,omitempty"`
+	Chars    string     `json:"chars,omitempty"` // forbidden_chars 额外禁用的字符
+}
+
+// QCRuleSet 质检规则集
+type QCRuleSet struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	BuiltIn bool     `json:"built_in"`
+	Rules   []QCRule `json:"rules"`
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// QCFinding 一条字幕上的质检问题；时长、时间类规则的 LangCode 为空
+type QCFinding struct {
+	Index     int        `json:"index"` // 字幕序号，从 1 开始
+	SegmentID string     `json:"segment_id"`
+	StartMs   int64      `json:"start_ms"`
+	EndMs     int64      `json:"end_ms"`
+	LangCode  string     `json:"lang_code,omitempty"`
+	Rule      QCRuleType `json:"rule"`
+	Severity  QCSeverity `json:"severity"`
+	Value     int64      `json:"value"`
+	Limit     int64      `json:"limit,omitempty"`
+	Message   string     `json:"message"`
+	Text      string     `json:"text,omitempty"`
+}
+
+// QCReport 质检报告
+type QCReport struct {
+	ProjectID   string             `json:"project_id"`
+	ProjectName string             `json:"project_name"`
+	RuleSetID   string             `json:"rule_set_id"`
+	RuleSetName string             `json:"rule_set_name"`
+	Languages   []string           `json:"languages"`
+	Segments    int                `json:"segments"`
+	Findings    []QCFinding        `json:"findings"`
+	Summary     map[QCSeverity]int `json:"summary"`
+	Passed      bool               `json:"passed"`
+	CheckedAt   int64              `json:"checked_at"`
+}
+
+// QCReportFormat 质检报告导出格式
+type QCReportFormat string
+
+const (
+	QCReportJSON QCReportFormat = "json"
+	QCReportCSV  QCReportFormat = "csv"
+	QCReportHTML QCReportFormat = "html"
+)
@@ -0,0 +1,69 @@
+package types
+
+// SnapshotKind 快照来源
+type SnapshotKind string
+
+const (
+	SnapshotManual SnapshotKind = "manual"
+	SnapshotAuto   SnapshotKind = "auto" // 翻译、简繁转换、恢复等批量操作前自动创建
+)
+
+// ProjectSnapshot 项目在某一时刻的完整副本
+type ProjectSnapshot struct {
+	ID        string          `json:"id"`
+	ProjectID string          `json:"project_id"`
+	Name      string          `json:"name"`
+	Kind      SnapshotKind    `json:"kind"`
+	Project   SubtitleProject `json:"project"`
+	CreatedAt int64           `json:"created_at"`
+}
+
+// SnapshotInfo 快照列表项（不含项目内容）
+type SnapshotInfo struct {
+	ID        string       `json:"id"`
+	ProjectID string       `json:"project_id"`
+	Name      string       `json:"name"`
+	Kind      SnapshotKind `json:"kind"`
+	Segments  int          `json:"segments"`
+	Languages []string     `json:"languages"`
+	CreatedAt int64        `json:"created_at"`
+}
+
+// SegmentDiffType 片段差异类型
+type SegmentDiffType string
+
+const (
+	SegmentDiffAdded   SegmentDiffType = "added"
+	SegmentDiffRemoved SegmentDiffType = "removed"
+	SegmentDiffChanged SegmentDiffType = "changed"
+)
+
+// TextChange 某种语言的文本变化
+type TextChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// SegmentDiff 单个片段的差异；新增片段的 From* 与删除片段的 To* 为零值
+type SegmentDiff struct {
+	Type          SegmentDiffType       `json:"type"`
+	FromID        string                `json:"from_id,omitempty"`
+	ToID          string                `json:"to_id,omitempty"`
+	FromStartMs   int64                 `json:"from_start_ms"`
+	FromEndMs     int64                 `json:"from_end_ms"`
+	ToStartMs     int64                 `json:"to_start_ms"`
+	ToEndMs       int64                 `json:"to_end_ms"`
+	TimingChanged bool                  `json:"timing_changed,omitempty"`
+	Texts         map[string]TextChange `json:"texts,omitempty"` // 按语言，仅包含有变化的语言
+}
+
+// ProjectDiff 两个版本之间的片段级差异
+type ProjectDiff struct {
+	From      string        `json:"from"` // 快照 ID 或项目 ID，空表示项目当前状态
+	To        string        `json:"to"`
+	Added     int           `json:"added"`
+	Removed   int           `json:"removed"`
+	Changed   int           `json:"changed"`
+	Unchanged int           `json:"unchanged"`
+	Segments  []SegmentDiff `json:"segments"`
+}
@@ -0,0 +1,38 @@
+package types
+
+// TimingOperationType 项目级时间轴操作类型
+type TimingOperationType string
+
+const (
+	TimingOperationOffset    TimingOperationType = "offset"     // 整体或区间平移
+	TimingOperationStretch   TimingOperationType = "stretch"    // 两点线性拉伸
+	TimingOperationFrameRate TimingOperationType = "frame_rate" // 帧率转换（播放速度变化）
+)
+
+// TimingSyncPoint 同步点：原时间 FromMs 对齐到 ToMs（毫秒）
+type TimingSyncPoint struct {
+	FromMs int64 `json:"from_ms"`
+	ToMs   int64 `json:"to_ms"`
+}
+
+// TimingOperation 时间轴操作参数，应用后记录在项目的时间轴历史中
+type TimingOperation struct {
+	Type TimingOperationType `json:"type"`
+
+	// offset：平移毫秒数（可为负）；FromSegmentID/ToSegmentID 限定区间（含两端），为空表示从头/到尾
+	OffsetMs      int64  `json:"offset_ms,omitempty"`
+	FromSegmentID string `json:"from_segment_id,omitempty"`
+	ToSegmentID   string `json:"to_segment_id,omitempty"`
+
+	// stretch：两个同步点
+	First  *TimingSyncPoint `json:"first,omitempty"`
+	Second *TimingSyncPoint `json:"second,omitempty"`
+
+	// frame_rate：原帧率与目标帧率（23.976 等近似值按 x/1001 处理）
+	FromFPS float64 `json:"from_fps,omitempty"`
+	ToFPS   float64 `json:"to_fps,omitempty"`
+
+	// 以下由服务端填写
+	Segments  int   `json:"segments,omitempty"` // 受影响的片段数
+	AppliedAt int64 `json:"applied_at,omitempty"`
+}
@@ -157,6 +157,12 @@ type ProjectMetadata struct {
     // 关联来源（可选）：用于与下载任务建立双向关系
     OriginTaskID string `json:"origin_task_id,omitempty"`
 
+    // 工作区：同一工作区的项目共享术语表与翻译记忆，空为默认工作区
+    Workspace string `json:"workspace,omitempty"`
+
+    // 时间轴操作历史（最近的在后）
+    TimingHistory []TimingOperation `json:"timing_history,omitempty"`
+
     // 原始 ITT 信息（用于高保真还原导出）
     SourceITT *ITTSourceInfo `json:"source_itt,omitempty"`
 }
@@ -188,6 +194,8 @@ type ExportConfigs struct {
     ASS    *ASSExportConfig    `json:"ass,omitempty"`
     VTT    *VTTExportConfig    `json:"vtt,omitempty"`
     ITT    *ITTExportConfig    `json:"itt,omitempty"`
+    // Bilingual 双语导出（SRT/VTT/ASS）配置
+    Bilingual *BilingualExportConfig `json:"bilingual,omitempty"`
 }
 
 // FCPXMLExportConfig FCPXML导出配置（包含视频参数）
@@ -497,13 +505,21 @@ type LanguageContent struct {
     Style             *Style             `json:"style"`
     StyleID           string             `json:"style_id,omitempty"`
     RegionID          string             `json:"region_id,omitempty"`
+
+    // 审核通过的译文会写入翻译记忆
+    Approved bool `json:"approved,omitempty"`
+    // TMScore 译文由翻译记忆预填时的匹配度（1 为完全匹配）
+    TMScore float64 `json:"tm_score,omitempty"`
+    // TermIssues 未遵守术语表的位置，翻译/转换后生成
+    TermIssues []GlossaryIssue `json:"term_issues,omitempty"`
 }
 
 type SubtitleGuideline struct {
 	CPS *Guideline `json:"cps"` // Characters-per-second
 	WPM *Guideline `json:"wpm"` // Words-per-minute
 	CPL *Guideline `json:"cpl"` // Characters-per-line
-
+	// Lines 行数，旧数据中可能为空
+	Lines *Guideline `json:"lines,omitempty"`
 }
 
 type Guideline struct {
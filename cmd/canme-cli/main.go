@@ -0,0 +1,11 @@
+// canme-cli 是 CanMe 的命令行入口，可在没有图形界面的环境中下载视频、管理任务与字幕。
+package main
+
+import (
+	"CanMe/backend/cli"
+	"os"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:]))
+}
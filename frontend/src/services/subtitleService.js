@@ -432,9 +432,11 @@ export class SubtitleService {
    * @param {string} projectId - 项目ID
    * @param {string} languageCode - 语言代码
    * @param {string} format - 导出格式
+   * @param {Object} [formatConfig] - 导出前保存的格式配置
+   * @param {string} [secondLanguageCode] - 双语导出的第二语言（显示在下方，仅 SRT/VTT/ASS）
    * @returns {Promise<Object>} 导出结果
    */
-  async exportSubtitles(projectId, languageCode, format, formatConfig) {
+  async exportSubtitles(projectId, languageCode, format, formatConfig, secondLanguageCode = '') {
     if (!projectId || !languageCode || !format) {
       throw new Error('Project ID, language code and format are required');
     }
@@ -460,7 +462,7 @@ export class SubtitleService {
       }
 
       // 调用后端导出API
-      const result = await ExportSubtitleToFile(projectId, languageCode, format);
+      const result = await ExportSubtitleToFile(projectId, languageCode, format, secondLanguageCode || '');
       const cancelled = !!result?.data && result.data.cancelled === true;
 
       if (cancelled) {
@@ -4,6 +4,7 @@ import (
 	"CanMe/backend/api"
 	"CanMe/backend/consts"
 	"CanMe/backend/core/downtasks"
+	"CanMe/backend/core/hooks"
 	"CanMe/backend/core/imageproxies"
 	"CanMe/backend/core/subtitles"
 	"CanMe/backend/mcpserver"
@@ -12,6 +13,7 @@ import (
 	"CanMe/backend/pkg/logger"
 	"CanMe/backend/pkg/proxy"
 	"CanMe/backend/pkg/websockets"
+	"CanMe/backend/restapi"
 	"CanMe/backend/services/preferences"
 	"CanMe/backend/services/systems"
 	"CanMe/backend/storage"
@@ -69,7 +71,9 @@ func main() {
 	// # Imagesproxies
 	ipsService := imageproxies.NewService(proxyManager, boltStorage)
 	// # Subtitles
-	subtitlesService := subtitles.NewService(boltStorage, proxyManager, eventBus)
+	subtitlesService := subtitles.NewService(boltStorage, proxyManager, eventBus, preferencesService)
+	// # Hooks
+	hooksService := hooks.NewService(boltStorage, eventBus, dtService)
 
 	// Packages
 	// # Websocket
@@ -88,10 +92,14 @@ func main() {
 	dependenciesAPI := api.NewDependenciesAPI(dtService)
 	// # Cookies API (New)
 	cookiesAPI := api.NewCookiesAPI(dtService)
+	// # Hooks API
+	hooksAPI := api.NewHooksAPI(hooksService)
 
 	// MCP
 	// # MCP Server
 	mcpServer := mcpserver.NewService(dtService)
+	// # Local REST API
+	restAPI := restapi.NewService(dtService, subtitlesService, eventBus, preferencesService)
 
 	// window
 	windowWidth, windowHeight, maximised := preferencesService.GetWindowSize()
@@ -176,6 +184,7 @@ func main() {
 			subtitlesAPI,
 			dependenciesAPI,
 			cookiesAPI,
+			hooksAPI,
 		},
 		Logger: logger.NewWailsLogger(),
 		OnStartup: func(ctx context.Context) {
@@ -190,6 +199,7 @@ func main() {
 			dtService.SetContext(ctx)
 			ipsService.SetContext(ctx)
 			subtitlesService.SetContext(ctx)
+			hooksService.SetContext(ctx)
 			// APIs
 			dtAPI.Subscribe(ctx)
 			pathsAPI.Subscribe(ctx)
@@ -197,6 +207,11 @@ func main() {
 			subtitlesAPI.Subscribe(ctx)
 			dependenciesAPI.Subscribe(ctx)
 			cookiesAPI.WailsInit(ctx)
+			hooksAPI.Subscribe(ctx)
+			// Local REST API（须在 MCP 之前启动：MCP 服务会阻塞直到退出）
+			if err := restAPI.Start(ctx); err != nil {
+				logger.Error("Error starting local API", zap.Error(err))
+			}
 			// MCP
 			if err := mcpServer.Start(ctx); err != nil {
 				logger.Error("Error starting MCP server", zap.Error(err))
@@ -208,6 +223,7 @@ func main() {
 			wailsRuntime.WindowShow(ctx)
 		},
 		OnShutdown: func(ctx context.Context) {
+			restAPI.Stop()
 			// 关闭下载任务服务
 			if err := dtService.Close(); err != nil {
 				logger.Error("Error closing downtasks service", zap.Error(err))